@@ -15,6 +15,7 @@ func main() {
 	devMode := flag.Bool("dev", false, "Run in development mode")
 	port := flag.Int("port", 0, "Server port (default: 8080)")
 	dbPath := flag.String("db", "", "Database path (default: ./data/console.db)")
+	traceExporter := flag.String("trace-exporter", "", "Trace exporter: otlp, stdout or none (default: $OTEL_TRACES_EXPORTER)")
 	flag.Parse()
 
 	// Load config from environment
@@ -30,6 +31,9 @@ func main() {
 	if *dbPath != "" {
 		cfg.DatabasePath = *dbPath
 	}
+	if *traceExporter != "" {
+		cfg.TraceExporter = *traceExporter
+	}
 
 	// Ensure data directory exists
 	if cfg.DatabasePath != "" {
@@ -1,28 +1,124 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/kubestellar/console/pkg/agent"
 )
 
+const usage = `Usage: kkc-agent [command] [flags]
+
+Commands:
+  (none)      Run the agent in the foreground
+  install     Install and start kkc-agent as a systemd user service (Linux)
+  uninstall   Stop and remove the systemd user service
+  status      Show service and agent status
+  logs        Print the agent log (-f to follow)
+
+Run "kkc-agent <command> -h" for command flags.
+`
+
 func main() {
-	port := flag.Int("port", 8585, "Port to listen on")
-	kubeconfig := flag.String("kubeconfig", "", "Path to kubeconfig file")
-	version := flag.Bool("version", false, "Print version and exit")
-	flag.Parse()
+	if len(os.Args) > 1 {
+		var err error
+		switch os.Args[1] {
+		case "install":
+			err = runInstall(os.Args[2:])
+		case "uninstall":
+			err = runUninstall(os.Args[2:])
+		case "status":
+			err = runStatus(os.Args[2:])
+		case "logs":
+			err = runLogs(os.Args[2:])
+		case "help":
+			fmt.Print(usage)
+			return
+		default:
+			run(os.Args[1:])
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "kkc-agent %s: %v\n", os.Args[1], err)
+			code := 1
+			var exitErr *exitCodeError
+			if errors.As(err, &exitErr) {
+				code = exitErr.code
+			}
+			os.Exit(code)
+		}
+		return
+	}
+	run(nil)
+}
+
+// statusNotRunning is the LSB exit code of status for a service that is
+// not running
+const statusNotRunning = 3
+
+// exitCodeError is a command error that main exits with code for
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// runOptions holds the flags of the foreground agent
+type runOptions struct {
+	port         int
+	kubeconfig   string
+	consoleURL   string
+	consoleToken string
+	origins      string
+	logFile      string
+	drainTimeout time.Duration
+	noBanner     bool
+	version      bool
+}
+
+// newRunFlags defines the agent flags on a new flag set
+func newRunFlags(name string) (*flag.FlagSet, *runOptions) {
+	opts := &runOptions{}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.IntVar(&opts.port, "port", 8585, "Port to listen on")
+	fs.StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	fs.StringVar(&opts.consoleURL, "console-url", os.Getenv("KKC_CONSOLE_URL"), "Console URL to open a reverse tunnel to (opt-in)")
+	fs.StringVar(&opts.consoleToken, "console-token", os.Getenv("KKC_CONSOLE_TOKEN"), "Console API token used to authenticate the tunnel")
+	fs.StringVar(&opts.origins, "allowed-origins", os.Getenv("KKC_ALLOWED_ORIGINS"), "Comma-separated browser origins allowed to change the kubeconfig (default: local console)")
+	fs.StringVar(&opts.logFile, "log-file", agent.DefaultLogFile(), "Rotating log file (empty to log to stderr only)")
+	fs.DurationVar(&opts.drainTimeout, "drain-timeout", 15*time.Second, "Time to wait for in-flight requests on shutdown")
+	fs.BoolVar(&opts.noBanner, "no-banner", false, "Do not print the startup banner")
+	fs.BoolVar(&opts.version, "version", false, "Print version and exit")
+	return fs, opts
+}
 
-	if *version {
+func run(args []string) {
+	fs, opts := newRunFlags("kkc-agent")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage+"\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if opts.version {
 		fmt.Printf("kkc-agent version %s\n", agent.Version)
 		os.Exit(0)
 	}
 
-	fmt.Print(`
+	if !opts.noBanner {
+		fmt.Print(`
  _    _                                    _
 | | _| | _____       __ _  __ _  ___ _ __ | |_
 | |/ / |/ / __|____ / _` + "`" + ` |/ _` + "`" + ` |/ _ \ '_ \| __|
@@ -31,21 +127,155 @@ func main() {
                          |___/
 KubeStellar Klaude Console - Local Agent
 `)
+	}
+
+	if opts.consoleURL != "" && opts.consoleToken == "" {
+		log.Fatal("--console-token (or KKC_CONSOLE_TOKEN) is required with --console-url")
+	}
+
+	if opts.logFile != "" {
+		logFile, err := agent.NewRotatingFile(opts.logFile, agent.DefaultLogMaxSize, agent.DefaultLogBackups)
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		defer logFile.Close()
+		log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+	}
 
-	server, err := agent.NewServer(agent.Config{Port: *port, Kubeconfig: *kubeconfig})
+	pidFile, err := agent.AcquirePIDFile(agent.PIDFilePath(opts.port))
 	if err != nil {
+		log.Fatal(err)
+	}
+	defer pidFile.Release()
+
+	var origins []string
+	for _, origin := range strings.Split(opts.origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	server, err := agent.NewServer(agent.Config{
+		Port:           opts.port,
+		Kubeconfig:     opts.kubeconfig,
+		ConsoleURL:     opts.consoleURL,
+		ConsoleToken:   opts.consoleToken,
+		AllowedOrigins: origins,
+	})
+	if err != nil {
+		pidFile.Release()
 		log.Fatalf("Failed to create server: %v", err)
 	}
 
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- server.Start() }()
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			pidFile.Release()
+			log.Fatalf("Server error: %v", err)
+		}
+		return
+	case sig := <-sigChan:
+		log.Printf("Received %s, shutting down (drain timeout %s)...", sig, opts.drainTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.drainTimeout)
+	defer cancel()
+	// A second signal skips the drain
 	go func() {
 		<-sigChan
-		fmt.Println("\nShutting down...")
-		os.Exit(0)
+		log.Printf("Forced shutdown")
+		cancel()
 	}()
 
-	if err := server.Start(); err != nil {
-		log.Fatalf("Server error: %v", err)
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown incomplete: %v", err)
+	} else {
+		log.Printf("Shutdown complete")
+	}
+}
+
+func runInstall(args []string) error {
+	fs, opts := newRunFlags("kkc-agent install")
+	fs.Parse(args)
+	if opts.consoleURL != "" && opts.consoleToken == "" {
+		return errors.New("--console-token (or KKC_CONSOLE_TOKEN) is required with --console-url")
+	}
+
+	// Forward only the flags that were set explicitly. The console token
+	// goes into the unit's environment instead, since command lines are
+	// visible to every local user.
+	var serviceArgs []string
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "version", "no-banner":
+		case "console-token":
+			os.Setenv("KKC_CONSOLE_TOKEN", opts.consoleToken)
+		default:
+			serviceArgs = append(serviceArgs, fmt.Sprintf("--%s=%s", f.Name, f.Value))
+		}
+	})
+	serviceArgs = append(serviceArgs, "--no-banner")
+
+	path, err := agent.InstallService(serviceArgs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installed %s (%s)\n", agent.ServiceName, path)
+	fmt.Printf("Logs: kkc-agent logs -f\n")
+	return nil
+}
+
+func runUninstall(args []string) error {
+	fs := flag.NewFlagSet("kkc-agent uninstall", flag.ExitOnError)
+	fs.Parse(args)
+
+	path, err := agent.UninstallService()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s (%s)\n", agent.ServiceName, path)
+	return nil
+}
+
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("kkc-agent status", flag.ExitOnError)
+	port := fs.Int("port", 8585, "Agent port")
+	fs.Parse(args)
+
+	status := agent.GetServiceStatus(*port)
+	installed := "no"
+	if status.Installed {
+		installed = "yes"
 	}
+	fmt.Printf("Service:   %s (installed: %s, %s)\n", agent.ServiceName, installed, status.UnitFile)
+	if status.Active != "" {
+		fmt.Printf("Active:    %s\n", status.Active)
+	}
+	if status.PID != 0 {
+		fmt.Printf("PID:       %d\n", status.PID)
+	}
+	if status.Healthy {
+		fmt.Printf("Agent:     healthy on port %d (version %s, %d clusters)\n", *port, status.Version, status.Clusters)
+		return nil
+	}
+	fmt.Printf("Agent:     not responding on port %d\n", *port)
+	return &exitCodeError{code: statusNotRunning, err: errors.New("agent is not running")}
+}
+
+func runLogs(args []string) error {
+	fs := flag.NewFlagSet("kkc-agent logs", flag.ExitOnError)
+	follow := fs.Bool("f", false, "Follow the log")
+	lines := fs.Int("n", 100, "Number of lines to show (-1 for all)")
+	logFile := fs.String("log-file", agent.DefaultLogFile(), "Agent log file")
+	fs.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	return agent.TailLog(ctx, os.Stdout, *logFile, *lines, *follow)
 }
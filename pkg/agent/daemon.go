@@ -0,0 +1,229 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	// DefaultLogMaxSize is the size at which the agent log file is rotated
+	DefaultLogMaxSize = 10 << 20
+
+	// DefaultLogBackups is the number of rotated log files kept
+	DefaultLogBackups = 3
+)
+
+// StateDir returns the directory for agent runtime files (~/.kkc)
+func StateDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "kkc")
+	}
+	return filepath.Join(home, ".kkc")
+}
+
+// DefaultLogFile returns the default agent log file path
+func DefaultLogFile() string {
+	return filepath.Join(StateDir(), "agent.log")
+}
+
+// PIDFilePath returns the PID file used to guard the given port
+func PIDFilePath(port int) string {
+	return filepath.Join(StateDir(), fmt.Sprintf("agent-%d.pid", port))
+}
+
+// PIDFile is an exclusive, per-port lock held by a running agent
+type PIDFile struct {
+	path string
+}
+
+// AcquirePIDFile writes the current PID to path, failing if another live
+// agent already holds it. A PID file left behind by a dead process is
+// replaced.
+func AcquirePIDFile(path string) (*PIDFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, werr := fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			if werr != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write PID file: %w", werr)
+			}
+			return &PIDFile{path: path}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create PID file: %w", err)
+		}
+
+		pid, err := ReadPIDFile(path)
+		if err == nil && processAlive(pid) {
+			return nil, fmt.Errorf("another kkc-agent is already running (pid %d, %s)", pid, path)
+		}
+		// Stale PID file
+		os.Remove(path)
+	}
+	return nil, fmt.Errorf("failed to acquire PID file %s", path)
+}
+
+// Release removes the PID file
+func (p *PIDFile) Release() {
+	if p != nil {
+		os.Remove(p.path)
+	}
+}
+
+// ReadPIDFile returns the PID recorded in a PID file
+func ReadPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid PID file %s: %w", path, err)
+	}
+	return pid, nil
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// RotatingFile is an io.Writer that appends to a log file and rotates it
+// when it grows past maxSize, keeping up to backups old files
+// (agent.log.1 is the newest)
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// NewRotatingFile opens (or creates) a rotating log file
+func NewRotatingFile(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	r := &RotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+// Write implements io.Writer
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size+int64(len(p)) > r.maxSize && r.size > 0 {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) rotate() error {
+	r.file.Close()
+	for i := r.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.backups > 0 {
+		os.Rename(r.path, r.path+".1")
+	} else {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
+
+// Close closes the underlying file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// inflightTracker counts in-flight requests so shutdown can drain them.
+// Once draining starts, begin rejects new requests.
+type inflightTracker struct {
+	mu       sync.Mutex
+	draining bool
+	wg       sync.WaitGroup
+}
+
+// begin registers a request, returning false if the tracker is draining
+func (t *inflightTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+// done marks a request started with begin as finished
+func (t *inflightTracker) done() {
+	t.wg.Done()
+}
+
+// drain rejects new requests and waits for in-flight ones or ctx
+func (t *inflightTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,446 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// kubeconfigLockTimeout is how long we wait for another writer (kubectl or
+	// another agent) to release the kubeconfig lock file
+	kubeconfigLockTimeout = 5 * time.Second
+
+	// maxKubeconfigBackups is the number of backup files kept next to the kubeconfig
+	maxKubeconfigBackups = 10
+
+	// contextProbeTimeout bounds the reachability check for a single context
+	contextProbeTimeout = 5 * time.Second
+)
+
+// ErrContextNotFound is returned when a context does not exist in the kubeconfig
+var ErrContextNotFound = errors.New("context not found")
+
+// ErrInvalidKubeconfig is returned when an uploaded kubeconfig cannot be
+// merged
+var ErrInvalidKubeconfig = errors.New("invalid kubeconfig")
+
+// lockKubeconfig takes the same "<file>.lock" lock that kubectl uses when it
+// modifies a kubeconfig, so concurrent edits from either tool don't clobber
+// each other. The returned function releases the lock.
+func lockKubeconfig(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(kubeconfigLockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL, 0)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("kubeconfig is locked by another process (remove %s if this is stale)", lockPath)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// backupKubeconfig copies a kubeconfig to a timestamped backup file and
+// prunes old backups. It returns the backup path, or "" if there was nothing
+// to back up.
+func backupKubeconfig(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read kubeconfig for backup: %w", err)
+	}
+
+	backupPath := fmt.Sprintf("%s.kkc-backup-%s", path, time.Now().Format("20060102-150405.000"))
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write kubeconfig backup: %w", err)
+	}
+
+	// Prune old backups, keeping the newest maxKubeconfigBackups
+	backups, _ := filepath.Glob(path + ".kkc-backup-*")
+	if len(backups) > maxKubeconfigBackups {
+		sort.Strings(backups)
+		for _, old := range backups[:len(backups)-maxKubeconfigBackups] {
+			os.Remove(old)
+		}
+	}
+
+	return backupPath, nil
+}
+
+func init() {
+	// modifyConfig holds the kubectl lock on every file from load to write,
+	// so clientcmd.ModifyConfig must not try to take it again
+	clientcmd.UseModifyConfigLock = false
+}
+
+// modifyConfig loads the merged kubeconfig under lock, applies fn and writes
+// the result. When $KUBECONFIG lists several files each of them is locked and
+// backed up, and clientcmd.ModifyConfig writes every entry back to the file
+// it came from, as kubectl config does. The in-memory config is refreshed on
+// success. The backups are returned comma-separated.
+func (k *KubectlProxy) modifyConfig(fn func(config *api.Config) error) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// Lock in sorted order, like kubectl, so two writers cannot deadlock
+	files := k.pathOptions.GetLoadingPrecedence()
+	sort.Strings(files)
+	for _, path := range files {
+		unlock, err := lockKubeconfig(path)
+		if err != nil {
+			return "", err
+		}
+		defer unlock()
+	}
+
+	config, err := k.pathOptions.GetStartingConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	if err := fn(config); err != nil {
+		return "", err
+	}
+
+	var backups []string
+	for _, path := range files {
+		backup, err := backupKubeconfig(path)
+		if err != nil {
+			return strings.Join(backups, ","), err
+		}
+		if backup != "" {
+			backups = append(backups, backup)
+		}
+	}
+	backup := strings.Join(backups, ",")
+
+	if err := clientcmd.ModifyConfig(k.pathOptions, *config, true); err != nil {
+		return backup, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	k.config = config
+	if merged, err := k.loadingRules.Load(); err == nil {
+		k.config = merged
+	}
+	return backup, nil
+}
+
+// RenameContext renames a kubeconfig context
+func (k *KubectlProxy) RenameContext(oldName, newName string) (string, error) {
+	return k.modifyConfig(func(config *api.Config) error {
+		ctx, ok := config.Contexts[oldName]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrContextNotFound, oldName)
+		}
+		if _, exists := config.Contexts[newName]; exists {
+			return fmt.Errorf("context %s already exists", newName)
+		}
+
+		delete(config.Contexts, oldName)
+		config.Contexts[newName] = ctx
+		if config.CurrentContext == oldName {
+			config.CurrentContext = newName
+		}
+		return nil
+	})
+}
+
+// SwitchContext sets the kubeconfig current-context
+func (k *KubectlProxy) SwitchContext(name string) (string, error) {
+	return k.modifyConfig(func(config *api.Config) error {
+		if _, ok := config.Contexts[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrContextNotFound, name)
+		}
+		config.CurrentContext = name
+		return nil
+	})
+}
+
+// SetNamespace sets the default namespace of a context. An empty context name
+// targets the current context.
+func (k *KubectlProxy) SetNamespace(contextName, namespace string) (string, error) {
+	return k.modifyConfig(func(config *api.Config) error {
+		if contextName == "" {
+			contextName = config.CurrentContext
+		}
+		ctx, ok := config.Contexts[contextName]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrContextNotFound, contextName)
+		}
+		ctx.Namespace = namespace
+		return nil
+	})
+}
+
+// DeleteContext removes a context from the kubeconfig. Clusters and users
+// that are no longer referenced by any context are removed too.
+func (k *KubectlProxy) DeleteContext(name string) (string, error) {
+	return k.modifyConfig(func(config *api.Config) error {
+		ctx, ok := config.Contexts[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrContextNotFound, name)
+		}
+		delete(config.Contexts, name)
+		if config.CurrentContext == name {
+			config.CurrentContext = ""
+		}
+
+		clusterUsed, userUsed := false, false
+		for _, other := range config.Contexts {
+			clusterUsed = clusterUsed || other.Cluster == ctx.Cluster
+			userUsed = userUsed || other.AuthInfo == ctx.AuthInfo
+		}
+		if !clusterUsed {
+			delete(config.Clusters, ctx.Cluster)
+		}
+		if !userUsed {
+			delete(config.AuthInfos, ctx.AuthInfo)
+		}
+		return nil
+	})
+}
+
+// MergeKubeconfig merges the contexts, clusters and users of an uploaded
+// kubeconfig into the local one. Clusters and users whose names collide with
+// different content are renamed; colliding contexts are skipped unless
+// overwrite is set.
+func (k *KubectlProxy) MergeKubeconfig(data []byte, overwrite bool) (*protocol.MergeKubeconfigResponse, error) {
+	incoming, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKubeconfig, err)
+	}
+	if len(incoming.Contexts) == 0 {
+		return nil, fmt.Errorf("%w: no contexts", ErrInvalidKubeconfig)
+	}
+	// exec and auth-provider users run commands on this machine once their
+	// context is used, and file paths would have the agent read local files
+	// and send them to a server the uploader chose, so uploads may only
+	// carry inline credentials
+	var unsafe []string
+	for name, user := range incoming.AuthInfos {
+		if user.Exec != nil || user.AuthProvider != nil || user.TokenFile != "" || user.ClientCertificate != "" || user.ClientKey != "" {
+			unsafe = append(unsafe, "user/"+name)
+		}
+	}
+	for name, cluster := range incoming.Clusters {
+		if cluster.CertificateAuthority != "" {
+			unsafe = append(unsafe, "cluster/"+name)
+		}
+	}
+	if len(unsafe) > 0 {
+		sort.Strings(unsafe)
+		return nil, fmt.Errorf("%w: exec or auth-provider users and file paths cannot be uploaded, use the inline *-data fields: %s", ErrInvalidKubeconfig, strings.Join(unsafe, ", "))
+	}
+
+	resp := &protocol.MergeKubeconfigResponse{Added: []string{}, Renamed: map[string]string{}}
+	backup, err := k.modifyConfig(func(config *api.Config) error {
+		clusterNames := make(map[string]string)
+		for name, cluster := range incoming.Clusters {
+			clusterNames[name] = mergeEntry(config.Clusters, "cluster", name, cluster, resp)
+		}
+		userNames := make(map[string]string)
+		for name, user := range incoming.AuthInfos {
+			userNames[name] = mergeEntry(config.AuthInfos, "user", name, user, resp)
+		}
+
+		for name, ctx := range incoming.Contexts {
+			if existing, ok := config.Contexts[name]; ok && !overwrite {
+				if !sameEntry(existing, ctx) {
+					resp.Skipped = append(resp.Skipped, name)
+				}
+				continue
+			}
+			merged := ctx.DeepCopy()
+			if renamed, ok := clusterNames[ctx.Cluster]; ok {
+				merged.Cluster = renamed
+			}
+			if renamed, ok := userNames[ctx.AuthInfo]; ok {
+				merged.AuthInfo = renamed
+			}
+			config.Contexts[name] = merged
+			resp.Added = append(resp.Added, name)
+		}
+
+		if config.CurrentContext == "" {
+			config.CurrentContext = incoming.CurrentContext
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(resp.Added)
+	sort.Strings(resp.Skipped)
+	resp.Success = true
+	resp.Backup = backup
+	return resp, nil
+}
+
+// mergeEntry adds value to entries under name, picking a new name if an
+// entry with different content already exists. It returns the name used.
+func mergeEntry[T any](entries map[string]T, kind, name string, value T, resp *protocol.MergeKubeconfigResponse) string {
+	existing, ok := entries[name]
+	if !ok {
+		entries[name] = value
+		return name
+	}
+	if sameEntry(existing, value) {
+		return name
+	}
+
+	newName := name
+	for i := 2; ; i++ {
+		newName = fmt.Sprintf("%s-%d", name, i)
+		if _, taken := entries[newName]; !taken {
+			break
+		}
+	}
+	entries[newName] = value
+	resp.Renamed[kind+"/"+name] = newName
+	return newName
+}
+
+// sameEntry compares two kubeconfig entries ignoring where they were loaded from
+func sameEntry(a, b interface{}) bool {
+	switch x := a.(type) {
+	case *api.Cluster:
+		y := b.(*api.Cluster)
+		xc, yc := *x, *y
+		xc.LocationOfOrigin, yc.LocationOfOrigin = "", ""
+		return reflect.DeepEqual(xc, yc)
+	case *api.AuthInfo:
+		y := b.(*api.AuthInfo)
+		xc, yc := *x, *y
+		xc.LocationOfOrigin, yc.LocationOfOrigin = "", ""
+		return reflect.DeepEqual(xc, yc)
+	case *api.Context:
+		y := b.(*api.Context)
+		return x.Cluster == y.Cluster && x.AuthInfo == y.AuthInfo && x.Namespace == y.Namespace
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// CheckContexts reports contexts that duplicate each other (same server and
+// credentials) and, when probe is set, contexts whose API server is unreachable
+func (k *KubectlProxy) CheckContexts(ctx context.Context, probe bool) protocol.CheckContextsResponse {
+	k.mu.RLock()
+	config := k.config.DeepCopy()
+	k.mu.RUnlock()
+
+	resp := protocol.CheckContextsResponse{
+		Checked:     len(config.Contexts),
+		Duplicates:  []protocol.DuplicateContexts{},
+		Unreachable: []protocol.UnreachableContext{},
+	}
+
+	// Group contexts by server + user
+	groups := make(map[string][]string)
+	servers := make(map[string]string)
+	for name, c := range config.Contexts {
+		server := ""
+		if cluster, ok := config.Clusters[c.Cluster]; ok {
+			server = cluster.Server
+		}
+		servers[name] = server
+		key := server + "|" + c.AuthInfo + "|" + c.Namespace
+		groups[key] = append(groups[key], name)
+	}
+	for _, names := range groups {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		resp.Duplicates = append(resp.Duplicates, protocol.DuplicateContexts{
+			Server:   servers[names[0]],
+			User:     config.Contexts[names[0]].AuthInfo,
+			Contexts: names,
+		})
+	}
+	sort.Slice(resp.Duplicates, func(i, j int) bool {
+		return resp.Duplicates[i].Contexts[0] < resp.Duplicates[j].Contexts[0]
+	})
+
+	if !probe {
+		return resp
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for name := range config.Contexts {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := probeContext(ctx, config, name); err != nil {
+				mu.Lock()
+				resp.Unreachable = append(resp.Unreachable, protocol.UnreachableContext{
+					Context: name,
+					Server:  servers[name],
+					Error:   err.Error(),
+				})
+				mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	sort.Slice(resp.Unreachable, func(i, j int) bool {
+		return resp.Unreachable[i].Context < resp.Unreachable[j].Context
+	})
+	return resp
+}
+
+// probeContext checks that the context's API server answers /version. An
+// HTTP status error (e.g. 401) still proves the server is reachable.
+func probeContext(ctx context.Context, config *api.Config, contextName string) error {
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*config, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return err
+	}
+	restConfig.Timeout = contextProbeTimeout
+
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := dc.ServerVersion()
+		errCh <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		var statusErr *apierrors.StatusError
+		if err != nil && !errors.As(err, &statusErr) {
+			return errors.New(strings.TrimSpace(err.Error()))
+		}
+		return nil
+	}
+}
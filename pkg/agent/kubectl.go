@@ -2,10 +2,9 @@ package agent
 
 import (
 	"bytes"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/kubestellar/console/pkg/agent/protocol"
 	"k8s.io/client-go/tools/clientcmd"
@@ -13,28 +12,31 @@ import (
 )
 
 type KubectlProxy struct {
-	kubeconfig string
-	config     *api.Config
+	mu sync.RWMutex
+	// pathOptions finds the kubeconfig files edits are written to, as
+	// kubectl config does when $KUBECONFIG lists several files
+	pathOptions  *clientcmd.PathOptions
+	loadingRules *clientcmd.ClientConfigLoadingRules
+	config       *api.Config
 }
 
 func NewKubectlProxy(kubeconfig string) (*KubectlProxy, error) {
-	if kubeconfig == "" {
-		kubeconfig = os.Getenv("KUBECONFIG")
-	}
-	if kubeconfig == "" {
-		home, _ := os.UserHomeDir()
-		kubeconfig = filepath.Join(home, ".kube", "config")
-	}
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	pathOptions.LoadingRules.ExplicitPath = kubeconfig
 
-	config, err := clientcmd.LoadFromFile(kubeconfig)
-	if err != nil {
-		return &KubectlProxy{kubeconfig: kubeconfig, config: &api.Config{}}, nil
+	k := &KubectlProxy{
+		pathOptions:  pathOptions,
+		loadingRules: pathOptions.LoadingRules,
+		config:       api.NewConfig(),
 	}
-
-	return &KubectlProxy{kubeconfig: kubeconfig, config: config}, nil
+	k.Reload()
+	return k, nil
 }
 
 func (k *KubectlProxy) ListContexts() ([]protocol.ClusterInfo, string) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
 	var clusters []protocol.ClusterInfo
 	current := k.config.CurrentContext
 
@@ -54,8 +56,9 @@ func (k *KubectlProxy) ListContexts() ([]protocol.ClusterInfo, string) {
 
 func (k *KubectlProxy) Execute(context, namespace string, args []string) protocol.KubectlResponse {
 	cmdArgs := []string{}
-	if k.kubeconfig != "" {
-		cmdArgs = append(cmdArgs, "--kubeconfig", k.kubeconfig)
+	// Without an explicit file kubectl reads $KUBECONFIG itself
+	if k.loadingRules.ExplicitPath != "" {
+		cmdArgs = append(cmdArgs, "--kubeconfig", k.loadingRules.ExplicitPath)
 	}
 	if context != "" {
 		cmdArgs = append(cmdArgs, "--context", context)
@@ -105,36 +108,19 @@ func (k *KubectlProxy) validateArgs(args []string) bool {
 	return true
 }
 
-func (k *KubectlProxy) GetCurrentContext() string { return k.config.CurrentContext }
-
-// Reload reloads the kubeconfig from disk
-func (k *KubectlProxy) Reload() {
-	config, err := clientcmd.LoadFromFile(k.kubeconfig)
-	if err == nil {
-		k.config = config
-	}
+func (k *KubectlProxy) GetCurrentContext() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.config.CurrentContext
 }
 
-// RenameContext renames a kubeconfig context
-func (k *KubectlProxy) RenameContext(oldName, newName string) error {
-	cmdArgs := []string{"config", "rename-context", oldName, newName}
-	if k.kubeconfig != "" {
-		cmdArgs = append([]string{"--kubeconfig", k.kubeconfig}, cmdArgs...)
-	}
-
-	cmd := exec.Command("kubectl", cmdArgs...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return err
-	}
-
-	// Reload the config to reflect changes
-	config, err := clientcmd.LoadFromFile(k.kubeconfig)
+// Reload reloads the kubeconfig from disk, merging every file listed in
+// $KUBECONFIG
+func (k *KubectlProxy) Reload() {
+	config, err := k.loadingRules.Load()
 	if err == nil {
+		k.mu.Lock()
 		k.config = config
+		k.mu.Unlock()
 	}
-
-	return nil
 }
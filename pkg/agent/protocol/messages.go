@@ -5,11 +5,16 @@ type MessageType string
 
 const (
 	// Request types
-	TypeHealth        MessageType = "health"
-	TypeClusters      MessageType = "clusters"
-	TypeKubectl       MessageType = "kubectl"
-	TypeClaude        MessageType = "claude"
-	TypeRenameContext MessageType = "rename_context"
+	TypeHealth          MessageType = "health"
+	TypeClusters        MessageType = "clusters"
+	TypeKubectl         MessageType = "kubectl"
+	TypeClaude          MessageType = "claude"
+	TypeRenameContext   MessageType = "rename_context"
+	TypeSwitchContext   MessageType = "switch_context"
+	TypeSetNamespace    MessageType = "set_namespace"
+	TypeDeleteContext   MessageType = "delete_context"
+	TypeMergeKubeconfig MessageType = "merge_kubeconfig"
+	TypeCheckContexts   MessageType = "check_contexts"
 
 	// Response types
 	TypeResult MessageType = "result"
@@ -19,9 +24,9 @@ const (
 
 // Message is the base message structure for WebSocket communication
 type Message struct {
-	ID      string          `json:"id"`
-	Type    MessageType     `json:"type"`
-	Payload interface{}     `json:"payload,omitempty"`
+	ID      string      `json:"id"`
+	Type    MessageType `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
 }
 
 // HealthPayload is the response for health checks
@@ -113,4 +118,98 @@ type RenameContextResponse struct {
 	Success bool   `json:"success"`
 	OldName string `json:"oldName"`
 	NewName string `json:"newName"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+// SwitchContextRequest is the payload for changing the current context
+type SwitchContextRequest struct {
+	Context string `json:"context"`
+}
+
+// SetNamespaceRequest is the payload for setting a context's default namespace.
+// An empty context targets the current context.
+type SetNamespaceRequest struct {
+	Context   string `json:"context,omitempty"`
+	Namespace string `json:"namespace"`
+}
+
+// DeleteContextRequest is the payload for deleting a kubeconfig context
+type DeleteContextRequest struct {
+	Context string `json:"context"`
+}
+
+// ContextChangeResponse is the response from switch, set-namespace and delete
+type ContextChangeResponse struct {
+	Success   bool   `json:"success"`
+	Context   string `json:"context"`
+	Namespace string `json:"namespace,omitempty"`
+	Backup    string `json:"backup,omitempty"`
+}
+
+// MergeKubeconfigRequest is the payload for merging an uploaded kubeconfig
+type MergeKubeconfigRequest struct {
+	Kubeconfig string `json:"kubeconfig"`
+	Overwrite  bool   `json:"overwrite,omitempty"`
+}
+
+// MergeKubeconfigResponse is the response from merging a kubeconfig
+type MergeKubeconfigResponse struct {
+	Success bool              `json:"success"`
+	Added   []string          `json:"added"`
+	Skipped []string          `json:"skipped,omitempty"` // contexts that already exist with different settings
+	Renamed map[string]string `json:"renamed,omitempty"` // "cluster/<name>" or "user/<name>" -> new name
+	Backup  string            `json:"backup,omitempty"`
+}
+
+// CheckContextsResponse reports duplicate and unreachable kubeconfig contexts
+type CheckContextsResponse struct {
+	Checked     int                  `json:"checked"`
+	Duplicates  []DuplicateContexts  `json:"duplicates"`
+	Unreachable []UnreachableContext `json:"unreachable"`
+}
+
+// DuplicateContexts is a group of contexts pointing at the same server with the same credentials
+type DuplicateContexts struct {
+	Server   string   `json:"server"`
+	User     string   `json:"user"`
+	Contexts []string `json:"contexts"`
+}
+
+// UnreachableContext is a context whose API server could not be reached
+type UnreachableContext struct {
+	Context string `json:"context"`
+	Server  string `json:"server"`
+	Error   string `json:"error"`
+}
+
+// Tunnel message types exchanged between kkc-agent and the console over the
+// reverse tunnel WebSocket (agent dials out to /api/agent/tunnel)
+const (
+	TypeTunnelRegister MessageType = "tunnel_register"
+	TypeTunnelRequest  MessageType = "tunnel_request"
+	TypeTunnelResponse MessageType = "tunnel_response"
+)
+
+// TunnelRegisterPayload announces the agent and the clusters it can reach
+type TunnelRegisterPayload struct {
+	Hostname string        `json:"hostname"`
+	Version  string        `json:"version"`
+	Clusters []ClusterInfo `json:"clusters"`
+}
+
+// TunnelRequest is a Kubernetes API request forwarded from the console to the agent
+type TunnelRequest struct {
+	Context string              `json:"context"`
+	Method  string              `json:"method"`
+	Path    string              `json:"path"` // path and query, e.g. /api/v1/pods?limit=10
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    []byte              `json:"body,omitempty"`
+}
+
+// TunnelResponse is the agent's answer to a TunnelRequest
+type TunnelResponse struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    []byte              `json:"body,omitempty"`
+	Error   string              `json:"error,omitempty"`
 }
@@ -1,11 +1,16 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/kubestellar/console/pkg/agent/protocol"
@@ -17,6 +22,22 @@ const Version = "0.1.0"
 type Config struct {
 	Port       int
 	Kubeconfig string
+	// ConsoleURL enables the reverse tunnel: the agent dials the console at
+	// this URL so the hosted console can reach local clusters
+	ConsoleURL   string
+	ConsoleToken string
+	// AllowedOrigins are the browser origins allowed to change the
+	// kubeconfig and open WebSockets; the console URL is always allowed
+	AllowedOrigins []string
+}
+
+// DefaultAllowedOrigins are the origins of a console served locally, by the
+// backend or the Vite dev server
+var DefaultAllowedOrigins = []string{
+	"http://localhost:8080",
+	"http://127.0.0.1:8080",
+	"http://localhost:5174",
+	"http://127.0.0.1:5174",
 }
 
 // Server is the local agent WebSocket server
@@ -27,6 +48,13 @@ type Server struct {
 	claude     *ClaudeDetector
 	clients    map[*websocket.Conn]bool
 	clientsMux sync.RWMutex
+
+	origins      map[string]bool
+	httpServer   *http.Server
+	tunnel       *TunnelClient
+	tunnelCtx    context.Context
+	cancelTunnel context.CancelFunc
+	inflight     inflightTracker
 }
 
 // NewServer creates a new agent server
@@ -36,22 +64,62 @@ func NewServer(cfg Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to initialize kubectl proxy: %w", err)
 	}
 
-	return &Server{
-		config: cfg,
-		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				// Allow connections from any origin (localhost only)
-				return true
-			},
-		},
+	origins := make(map[string]bool)
+	allowed := cfg.AllowedOrigins
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedOrigins
+	}
+	for _, origin := range allowed {
+		origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	if cfg.ConsoleURL != "" {
+		u, err := url.Parse(cfg.ConsoleURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid console URL: %w", err)
+		}
+		origins[u.Scheme+"://"+u.Host] = true
+	}
+
+	s := &Server{
+		config:  cfg,
+		origins: origins,
 		kubectl: kubectl,
 		claude:  NewClaudeDetector(),
 		clients: make(map[*websocket.Conn]bool),
-	}, nil
+	}
+	s.upgrader = websocket.Upgrader{
+		// WebSocket messages can change the kubeconfig, so only the console
+		// may connect from a browser
+		CheckOrigin: func(r *http.Request) bool {
+			return s.originAllowed(r.Header.Get("Origin"))
+		},
+	}
+
+	// The HTTP server and tunnel are created here rather than in Start, so
+	// Shutdown can run at any time without racing it
+	s.httpServer = &http.Server{Addr: fmt.Sprintf("127.0.0.1:%d", cfg.Port), Handler: s.routes()}
+
+	// Opt-in reverse tunnel to the console
+	if cfg.ConsoleURL != "" {
+		tunnel, err := NewTunnelClient(cfg.ConsoleURL, cfg.ConsoleToken, kubectl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure console tunnel: %w", err)
+		}
+		s.tunnel = tunnel
+		s.tunnelCtx, s.cancelTunnel = context.WithCancel(context.Background())
+	}
+	return s, nil
 }
 
-// Start starts the agent server
-func (s *Server) Start() error {
+// originAllowed reports whether a browser origin may call the endpoints
+// that change the kubeconfig. Browsers send Origin on cross-origin POSTs
+// and WebSocket upgrades; requests without one come from local tools.
+func (s *Server) originAllowed(origin string) bool {
+	return origin == "" || s.origins[origin]
+}
+
+// routes returns the agent's HTTP handler
+func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
 
 	// Health endpoint (HTTP for easy browser detection)
@@ -60,8 +128,13 @@ func (s *Server) Start() error {
 	// Clusters endpoint - returns fresh kubeconfig contexts
 	mux.HandleFunc("/clusters", s.handleClustersHTTP)
 
-	// Rename context endpoint
+	// Kubeconfig context management endpoints
 	mux.HandleFunc("/rename-context", s.handleRenameContextHTTP)
+	mux.HandleFunc("/switch-context", s.handleSwitchContextHTTP)
+	mux.HandleFunc("/set-namespace", s.handleSetNamespaceHTTP)
+	mux.HandleFunc("/delete-context", s.handleDeleteContextHTTP)
+	mux.HandleFunc("/merge-kubeconfig", s.handleMergeKubeconfigHTTP)
+	mux.HandleFunc("/check-contexts", s.handleCheckContextsHTTP)
 
 	// WebSocket endpoint
 	mux.HandleFunc("/ws", s.handleWebSocket)
@@ -78,13 +151,56 @@ func (s *Server) Start() error {
 		}
 		http.NotFound(w, r)
 	})
+	return mux
+}
+
+// Start starts the agent server and blocks until it is shut down. A
+// Shutdown before Start makes it return immediately.
+func (s *Server) Start() error {
+	if s.tunnel != nil {
+		go s.tunnel.Run(s.tunnelCtx)
+	}
 
-	addr := fmt.Sprintf("127.0.0.1:%d", s.config.Port)
+	addr := s.httpServer.Addr
 	log.Printf("KKC Agent starting on %s", addr)
 	log.Printf("Health: http://%s/health", addr)
 	log.Printf("WebSocket: ws://%s/ws", addr)
 
-	return http.ListenAndServe(addr, mux)
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for in-flight HTTP
+// requests, WebSocket messages and tunnelled requests to finish, or for ctx
+// to expire
+func (s *Server) Shutdown(ctx context.Context) error {
+	var errs []error
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http: %w", err))
+	}
+	if err := s.inflight.drain(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("websocket: %w", err))
+	}
+	if s.tunnel != nil {
+		if err := s.tunnel.inflight.drain(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("tunnel: %w", err))
+		}
+		s.cancelTunnel()
+	}
+
+	// WebSocket connections are hijacked, so http.Server.Shutdown leaves them open
+	s.clientsMux.Lock()
+	for conn := range s.clients {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "agent shutting down"),
+			time.Now().Add(time.Second))
+		conn.Close()
+	}
+	s.clientsMux.Unlock()
+
+	return errors.Join(errs...)
 }
 
 // handleHealth handles HTTP health checks
@@ -134,44 +250,228 @@ func (s *Server) handleClustersHTTP(w http.ResponseWriter, r *http.Request) {
 
 // handleRenameContextHTTP renames a kubeconfig context
 func (s *Server) handleRenameContextHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if !s.setPostHeaders(w, r) {
+		return
+	}
+
+	var req protocol.RenameContextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "invalid_request", Message: "Invalid JSON"})
+		return
+	}
+
+	if req.OldName == "" || req.NewName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "invalid_names", Message: "Both oldName and newName required"})
+		return
+	}
+
+	backup, err := s.kubectl.RenameContext(req.OldName, req.NewName)
+	if err != nil {
+		writeKubeconfigError(w, "rename_failed", err)
+		return
+	}
+
+	log.Printf("Renamed context: %s -> %s", req.OldName, req.NewName)
+	json.NewEncoder(w).Encode(protocol.RenameContextResponse{Success: true, OldName: req.OldName, NewName: req.NewName, Backup: backup})
+}
+
+// setPostHeaders sets the CORS headers for POST endpoints, which only the
+// allowed origins may call, and handles method checks. It returns false if
+// the request has already been answered.
+func (s *Server) setPostHeaders(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Vary", "Origin")
+
+	origin := r.Header.Get("Origin")
+	if !s.originAllowed(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "origin_not_allowed", Message: "Origin " + origin + " may not change the kubeconfig"})
+		return false
+	}
+	if origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
 	w.Header().Set("Access-Control-Allow-Private-Network", "true")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
-		return
+		return false
 	}
 
 	if r.Method != "POST" {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "method_not_allowed", Message: "POST required"})
-		return
+		return false
 	}
+	return true
+}
 
-	var req protocol.RenameContextRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+// decodeRequest decodes a JSON request body, answering with 400 on failure
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "invalid_request", Message: "Invalid JSON"})
+		return false
+	}
+	return true
+}
+
+// writeKubeconfigError maps kubeconfig edit errors to an HTTP status
+func writeKubeconfigError(w http.ResponseWriter, code string, err error) {
+	if errors.Is(err, ErrContextNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+	} else if errors.Is(err, ErrInvalidKubeconfig) {
+		w.WriteHeader(http.StatusBadRequest)
+	} else {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: code, Message: err.Error()})
+}
+
+// handleSwitchContextHTTP changes the kubeconfig current-context
+func (s *Server) handleSwitchContextHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.setPostHeaders(w, r) {
 		return
 	}
 
-	if req.OldName == "" || req.NewName == "" {
+	var req protocol.SwitchContextRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Context == "" {
 		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "invalid_names", Message: "Both oldName and newName required"})
+		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "invalid_context", Message: "context required"})
 		return
 	}
 
-	if err := s.kubectl.RenameContext(req.OldName, req.NewName); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "rename_failed", Message: err.Error()})
+	resp, err := s.switchContext(req)
+	if err != nil {
+		writeKubeconfigError(w, "switch_failed", err)
 		return
 	}
+	json.NewEncoder(w).Encode(resp)
+}
 
-	log.Printf("Renamed context: %s -> %s", req.OldName, req.NewName)
-	json.NewEncoder(w).Encode(protocol.RenameContextResponse{Success: true, OldName: req.OldName, NewName: req.NewName})
+// handleSetNamespaceHTTP sets the default namespace of a context
+func (s *Server) handleSetNamespaceHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.setPostHeaders(w, r) {
+		return
+	}
+
+	var req protocol.SetNamespaceRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	resp, err := s.setNamespace(req)
+	if err != nil {
+		writeKubeconfigError(w, "set_namespace_failed", err)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleDeleteContextHTTP removes a context from the kubeconfig
+func (s *Server) handleDeleteContextHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.setPostHeaders(w, r) {
+		return
+	}
+
+	var req protocol.DeleteContextRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Context == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "invalid_context", Message: "context required"})
+		return
+	}
+
+	resp, err := s.deleteContext(req)
+	if err != nil {
+		writeKubeconfigError(w, "delete_failed", err)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleMergeKubeconfigHTTP merges an uploaded kubeconfig into the local one
+func (s *Server) handleMergeKubeconfigHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.setPostHeaders(w, r) {
+		return
+	}
+
+	var req protocol.MergeKubeconfigRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Kubeconfig == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(protocol.ErrorPayload{Code: "invalid_kubeconfig", Message: "kubeconfig required"})
+		return
+	}
+
+	resp, err := s.kubectl.MergeKubeconfig([]byte(req.Kubeconfig), req.Overwrite)
+	if err != nil {
+		writeKubeconfigError(w, "merge_failed", err)
+		return
+	}
+
+	log.Printf("Merged kubeconfig: added %v, skipped %v", resp.Added, resp.Skipped)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleCheckContextsHTTP reports duplicate and unreachable contexts.
+// Reachability probing can be disabled with ?probe=false.
+func (s *Server) handleCheckContextsHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Private-Network", "true")
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	s.kubectl.Reload()
+	probe := r.URL.Query().Get("probe") != "false"
+	json.NewEncoder(w).Encode(s.kubectl.CheckContexts(r.Context(), probe))
+}
+
+func (s *Server) switchContext(req protocol.SwitchContextRequest) (protocol.ContextChangeResponse, error) {
+	backup, err := s.kubectl.SwitchContext(req.Context)
+	if err != nil {
+		return protocol.ContextChangeResponse{}, err
+	}
+	log.Printf("Switched current context to %s", req.Context)
+	return protocol.ContextChangeResponse{Success: true, Context: req.Context, Backup: backup}, nil
+}
+
+func (s *Server) setNamespace(req protocol.SetNamespaceRequest) (protocol.ContextChangeResponse, error) {
+	backup, err := s.kubectl.SetNamespace(req.Context, req.Namespace)
+	if err != nil {
+		return protocol.ContextChangeResponse{}, err
+	}
+	contextName := req.Context
+	if contextName == "" {
+		contextName = s.kubectl.GetCurrentContext()
+	}
+	log.Printf("Set namespace of context %s to %q", contextName, req.Namespace)
+	return protocol.ContextChangeResponse{Success: true, Context: contextName, Namespace: req.Namespace, Backup: backup}, nil
+}
+
+func (s *Server) deleteContext(req protocol.DeleteContextRequest) (protocol.ContextChangeResponse, error) {
+	backup, err := s.kubectl.DeleteContext(req.Context)
+	if err != nil {
+		return protocol.ContextChangeResponse{}, err
+	}
+	log.Printf("Deleted context %s", req.Context)
+	return protocol.ContextChangeResponse{Success: true, Context: req.Context, Backup: backup}, nil
 }
 
 // handleWebSocket handles WebSocket connections
@@ -204,8 +504,14 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 
+		if !s.inflight.begin() {
+			conn.WriteJSON(s.errorResponse(msg.ID, "shutting_down", "Agent is shutting down"))
+			break
+		}
 		response := s.handleMessage(msg)
-		if err := conn.WriteJSON(response); err != nil {
+		err := conn.WriteJSON(response)
+		s.inflight.done()
+		if err != nil {
 			log.Printf("Write error: %v", err)
 			break
 		}
@@ -225,6 +531,9 @@ func (s *Server) handleMessage(msg protocol.Message) protocol.Message {
 		return s.handleKubectlMessage(msg)
 	case protocol.TypeClaude:
 		return s.handleClaudeMessage(msg)
+	case protocol.TypeRenameContext, protocol.TypeSwitchContext, protocol.TypeSetNamespace,
+		protocol.TypeDeleteContext, protocol.TypeMergeKubeconfig, protocol.TypeCheckContexts:
+		return s.handleKubeconfigMessage(msg)
 	default:
 		return protocol.Message{
 			ID:   msg.ID,
@@ -285,6 +594,62 @@ func (s *Server) handleKubectlMessage(msg protocol.Message) protocol.Message {
 	}
 }
 
+// handleKubeconfigMessage handles the kubeconfig editing message types
+func (s *Server) handleKubeconfigMessage(msg protocol.Message) protocol.Message {
+	payloadBytes, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return s.errorResponse(msg.ID, "invalid_payload", "Failed to parse request")
+	}
+
+	var result interface{}
+	switch msg.Type {
+	case protocol.TypeRenameContext:
+		var req protocol.RenameContextRequest
+		if err = json.Unmarshal(payloadBytes, &req); err == nil {
+			var backup string
+			backup, err = s.kubectl.RenameContext(req.OldName, req.NewName)
+			result = protocol.RenameContextResponse{Success: true, OldName: req.OldName, NewName: req.NewName, Backup: backup}
+		}
+	case protocol.TypeSwitchContext:
+		var req protocol.SwitchContextRequest
+		if err = json.Unmarshal(payloadBytes, &req); err == nil {
+			result, err = s.switchContext(req)
+		}
+	case protocol.TypeSetNamespace:
+		var req protocol.SetNamespaceRequest
+		if err = json.Unmarshal(payloadBytes, &req); err == nil {
+			result, err = s.setNamespace(req)
+		}
+	case protocol.TypeDeleteContext:
+		var req protocol.DeleteContextRequest
+		if err = json.Unmarshal(payloadBytes, &req); err == nil {
+			result, err = s.deleteContext(req)
+		}
+	case protocol.TypeMergeKubeconfig:
+		var req protocol.MergeKubeconfigRequest
+		if err = json.Unmarshal(payloadBytes, &req); err == nil {
+			result, err = s.kubectl.MergeKubeconfig([]byte(req.Kubeconfig), req.Overwrite)
+		}
+	case protocol.TypeCheckContexts:
+		s.kubectl.Reload()
+		result = s.kubectl.CheckContexts(context.Background(), true)
+	}
+
+	if err != nil {
+		code := "kubeconfig_error"
+		if errors.Is(err, ErrContextNotFound) {
+			code = "context_not_found"
+		}
+		return s.errorResponse(msg.ID, code, err.Error())
+	}
+
+	return protocol.Message{
+		ID:      msg.ID,
+		Type:    protocol.TypeResult,
+		Payload: result,
+	}
+}
+
 func (s *Server) handleClaudeMessage(msg protocol.Message) protocol.Message {
 	// TODO: Implement Claude Code integration
 	return s.errorResponse(msg.ID, "not_implemented", "Claude Code integration not yet implemented")
@@ -0,0 +1,260 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+)
+
+// ServiceName is the systemd user unit name of the agent
+const ServiceName = "kkc-agent.service"
+
+// serviceEnv lists environment variables copied into the unit at install time
+// so the service sees the same kubeconfig, kubectl and console settings as
+// the installing shell
+var serviceEnv = []string{"PATH", "KUBECONFIG", "KKC_CONSOLE_URL", "KKC_CONSOLE_TOKEN"}
+
+// ErrServiceUnsupported is returned when systemd user services are unavailable
+var ErrServiceUnsupported = errors.New("service management requires Linux with systemd")
+
+// ServiceStatus describes the installed service and the running agent
+type ServiceStatus struct {
+	UnitFile  string `json:"unitFile"`
+	Installed bool   `json:"installed"`
+	Active    string `json:"active,omitempty"`
+	PID       int    `json:"pid,omitempty"`
+	Healthy   bool   `json:"healthy"`
+	Version   string `json:"version,omitempty"`
+	Clusters  int    `json:"clusters,omitempty"`
+}
+
+// UnitFilePath returns the path of the systemd user unit
+func UnitFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user", ServiceName), nil
+}
+
+// GenerateUnit renders a systemd user unit that runs exe with args
+func GenerateUnit(exe string, args []string, env map[string]string) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=KubeStellar Klaude Console local agent\n")
+	b.WriteString("After=network-online.target\n\n")
+
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+	command := []string{systemdQuote(exe)}
+	for _, arg := range args {
+		command = append(command, systemdQuote(arg))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(command, " "))
+	for _, key := range serviceEnv {
+		if value, ok := env[key]; ok {
+			fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(key+"="+value))
+		}
+	}
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	b.WriteString("TimeoutStopSec=30\n\n")
+
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote quotes a word for use in a unit file, escaping specifier and
+// variable expansion
+func systemdQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$", "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// InstallService writes the unit for the current executable with the given
+// agent arguments, then enables and starts it
+func InstallService(args []string) (string, error) {
+	if err := checkSystemd(); err != nil {
+		return "", err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate kkc-agent binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	env := make(map[string]string)
+	for _, key := range serviceEnv {
+		if value, ok := os.LookupEnv(key); ok && value != "" {
+			env[key] = value
+		}
+	}
+
+	path, err := UnitFilePath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create unit directory: %w", err)
+	}
+	// The unit may carry the console token
+	if err := os.WriteFile(path, []byte(GenerateUnit(exe, args, env)), 0600); err != nil {
+		return "", fmt.Errorf("failed to write unit file: %w", err)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return path, err
+	}
+	if err := systemctl("enable", "--now", ServiceName); err != nil {
+		return path, err
+	}
+	// Pick up a changed unit if the service was already running
+	if err := systemctl("restart", ServiceName); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// UninstallService stops and disables the service and removes its unit
+func UninstallService() (string, error) {
+	if err := checkSystemd(); err != nil {
+		return "", err
+	}
+	path, err := UnitFilePath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path, fmt.Errorf("%s is not installed", ServiceName)
+	}
+
+	if err := systemctl("disable", "--now", ServiceName); err != nil {
+		return path, err
+	}
+	if err := os.Remove(path); err != nil {
+		return path, fmt.Errorf("failed to remove unit file: %w", err)
+	}
+	return path, systemctl("daemon-reload")
+}
+
+// GetServiceStatus reports the service state and probes the agent on port
+func GetServiceStatus(port int) ServiceStatus {
+	var status ServiceStatus
+	if path, err := UnitFilePath(); err == nil {
+		status.UnitFile = path
+		_, err := os.Stat(path)
+		status.Installed = err == nil
+	}
+	if checkSystemd() == nil {
+		out, _ := exec.Command("systemctl", "--user", "is-active", ServiceName).Output()
+		status.Active = strings.TrimSpace(string(out))
+	}
+	if pid, err := ReadPIDFile(PIDFilePath(port)); err == nil && processAlive(pid) {
+		status.PID = pid
+	}
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/health", port))
+	if err == nil {
+		defer resp.Body.Close()
+		var health protocol.HealthPayload
+		if json.NewDecoder(resp.Body).Decode(&health) == nil && health.Status == "ok" {
+			status.Healthy = true
+			status.Version = health.Version
+			status.Clusters = health.Clusters
+		}
+	}
+	return status
+}
+
+// TailLog writes the last n lines of the log file to w. With follow set it
+// keeps writing appended lines until ctx is cancelled, reopening the file
+// when it is rotated.
+func TailLog(ctx context.Context, w io.Writer, path string, n int, follow bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if n >= 0 && len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	if !follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+
+		// Rotation renames the file; continue with the new one
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if current, err := f.Stat(); err == nil && !os.SameFile(info, current) {
+			if nf, err := os.Open(path); err == nil {
+				f.Close()
+				f = nf
+			}
+		}
+	}
+}
+
+// checkSystemd verifies that systemd user services can be managed
+func checkSystemd() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("%w (running on %s)", ErrServiceUnsupported, runtime.GOOS)
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return fmt.Errorf("%w: systemctl not found", ErrServiceUnsupported)
+	}
+	return nil
+}
+
+// systemctl runs a systemctl --user command
+func systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
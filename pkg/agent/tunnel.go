@@ -0,0 +1,318 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// tunnelPath is the console endpoint the agent dials
+	tunnelPath = "/api/agent/tunnel"
+
+	// maxTunnelBodySize caps a single forwarded API response
+	maxTunnelBodySize = 64 << 20
+
+	tunnelMinBackoff       = 1 * time.Second
+	tunnelMaxBackoff       = 60 * time.Second
+	tunnelRegisterInterval = 30 * time.Second
+	tunnelRequestTimeout   = 30 * time.Second
+)
+
+// TunnelClient keeps an outbound WebSocket to the console open so that the
+// console can reach clusters that only exist in the local kubeconfig
+type TunnelClient struct {
+	consoleURL string
+	token      string
+	kubectl    *KubectlProxy
+
+	writeMu sync.Mutex
+	conn    *websocket.Conn
+
+	clientsMu sync.Mutex
+	clients   map[string]*tunnelTarget
+
+	inflight inflightTracker
+}
+
+// tunnelTarget is an authenticated HTTP client for one kubeconfig context
+type tunnelTarget struct {
+	host   string
+	client *http.Client
+}
+
+// NewTunnelClient creates a tunnel client for the given console URL
+// (http(s):// or ws(s)://) and console API token
+func NewTunnelClient(consoleURL, token string, kubectl *KubectlProxy) (*TunnelClient, error) {
+	u, err := url.Parse(consoleURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid console URL: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	case "ws", "wss":
+	default:
+		return nil, fmt.Errorf("unsupported console URL scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + tunnelPath
+
+	return &TunnelClient{
+		consoleURL: u.String(),
+		token:      token,
+		kubectl:    kubectl,
+		clients:    make(map[string]*tunnelTarget),
+	}, nil
+}
+
+// Run connects to the console and serves forwarded requests until ctx is
+// cancelled, reconnecting with exponential backoff
+func (t *TunnelClient) Run(ctx context.Context) {
+	backoff := tunnelMinBackoff
+	for {
+		connected, err := t.runOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = tunnelMinBackoff
+		}
+		log.Printf("Console tunnel disconnected: %v (retrying in %s)", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > tunnelMaxBackoff {
+			backoff = tunnelMaxBackoff
+		}
+	}
+}
+
+// runOnce dials the console and serves requests until the connection drops.
+// It reports whether the connection was established.
+func (t *TunnelClient) runOnce(ctx context.Context) (bool, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+t.token)
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, t.consoleURL, header)
+	if err != nil {
+		if resp != nil {
+			return false, fmt.Errorf("dial %s: %w (HTTP %d)", t.consoleURL, err, resp.StatusCode)
+		}
+		return false, fmt.Errorf("dial %s: %w", t.consoleURL, err)
+	}
+	defer conn.Close()
+
+	t.writeMu.Lock()
+	t.conn = conn
+	t.writeMu.Unlock()
+
+	// Credentials may have changed since the last connection
+	t.clientsMu.Lock()
+	t.clients = make(map[string]*tunnelTarget)
+	t.clientsMu.Unlock()
+
+	registered, err := t.register(nil)
+	if err != nil {
+		return true, err
+	}
+	log.Printf("Console tunnel connected to %s (%d clusters)", t.consoleURL, len(registered))
+
+	// Re-register periodically so kubeconfig edits reach the console
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(tunnelRegisterInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-ticker.C:
+				var regErr error
+				if registered, regErr = t.register(registered); regErr != nil {
+					log.Printf("Console tunnel re-register failed: %v", regErr)
+				}
+			}
+		}
+	}()
+
+	for {
+		var msg struct {
+			ID      string               `json:"id"`
+			Type    protocol.MessageType `json:"type"`
+			Payload json.RawMessage      `json:"payload"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return true, err
+		}
+		if msg.Type != protocol.TypeTunnelRequest {
+			continue
+		}
+
+		var req protocol.TunnelRequest
+		if err := json.Unmarshal(msg.Payload, &req); err != nil {
+			t.write(protocol.Message{ID: msg.ID, Type: protocol.TypeTunnelResponse, Payload: protocol.TunnelResponse{Error: "invalid tunnel request"}})
+			continue
+		}
+		if !t.inflight.begin() {
+			t.write(protocol.Message{ID: msg.ID, Type: protocol.TypeTunnelResponse, Payload: protocol.TunnelResponse{Error: "agent shutting down"}})
+			continue
+		}
+		go func(id string, req protocol.TunnelRequest) {
+			defer t.inflight.done()
+			resp := t.forward(ctx, req)
+			t.write(protocol.Message{ID: id, Type: protocol.TypeTunnelResponse, Payload: resp})
+		}(msg.ID, req)
+	}
+}
+
+// register sends the current cluster list to the console if it differs from
+// the previously registered one and returns the list that is now registered
+func (t *TunnelClient) register(previous []protocol.ClusterInfo) ([]protocol.ClusterInfo, error) {
+	t.kubectl.Reload()
+	clusters, _ := t.kubectl.ListContexts()
+	if previous != nil && reflect.DeepEqual(clusterKeys(previous), clusterKeys(clusters)) {
+		return previous, nil
+	}
+
+	hostname, _ := os.Hostname()
+	err := t.write(protocol.Message{
+		Type: protocol.TypeTunnelRegister,
+		Payload: protocol.TunnelRegisterPayload{
+			Hostname: hostname,
+			Version:  Version,
+			Clusters: clusters,
+		},
+	})
+	if err != nil {
+		return previous, err
+	}
+
+	// Drop cached clients so changed credentials are picked up
+	t.clientsMu.Lock()
+	t.clients = make(map[string]*tunnelTarget)
+	t.clientsMu.Unlock()
+	return clusters, nil
+}
+
+// clusterKeys returns an order-independent representation of a cluster list
+func clusterKeys(clusters []protocol.ClusterInfo) map[string]string {
+	keys := make(map[string]string, len(clusters))
+	for _, c := range clusters {
+		keys[c.Context] = c.Server + "|" + c.Namespace
+	}
+	return keys
+}
+
+func (t *TunnelClient) write(msg protocol.Message) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if t.conn == nil {
+		return fmt.Errorf("not connected")
+	}
+	return t.conn.WriteJSON(msg)
+}
+
+// forward executes a tunnelled request against the cluster for req.Context
+// using the local kubeconfig credentials
+func (t *TunnelClient) forward(ctx context.Context, req protocol.TunnelRequest) protocol.TunnelResponse {
+	target, err := t.target(req.Context)
+	if err != nil {
+		return protocol.TunnelResponse{Error: err.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tunnelRequestTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, target.host+req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		return protocol.TunnelResponse{Error: err.Error()}
+	}
+	for k, v := range req.Headers {
+		// Credentials always come from the local kubeconfig
+		if strings.EqualFold(k, "Authorization") {
+			continue
+		}
+		httpReq.Header[k] = v
+	}
+
+	resp, err := target.client.Do(httpReq)
+	if err != nil {
+		return protocol.TunnelResponse{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	// Read one byte past the cap so oversized responses fail instead of
+	// being forwarded truncated
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTunnelBodySize+1))
+	if err != nil {
+		return protocol.TunnelResponse{Error: err.Error()}
+	}
+	if len(body) > maxTunnelBodySize {
+		return protocol.TunnelResponse{Error: fmt.Sprintf("response exceeds the %d MiB tunnel limit; narrow the request with a namespace, selector or limit", maxTunnelBodySize>>20)}
+	}
+
+	return protocol.TunnelResponse{
+		Status:  resp.StatusCode,
+		Headers: resp.Header,
+		Body:    body,
+	}
+}
+
+// target returns (and caches) the HTTP client for a kubeconfig context
+func (t *TunnelClient) target(contextName string) (*tunnelTarget, error) {
+	t.clientsMu.Lock()
+	defer t.clientsMu.Unlock()
+
+	if target, ok := t.clients[contextName]; ok {
+		return target, nil
+	}
+
+	t.kubectl.mu.RLock()
+	config := t.kubectl.config.DeepCopy()
+	t.kubectl.mu.RUnlock()
+
+	if _, ok := config.Contexts[contextName]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrContextNotFound, contextName)
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*config, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config for context %s: %w", contextName, err)
+	}
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for context %s: %w", contextName, err)
+	}
+
+	target := &tunnelTarget{
+		host:   strings.TrimSuffix(restConfig.Host, "/"),
+		client: httpClient,
+	}
+	t.clients[contextName] = target
+	return target, nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// AgentTunnelHandler accepts reverse tunnels from kkc-agent instances and
+// registers the clusters they expose with the multi-cluster client
+type AgentTunnelHandler struct {
+	k8sClient *k8s.MultiClusterClient
+}
+
+// NewAgentTunnelHandler creates a new agent tunnel handler
+func NewAgentTunnelHandler(k8sClient *k8s.MultiClusterClient) *AgentTunnelHandler {
+	return &AgentTunnelHandler{k8sClient: k8sClient}
+}
+
+// HandleConnection serves one agent tunnel until the agent disconnects
+func (h *AgentTunnelHandler) HandleConnection(conn *websocket.Conn) {
+	if h.k8sClient == nil {
+		conn.WriteJSON(Message{Type: "error", Data: "Kubernetes client not available"})
+		conn.Close()
+		return
+	}
+
+	ownerID, _ := conn.Locals("userID").(uuid.UUID)
+	owner, _ := conn.Locals("githubLogin").(string)
+	tunnel := k8s.NewAgentTunnel(uuid.New().String(), ownerID.String(), owner, conn.WriteJSON)
+	log.Printf("Agent tunnel connected: %s (user %s)", tunnel.ID, owner)
+
+	defer func() {
+		h.k8sClient.UnregisterAgent(tunnel)
+		conn.Close()
+		log.Printf("Agent tunnel disconnected: %s", tunnel.ID)
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("Agent tunnel error: %v", err)
+			}
+			return
+		}
+
+		if _, err := h.k8sClient.HandleAgentFrame(tunnel, message); err != nil {
+			log.Printf("Agent tunnel %s: %v", tunnel.ID, err)
+		}
+	}
+}
+
+// ListAgents returns the current user's connected agents and the clusters
+// they expose
+func (h *AgentTunnelHandler) ListAgents(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+	return c.JSON(fiber.Map{"agents": h.k8sClient.ListAgents(c.UserContext())})
+}
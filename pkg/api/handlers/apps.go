@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// AppHandler serves applications grouped from the workloads of all clusters
+type AppHandler struct {
+	k8sClient *k8s.MultiClusterClient
+}
+
+// NewAppHandler creates a new application handler
+func NewAppHandler(k8sClient *k8s.MultiClusterClient) *AppHandler {
+	return &AppHandler{k8sClient: k8sClient}
+}
+
+// ListApps returns the applications found across clusters as a list (see
+// list.go). Filter with fieldSelector on health, source, namespace or
+// drifted, e.g. drifted=true for apps whose image versions differ between
+// clusters.
+func (h *AppHandler) ListApps(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	apps, status, err := h.applications(c)
+	if err != nil {
+		return err
+	}
+	return listResponse(c, p, apps, fiber.Map{
+		"clusterCount":  status.Clusters,
+		"clusterErrors": status.Errors,
+	})
+}
+
+// GetApp returns one application with its workloads in each cluster.
+// ?namespace= and ?source= pick among apps sharing a name; without them
+// such a name answers 409 Conflict.
+func (h *AppHandler) GetApp(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	name := c.Params("name")
+	namespace, namespaceSet := c.Queries()["namespace"]
+	source := c.Query("source")
+	apps, status, err := h.applications(c)
+	if err != nil {
+		return err
+	}
+
+	var matches []k8s.Application
+	for _, app := range apps {
+		if app.Name != name || (namespaceSet && app.Namespace != namespace) || (source != "" && app.Source != source) {
+			continue
+		}
+		matches = append(matches, app)
+	}
+	switch len(matches) {
+	case 0:
+		return fiber.NewError(fiber.StatusNotFound, "Application not found")
+	case 1:
+		return c.JSON(fiber.Map{
+			"app":           matches[0],
+			"clusterCount":  status.Clusters,
+			"clusterErrors": status.Errors,
+		})
+	}
+	return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("%d applications are named %s; pick one with namespace and source", len(matches), name))
+}
+
+// applications groups the workloads of every cluster into applications
+func (h *AppHandler) applications(c *fiber.Ctx) ([]k8s.Application, k8s.FanOutStatus, error) {
+	result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.ClusterWorkloads, error) {
+		workloads, err := h.k8sClient.GetClusterWorkloads(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		return []k8s.ClusterWorkloads{*workloads}, nil
+	})
+	if err != nil {
+		return nil, k8s.FanOutStatus{}, fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+	}
+	return k8s.GroupApplications(result.Items), result.FanOutStatus, nil
+}
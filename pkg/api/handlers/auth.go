@@ -110,7 +110,7 @@ func (h *AuthHandler) devModeLogin(c *fiber.Ctx) error {
 	}
 
 	// Find or create dev user
-	user, err := h.store.GetUserByGitHubID(devGitHubID)
+	user, err := h.store.GetUserByGitHubID(c.UserContext(), devGitHubID)
 	if err != nil {
 		return c.Redirect(h.frontendURL+"/login?error=db_error", fiber.StatusTemporaryRedirect)
 	}
@@ -143,7 +143,7 @@ func (h *AuthHandler) devModeLogin(c *fiber.Ctx) error {
 			AvatarURL:   avatarURL,
 			Onboarded:   true, // Skip onboarding in dev mode
 		}
-		if err := h.store.CreateUser(user); err != nil {
+		if err := h.store.CreateUser(c.UserContext(), user); err != nil {
 			return c.Redirect(h.frontendURL+"/login?error=create_user_failed", fiber.StatusTemporaryRedirect)
 		}
 	} else {
@@ -151,11 +151,11 @@ func (h *AuthHandler) devModeLogin(c *fiber.Ctx) error {
 		user.GitHubLogin = devLogin
 		user.Email = devEmail
 		user.AvatarURL = avatarURL
-		h.store.UpdateUser(user)
+		h.store.UpdateUser(c.UserContext(), user)
 	}
 
 	// Update last login
-	h.store.UpdateLastLogin(user.ID)
+	h.store.UpdateLastLogin(c.UserContext(), user.ID)
 
 	// Generate JWT
 	jwtToken, err := h.generateJWT(user)
@@ -190,7 +190,7 @@ func (h *AuthHandler) GitHubCallback(c *fiber.Ctx) error {
 	}
 
 	// Find or create user
-	user, err := h.store.GetUserByGitHubID(fmt.Sprintf("%d", ghUser.ID))
+	user, err := h.store.GetUserByGitHubID(c.UserContext(), fmt.Sprintf("%d", ghUser.ID))
 	if err != nil {
 		log.Printf("[Auth] Database error getting user: %v", err)
 		return c.Redirect(h.frontendURL+"/login?error=db_error", fiber.StatusTemporaryRedirect)
@@ -204,7 +204,7 @@ func (h *AuthHandler) GitHubCallback(c *fiber.Ctx) error {
 			Email:       ghUser.Email,
 			AvatarURL:   ghUser.AvatarURL,
 		}
-		if err := h.store.CreateUser(user); err != nil {
+		if err := h.store.CreateUser(c.UserContext(), user); err != nil {
 			return c.Redirect(h.frontendURL+"/login?error=create_user_failed", fiber.StatusTemporaryRedirect)
 		}
 	} else {
@@ -212,11 +212,11 @@ func (h *AuthHandler) GitHubCallback(c *fiber.Ctx) error {
 		user.GitHubLogin = ghUser.Login
 		user.Email = ghUser.Email
 		user.AvatarURL = ghUser.AvatarURL
-		h.store.UpdateUser(user)
+		h.store.UpdateUser(c.UserContext(), user)
 	}
 
 	// Update last login
-	h.store.UpdateLastLogin(user.ID)
+	h.store.UpdateLastLogin(c.UserContext(), user.ID)
 
 	// Generate JWT
 	jwtToken, err := h.generateJWT(user)
@@ -252,7 +252,7 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	}
 
 	// Get fresh user data
-	user, err := h.store.GetUser(claims.UserID)
+	user, err := h.store.GetUser(c.UserContext(), claims.UserID)
 	if err != nil || user == nil {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not found")
 	}
@@ -29,7 +29,7 @@ func (h *CardHandler) ListCards(c *fiber.Ctx) error {
 	}
 
 	// Verify ownership
-	dashboard, err := h.store.GetDashboard(dashboardID)
+	dashboard, err := h.store.GetDashboard(c.UserContext(), dashboardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
 	}
@@ -37,7 +37,7 @@ func (h *CardHandler) ListCards(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusForbidden, "Access denied")
 	}
 
-	cards, err := h.store.GetDashboardCards(dashboardID)
+	cards, err := h.store.GetDashboardCards(c.UserContext(), dashboardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list cards")
 	}
@@ -53,7 +53,7 @@ func (h *CardHandler) CreateCard(c *fiber.Ctx) error {
 	}
 
 	// Verify ownership
-	dashboard, err := h.store.GetDashboard(dashboardID)
+	dashboard, err := h.store.GetDashboard(c.UserContext(), dashboardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
 	}
@@ -76,7 +76,7 @@ func (h *CardHandler) CreateCard(c *fiber.Ctx) error {
 		Position:    input.Position,
 	}
 
-	if err := h.store.CreateCard(card); err != nil {
+	if err := h.store.CreateCard(c.UserContext(), card); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create card")
 	}
 
@@ -97,7 +97,7 @@ func (h *CardHandler) UpdateCard(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid card ID")
 	}
 
-	card, err := h.store.GetCard(cardID)
+	card, err := h.store.GetCard(c.UserContext(), cardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get card")
 	}
@@ -106,7 +106,7 @@ func (h *CardHandler) UpdateCard(c *fiber.Ctx) error {
 	}
 
 	// Verify ownership via dashboard
-	dashboard, err := h.store.GetDashboard(card.DashboardID)
+	dashboard, err := h.store.GetDashboard(c.UserContext(), card.DashboardID)
 	if err != nil || dashboard == nil || dashboard.UserID != userID {
 		return fiber.NewError(fiber.StatusForbidden, "Access denied")
 	}
@@ -126,7 +126,7 @@ func (h *CardHandler) UpdateCard(c *fiber.Ctx) error {
 		card.Position = *input.Position
 	}
 
-	if err := h.store.UpdateCard(card); err != nil {
+	if err := h.store.UpdateCard(c.UserContext(), card); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update card")
 	}
 
@@ -147,7 +147,7 @@ func (h *CardHandler) DeleteCard(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid card ID")
 	}
 
-	card, err := h.store.GetCard(cardID)
+	card, err := h.store.GetCard(c.UserContext(), cardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get card")
 	}
@@ -156,12 +156,12 @@ func (h *CardHandler) DeleteCard(c *fiber.Ctx) error {
 	}
 
 	// Verify ownership via dashboard
-	dashboard, err := h.store.GetDashboard(card.DashboardID)
+	dashboard, err := h.store.GetDashboard(c.UserContext(), card.DashboardID)
 	if err != nil || dashboard == nil || dashboard.UserID != userID {
 		return fiber.NewError(fiber.StatusForbidden, "Access denied")
 	}
 
-	if err := h.store.DeleteCard(cardID); err != nil {
+	if err := h.store.DeleteCard(c.UserContext(), cardID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete card")
 	}
 
@@ -182,7 +182,7 @@ func (h *CardHandler) RecordFocus(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid card ID")
 	}
 
-	card, err := h.store.GetCard(cardID)
+	card, err := h.store.GetCard(c.UserContext(), cardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get card")
 	}
@@ -191,7 +191,7 @@ func (h *CardHandler) RecordFocus(c *fiber.Ctx) error {
 	}
 
 	// Verify ownership via dashboard
-	dashboard, err := h.store.GetDashboard(card.DashboardID)
+	dashboard, err := h.store.GetDashboard(c.UserContext(), card.DashboardID)
 	if err != nil || dashboard == nil || dashboard.UserID != userID {
 		return fiber.NewError(fiber.StatusForbidden, "Access denied")
 	}
@@ -201,7 +201,7 @@ func (h *CardHandler) RecordFocus(c *fiber.Ctx) error {
 	}
 	c.BodyParser(&input)
 
-	if err := h.store.UpdateCardFocus(cardID, input.Summary); err != nil {
+	if err := h.store.UpdateCardFocus(c.UserContext(), cardID, input.Summary); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update focus")
 	}
 
@@ -211,7 +211,7 @@ func (h *CardHandler) RecordFocus(c *fiber.Ctx) error {
 		EventType: models.EventTypeCardFocus,
 		CardID:    &cardID,
 	}
-	h.store.RecordEvent(event)
+	h.store.RecordEvent(c.UserContext(), event)
 
 	return c.JSON(fiber.Map{"status": "ok"})
 }
@@ -230,7 +230,7 @@ func (h *CardHandler) GetHistory(c *fiber.Ctx) error {
 		limit = l
 	}
 
-	history, err := h.store.GetUserCardHistory(userID, limit)
+	history, err := h.store.GetUserCardHistory(c.UserContext(), userID, limit)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get history")
 	}
@@ -258,7 +258,7 @@ func (h *CardHandler) MoveCard(c *fiber.Ctx) error {
 	}
 
 	// Get the card
-	card, err := h.store.GetCard(cardID)
+	card, err := h.store.GetCard(c.UserContext(), cardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get card")
 	}
@@ -267,20 +267,20 @@ func (h *CardHandler) MoveCard(c *fiber.Ctx) error {
 	}
 
 	// Verify ownership of source dashboard
-	sourceDashboard, err := h.store.GetDashboard(card.DashboardID)
+	sourceDashboard, err := h.store.GetDashboard(c.UserContext(), card.DashboardID)
 	if err != nil || sourceDashboard == nil || sourceDashboard.UserID != userID {
 		return fiber.NewError(fiber.StatusForbidden, "Access denied to source dashboard")
 	}
 
 	// Verify ownership of target dashboard
-	targetDashboard, err := h.store.GetDashboard(targetDashboardID)
+	targetDashboard, err := h.store.GetDashboard(c.UserContext(), targetDashboardID)
 	if err != nil || targetDashboard == nil || targetDashboard.UserID != userID {
 		return fiber.NewError(fiber.StatusForbidden, "Access denied to target dashboard")
 	}
 
 	// Update the card's dashboard ID
 	card.DashboardID = targetDashboardID
-	if err := h.store.UpdateCard(card); err != nil {
+	if err := h.store.UpdateCard(c.UserContext(), card); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to move card")
 	}
 
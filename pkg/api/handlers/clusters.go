@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// ClusterHandler serves cluster inventory read directly from the clusters
+type ClusterHandler struct {
+	k8sClient *k8s.MultiClusterClient
+}
+
+// NewClusterHandler creates a new cluster handler
+func NewClusterHandler(k8sClient *k8s.MultiClusterClient) *ClusterHandler {
+	return &ClusterHandler{k8sClient: k8sClient}
+}
+
+// GetClusterCapacity returns a cluster's node capacity against pod requests
+// and limits, with namespace and cluster rollups
+func (h *ClusterHandler) GetClusterCapacity(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	capacity, err := h.k8sClient.GetClusterCapacity(c.UserContext(), c.Params("cluster"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get cluster capacity: "+err.Error())
+	}
+	return c.JSON(capacity)
+}
+
+// GetAllClusterCapacity returns the capacity of every cluster and their
+// combined totals
+func (h *ClusterHandler) GetAllClusterCapacity(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.ClusterCapacity, error) {
+		capacity, err := h.k8sClient.GetClusterCapacity(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		return []k8s.ClusterCapacity{*capacity}, nil
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+	}
+
+	response := fanOutResponse("clusters", result)
+	response["totals"] = k8s.TotalCapacity(result.Items)
+	return c.JSON(response)
+}
+
+// GetNodeUsage returns the CPU and memory usage of a cluster's nodes from
+// metrics.k8s.io against their allocatable resources
+func (h *ClusterHandler) GetNodeUsage(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	usage, err := h.k8sClient.GetNodeUsage(c.UserContext(), c.Params("cluster"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get node usage: "+err.Error())
+	}
+	return c.JSON(usage)
+}
@@ -22,7 +22,7 @@ func NewDashboardHandler(s store.Store) *DashboardHandler {
 // ListDashboards returns all dashboards for the current user
 func (h *DashboardHandler) ListDashboards(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
-	dashboards, err := h.store.GetUserDashboards(userID)
+	dashboards, err := h.store.GetUserDashboards(c.UserContext(), userID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list dashboards")
 	}
@@ -37,7 +37,7 @@ func (h *DashboardHandler) GetDashboard(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid dashboard ID")
 	}
 
-	dashboard, err := h.store.GetDashboard(dashboardID)
+	dashboard, err := h.store.GetDashboard(c.UserContext(), dashboardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
 	}
@@ -49,7 +49,7 @@ func (h *DashboardHandler) GetDashboard(c *fiber.Ctx) error {
 	}
 
 	// Get cards
-	cards, err := h.store.GetDashboardCards(dashboardID)
+	cards, err := h.store.GetDashboardCards(c.UserContext(), dashboardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get cards")
 	}
@@ -82,7 +82,7 @@ func (h *DashboardHandler) CreateDashboard(c *fiber.Ctx) error {
 		IsDefault: input.IsDefault,
 	}
 
-	if err := h.store.CreateDashboard(dashboard); err != nil {
+	if err := h.store.CreateDashboard(c.UserContext(), dashboard); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create dashboard")
 	}
 
@@ -97,7 +97,7 @@ func (h *DashboardHandler) UpdateDashboard(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid dashboard ID")
 	}
 
-	dashboard, err := h.store.GetDashboard(dashboardID)
+	dashboard, err := h.store.GetDashboard(c.UserContext(), dashboardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
 	}
@@ -123,7 +123,7 @@ func (h *DashboardHandler) UpdateDashboard(c *fiber.Ctx) error {
 		dashboard.IsDefault = *input.IsDefault
 	}
 
-	if err := h.store.UpdateDashboard(dashboard); err != nil {
+	if err := h.store.UpdateDashboard(c.UserContext(), dashboard); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update dashboard")
 	}
 
@@ -138,7 +138,7 @@ func (h *DashboardHandler) DeleteDashboard(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid dashboard ID")
 	}
 
-	dashboard, err := h.store.GetDashboard(dashboardID)
+	dashboard, err := h.store.GetDashboard(c.UserContext(), dashboardID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
 	}
@@ -149,7 +149,7 @@ func (h *DashboardHandler) DeleteDashboard(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusForbidden, "Access denied")
 	}
 
-	if err := h.store.DeleteDashboard(dashboardID); err != nil {
+	if err := h.store.DeleteDashboard(c.UserContext(), dashboardID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete dashboard")
 	}
 
@@ -124,7 +124,7 @@ func (h *GitOpsHandlers) ListHelmReleases(c *fiber.Ctx) error {
 		args = append(args, "--kube-context", cluster)
 	}
 
-	cmd := exec.CommandContext(c.Context(), "helm", args...)
+	cmd := exec.CommandContext(c.UserContext(), "helm", args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -166,7 +166,7 @@ func (h *GitOpsHandlers) DetectDrift(c *fiber.Ctx) error {
 
 	// Try MCP bridge first (detect_drift tool from klaude-ops)
 	if h.bridge != nil {
-		result, err := h.detectDriftViaMCP(c.Context(), req)
+		result, err := h.detectDriftViaMCP(c.UserContext(), req)
 		if err == nil {
 			return c.JSON(result)
 		}
@@ -174,7 +174,7 @@ func (h *GitOpsHandlers) DetectDrift(c *fiber.Ctx) error {
 	}
 
 	// Fall back to kubectl diff
-	result, err := h.detectDriftViaKubectl(c.Context(), req)
+	result, err := h.detectDriftViaKubectl(c.UserContext(), req)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -204,10 +204,7 @@ func (h *GitOpsHandlers) detectDriftViaMCP(ctx context.Context, req DetectDriftR
 	}
 
 	if result.IsError {
-		if len(result.Content) > 0 {
-			return nil, fmt.Errorf("MCP tool error: %s", result.Content[0].Text)
-		}
-		return nil, fmt.Errorf("MCP tool returned error")
+		return nil, &mcp.ToolError{Tool: "detect_drift", Message: result.Text()}
 	}
 
 	// Parse MCP result - content is text that may contain JSON
@@ -216,14 +213,12 @@ func (h *GitOpsHandlers) detectDriftViaMCP(ctx context.Context, req DetectDriftR
 		TokensUsed: 350, // Estimate
 	}
 
-	// Try to parse the first content item as JSON
-	if len(result.Content) > 0 {
-		text := result.Content[0].Text
+	// Prefer structured data; plain text is kept as the raw diff
+	if text := result.Text(); text != "" || len(result.StructuredContent) > 0 {
 		response.RawDiff = text
 
-		// Try to parse as JSON for structured data
 		var parsed map[string]interface{}
-		if err := json.Unmarshal([]byte(text), &parsed); err == nil {
+		if err := result.Decode("detect_drift", "", &parsed); err == nil {
 			if drifted, ok := parsed["drifted"].(bool); ok {
 				response.Drifted = drifted
 			}
@@ -327,7 +322,7 @@ func (h *GitOpsHandlers) Sync(c *fiber.Ctx) error {
 
 	// Try MCP bridge first
 	if h.bridge != nil {
-		result, err := h.syncViaMCP(c.Context(), req)
+		result, err := h.syncViaMCP(c.UserContext(), req)
 		if err == nil {
 			return c.JSON(result)
 		}
@@ -335,7 +330,7 @@ func (h *GitOpsHandlers) Sync(c *fiber.Ctx) error {
 	}
 
 	// Fall back to kubectl apply
-	result, err := h.syncViaKubectl(c.Context(), req)
+	result, err := h.syncViaKubectl(c.UserContext(), req)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -374,22 +369,20 @@ func (h *GitOpsHandlers) syncViaMCP(ctx context.Context, req SyncRequest) (*Sync
 
 	if result.IsError {
 		response.Success = false
-		if len(result.Content) > 0 {
-			response.Message = result.Content[0].Text
-			response.Errors = []string{result.Content[0].Text}
+		if text := result.Text(); text != "" {
+			response.Message = text
+			response.Errors = []string{text}
 		}
 		return response, nil
 	}
 
 	// Parse content
-	if len(result.Content) > 0 {
-		text := result.Content[0].Text
+	if text := result.Text(); text != "" || len(result.StructuredContent) > 0 {
 		response.Message = text
 		response.Success = true
 
-		// Try to parse as JSON
 		var parsed map[string]interface{}
-		if err := json.Unmarshal([]byte(text), &parsed); err == nil {
+		if err := result.Decode("apply_manifests", "", &parsed); err == nil {
 			if success, ok := parsed["success"].(bool); ok {
 				response.Success = success
 			}
@@ -0,0 +1,642 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// List endpoints share these query parameters:
+//
+//	limit          page size; all items when unset
+//	continue       token from the previous page
+//	sort           comma-separated JSON field names, "-" for descending
+//	               (e.g. -restarts,name)
+//	labelSelector  Kubernetes label selector
+//	fieldSelector  Kubernetes field selector, matched against the items'
+//	               JSON fields when the source cannot apply it
+//	q              case-insensitive text matched against string fields
+//
+// and answer with {"items": [...], "continue": "...", "total": N} plus
+// endpoint fields such as source. continue is omitted on the last page and
+// total when it is unknown.
+//
+// Lists that the Kubernetes API server can page (no sort or q) use its
+// continue tokens. Others are filtered and sorted in memory, and the rest
+// of the result is kept for listCacheTTL so later pages are consistent and
+// cheap. Cached lists belong to the user who started them.
+
+const (
+	// maxListLimit caps the limit parameter
+	maxListLimit = 1000
+	// listCacheTTL is how long a continue token of an in-memory list stays
+	// valid
+	listCacheTTL = 5 * time.Minute
+	// maxCachedLists bounds the lists kept for continue tokens
+	maxCachedLists = 256
+	// maxCachedItems bounds the items of all lists kept for continue tokens
+	maxCachedItems = 200000
+)
+
+// errContinueExpired is returned for continue tokens of lists no longer
+// cached, answered with 410 Gone like the Kubernetes API does
+var errContinueExpired = errors.New("continue token expired; restart the list without it")
+
+// errListTooLarge is returned for paged lists too large to keep in memory
+var errListTooLarge = fmt.Errorf("list exceeds %d items and cannot be sorted or searched page by page; narrow it with a selector or request it without limit", maxCachedItems)
+
+// listParams are the parsed list query parameters
+type listParams struct {
+	Limit         int
+	Continue      string
+	Sort          []sortKey
+	LabelSelector string
+	FieldSelector string
+	Query         string
+
+	labels labels.Selector
+	fields fields.Selector
+}
+
+type sortKey struct {
+	Field string
+	Desc  bool
+}
+
+// parseListParams reads the list query parameters; defaultLimit applies
+// when limit is unset
+func parseListParams(c *fiber.Ctx, defaultLimit int) (listParams, error) {
+	p := listParams{
+		Limit:         defaultLimit,
+		Continue:      c.Query("continue"),
+		LabelSelector: c.Query("labelSelector"),
+		FieldSelector: c.Query("fieldSelector"),
+		Query:         strings.TrimSpace(c.Query("q")),
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return p, fmt.Errorf("invalid limit %q", raw)
+		}
+		p.Limit = limit
+	}
+	if p.Limit > maxListLimit {
+		p.Limit = maxListLimit
+	}
+
+	for _, field := range strings.Split(c.Query("sort"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key := sortKey{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		p.Sort = append(p.Sort, key)
+	}
+
+	var err error
+	if p.labels, err = labels.Parse(p.LabelSelector); err != nil {
+		return p, fmt.Errorf("invalid labelSelector: %w", err)
+	}
+	if p.fields, err = fields.ParseSelector(p.FieldSelector); err != nil {
+		return p, fmt.Errorf("invalid fieldSelector: %w", err)
+	}
+	return p, nil
+}
+
+// startList parses the list parameters and answers requests that continue
+// an in-memory list from its cache. done reports that the response (or an
+// error response) has been written and the handler should return err.
+func startList(c *fiber.Ctx, defaultLimit int) (p listParams, done bool, err error) {
+	p, err = parseListParams(c, defaultLimit)
+	if err != nil {
+		return p, true, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	token, ok := decodeContinue(p.Continue)
+	if p.Continue != "" && !ok {
+		return p, true, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid continue token"})
+	}
+	if token.kind != continueCached {
+		return p, false, nil
+	}
+
+	snapshot, ok := lists.get(token.id, middleware.GetUserID(c))
+	if !ok {
+		return p, true, c.Status(fiber.StatusGone).JSON(fiber.Map{"error": errContinueExpired.Error()})
+	}
+	limit := p.Limit
+	if c.Query("limit") == "" {
+		limit = snapshot.limit
+	}
+	return p, true, c.JSON(snapshot.page(token.id, token.offset, limit))
+}
+
+// listResponse filters, sorts and pages items in memory and writes the
+// page, with extra added to the envelope of this and later pages
+func listResponse[T any](c *fiber.Ctx, p listParams, items []T, extra fiber.Map) error {
+	filtered, err := filterAndSort(p, items)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if filtered == nil {
+		filtered = []T{}
+	}
+
+	snapshot := &listSnapshot{
+		owner: middleware.GetUserID(c),
+		total: len(filtered),
+		limit: p.Limit,
+		extra: extra,
+		slice: func(offset, limit int) interface{} {
+			end := len(filtered)
+			if limit > 0 && offset+limit < end {
+				end = offset + limit
+			}
+			return filtered[offset:end]
+		},
+	}
+	if p.Limit == 0 || p.Limit >= len(filtered) {
+		return c.JSON(snapshot.page("", 0, p.Limit))
+	}
+	id, ok := lists.put(snapshot)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errListTooLarge.Error()})
+	}
+	return c.JSON(snapshot.page(id, 0, p.Limit))
+}
+
+// listError answers a failed list, with 410 Gone for an expired continue
+// token of the API server
+func listError(c *fiber.Ctx, err error) error {
+	if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": errContinueExpired.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
+
+// kubeListOptions returns the options of a list the Kubernetes API server
+// can page by itself, and the number of items before the requested page.
+// ok is false when the list must be sorted or searched in memory.
+func (p listParams) kubeListOptions() (opts metav1.ListOptions, offset int, ok bool) {
+	token, _ := decodeContinue(p.Continue)
+	if token.kind == continueCached || len(p.Sort) > 0 || p.Query != "" {
+		return opts, 0, false
+	}
+	if p.Limit == 0 && token.kind != continueKube {
+		return opts, 0, false
+	}
+
+	opts = p.selectorOptions()
+	opts.Limit = int64(p.Limit)
+	opts.Continue = token.kube
+	return opts, token.offset, true
+}
+
+// continuesKube reports whether the request continues a list paged by the
+// Kubernetes API server, so it must be served from the same source
+func (p listParams) continuesKube() bool {
+	token, _ := decodeContinue(p.Continue)
+	return token.kind == continueKube
+}
+
+// selectorOptions passes the selectors on to the Kubernetes API server
+func (p listParams) selectorOptions() metav1.ListOptions {
+	return metav1.ListOptions{LabelSelector: p.LabelSelector, FieldSelector: p.FieldSelector}
+}
+
+// withoutSelectors returns the parameters for filtering items whose source
+// has already applied the selectors
+func (p listParams) withoutSelectors() listParams {
+	p.LabelSelector, p.FieldSelector = "", ""
+	p.labels, p.fields = labels.Everything(), fields.Everything()
+	return p
+}
+
+// withoutLabelSelector is withoutSelectors for sources that only apply the
+// label selector
+func (p listParams) withoutLabelSelector() listParams {
+	p.LabelSelector = ""
+	p.labels = labels.Everything()
+	return p
+}
+
+// kubePage is the envelope of a page served by the Kubernetes API server.
+// offset is the number of items on earlier pages.
+func kubePage[T any](items []T, offset int, cont string, remaining *int64, extra fiber.Map) fiber.Map {
+	if items == nil {
+		items = []T{}
+	}
+	page := fiber.Map{"items": items}
+	for k, v := range extra {
+		page[k] = v
+	}
+
+	switch {
+	case cont == "":
+		page["total"] = offset + len(items)
+	case remaining != nil:
+		page["total"] = offset + len(items) + int(*remaining)
+	}
+	if cont != "" {
+		page["continue"] = encodeContinue(continueToken{kind: continueKube, offset: offset + len(items), kube: cont})
+	}
+	return page
+}
+
+// filterAndSort applies the selectors, q and sort of p to items
+func filterAndSort[T any](p listParams, items []T) ([]T, error) {
+	var zero T
+	t := reflect.TypeOf(zero)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return items, nil
+	}
+	fieldsOfT := jsonFields(t)
+
+	for _, key := range p.Sort {
+		if _, ok := fieldsOfT[key.Field]; !ok {
+			return nil, fmt.Errorf("cannot sort by %q; sortable fields: %s", key.Field, strings.Join(sortableFields(fieldsOfT), ", "))
+		}
+	}
+
+	filtering := p.Query != "" || (p.labels != nil && !p.labels.Empty()) || (p.fields != nil && !p.fields.Empty())
+	result := make([]T, 0, len(items))
+	query := strings.ToLower(p.Query)
+	for _, item := range items {
+		if filtering && !matchesItem(reflect.Indirect(reflect.ValueOf(item)), fieldsOfT, p, query) {
+			continue
+		}
+		result = append(result, item)
+	}
+
+	if len(p.Sort) > 0 {
+		sort.SliceStable(result, func(i, j int) bool {
+			a := reflect.Indirect(reflect.ValueOf(result[i]))
+			b := reflect.Indirect(reflect.ValueOf(result[j]))
+			for _, key := range p.Sort {
+				index := fieldsOfT[key.Field]
+				cmp := compareValues(a.FieldByIndex(index), b.FieldByIndex(index))
+				if cmp == 0 {
+					continue
+				}
+				if key.Desc {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+	}
+	return result, nil
+}
+
+// matchesItem applies the selectors and text query to one item. Field
+// selectors see the item's scalar JSON fields, with metadata.name and
+// metadata.namespace as aliases; label selectors its "labels" map.
+func matchesItem(v reflect.Value, fieldsOfT map[string][]int, p listParams, query string) bool {
+	if !v.IsValid() {
+		return false
+	}
+
+	set := fields.Set{}
+	found := query == ""
+	for name, index := range fieldsOfT {
+		text, ok := scalarString(v.FieldByIndex(index))
+		if !ok {
+			continue
+		}
+		set[name] = text
+		if !found && v.FieldByIndex(index).Kind() == reflect.String && strings.Contains(strings.ToLower(text), query) {
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+
+	if p.fields != nil && !p.fields.Empty() {
+		if name, ok := set["name"]; ok {
+			set["metadata.name"] = name
+		}
+		if ns, ok := set["namespace"]; ok {
+			set["metadata.namespace"] = ns
+		}
+		if !p.fields.Matches(set) {
+			return false
+		}
+	}
+
+	if p.labels != nil && !p.labels.Empty() {
+		var itemLabels labels.Set
+		if index, ok := fieldsOfT["labels"]; ok {
+			itemLabels, _ = v.FieldByIndex(index).Interface().(map[string]string)
+		}
+		if !p.labels.Matches(itemLabels) {
+			return false
+		}
+	}
+	return true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// scalarString formats strings, numbers, booleans and times
+func scalarString(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).Format(time.RFC3339), true
+		}
+	}
+	return "", false
+}
+
+// compareValues orders two values of the same field; nil pointers sort
+// first
+func compareValues(a, b reflect.Value) int {
+	if a.Kind() == reflect.Ptr {
+		switch {
+		case a.IsNil() && b.IsNil():
+			return 0
+		case a.IsNil():
+			return -1
+		case b.IsNil():
+			return 1
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case b.Bool():
+			return -1
+		}
+		return 1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float(), b.Float())
+	case reflect.Struct:
+		if a.Type() == timeType {
+			return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+		}
+	}
+	return 0
+}
+
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// jsonFieldCache maps struct types to their JSON field names and indices
+var jsonFieldCache sync.Map
+
+// jsonFields returns the exported fields of t by JSON name, including those
+// of embedded structs
+func jsonFields(t reflect.Type) map[string][]int {
+	if cached, ok := jsonFieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	result := map[string][]int{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if n, _, _ := strings.Cut(tag, ","); n != "" {
+				name = n
+			}
+		}
+		if _, taken := result[name]; !taken {
+			result[name] = f.Index
+		}
+	}
+	jsonFieldCache.Store(t, result)
+	return result
+}
+
+func sortableFields(fieldsOfT map[string][]int) []string {
+	names := make([]string, 0, len(fieldsOfT))
+	for name := range fieldsOfT {
+		if name != "labels" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Continue token kinds
+const (
+	continueNone   = ""
+	continueCached = "c"
+	continueKube   = "k"
+)
+
+// continueToken is encoded as base64 of "c:<id>:<offset>" for in-memory
+// lists and "k:<offset>:<token>" for lists paged by the API server
+type continueToken struct {
+	kind   string
+	id     string
+	offset int
+	kube   string
+}
+
+func encodeContinue(t continueToken) string {
+	var raw string
+	if t.kind == continueKube {
+		raw = fmt.Sprintf("%s:%d:%s", t.kind, t.offset, t.kube)
+	} else {
+		raw = fmt.Sprintf("%s:%s:%d", t.kind, t.id, t.offset)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeContinue(s string) (continueToken, bool) {
+	if s == "" {
+		return continueToken{kind: continueNone}, true
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return continueToken{}, false
+	}
+	parts := strings.SplitN(string(data), ":", 3)
+	if len(parts) != 3 {
+		return continueToken{}, false
+	}
+
+	switch parts[0] {
+	case continueCached:
+		offset, err := strconv.Atoi(parts[2])
+		if err != nil || offset < 0 {
+			return continueToken{}, false
+		}
+		return continueToken{kind: continueCached, id: parts[1], offset: offset}, true
+	case continueKube:
+		offset, err := strconv.Atoi(parts[1])
+		if err != nil || offset < 0 || parts[2] == "" {
+			return continueToken{}, false
+		}
+		return continueToken{kind: continueKube, offset: offset, kube: parts[2]}, true
+	}
+	return continueToken{}, false
+}
+
+// listSnapshot is a filtered, sorted list being paged through
+type listSnapshot struct {
+	// owner is the user who started the list; only they can continue it
+	owner uuid.UUID
+	total int
+	// limit is the page size of the first page, used for later pages
+	// requested without a limit
+	limit   int
+	extra   fiber.Map
+	slice   func(offset, limit int) interface{}
+	expires time.Time
+}
+
+// page returns the envelope of the page at offset of the list cached as id
+func (s *listSnapshot) page(id string, offset, limit int) fiber.Map {
+	if offset > s.total {
+		offset = s.total
+	}
+	page := fiber.Map{"items": s.slice(offset, limit), "total": s.total}
+	for k, v := range s.extra {
+		page[k] = v
+	}
+	if next := offset + limit; limit > 0 && next < s.total {
+		page["continue"] = encodeContinue(continueToken{kind: continueCached, id: id, offset: next})
+	}
+	return page
+}
+
+// listCache keeps the snapshots behind continue tokens, at most maxLists
+// of them holding at most maxItems items together
+type listCache struct {
+	mu       sync.Mutex
+	lists    map[string]*listSnapshot
+	items    int
+	maxLists int
+	maxItems int
+}
+
+var lists = newListCache(maxCachedLists, maxCachedItems)
+
+func newListCache(maxLists, maxItems int) *listCache {
+	return &listCache{lists: map[string]*listSnapshot{}, maxLists: maxLists, maxItems: maxItems}
+}
+
+// put stores a snapshot and returns its ID, dropping expired snapshots and
+// then those closest to expiry until it fits. ok is false for a snapshot
+// larger than the whole cache.
+func (lc *listCache) put(s *listSnapshot) (id string, ok bool) {
+	if s.total > lc.maxItems {
+		return "", false
+	}
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	id = hex.EncodeToString(buf)
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	now := time.Now()
+	for key, cached := range lc.lists {
+		if now.After(cached.expires) {
+			lc.remove(key)
+		}
+	}
+	for len(lc.lists) >= lc.maxLists || lc.items+s.total > lc.maxItems {
+		var oldest string
+		for key, cached := range lc.lists {
+			if oldest == "" || cached.expires.Before(lc.lists[oldest].expires) {
+				oldest = key
+			}
+		}
+		lc.remove(oldest)
+	}
+
+	s.expires = now.Add(listCacheTTL)
+	lc.lists[id] = s
+	lc.items += s.total
+	return id, true
+}
+
+// get returns the snapshot cached as id if owner started it
+func (lc *listCache) get(id string, owner uuid.UUID) (*listSnapshot, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	s, ok := lc.lists[id]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(s.expires) {
+		lc.remove(id)
+		return nil, false
+	}
+	if s.owner != owner {
+		return nil, false
+	}
+	return s, true
+}
+
+func (lc *listCache) remove(id string) {
+	if s, ok := lc.lists[id]; ok {
+		lc.items -= s.total
+		delete(lc.lists, id)
+	}
+}
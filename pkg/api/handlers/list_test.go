@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func snapshotOf(owner uuid.UUID, total int) *listSnapshot {
+	return &listSnapshot{owner: owner, total: total, slice: func(int, int) interface{} { return nil }}
+}
+
+func TestListCacheOwner(t *testing.T) {
+	lc := newListCache(4, 100)
+	alice, bob := uuid.New(), uuid.New()
+
+	id, ok := lc.put(snapshotOf(alice, 10))
+	if !ok {
+		t.Fatal("put failed")
+	}
+	if _, ok := lc.get(id, alice); !ok {
+		t.Fatal("owner cannot continue the list")
+	}
+	if _, ok := lc.get(id, bob); ok {
+		t.Fatal("another user continued the list")
+	}
+	if _, ok := lc.get(id, alice); !ok {
+		t.Fatal("list dropped after another user's attempt")
+	}
+}
+
+func TestListCacheBounds(t *testing.T) {
+	lc := newListCache(3, 100)
+	owner := uuid.New()
+
+	first, _ := lc.put(snapshotOf(owner, 40))
+	second, _ := lc.put(snapshotOf(owner, 40))
+	third, _ := lc.put(snapshotOf(owner, 40))
+	if _, ok := lc.get(first, owner); ok {
+		t.Fatal("oldest list kept past the item budget")
+	}
+	if _, ok := lc.get(second, owner); !ok {
+		t.Fatal("list evicted though it fit")
+	}
+	if _, ok := lc.get(third, owner); !ok {
+		t.Fatal("new list not cached")
+	}
+	if lc.items != 80 {
+		t.Fatalf("items = %d, want 80", lc.items)
+	}
+
+	lc.put(snapshotOf(owner, 1))
+	lc.put(snapshotOf(owner, 1))
+	if len(lc.lists) != 3 {
+		t.Fatalf("lists = %d, want 3", len(lc.lists))
+	}
+
+	if _, ok := lc.put(snapshotOf(owner, 101)); ok {
+		t.Fatal("list larger than the cache was stored")
+	}
+}
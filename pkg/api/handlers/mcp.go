@@ -1,24 +1,104 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log"
+	"sort"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/middleware"
 	"github.com/kubestellar/console/pkg/k8s"
 	"github.com/kubestellar/console/pkg/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // MCPHandlers handles MCP-related API endpoints
 type MCPHandlers struct {
 	bridge    *mcp.Bridge
 	k8sClient *k8s.MultiClusterClient
+	hub       *Hub
 }
 
 // NewMCPHandlers creates a new MCP handlers instance
-func NewMCPHandlers(bridge *mcp.Bridge, k8sClient *k8s.MultiClusterClient) *MCPHandlers {
+func NewMCPHandlers(bridge *mcp.Bridge, k8sClient *k8s.MultiClusterClient, hub *Hub) *MCPHandlers {
 	return &MCPHandlers{
 		bridge:    bridge,
 		k8sClient: k8sClient,
+		hub:       hub,
+	}
+}
+
+// Data sources reported in the "source" field of responses
+const (
+	sourceMCP = "mcp"
+	sourceK8s = "k8s"
+)
+
+// mcpFallback explains why a response was served from the k8s client after
+// the MCP bridge was tried
+type mcpFallback struct {
+	// Reason is "unavailable" (server or tool missing or down), "tool_error"
+	// (the tool reported failure), "unparseable" (no data in the expected
+	// shape), "empty" or "error"
+	Reason string `json:"reason"`
+	Error  string `json:"error,omitempty"`
+}
+
+// newMCPFallback classifies a failed MCP attempt and logs the fallback
+func newMCPFallback(op string, err error) *mcpFallback {
+	var toolErr *mcp.ToolError
+	fb := &mcpFallback{Reason: "error"}
+	switch {
+	case err == nil:
+		fb.Reason = "empty"
+	case errors.Is(err, mcp.ErrServerNotFound), errors.Is(err, mcp.ErrServerNotAvailable),
+		errors.Is(err, mcp.ErrServerExited), errors.Is(err, mcp.ErrToolNotFound):
+		fb.Reason = "unavailable"
+	case errors.As(err, &toolErr):
+		fb.Reason = "tool_error"
+	case errors.Is(err, mcp.ErrUnparseableResult), errors.Is(err, mcp.ErrInvalidOutput):
+		fb.Reason = "unparseable"
+	}
+	if err != nil {
+		fb.Error = err.Error()
+	}
+	log.Printf("MCP bridge %s failed (%s), falling back to k8s client: %v", op, fb.Reason, err)
+	return fb
+}
+
+// k8sResult adds the k8s source, and the fallback if MCP was tried, to a
+// response
+func k8sResult(result fiber.Map, fallback *mcpFallback) fiber.Map {
+	result["source"] = sourceK8s
+	if fallback != nil {
+		result["fallback"] = fallback
+	}
+	return result
+}
+
+// maxClusterTimeout caps the clusterTimeout query parameter
+const maxClusterTimeout = 30 * time.Second
+
+// fanOutOptions reads the optional clusterTimeout query parameter (e.g. 5s)
+// of an all-cluster query
+func fanOutOptions(c *fiber.Ctx) k8s.FanOutOptions {
+	var opts k8s.FanOutOptions
+	if d, err := time.ParseDuration(c.Query("clusterTimeout")); err == nil && d > 0 {
+		opts.ClusterTimeout = min(d, maxClusterTimeout)
+	}
+	return opts
+}
+
+// fanOutResponse is the body of an all-cluster query: the items under key,
+// plus how many clusters were asked and which failed or timed out
+func fanOutResponse[T any](key string, result k8s.FanOutResult[T]) fiber.Map {
+	return fiber.Map{
+		key:             result.Items,
+		"clusterCount":  result.Clusters,
+		"clusterErrors": result.Errors,
 	}
 }
 
@@ -60,24 +140,25 @@ func (h *MCPHandlers) GetDeployTools(c *fiber.Ctx) error {
 
 // ListClusters returns all discovered clusters with health data
 func (h *MCPHandlers) ListClusters(c *fiber.Ctx) error {
-	// Try MCP bridge first if available
+	// Try MCP bridge first if available; an empty list is not trusted
+	var fallback *mcpFallback
 	if h.bridge != nil {
-		clusters, err := h.bridge.ListClusters(c.Context())
+		clusters, err := h.bridge.ListClusters(c.UserContext())
 		if err == nil && len(clusters) > 0 {
-			return c.JSON(fiber.Map{"clusters": clusters, "source": "mcp"})
+			return c.JSON(fiber.Map{"clusters": clusters, "source": sourceMCP})
 		}
-		log.Printf("MCP bridge ListClusters failed, falling back to k8s client: %v", err)
+		fallback = newMCPFallback("ListClusters", err)
 	}
 
 	// Fall back to direct k8s client
 	if h.k8sClient != nil {
-		clusters, err := h.k8sClient.ListClusters(c.Context())
+		clusters, err := h.k8sClient.ListClusters(c.UserContext())
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
 
 		// Enrich with health data (parallel fetch)
-		healthData, _ := h.k8sClient.GetAllClusterHealth(c.Context())
+		healthData, _ := h.k8sClient.GetAllClusterHealth(c.UserContext())
 		healthMap := make(map[string]*k8s.ClusterHealth)
 		for i := range healthData {
 			healthMap[healthData[i].Cluster] = &healthData[i]
@@ -92,7 +173,7 @@ func (h *MCPHandlers) ListClusters(c *fiber.Ctx) error {
 			}
 		}
 
-		return c.JSON(fiber.Map{"clusters": clusters, "source": "k8s"})
+		return c.JSON(k8sResult(fiber.Map{"clusters": clusters}, fallback))
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
@@ -103,21 +184,29 @@ func (h *MCPHandlers) GetClusterHealth(c *fiber.Ctx) error {
 	cluster := c.Params("cluster")
 
 	// Try MCP bridge first if available
+	var fallback *mcpFallback
 	if h.bridge != nil {
-		health, err := h.bridge.GetClusterHealth(c.Context(), cluster)
+		health, err := h.bridge.GetClusterHealth(c.UserContext(), cluster)
 		if err == nil {
-			return c.JSON(health)
+			return c.JSON(struct {
+				*mcp.ClusterHealth
+				Source string `json:"source"`
+			}{health, sourceMCP})
 		}
-		log.Printf("MCP bridge GetClusterHealth failed, falling back: %v", err)
+		fallback = newMCPFallback("GetClusterHealth", err)
 	}
 
 	// Fall back to direct k8s client
 	if h.k8sClient != nil {
-		health, err := h.k8sClient.GetClusterHealth(c.Context(), cluster)
+		health, err := h.k8sClient.GetClusterHealth(c.UserContext(), cluster)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
-		return c.JSON(health)
+		return c.JSON(struct {
+			*k8s.ClusterHealth
+			Source   string       `json:"source"`
+			Fallback *mcpFallback `json:"fallback,omitempty"`
+		}{health, sourceK8s, fallback})
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
@@ -127,7 +216,7 @@ func (h *MCPHandlers) GetClusterHealth(c *fiber.Ctx) error {
 func (h *MCPHandlers) GetAllClusterHealth(c *fiber.Ctx) error {
 	// Use direct k8s client for this as it's more efficient
 	if h.k8sClient != nil {
-		health, err := h.k8sClient.GetAllClusterHealth(c.Context())
+		health, err := h.k8sClient.GetAllClusterHealth(c.UserContext())
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -141,24 +230,38 @@ func (h *MCPHandlers) GetAllClusterHealth(c *fiber.Ctx) error {
 func (h *MCPHandlers) GetPods(c *fiber.Ctx) error {
 	cluster := c.Query("cluster")
 	namespace := c.Query("namespace")
-	labelSelector := c.Query("labelSelector")
 
-	// Try MCP bridge first for its richer functionality
-	if h.bridge != nil {
-		pods, err := h.bridge.GetPods(c.Context(), cluster, namespace, labelSelector)
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	// Try MCP bridge first for its richer functionality, unless the request
+	// continues a page of the API server's
+	var fallback *mcpFallback
+	if h.bridge != nil && !p.continuesKube() {
+		pods, err := h.bridge.GetPods(c.UserContext(), cluster, namespace, p.LabelSelector)
 		if err == nil {
-			return c.JSON(fiber.Map{"pods": pods, "source": "mcp"})
+			return listResponse(c, p.withoutLabelSelector(), pods, fiber.Map{"source": sourceMCP})
 		}
-		log.Printf("MCP bridge GetPods failed, falling back: %v", err)
+		fallback = newMCPFallback("GetPods", err)
 	}
 
 	// Fall back to direct k8s client
 	if h.k8sClient != nil && cluster != "" {
-		pods, err := h.k8sClient.GetPods(c.Context(), cluster, namespace)
+		if opts, offset, ok := p.kubeListOptions(); ok {
+			pods, err := h.k8sClient.ListPods(c.UserContext(), cluster, namespace, opts)
+			if err != nil {
+				return listError(c, err)
+			}
+			return c.JSON(kubePage(pods.Items, offset, pods.Continue, pods.RemainingItemCount, k8sResult(fiber.Map{}, fallback)))
+		}
+
+		pods, err := h.k8sClient.ListPods(c.UserContext(), cluster, namespace, p.selectorOptions())
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			return listError(c, err)
 		}
-		return c.JSON(fiber.Map{"pods": pods, "source": "k8s"})
+		return listResponse(c, p.withoutSelectors(), pods.Items, k8sResult(fiber.Map{}, fallback))
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
@@ -170,37 +273,33 @@ func (h *MCPHandlers) FindPodIssues(c *fiber.Ctx) error {
 	namespace := c.Query("namespace")
 
 	// Try MCP bridge first
+	var fallback *mcpFallback
 	if h.bridge != nil {
-		issues, err := h.bridge.FindPodIssues(c.Context(), cluster, namespace)
+		issues, err := h.bridge.FindPodIssues(c.UserContext(), cluster, namespace)
 		if err == nil {
-			return c.JSON(fiber.Map{"issues": issues, "source": "mcp"})
+			return c.JSON(fiber.Map{"issues": issues, "source": sourceMCP})
 		}
-		log.Printf("MCP bridge FindPodIssues failed, falling back: %v", err)
+		fallback = newMCPFallback("FindPodIssues", err)
 	}
 
 	// Fall back to direct k8s client
 	if h.k8sClient != nil {
 		// If no cluster specified, query all clusters
 		if cluster == "" {
-			clusters, err := h.k8sClient.ListClusters(c.Context())
+			result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.PodIssue, error) {
+				return h.k8sClient.FindPodIssues(ctx, cluster, namespace)
+			})
 			if err != nil {
 				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 			}
-			var allIssues []k8s.PodIssue
-			for _, cl := range clusters {
-				issues, err := h.k8sClient.FindPodIssues(c.Context(), cl.Name, namespace)
-				if err == nil {
-					allIssues = append(allIssues, issues...)
-				}
-			}
-			return c.JSON(fiber.Map{"issues": allIssues, "source": "k8s"})
+			return c.JSON(k8sResult(fanOutResponse("issues", result), fallback))
 		}
 
-		issues, err := h.k8sClient.FindPodIssues(c.Context(), cluster, namespace)
+		issues, err := h.k8sClient.FindPodIssues(c.UserContext(), cluster, namespace)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
-		return c.JSON(fiber.Map{"issues": issues, "source": "k8s"})
+		return c.JSON(k8sResult(fiber.Map{"issues": issues}, fallback))
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
@@ -213,30 +312,87 @@ func (h *MCPHandlers) GetGPUNodes(c *fiber.Ctx) error {
 	if h.k8sClient != nil {
 		// If no cluster specified, query all clusters
 		if cluster == "" {
-			clusters, err := h.k8sClient.ListClusters(c.Context())
+			result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.GPUNode, error) {
+				return h.k8sClient.GetGPUNodes(ctx, cluster)
+			})
 			if err != nil {
 				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 			}
-			var allNodes []k8s.GPUNode
-			for _, cl := range clusters {
-				nodes, err := h.k8sClient.GetGPUNodes(c.Context(), cl.Name)
-				if err == nil {
-					allNodes = append(allNodes, nodes...)
-				}
-			}
-			return c.JSON(fiber.Map{"nodes": allNodes, "source": "k8s"})
+			return c.JSON(k8sResult(fanOutResponse("nodes", result), nil))
 		}
 
-		nodes, err := h.k8sClient.GetGPUNodes(c.Context(), cluster)
+		nodes, err := h.k8sClient.GetGPUNodes(c.UserContext(), cluster)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
-		return c.JSON(fiber.Map{"nodes": nodes, "source": "k8s"})
+		return c.JSON(fiber.Map{"nodes": nodes, "source": sourceK8s})
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
 }
 
+// defaultTopPods is the number of pods ranked by GetTopPods without a limit
+const defaultTopPods = 10
+
+// GetTopPods ranks pods by CPU or memory usage from metrics.k8s.io, or by
+// restarts, with usage against requests and limits. Clusters without
+// metrics-server are listed in metricsUnavailable.
+func (h *MCPHandlers) GetTopPods(c *fiber.Ctx) error {
+	cluster := c.Query("cluster")
+	namespace := c.Query("namespace")
+	by := c.Query("by", k8s.TopByCPU)
+	limit := min(c.QueryInt("limit", defaultTopPods), maxListLimit)
+
+	if h.k8sClient == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
+	}
+
+	var result k8s.FanOutResult[k8s.PodUsageList]
+	if cluster == "" {
+		var err error
+		result, err = k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.PodUsageList, error) {
+			list, err := h.k8sClient.GetPodUsage(ctx, cluster, namespace)
+			if err != nil {
+				return nil, err
+			}
+			return []k8s.PodUsageList{*list}, nil
+		})
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+	} else {
+		list, err := h.k8sClient.GetPodUsage(c.UserContext(), cluster, namespace)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		result.Items = []k8s.PodUsageList{*list}
+		result.Clusters = 1
+		result.Errors = []k8s.ClusterError{}
+	}
+
+	pods := []k8s.PodUsage{}
+	unavailable := []string{}
+	for _, list := range result.Items {
+		pods = append(pods, list.Items...)
+		if !list.MetricsAvailable {
+			unavailable = append(unavailable, list.Cluster)
+		}
+	}
+	top, err := k8s.TopPods(pods, by, limit)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"pods":               top,
+		"by":                 by,
+		"metricsUnavailable": unavailable,
+		"clusterCount":       result.Clusters,
+		"clusterErrors":      result.Errors,
+		"source":             sourceK8s,
+	})
+}
+
 // FindDeploymentIssues returns deployments with issues
 func (h *MCPHandlers) FindDeploymentIssues(c *fiber.Ctx) error {
 	cluster := c.Query("cluster")
@@ -246,25 +402,20 @@ func (h *MCPHandlers) FindDeploymentIssues(c *fiber.Ctx) error {
 	if h.k8sClient != nil {
 		// If no cluster specified, query all clusters
 		if cluster == "" {
-			clusters, err := h.k8sClient.ListClusters(c.Context())
+			result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.DeploymentIssue, error) {
+				return h.k8sClient.FindDeploymentIssues(ctx, cluster, namespace)
+			})
 			if err != nil {
 				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 			}
-			var allIssues []k8s.DeploymentIssue
-			for _, cl := range clusters {
-				issues, err := h.k8sClient.FindDeploymentIssues(c.Context(), cl.Name, namespace)
-				if err == nil {
-					allIssues = append(allIssues, issues...)
-				}
-			}
-			return c.JSON(fiber.Map{"issues": allIssues, "source": "k8s"})
+			return c.JSON(k8sResult(fanOutResponse("issues", result), nil))
 		}
 
-		issues, err := h.k8sClient.FindDeploymentIssues(c.Context(), cluster, namespace)
+		issues, err := h.k8sClient.FindDeploymentIssues(c.UserContext(), cluster, namespace)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
-		return c.JSON(fiber.Map{"issues": issues, "source": "k8s"})
+		return c.JSON(fiber.Map{"issues": issues, "source": sourceK8s})
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
@@ -278,114 +429,120 @@ func (h *MCPHandlers) GetDeployments(c *fiber.Ctx) error {
 	if h.k8sClient != nil {
 		// If no cluster specified, query all clusters
 		if cluster == "" {
-			clusters, err := h.k8sClient.ListClusters(c.Context())
+			result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.Deployment, error) {
+				return h.k8sClient.GetDeployments(ctx, cluster, namespace)
+			})
 			if err != nil {
 				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 			}
-			var allDeployments []k8s.Deployment
-			for _, cl := range clusters {
-				deployments, err := h.k8sClient.GetDeployments(c.Context(), cl.Name, namespace)
-				if err == nil {
-					allDeployments = append(allDeployments, deployments...)
-				}
-			}
-			return c.JSON(fiber.Map{"deployments": allDeployments, "source": "k8s"})
+			return c.JSON(k8sResult(fanOutResponse("deployments", result), nil))
 		}
 
-		deployments, err := h.k8sClient.GetDeployments(c.Context(), cluster, namespace)
+		deployments, err := h.k8sClient.GetDeployments(c.UserContext(), cluster, namespace)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
-		return c.JSON(fiber.Map{"deployments": deployments, "source": "k8s"})
+		return c.JSON(fiber.Map{"deployments": deployments, "source": sourceK8s})
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
 }
 
-// GetEvents returns events from a cluster
+const (
+	// defaultEventLimit is the page size of event lists without a limit
+	defaultEventLimit = 50
+	// maxListedEvents caps the events requested from the MCP bridge, which
+	// cannot page
+	maxListedEvents = maxListLimit
+)
+
+// warningFieldSelector adds type=Warning to a field selector
+func warningFieldSelector(selector string) string {
+	if selector == "" {
+		return "type=Warning"
+	}
+	return "type=Warning," + selector
+}
+
+// listK8sEvents answers an event list from the Kubernetes API: one cluster's
+// events, or every cluster's merged most recent first when cluster is empty
+func (h *MCPHandlers) listK8sEvents(c *fiber.Ctx, p listParams, cluster, namespace string, opts metav1.ListOptions, fallback *mcpFallback) error {
+	if cluster != "" {
+		events, err := h.k8sClient.ListEvents(c.UserContext(), cluster, namespace, opts)
+		if err != nil {
+			return listError(c, err)
+		}
+		return listResponse(c, p.withoutSelectors(), events, k8sResult(fiber.Map{"cluster": cluster}, fallback))
+	}
+
+	result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.Event, error) {
+		return h.k8sClient.ListEvents(ctx, cluster, namespace, opts)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		return result.Items[i].LastSeen.After(result.Items[j].LastSeen)
+	})
+	extra := fiber.Map{"clusterCount": result.Clusters, "clusterErrors": result.Errors}
+	return listResponse(c, p.withoutSelectors(), result.Items, k8sResult(extra, fallback))
+}
+
+// GetEvents returns events from a cluster, or from all clusters when none is
+// given
 func (h *MCPHandlers) GetEvents(c *fiber.Ctx) error {
 	cluster := c.Query("cluster")
 	namespace := c.Query("namespace")
-	limit := c.QueryInt("limit", 50)
+
+	p, done, err := startList(c, defaultEventLimit)
+	if done {
+		return err
+	}
 
 	// Try MCP bridge first
+	var fallback *mcpFallback
 	if h.bridge != nil {
-		events, err := h.bridge.GetEvents(c.Context(), cluster, namespace, limit)
+		events, err := h.bridge.GetEvents(c.UserContext(), cluster, namespace, maxListedEvents)
 		if err == nil {
-			return c.JSON(fiber.Map{"events": events, "source": "mcp"})
+			return listResponse(c, p, events, fiber.Map{"source": sourceMCP})
 		}
-		log.Printf("MCP bridge GetEvents failed, falling back: %v", err)
+		fallback = newMCPFallback("GetEvents", err)
 	}
 
 	// Fall back to direct k8s client
 	if h.k8sClient != nil {
-		// If no cluster specified, query first available cluster
-		if cluster == "" {
-			clusters, err := h.k8sClient.ListClusters(c.Context())
-			if err != nil || len(clusters) == 0 {
-				return c.JSON(fiber.Map{"events": []k8s.Event{}, "source": "k8s"})
-			}
-			// Get events from current context cluster
-			for _, cl := range clusters {
-				if cl.IsCurrent {
-					cluster = cl.Name
-					break
-				}
-			}
-			if cluster == "" {
-				cluster = clusters[0].Name
-			}
-		}
-
-		events, err := h.k8sClient.GetEvents(c.Context(), cluster, namespace, limit)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-		return c.JSON(fiber.Map{"events": events, "source": "k8s", "cluster": cluster})
+		return h.listK8sEvents(c, p, cluster, namespace, p.selectorOptions(), fallback)
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
 }
 
-// GetWarningEvents returns warning events from a cluster
+// GetWarningEvents returns warning events from a cluster, or from all
+// clusters when none is given
 func (h *MCPHandlers) GetWarningEvents(c *fiber.Ctx) error {
 	cluster := c.Query("cluster")
 	namespace := c.Query("namespace")
-	limit := c.QueryInt("limit", 50)
+
+	p, done, err := startList(c, defaultEventLimit)
+	if done {
+		return err
+	}
 
 	// Try MCP bridge first
+	var fallback *mcpFallback
 	if h.bridge != nil {
-		events, err := h.bridge.GetWarningEvents(c.Context(), cluster, namespace, limit)
+		events, err := h.bridge.GetWarningEvents(c.UserContext(), cluster, namespace, maxListedEvents)
 		if err == nil {
-			return c.JSON(fiber.Map{"events": events, "source": "mcp"})
+			return listResponse(c, p, events, fiber.Map{"source": sourceMCP})
 		}
-		log.Printf("MCP bridge GetWarningEvents failed, falling back: %v", err)
+		fallback = newMCPFallback("GetWarningEvents", err)
 	}
 
 	// Fall back to direct k8s client
 	if h.k8sClient != nil {
-		// If no cluster specified, query first available cluster
-		if cluster == "" {
-			clusters, err := h.k8sClient.ListClusters(c.Context())
-			if err != nil || len(clusters) == 0 {
-				return c.JSON(fiber.Map{"events": []k8s.Event{}, "source": "k8s"})
-			}
-			for _, cl := range clusters {
-				if cl.IsCurrent {
-					cluster = cl.Name
-					break
-				}
-			}
-			if cluster == "" {
-				cluster = clusters[0].Name
-			}
-		}
-
-		events, err := h.k8sClient.GetWarningEvents(c.Context(), cluster, namespace, limit)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-		return c.JSON(fiber.Map{"events": events, "source": "k8s", "cluster": cluster})
+		opts := p.selectorOptions()
+		opts.FieldSelector = warningFieldSelector(opts.FieldSelector)
+		return h.listK8sEvents(c, p, cluster, namespace, opts, fallback)
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
@@ -400,25 +557,20 @@ func (h *MCPHandlers) CheckSecurityIssues(c *fiber.Ctx) error {
 	if h.k8sClient != nil {
 		// If no cluster specified, query all clusters
 		if cluster == "" {
-			clusters, err := h.k8sClient.ListClusters(c.Context())
+			result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.SecurityIssue, error) {
+				return h.k8sClient.CheckSecurityIssues(ctx, cluster, namespace)
+			})
 			if err != nil {
 				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 			}
-			var allIssues []k8s.SecurityIssue
-			for _, cl := range clusters {
-				issues, err := h.k8sClient.CheckSecurityIssues(c.Context(), cl.Name, namespace)
-				if err == nil {
-					allIssues = append(allIssues, issues...)
-				}
-			}
-			return c.JSON(fiber.Map{"issues": allIssues, "source": "k8s"})
+			return c.JSON(k8sResult(fanOutResponse("issues", result), nil))
 		}
 
-		issues, err := h.k8sClient.CheckSecurityIssues(c.Context(), cluster, namespace)
+		issues, err := h.k8sClient.CheckSecurityIssues(c.UserContext(), cluster, namespace)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
-		return c.JSON(fiber.Map{"issues": issues, "source": "k8s"})
+		return c.JSON(fiber.Map{"issues": issues, "source": sourceK8s})
 	}
 
 	return c.Status(503).JSON(fiber.Map{"error": "No cluster access available"})
@@ -428,6 +580,9 @@ func (h *MCPHandlers) CheckSecurityIssues(c *fiber.Ctx) error {
 type CallToolRequest struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
+	// ProgressToken, when set, streams the tool's progress to the caller's
+	// WebSocket as mcp_progress messages tagged with this token
+	ProgressToken string `json:"progressToken,omitempty"`
 }
 
 // CallOpsTool calls a klaude-ops tool
@@ -441,9 +596,9 @@ func (h *MCPHandlers) CallOpsTool(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 	}
 
-	result, err := h.bridge.CallOpsTool(c.Context(), req.Name, req.Arguments)
+	result, err := h.bridge.CallOpsTool(c.UserContext(), req.Name, req.Arguments)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return toolError(c, err)
 	}
 
 	return c.JSON(result)
@@ -460,10 +615,260 @@ func (h *MCPHandlers) CallDeployTool(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 	}
 
-	result, err := h.bridge.CallDeployTool(c.Context(), req.Name, req.Arguments)
+	result, err := h.bridge.CallDeployTool(c.UserContext(), req.Name, req.Arguments)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return toolError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// ListServers returns all registered MCP servers
+func (h *MCPHandlers) ListServers(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.JSON(fiber.Map{"servers": []mcp.ServerStatus{}})
+	}
+	return c.JSON(fiber.Map{"servers": h.bridge.Servers()})
+}
+
+// GetServerTools returns the tools of a named MCP server
+func (h *MCPHandlers) GetServerTools(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	name := c.Params("name")
+	tools, err := h.bridge.GetTools(name)
+	if err != nil {
+		return toolError(c, err)
+	}
+	return c.JSON(fiber.Map{"server": name, "tools": tools})
+}
+
+// CallServerTool calls a tool on a named MCP server
+func (h *MCPHandlers) CallServerTool(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	var req CallToolRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	var onProgress func(mcp.Progress)
+	if req.ProgressToken != "" && h.hub != nil {
+		onProgress = h.progressReporter(middleware.GetUserID(c), c.Params("name"), c.Params("tool"), req.ProgressToken)
+	}
+
+	result, err := h.bridge.CallToolWithProgress(c.UserContext(), c.Params("name"), c.Params("tool"), req.Arguments, onProgress)
+	if err != nil {
+		return toolError(c, err)
 	}
 
 	return c.JSON(result)
 }
+
+// MCPProgress is the payload of mcp_progress WebSocket messages
+type MCPProgress struct {
+	Server        string  `json:"server"`
+	Tool          string  `json:"tool"`
+	ProgressToken string  `json:"progressToken"`
+	Progress      float64 `json:"progress"`
+	Total         float64 `json:"total,omitempty"`
+	Message       string  `json:"message,omitempty"`
+}
+
+// progressReporter returns a callback that forwards tool progress to the
+// user's WebSocket clients, or to everyone when there is no user (dev mode)
+func (h *MCPHandlers) progressReporter(userID uuid.UUID, server, tool, token string) func(mcp.Progress) {
+	return func(p mcp.Progress) {
+		msg := Message{Type: "mcp_progress", Data: MCPProgress{
+			Server:        server,
+			Tool:          tool,
+			ProgressToken: token,
+			Progress:      p.Progress,
+			Total:         p.Total,
+			Message:       p.Message,
+		}}
+		if userID == uuid.Nil {
+			h.hub.BroadcastAll(msg)
+			return
+		}
+		h.hub.Broadcast(userID, msg)
+	}
+}
+
+// ListServerResources returns the resources of a named MCP server
+func (h *MCPHandlers) ListServerResources(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	name := c.Params("name")
+	resources, err := h.bridge.ListResources(c.UserContext(), name)
+	if err != nil {
+		return toolError(c, err)
+	}
+	return c.JSON(fiber.Map{"server": name, "resources": resources})
+}
+
+// ReadServerResource returns the contents of the resource given by ?uri=
+func (h *MCPHandlers) ReadServerResource(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	uri := c.Query("uri")
+	if uri == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "uri is required"})
+	}
+
+	result, err := h.bridge.ReadResource(c.UserContext(), c.Params("name"), uri)
+	if err != nil {
+		return toolError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// ResourceSubscriptionRequest names a resource to (un)subscribe
+type ResourceSubscriptionRequest struct {
+	URI string `json:"uri"`
+}
+
+// SubscribeServerResource subscribes to updates of a resource; updates are
+// broadcast as mcp_notification messages
+func (h *MCPHandlers) SubscribeServerResource(c *fiber.Ctx) error {
+	return h.updateSubscription(c, h.bridge.SubscribeResource)
+}
+
+// UnsubscribeServerResource cancels a resource subscription
+func (h *MCPHandlers) UnsubscribeServerResource(c *fiber.Ctx) error {
+	return h.updateSubscription(c, h.bridge.UnsubscribeResource)
+}
+
+func (h *MCPHandlers) updateSubscription(c *fiber.Ctx, update func(ctx context.Context, server, uri string) error) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	var req ResourceSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.URI == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "uri is required"})
+	}
+
+	if err := update(c.UserContext(), c.Params("name"), req.URI); err != nil {
+		return toolError(c, err)
+	}
+	return c.JSON(fiber.Map{"server": c.Params("name"), "uri": req.URI})
+}
+
+// ListServerPrompts returns the prompts of a named MCP server
+func (h *MCPHandlers) ListServerPrompts(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	name := c.Params("name")
+	prompts, err := h.bridge.ListPrompts(c.UserContext(), name)
+	if err != nil {
+		return toolError(c, err)
+	}
+	return c.JSON(fiber.Map{"server": name, "prompts": prompts})
+}
+
+// GetPromptRequest holds the arguments of a prompt
+type GetPromptRequest struct {
+	Arguments map[string]string `json:"arguments"`
+}
+
+// GetServerPrompt renders a prompt of a named MCP server
+func (h *MCPHandlers) GetServerPrompt(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	var req GetPromptRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	result, err := h.bridge.GetPrompt(c.UserContext(), c.Params("name"), c.Params("prompt"), req.Arguments)
+	if err != nil {
+		return toolError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// GetServerLogs returns the recent log messages of a named MCP server
+func (h *MCPHandlers) GetServerLogs(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	name := c.Params("name")
+	logs, err := h.bridge.Logs(name)
+	if err != nil {
+		return toolError(c, err)
+	}
+	return c.JSON(fiber.Map{"server": name, "logs": logs})
+}
+
+// GetToolSchema returns the input schema of a tool so the frontend can
+// render a form for it. ?server= picks the server when several offer the
+// same tool name.
+func (h *MCPHandlers) GetToolSchema(c *fiber.Ctx) error {
+	if h.bridge == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "MCP bridge not available"})
+	}
+
+	server, tool, err := h.bridge.FindTool(c.Query("server"), c.Params("name"))
+	if err != nil {
+		return toolError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"server":      server,
+		"name":        tool.Name,
+		"description": tool.Description,
+		"inputSchema": tool.InputSchema,
+	})
+}
+
+// toolError writes an MCP error response; argument validation failures
+// list each violation
+func toolError(c *fiber.Ctx, err error) error {
+	var invalid *mcp.ValidationErrors
+	if errors.As(err, &invalid) && !invalid.Output {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":      mcp.ErrInvalidArguments.Error(),
+			"tool":       invalid.Tool,
+			"violations": invalid.Violations,
+		})
+	}
+	return c.Status(toolErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+}
+
+// toolErrorStatus maps MCP errors to HTTP status codes; 503 covers servers
+// that are down or being restarted, 504 calls that timed out
+func toolErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, mcp.ErrServerNotFound), errors.Is(err, mcp.ErrToolNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, mcp.ErrServerNotAvailable), errors.Is(err, mcp.ErrServerExited):
+		return fiber.StatusServiceUnavailable
+	case errors.Is(err, mcp.ErrNotSupported):
+		return fiber.StatusNotImplemented
+	case errors.Is(err, mcp.ErrInvalidOutput), errors.Is(err, mcp.ErrUnparseableResult):
+		return fiber.StatusBadGateway
+	case errors.Is(err, context.DeadlineExceeded):
+		return fiber.StatusGatewayTimeout
+	}
+	return fiber.StatusInternalServerError
+}
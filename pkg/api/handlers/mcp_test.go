@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/mcp"
+)
+
+func TestToolError(t *testing.T) {
+	invalid := &mcp.ValidationErrors{Tool: "scale", Violations: []mcp.ValidationError{
+		{Path: "replicas", Message: "expected integer, got string"},
+	}}
+
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		violations int
+	}{
+		{"invalid arguments", fmt.Errorf("call failed: %w", invalid), fiber.StatusBadRequest, 1},
+		{"invalid output", &mcp.ValidationErrors{Tool: "scale", Output: true, Violations: invalid.Violations}, fiber.StatusBadGateway, 0},
+		{"unknown tool", mcp.ErrToolNotFound, fiber.StatusNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error { return toolError(c, tt.err) })
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+
+			var body struct {
+				Error      string                `json:"error"`
+				Tool       string                `json:"tool"`
+				Violations []mcp.ValidationError `json:"violations"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Violations) != tt.violations {
+				t.Fatalf("body = %+v", body)
+			}
+			if tt.violations > 0 && (body.Tool != "scale" || body.Violations[0].Path != "replicas" || body.Error != mcp.ErrInvalidArguments.Error()) {
+				t.Fatalf("body = %+v", body)
+			}
+		})
+	}
+}
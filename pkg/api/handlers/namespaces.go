@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"sync"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/kubestellar/console/pkg/api/middleware"
 	"github.com/kubestellar/console/pkg/k8s"
@@ -9,15 +13,26 @@ import (
 	"github.com/kubestellar/console/pkg/store"
 )
 
+// namespaceAnalysisTTL is how long a namespace analysis is served from cache
+const namespaceAnalysisTTL = 30 * time.Second
+
 // NamespaceHandler handles namespace management operations
 type NamespaceHandler struct {
 	store     store.Store
 	k8sClient *k8s.MultiClusterClient
+
+	analysisMu sync.Mutex
+	analyses   map[string]cachedAnalysis // by cluster/namespace
+}
+
+type cachedAnalysis struct {
+	analysis *k8s.NamespaceAnalysis
+	expires  time.Time
 }
 
 // NewNamespaceHandler creates a new namespace handler
 func NewNamespaceHandler(s store.Store, k8sClient *k8s.MultiClusterClient) *NamespaceHandler {
-	return &NamespaceHandler{store: s, k8sClient: k8sClient}
+	return &NamespaceHandler{store: s, k8sClient: k8sClient, analyses: make(map[string]cachedAnalysis)}
 }
 
 // ListNamespaces returns namespaces for a cluster
@@ -31,7 +46,7 @@ func (h *NamespaceHandler) ListNamespaces(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cluster parameter required")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 	namespaces, err := h.k8sClient.ListNamespacesWithDetails(ctx, cluster)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list namespaces: "+err.Error())
@@ -40,6 +55,52 @@ func (h *NamespaceHandler) ListNamespaces(c *fiber.Ctx) error {
 	return c.JSON(namespaces)
 }
 
+// GetNamespaceAnalysis returns workload health, quota, limit range and PVC
+// usage, warning events, security issues, access and network policy
+// coverage of a namespace. Results are cached briefly; ?refresh=true
+// recomputes them.
+func (h *NamespaceHandler) GetNamespaceAnalysis(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster := c.Query("cluster")
+	name := c.Params("name")
+	if cluster == "" || name == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Cluster and namespace name are required")
+	}
+
+	key := cluster + "/" + name
+	now := time.Now()
+	if !c.QueryBool("refresh") {
+		h.analysisMu.Lock()
+		cached, ok := h.analyses[key]
+		h.analysisMu.Unlock()
+		if ok && now.Before(cached.expires) {
+			return c.JSON(cached.analysis)
+		}
+	}
+
+	analysis, err := h.k8sClient.AnalyzeNamespace(c.UserContext(), cluster, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "Namespace not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to analyze namespace: "+err.Error())
+	}
+
+	h.analysisMu.Lock()
+	for k, cached := range h.analyses {
+		if now.After(cached.expires) {
+			delete(h.analyses, k)
+		}
+	}
+	h.analyses[key] = cachedAnalysis{analysis: analysis, expires: now.Add(namespaceAnalysisTTL)}
+	h.analysisMu.Unlock()
+
+	return c.JSON(analysis)
+}
+
 // CreateNamespace creates a new namespace
 func (h *NamespaceHandler) CreateNamespace(c *fiber.Ctx) error {
 	if h.k8sClient == nil {
@@ -48,7 +109,7 @@ func (h *NamespaceHandler) CreateNamespace(c *fiber.Ctx) error {
 
 	// Check if current user is console admin
 	currentUserID := middleware.GetUserID(c)
-	currentUser, err := h.store.GetUser(currentUserID)
+	currentUser, err := h.store.GetUser(c.UserContext(), currentUserID)
 	if err != nil || currentUser == nil || currentUser.Role != "admin" {
 		return fiber.NewError(fiber.StatusForbidden, "Console admin access required")
 	}
@@ -62,7 +123,7 @@ func (h *NamespaceHandler) CreateNamespace(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cluster and name are required")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Check if user has cluster-admin access on the target cluster
 	isAdmin, err := h.k8sClient.CheckClusterAdminAccess(ctx, req.Cluster)
@@ -89,7 +150,7 @@ func (h *NamespaceHandler) DeleteNamespace(c *fiber.Ctx) error {
 
 	// Check if current user is console admin
 	currentUserID := middleware.GetUserID(c)
-	currentUser, err := h.store.GetUser(currentUserID)
+	currentUser, err := h.store.GetUser(c.UserContext(), currentUserID)
 	if err != nil || currentUser == nil || currentUser.Role != "admin" {
 		return fiber.NewError(fiber.StatusForbidden, "Console admin access required")
 	}
@@ -100,7 +161,7 @@ func (h *NamespaceHandler) DeleteNamespace(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cluster and namespace name are required")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Check if user has cluster-admin access on the target cluster
 	isAdmin, err := h.k8sClient.CheckClusterAdminAccess(ctx, cluster)
@@ -127,7 +188,7 @@ func (h *NamespaceHandler) GetNamespaceAccess(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cluster and namespace name are required")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 	bindings, err := h.k8sClient.ListRoleBindings(ctx, cluster, name)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list role bindings: "+err.Error())
@@ -163,7 +224,7 @@ func (h *NamespaceHandler) GrantNamespaceAccess(c *fiber.Ctx) error {
 
 	// Check if current user is console admin
 	currentUserID := middleware.GetUserID(c)
-	currentUser, err := h.store.GetUser(currentUserID)
+	currentUser, err := h.store.GetUser(c.UserContext(), currentUserID)
 	if err != nil || currentUser == nil || currentUser.Role != "admin" {
 		return fiber.NewError(fiber.StatusForbidden, "Console admin access required")
 	}
@@ -187,7 +248,7 @@ func (h *NamespaceHandler) GrantNamespaceAccess(c *fiber.Ctx) error {
 		req.Role = "admin"
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Check if user has cluster-admin access on the target cluster
 	isAdmin, err := h.k8sClient.CheckClusterAdminAccess(ctx, req.Cluster)
@@ -214,7 +275,7 @@ func (h *NamespaceHandler) RevokeNamespaceAccess(c *fiber.Ctx) error {
 
 	// Check if current user is console admin
 	currentUserID := middleware.GetUserID(c)
-	currentUser, err := h.store.GetUser(currentUserID)
+	currentUser, err := h.store.GetUser(c.UserContext(), currentUserID)
 	if err != nil || currentUser == nil || currentUser.Role != "admin" {
 		return fiber.NewError(fiber.StatusForbidden, "Console admin access required")
 	}
@@ -227,7 +288,7 @@ func (h *NamespaceHandler) RevokeNamespaceAccess(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cluster, namespace, and binding name are required")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Check if user has cluster-admin access on the target cluster
 	isAdmin, err := h.k8sClient.CheckClusterAdminAccess(ctx, cluster)
@@ -44,7 +44,7 @@ func (h *OnboardingHandler) SaveResponses(c *fiber.Ctx) error {
 			QuestionKey: r.QuestionKey,
 			Answer:      r.Answer,
 		}
-		if err := h.store.SaveOnboardingResponse(response); err != nil {
+		if err := h.store.SaveOnboardingResponse(c.UserContext(), response); err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to save response")
 		}
 	}
@@ -57,7 +57,7 @@ func (h *OnboardingHandler) CompleteOnboarding(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 
 	// Get user's responses
-	responses, err := h.store.GetOnboardingResponses(userID)
+	responses, err := h.store.GetOnboardingResponses(c.UserContext(), userID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get responses")
 	}
@@ -71,7 +71,7 @@ func (h *OnboardingHandler) CompleteOnboarding(c *fiber.Ctx) error {
 		Name:      "My Dashboard",
 		IsDefault: true,
 	}
-	if err := h.store.CreateDashboard(dashboard); err != nil {
+	if err := h.store.CreateDashboard(c.UserContext(), dashboard); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create dashboard")
 	}
 
@@ -84,13 +84,13 @@ func (h *OnboardingHandler) CompleteOnboarding(c *fiber.Ctx) error {
 			W: 4,
 			H: 3,
 		}
-		if err := h.store.CreateCard(&card); err != nil {
+		if err := h.store.CreateCard(c.UserContext(), &card); err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to create card")
 		}
 	}
 
 	// Mark user as onboarded
-	if err := h.store.SetUserOnboarded(userID); err != nil {
+	if err := h.store.SetUserOnboarded(c.UserContext(), userID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to complete onboarding")
 	}
 
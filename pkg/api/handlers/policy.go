@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// PolicyHandler serves policy engine results read from the clusters
+type PolicyHandler struct {
+	k8sClient *k8s.MultiClusterClient
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(k8sClient *k8s.MultiClusterClient) *PolicyHandler {
+	return &PolicyHandler{k8sClient: k8sClient}
+}
+
+// ListViolations returns Gatekeeper and Kyverno violations of one cluster
+// or all of them as a list (see list.go), with per-policy aggregates in
+// policies, the engines found per cluster in engines and what could not be
+// read per cluster and engine in engineErrors. Filter by engine or
+// enforcement action with fieldSelector, e.g. engine=kyverno.
+func (h *PolicyHandler) ListViolations(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster := c.Query("cluster")
+	namespace := c.Query("namespace")
+
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	var result k8s.FanOutResult[k8s.ClusterPolicyViolations]
+	if cluster == "" {
+		result, err = k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.ClusterPolicyViolations, error) {
+			violations, err := h.k8sClient.GetPolicyViolations(ctx, cluster, namespace)
+			if err != nil {
+				return nil, err
+			}
+			return []k8s.ClusterPolicyViolations{*violations}, nil
+		})
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+		}
+	} else {
+		violations, err := h.k8sClient.GetPolicyViolations(c.UserContext(), cluster, namespace)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get policy violations: "+err.Error())
+		}
+		result.Items = []k8s.ClusterPolicyViolations{*violations}
+		result.Clusters = 1
+		result.Errors = []k8s.ClusterError{}
+	}
+
+	violations := []k8s.PolicyViolation{}
+	engines := map[string][]string{}
+	engineErrors := map[string]map[string]string{}
+	for _, cluster := range result.Items {
+		violations = append(violations, cluster.Violations...)
+		engines[cluster.Cluster] = cluster.Engines
+		if len(cluster.Errors) > 0 {
+			engineErrors[cluster.Cluster] = cluster.Errors
+		}
+	}
+
+	return listResponse(c, p, violations, fiber.Map{
+		"policies":      k8s.MergePolicySummaries(result.Items),
+		"engines":       engines,
+		"engineErrors":  engineErrors,
+		"clusterCount":  result.Clusters,
+		"clusterErrors": result.Errors,
+	})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
@@ -30,24 +32,29 @@ func NewRBACHandler(s store.Store, k8sClient *k8s.MultiClusterClient) *RBACHandl
 func (h *RBACHandler) ListConsoleUsers(c *fiber.Ctx) error {
 	// Check if current user is admin
 	userID := middleware.GetUserID(c)
-	currentUser, err := h.store.GetUser(userID)
+	currentUser, err := h.store.GetUser(c.UserContext(), userID)
 	if err != nil || currentUser == nil {
 		return fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
 	}
 
-	users, err := h.store.ListUsers()
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	users, err := h.store.ListUsers(c.UserContext())
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list users")
 	}
 
-	return c.JSON(users)
+	return listResponse(c, p, users, nil)
 }
 
 // UpdateUserRole updates a user's role (admin only)
 func (h *RBACHandler) UpdateUserRole(c *fiber.Ctx) error {
 	// Check if current user is admin
 	currentUserID := middleware.GetUserID(c)
-	currentUser, err := h.store.GetUser(currentUserID)
+	currentUser, err := h.store.GetUser(c.UserContext(), currentUserID)
 	if err != nil || currentUser == nil || currentUser.Role != "admin" {
 		return fiber.NewError(fiber.StatusForbidden, "Admin access required")
 	}
@@ -78,7 +85,7 @@ func (h *RBACHandler) UpdateUserRole(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cannot remove your own admin role")
 	}
 
-	if err := h.store.UpdateUserRole(targetID, string(req.Role)); err != nil {
+	if err := h.store.UpdateUserRole(c.UserContext(), targetID, string(req.Role)); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update user role")
 	}
 
@@ -89,7 +96,7 @@ func (h *RBACHandler) UpdateUserRole(c *fiber.Ctx) error {
 func (h *RBACHandler) DeleteConsoleUser(c *fiber.Ctx) error {
 	// Check if current user is admin
 	currentUserID := middleware.GetUserID(c)
-	currentUser, err := h.store.GetUser(currentUserID)
+	currentUser, err := h.store.GetUser(c.UserContext(), currentUserID)
 	if err != nil || currentUser == nil || currentUser.Role != "admin" {
 		return fiber.NewError(fiber.StatusForbidden, "Admin access required")
 	}
@@ -109,7 +116,7 @@ func (h *RBACHandler) DeleteConsoleUser(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cannot delete your own account")
 	}
 
-	if err := h.store.DeleteUser(targetID); err != nil {
+	if err := h.store.DeleteUser(c.UserContext(), targetID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete user")
 	}
 
@@ -121,7 +128,7 @@ func (h *RBACHandler) GetUserManagementSummary(c *fiber.Ctx) error {
 	summary := models.UserManagementSummary{}
 
 	// Count console users by role
-	admins, editors, viewers, err := h.store.CountUsersByRole()
+	admins, editors, viewers, err := h.store.CountUsersByRole(c.UserContext())
 	if err == nil {
 		summary.ConsoleUsers.Total = admins + editors + viewers
 		summary.ConsoleUsers.Admins = admins
@@ -131,7 +138,7 @@ func (h *RBACHandler) GetUserManagementSummary(c *fiber.Ctx) error {
 
 	// Count K8s service accounts (if k8s client is available)
 	if h.k8sClient != nil {
-		ctx := c.Context()
+		ctx := c.UserContext()
 		total, clusters, err := h.k8sClient.CountServiceAccountsAllClusters(ctx)
 		if err == nil {
 			summary.K8sServiceAccounts.Total = total
@@ -157,7 +164,12 @@ func (h *RBACHandler) ListK8sServiceAccounts(c *fiber.Ctx) error {
 	cluster := c.Query("cluster")
 	namespace := c.Query("namespace")
 
-	ctx := c.Context()
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	ctx := c.UserContext()
 
 	if cluster != "" {
 		// Get SAs from specific cluster
@@ -165,25 +177,21 @@ func (h *RBACHandler) ListK8sServiceAccounts(c *fiber.Ctx) error {
 		if err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to list service accounts: "+err.Error())
 		}
-		return c.JSON(sas)
+		return listResponse(c, p, sas, nil)
 	}
 
-	// Get SAs from all clusters
-	clusters, err := h.k8sClient.ListClusters(ctx)
+	// Get SAs from all clusters, skipping clusters we can't access
+	result, err := k8s.FanOutAll(ctx, h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]models.K8sServiceAccount, error) {
+		return h.k8sClient.ListServiceAccounts(ctx, cluster, namespace)
+	})
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
 	}
 
-	var allSAs []models.K8sServiceAccount
-	for _, cl := range clusters {
-		sas, err := h.k8sClient.ListServiceAccounts(ctx, cl.Name, namespace)
-		if err != nil {
-			continue // Skip clusters we can't access
-		}
-		allSAs = append(allSAs, sas...)
-	}
-
-	return c.JSON(allSAs)
+	return listResponse(c, p, result.Items, fiber.Map{
+		"clusterCount":  result.Clusters,
+		"clusterErrors": result.Errors,
+	})
 }
 
 // ListK8sRoles returns roles from clusters
@@ -196,7 +204,12 @@ func (h *RBACHandler) ListK8sRoles(c *fiber.Ctx) error {
 	namespace := c.Query("namespace")
 	includeSystem := c.Query("includeSystem") == "true"
 
-	ctx := c.Context()
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	ctx := c.UserContext()
 
 	if cluster != "" {
 		// Get roles from specific cluster
@@ -213,7 +226,7 @@ func (h *RBACHandler) ListK8sRoles(c *fiber.Ctx) error {
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to list cluster roles")
 		}
 		roles = append(roles, clusterRoles...)
-		return c.JSON(roles)
+		return listResponse(c, p, roles, nil)
 	}
 
 	// Return error if no cluster specified
@@ -230,7 +243,12 @@ func (h *RBACHandler) ListK8sRoleBindings(c *fiber.Ctx) error {
 	namespace := c.Query("namespace")
 	includeSystem := c.Query("includeSystem") == "true"
 
-	ctx := c.Context()
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	ctx := c.UserContext()
 
 	if cluster == "" {
 		return fiber.NewError(fiber.StatusBadRequest, "Cluster parameter required")
@@ -252,7 +270,7 @@ func (h *RBACHandler) ListK8sRoleBindings(c *fiber.Ctx) error {
 	}
 	bindings = append(bindings, clusterBindings...)
 
-	return c.JSON(bindings)
+	return listResponse(c, p, bindings, nil)
 }
 
 // GetClusterPermissions returns current user's permissions on clusters
@@ -261,7 +279,7 @@ func (h *RBACHandler) GetClusterPermissions(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 	cluster := c.Query("cluster")
 
 	if cluster != "" {
@@ -295,7 +313,7 @@ func (h *RBACHandler) CreateServiceAccount(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Name, namespace, and cluster are required")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Check if user has cluster-admin access
 	isAdmin, err := h.k8sClient.CheckClusterAdminAccess(ctx, req.Cluster)
@@ -326,7 +344,7 @@ func (h *RBACHandler) CreateRoleBinding(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Missing required fields")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Check if user has cluster-admin access
 	isAdmin, err := h.k8sClient.CheckClusterAdminAccess(ctx, req.Cluster)
@@ -352,13 +370,18 @@ func (h *RBACHandler) ListK8sUsers(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cluster parameter required")
 	}
 
-	ctx := c.Context()
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	ctx := c.UserContext()
 	users, err := h.k8sClient.GetAllK8sUsers(ctx, cluster)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list K8s users")
 	}
 
-	return c.JSON(users)
+	return listResponse(c, p, users, nil)
 }
 
 // GetPermissionsSummary returns permission summaries for all clusters
@@ -367,7 +390,7 @@ func (h *RBACHandler) GetPermissionsSummary(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 	summaries, err := h.k8sClient.GetAllPermissionsSummaries(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get permissions summary: "+err.Error())
@@ -408,7 +431,7 @@ func (h *RBACHandler) CheckCanI(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cluster, verb, and resource are required")
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 	result, err := h.k8sClient.CheckCanI(ctx, req.Cluster, req)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to check permission: "+err.Error())
@@ -419,3 +442,62 @@ func (h *RBACHandler) CheckCanI(c *fiber.Ctx) error {
 		Reason:  result.Reason,
 	})
 }
+
+// GetRBACAnalysis returns each subject's effective permissions with a risk
+// score, plus orphaned bindings, for ?cluster= or every cluster. System
+// subjects and bindings are included with includeSystem=true.
+func (h *RBACHandler) GetRBACAnalysis(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster := c.Query("cluster")
+	includeSystem := c.Query("includeSystem") == "true"
+
+	if cluster != "" {
+		analysis, err := h.k8sClient.AnalyzeRBAC(c.UserContext(), cluster, includeSystem)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to analyze RBAC: "+err.Error())
+		}
+		return c.JSON(analysis)
+	}
+
+	result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.RBACAnalysis, error) {
+		analysis, err := h.k8sClient.AnalyzeRBAC(ctx, cluster, includeSystem)
+		if err != nil {
+			return nil, err
+		}
+		return []k8s.RBACAnalysis{*analysis}, nil
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+	}
+	return c.JSON(fanOutResponse("analyses", result))
+}
+
+// WhoCan returns the subjects allowed to perform an action, each with the
+// bindings that grant it: the reverse of CheckCanI
+func (h *RBACHandler) WhoCan(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster := c.Query("cluster")
+	req := k8s.WhoCanRequest{
+		Verb:        c.Query("verb"),
+		Resource:    c.Query("resource"),
+		Group:       c.Query("group"),
+		Subresource: c.Query("subresource"),
+		Namespace:   c.Query("namespace"),
+		Name:        c.Query("name"),
+	}
+	if cluster == "" || req.Verb == "" || req.Resource == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Cluster, verb, and resource are required")
+	}
+
+	result, err := h.k8sClient.WhoCan(c.UserContext(), cluster, req)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to look up who can: "+err.Error())
+	}
+	return c.JSON(result)
+}
@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// rolloutPollInterval is how often tracked rollouts are checked
+	rolloutPollInterval = 3 * time.Second
+	// rolloutPollTimeout bounds each poll. It is longer than the 10s
+	// Kubernetes client timeout, so a slow but healthy cluster answers
+	// instead of counting as a failure.
+	rolloutPollTimeout = 15 * time.Second
+	// rolloutStallTimeout is how long a rollout may go without progress
+	// before it is reported as stalled. Deployments also report their own
+	// progress deadline; StatefulSets and DaemonSets have none.
+	rolloutStallTimeout = 10 * time.Minute
+	// rolloutMaxTrackAge is how long a rollout is tracked at most, so
+	// stalled rollouts that never recover are eventually dropped
+	rolloutMaxTrackAge = time.Hour
+	// rolloutMaxPollFailures drops a rollout whose status could not be read
+	// this many times in a row, e.g. on an unreachable cluster
+	rolloutMaxPollFailures = 5
+	// rolloutPollConcurrency bounds the rollouts polled at once
+	rolloutPollConcurrency = 10
+)
+
+// RolloutHandler tracks workload rollouts and performs rollout actions.
+// Rollouts in progress are polled as the user who viewed or changed them,
+// and their progress is sent to that user over the hub as rollout_progress
+// messages until they complete.
+type RolloutHandler struct {
+	store     store.Store
+	k8sClient *k8s.MultiClusterClient
+	hub       *Hub
+
+	mu       sync.Mutex
+	tracked  map[string]*trackedRollout // by user/cluster/resource/namespace/name
+	tracking bool                       // whether the tracker goroutine is running
+}
+
+type trackedRollout struct {
+	// userID is the user the rollout is polled as and reported to, so
+	// their agent clusters stay private
+	userID                             uuid.UUID
+	cluster, resource, namespace, name string
+
+	last         *k8s.RolloutStatus
+	lastProgress time.Time // when updated or available replicas last changed
+	since        time.Time // when tracking started
+	failures     int       // consecutive failed polls
+}
+
+// NewRolloutHandler creates a new rollout handler
+func NewRolloutHandler(s store.Store, k8sClient *k8s.MultiClusterClient, hub *Hub) *RolloutHandler {
+	return &RolloutHandler{store: s, k8sClient: k8sClient, hub: hub, tracked: make(map[string]*trackedRollout)}
+}
+
+// WorkloadResource returns middleware that sets the workload resource
+// (deployments, statefulsets or daemonsets) the rollout routes act on
+func WorkloadResource(resource string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("workloadResource", resource)
+		return c.Next()
+	}
+}
+
+// ListRollouts returns the rollout status of workloads as a list (see
+// list.go), from one cluster or all of them. Filter with fieldSelector on
+// phase or kind, e.g. phase=progressing.
+func (h *RolloutHandler) ListRollouts(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	cluster := c.Query("cluster")
+	namespace := c.Query("namespace")
+	extras := fiber.Map{}
+
+	var rollouts []k8s.RolloutStatus
+	if cluster == "" {
+		result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.RolloutStatus, error) {
+			return h.k8sClient.ListRollouts(ctx, cluster, namespace)
+		})
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+		}
+		rollouts = result.Items
+		extras["clusterCount"] = result.Clusters
+		extras["clusterErrors"] = result.Errors
+	} else {
+		rollouts, err = h.k8sClient.ListRollouts(c.UserContext(), cluster, namespace)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+
+	userID := middleware.GetUserID(c)
+	for i := range rollouts {
+		h.observe(userID, &rollouts[i])
+	}
+	return listResponse(c, p, rollouts, extras)
+}
+
+// GetRollout returns a workload's rollout status and revision history
+func (h *RolloutHandler) GetRollout(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster, resource, namespace, name, err := rolloutTarget(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.UserContext()
+	status, err := h.k8sClient.GetRolloutStatus(ctx, cluster, resource, namespace, name)
+	if err != nil {
+		return rolloutError(err)
+	}
+	revisions, err := h.k8sClient.GetRolloutHistory(ctx, cluster, resource, namespace, name)
+	if err != nil {
+		return rolloutError(err)
+	}
+
+	h.observe(middleware.GetUserID(c), status)
+	return c.JSON(fiber.Map{"rollout": status, "revisions": revisions})
+}
+
+// Rollback rolls a workload back to ?revision=, or to the previous
+// revision when none is given
+func (h *RolloutHandler) Rollback(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster, resource, namespace, name, err := h.authorize(c)
+	if err != nil {
+		return err
+	}
+
+	var revision int64
+	if s := c.Query("revision"); s != "" {
+		revision, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || revision < 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid revision")
+		}
+	}
+
+	revision, err = h.k8sClient.RollbackWorkload(c.UserContext(), cluster, resource, namespace, name, revision)
+	if err != nil {
+		return rolloutError(err)
+	}
+	return h.actionResponse(c, fiber.Map{"revision": revision})
+}
+
+// Pause pauses a Deployment's rollout
+func (h *RolloutHandler) Pause(c *fiber.Ctx) error {
+	return h.setPaused(c, true)
+}
+
+// Resume resumes a paused Deployment's rollout
+func (h *RolloutHandler) Resume(c *fiber.Ctx) error {
+	return h.setPaused(c, false)
+}
+
+func (h *RolloutHandler) setPaused(c *fiber.Ctx, paused bool) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster, resource, namespace, name, err := h.authorize(c)
+	if err != nil {
+		return err
+	}
+	if err := h.k8sClient.PauseRollout(c.UserContext(), cluster, resource, namespace, name, paused); err != nil {
+		return rolloutError(err)
+	}
+	return h.actionResponse(c, fiber.Map{})
+}
+
+// Restart replaces a workload's pods with a new rollout
+func (h *RolloutHandler) Restart(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster, resource, namespace, name, err := h.authorize(c)
+	if err != nil {
+		return err
+	}
+	if err := h.k8sClient.RestartWorkload(c.UserContext(), cluster, resource, namespace, name); err != nil {
+		return rolloutError(err)
+	}
+	return h.actionResponse(c, fiber.Map{})
+}
+
+// actionResponse returns the workload's rollout status after an action and
+// starts tracking it
+func (h *RolloutHandler) actionResponse(c *fiber.Ctx, body fiber.Map) error {
+	cluster, resource, namespace, name, _ := rolloutTarget(c)
+	body["success"] = true
+
+	status, err := h.k8sClient.GetRolloutStatus(c.UserContext(), cluster, resource, namespace, name)
+	if err != nil {
+		log.Printf("Failed to get rollout status of %s/%s in %s: %v", namespace, name, cluster, err)
+		return c.JSON(body)
+	}
+	// The controller has not observed the change yet, so track it
+	// regardless of the phase reported
+	h.track(middleware.GetUserID(c), cluster, resource, status)
+	body["rollout"] = status
+	return c.JSON(body)
+}
+
+// authorize checks that the user may change the workload: a console admin
+// or editor with patch access to it on the cluster
+func (h *RolloutHandler) authorize(c *fiber.Ctx) (cluster, resource, namespace, name string, err error) {
+	currentUser, err := h.store.GetUser(c.UserContext(), middleware.GetUserID(c))
+	if err != nil || currentUser == nil || (currentUser.Role != string(models.UserRoleAdmin) && currentUser.Role != string(models.UserRoleEditor)) {
+		return "", "", "", "", fiber.NewError(fiber.StatusForbidden, "Console editor access required")
+	}
+
+	cluster, resource, namespace, name, err = rolloutTarget(c)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	allowed, err := h.k8sClient.CheckGroupPermission(c.UserContext(), cluster, "patch", "apps", resource, namespace)
+	if err != nil || !allowed {
+		return "", "", "", "", fiber.NewError(fiber.StatusForbidden, "Permission to patch "+resource+" required on target cluster")
+	}
+	return cluster, resource, namespace, name, nil
+}
+
+// rolloutTarget returns the workload a rollout route refers to
+func rolloutTarget(c *fiber.Ctx) (cluster, resource, namespace, name string, err error) {
+	resource, _ = c.Locals("workloadResource").(string)
+	cluster = c.Query("cluster")
+	if cluster == "" {
+		return "", "", "", "", fiber.NewError(fiber.StatusBadRequest, "cluster parameter is required")
+	}
+	return cluster, resource, c.Params("ns"), c.Params("name"), nil
+}
+
+func rolloutError(err error) error {
+	switch {
+	case apierrors.IsNotFound(err), errors.Is(err, k8s.ErrRevisionNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, k8s.ErrRolloutUnsupported):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, k8s.ErrRolloutPaused), apierrors.IsConflict(err):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case apierrors.IsForbidden(err):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
+
+func rolloutKey(userID uuid.UUID, cluster, resource, namespace, name string) string {
+	return userID.String() + "/" + cluster + "/" + resource + "/" + namespace + "/" + name
+}
+
+// observe starts tracking a rollout a user saw in progress, and reports it
+// as stalled if the tracker has seen no progress for too long
+func (h *RolloutHandler) observe(userID uuid.UUID, status *k8s.RolloutStatus) {
+	resource := kindResource(status.Kind)
+
+	h.mu.Lock()
+	t, ok := h.tracked[rolloutKey(userID, status.Cluster, resource, status.Namespace, status.Name)]
+	if ok && t.last != nil && t.last.Phase == k8s.RolloutStalled && status.Phase == k8s.RolloutProgressing {
+		status.Phase = k8s.RolloutStalled
+		status.Message = t.last.Message
+	}
+	h.mu.Unlock()
+
+	if !ok && status.Phase == k8s.RolloutProgressing {
+		h.track(userID, status.Cluster, resource, status)
+	}
+}
+
+// track polls a rollout for a user until it completes, starting the
+// tracker if needed. Without a user there is no one to report to.
+func (h *RolloutHandler) track(userID uuid.UUID, cluster, resource string, status *k8s.RolloutStatus) {
+	if userID == uuid.Nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := rolloutKey(userID, cluster, resource, status.Namespace, status.Name)
+	if _, ok := h.tracked[key]; !ok {
+		h.tracked[key] = &trackedRollout{
+			userID:       userID,
+			cluster:      cluster,
+			resource:     resource,
+			namespace:    status.Namespace,
+			name:         status.Name,
+			lastProgress: time.Now(),
+			since:        time.Now(),
+		}
+	}
+	if !h.tracking {
+		h.tracking = true
+		go h.runTracker()
+	}
+}
+
+// runTracker polls tracked rollouts until there are none left. Each round
+// polls at most rolloutPollConcurrency rollouts at once, each within
+// rolloutPollTimeout; ticks missed while a round runs are skipped.
+func (h *RolloutHandler) runTracker() {
+	ticker := time.NewTicker(rolloutPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.mu.Lock()
+		if len(h.tracked) == 0 {
+			h.tracking = false
+			h.mu.Unlock()
+			return
+		}
+		rollouts := make([]*trackedRollout, 0, len(h.tracked))
+		for _, t := range h.tracked {
+			rollouts = append(rollouts, t)
+		}
+		h.mu.Unlock()
+
+		sem := make(chan struct{}, rolloutPollConcurrency)
+		var wg sync.WaitGroup
+		for _, t := range rollouts {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(t *trackedRollout) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				ctx, cancel := context.WithTimeout(k8s.WithUser(context.Background(), t.userID.String()), rolloutPollTimeout)
+				defer cancel()
+				h.poll(ctx, t)
+			}(t)
+		}
+		wg.Wait()
+	}
+}
+
+// poll checks one tracked rollout and broadcasts its progress if it changed.
+// Rollouts that are gone, keep failing or are tracked for too long are
+// dropped.
+func (h *RolloutHandler) poll(ctx context.Context, t *trackedRollout) {
+	key := rolloutKey(t.userID, t.cluster, t.resource, t.namespace, t.name)
+	status, err := h.k8sClient.GetRolloutStatus(ctx, t.cluster, t.resource, t.namespace, t.name)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if time.Since(t.since) > rolloutMaxTrackAge {
+		delete(h.tracked, key)
+		return
+	}
+	if err != nil {
+		t.failures++
+		if apierrors.IsNotFound(err) || t.failures >= rolloutMaxPollFailures {
+			delete(h.tracked, key)
+		}
+		return
+	}
+	t.failures = 0
+
+	now := time.Now()
+	last := t.last
+	if last == nil || status.Updated != last.Updated || status.Available != last.Available || status.Revision != last.Revision {
+		t.lastProgress = now
+	}
+	if status.Phase == k8s.RolloutProgressing && now.Sub(t.lastProgress) > rolloutStallTimeout {
+		status.Phase = k8s.RolloutStalled
+		status.Message = "no progress for " + rolloutStallTimeout.String()
+	}
+	t.last = status
+
+	if last == nil || rolloutChanged(last, status) {
+		h.hub.Broadcast(t.userID, Message{Type: "rollout_progress", Data: status})
+	}
+	// Stalled rollouts stay tracked in case they recover
+	if status.Phase == k8s.RolloutComplete || status.Phase == k8s.RolloutPaused {
+		delete(h.tracked, key)
+	}
+}
+
+func rolloutChanged(a, b *k8s.RolloutStatus) bool {
+	return a.Phase != b.Phase || a.Progress != b.Progress || a.Revision != b.Revision ||
+		a.Updated != b.Updated || a.Ready != b.Ready || a.Available != b.Available || a.Desired != b.Desired
+}
+
+// kindResource returns the workload resource for a RolloutStatus kind
+func kindResource(kind string) string {
+	switch kind {
+	case "StatefulSet":
+		return k8s.ResourceStatefulSets
+	case "DaemonSet":
+		return k8s.ResourceDaemonSets
+	}
+	return k8s.ResourceDeployments
+}
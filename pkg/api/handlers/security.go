@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// SecurityHandler serves the Pod Security Standards posture and secret
+// hygiene of clusters and manages rule suppressions
+type SecurityHandler struct {
+	store     store.Store
+	k8sClient *k8s.MultiClusterClient
+}
+
+// NewSecurityHandler creates a new security handler
+func NewSecurityHandler(s store.Store, k8sClient *k8s.MultiClusterClient) *SecurityHandler {
+	return &SecurityHandler{store: s, k8sClient: k8sClient}
+}
+
+// GetPodSecurityPosture evaluates pods against the baseline and restricted
+// profiles, for ?cluster= or every cluster, optionally in one ?namespace=
+func (h *SecurityHandler) GetPodSecurityPosture(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster := c.Query("cluster")
+	namespace := c.Query("namespace")
+
+	suppressions, err := h.store.ListPSSSuppressions(c.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list suppressions")
+	}
+
+	if cluster != "" {
+		posture, err := h.k8sClient.EvaluatePodSecurity(c.UserContext(), cluster, namespace, suppressions)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to evaluate pod security: "+err.Error())
+		}
+		return c.JSON(posture)
+	}
+
+	result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.PSSPosture, error) {
+		posture, err := h.k8sClient.EvaluatePodSecurity(ctx, cluster, namespace, suppressions)
+		if err != nil {
+			return nil, err
+		}
+		return []k8s.PSSPosture{*posture}, nil
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+	}
+	return c.JSON(fanOutResponse("postures", result))
+}
+
+// GetSecretHygiene scans secrets and service accounts for token, pull
+// secret and certificate problems, for ?cluster= or every cluster.
+// Certificates expiring within ?days= (default 30) are reported, and
+// system namespaces are included with includeSystem=true.
+func (h *SecurityHandler) GetSecretHygiene(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	cluster := c.Query("cluster")
+	includeSystem := c.Query("includeSystem") == "true"
+	days := c.QueryInt("days", k8s.DefaultCertExpiryDays)
+	if days < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "days must not be negative")
+	}
+
+	if cluster != "" {
+		report, err := h.k8sClient.ScanSecretHygiene(c.UserContext(), cluster, days, includeSystem)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to scan secrets: "+err.Error())
+		}
+		return c.JSON(report)
+	}
+
+	result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.SecretHygieneReport, error) {
+		report, err := h.k8sClient.ScanSecretHygiene(ctx, cluster, days, includeSystem)
+		if err != nil {
+			return nil, err
+		}
+		return []k8s.SecretHygieneReport{*report}, nil
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+	}
+	return c.JSON(fanOutResponse("reports", result))
+}
+
+// ListPSSRules returns the Pod Security Standards rules with remediation
+func (h *SecurityHandler) ListPSSRules(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"rules": k8s.PSSRules()})
+}
+
+// ListSuppressions returns the rule suppressions as a list (see list.go)
+func (h *SecurityHandler) ListSuppressions(c *fiber.Ctx) error {
+	p, done, err := startList(c, 0)
+	if done {
+		return err
+	}
+
+	suppressions, err := h.store.ListPSSSuppressions(c.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list suppressions")
+	}
+	return listResponse(c, p, suppressions, nil)
+}
+
+// CreateSuppression suppresses a rule in a namespace (editor or admin)
+func (h *SecurityHandler) CreateSuppression(c *fiber.Ctx) error {
+	if err := h.requireEditor(c); err != nil {
+		return err
+	}
+
+	var req models.PSSSuppression
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if req.Namespace == "" || req.RuleID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Namespace and rule_id are required")
+	}
+	if !knownPSSRule(req.RuleID) {
+		return fiber.NewError(fiber.StatusBadRequest, "Unknown rule: "+req.RuleID)
+	}
+
+	suppression := models.PSSSuppression{
+		Cluster:   req.Cluster,
+		Namespace: req.Namespace,
+		RuleID:    req.RuleID,
+		Reason:    req.Reason,
+		CreatedBy: middleware.GetUserID(c),
+	}
+	if err := h.store.CreatePSSSuppression(c.UserContext(), &suppression); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fiber.NewError(fiber.StatusConflict, "Rule is already suppressed in this namespace")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create suppression")
+	}
+	return c.Status(fiber.StatusCreated).JSON(suppression)
+}
+
+// DeleteSuppression removes a suppression (editor or admin)
+func (h *SecurityHandler) DeleteSuppression(c *fiber.Ctx) error {
+	if err := h.requireEditor(c); err != nil {
+		return err
+	}
+
+	id, err := parseUUID(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid suppression ID")
+	}
+	if err := h.store.DeletePSSSuppression(c.UserContext(), id); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete suppression")
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// requireEditor checks that the current user is a console admin or editor
+func (h *SecurityHandler) requireEditor(c *fiber.Ctx) error {
+	currentUser, err := h.store.GetUser(c.UserContext(), middleware.GetUserID(c))
+	if err != nil || currentUser == nil || (currentUser.Role != string(models.UserRoleAdmin) && currentUser.Role != string(models.UserRoleEditor)) {
+		return fiber.NewError(fiber.StatusForbidden, "Console editor access required")
+	}
+	return nil
+}
+
+func knownPSSRule(id string) bool {
+	for _, rule := range k8s.PSSRules() {
+		if rule.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// Version history limits
+const (
+	defaultVersionHistory = 50
+	maxVersionHistory     = 500
+)
+
+// versionPollInterval is how often every cluster's versions are recorded
+const versionPollInterval = 5 * time.Minute
+
+// UpgradeHandler serves cluster version skew and upgrade progress. Run
+// records each cluster's versions in the history as they change.
+type UpgradeHandler struct {
+	store     store.Store
+	k8sClient *k8s.MultiClusterClient
+
+	// ctx is cancelled by Close, ending Run and any poll in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewUpgradeHandler creates a new upgrade handler
+func NewUpgradeHandler(s store.Store, k8sClient *k8s.MultiClusterClient) *UpgradeHandler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &UpgradeHandler{store: s, k8sClient: k8sClient, ctx: ctx, cancel: cancel}
+}
+
+// Run records the versions of every cluster now and then every
+// versionPollInterval until Close
+func (h *UpgradeHandler) Run() {
+	if h.k8sClient == nil {
+		return
+	}
+	ticker := time.NewTicker(versionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		h.recordVersions()
+		select {
+		case <-ticker.C:
+		case <-h.ctx.Done():
+			return
+		}
+	}
+}
+
+// Close stops Run
+func (h *UpgradeHandler) Close() {
+	h.cancel()
+}
+
+// recordVersions snapshots the versions of every reachable cluster. It runs
+// without a user, so agent clusters, which only their owner may see, are
+// not recorded.
+func (h *UpgradeHandler) recordVersions() {
+	ctx, cancel := context.WithTimeout(h.ctx, versionPollInterval)
+	defer cancel()
+
+	result, err := k8s.FanOutAll(ctx, h.k8sClient, k8s.FanOutOptions{}, func(ctx context.Context, cluster string) ([]k8s.ClusterUpgradeStatus, error) {
+		status, err := h.k8sClient.GetUpgradeStatus(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		return []k8s.ClusterUpgradeStatus{*status}, nil
+	})
+	if err != nil {
+		log.Printf("Warning: failed to list clusters for version history: %v", err)
+		return
+	}
+	for i := range result.Items {
+		h.recordSnapshot(ctx, &result.Items[i])
+	}
+}
+
+// GetClusterUpgradeStatus returns a cluster's control plane and kubelet
+// versions with their skew and upgrade phase
+func (h *UpgradeHandler) GetClusterUpgradeStatus(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	status, err := h.k8sClient.GetUpgradeStatus(c.UserContext(), c.Params("cluster"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get upgrade status: "+err.Error())
+	}
+	return c.JSON(status)
+}
+
+// GetAllUpgradeStatus returns the upgrade status of every cluster with
+// fleet-wide counts per phase
+func (h *UpgradeHandler) GetAllUpgradeStatus(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	result, err := k8s.FanOutAll(c.UserContext(), h.k8sClient, fanOutOptions(c), func(ctx context.Context, cluster string) ([]k8s.ClusterUpgradeStatus, error) {
+		status, err := h.k8sClient.GetUpgradeStatus(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		return []k8s.ClusterUpgradeStatus{*status}, nil
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+	}
+
+	phases := map[string]int{
+		k8s.UpgradePhaseCurrent:    0,
+		k8s.UpgradePhasePending:    0,
+		k8s.UpgradePhaseInProgress: 0,
+	}
+	controlPlaneVersions := map[string]int{}
+	nodesOutOfSkew := 0
+	for i := range result.Items {
+		status := &result.Items[i]
+		phases[status.Phase]++
+		controlPlaneVersions[status.ControlPlaneVersion]++
+		nodesOutOfSkew += status.NodesOutOfSkew
+	}
+
+	response := fanOutResponse("clusters", result)
+	response["phases"] = phases
+	response["controlPlaneVersions"] = controlPlaneVersions
+	response["nodesOutOfSkew"] = nodesOutOfSkew
+	return c.JSON(response)
+}
+
+// GetUpgradeHistory returns the version changes recorded by Run, newest
+// first, of one cluster or, without ?cluster=, of all clusters. Only the
+// history of clusters the caller can currently list is returned, so rows
+// of other users' agent clusters never leak.
+func (h *UpgradeHandler) GetUpgradeHistory(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Kubernetes client not available")
+	}
+
+	limit := defaultVersionHistory
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			return fiber.NewError(fiber.StatusBadRequest, "limit must be a positive integer")
+		}
+		limit = min(n, maxVersionHistory)
+	}
+
+	visible, err := h.k8sClient.ListClusters(c.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list clusters")
+	}
+	cluster := c.Query("cluster")
+	var clusters []string
+	for _, cl := range visible {
+		if cluster == "" || cl.Name == cluster {
+			clusters = append(clusters, cl.Name)
+		}
+	}
+	if cluster != "" && len(clusters) == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Cluster not found: "+cluster)
+	}
+
+	history, err := h.store.GetVersionHistory(c.UserContext(), clusters, limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get upgrade history")
+	}
+	if history == nil {
+		history = []models.ClusterVersionSnapshot{}
+	}
+	return c.JSON(fiber.Map{"history": history})
+}
+
+// recordSnapshot stores a cluster's versions when they differ from the
+// last snapshot, so the history shows each step of an upgrade once
+func (h *UpgradeHandler) recordSnapshot(ctx context.Context, status *k8s.ClusterUpgradeStatus) {
+	snapshot := &models.ClusterVersionSnapshot{
+		Cluster:             status.Cluster,
+		ControlPlaneVersion: status.ControlPlaneVersion,
+		KubeletVersions:     status.KubeletVersions,
+		Phase:               status.Phase,
+		Nodes:               len(status.Nodes),
+		NodesUpgraded:       status.NodesUpgraded,
+		NodesOutOfSkew:      status.NodesOutOfSkew,
+		CordonedNodes:       status.CordonedNodes,
+	}
+	if _, err := h.store.AddVersionSnapshot(ctx, snapshot); err != nil {
+		log.Printf("Warning: failed to record version snapshot of %s: %v", status.Cluster, err)
+	}
+}
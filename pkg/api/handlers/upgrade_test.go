@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: kind
+contexts:
+- name: kind
+  context: {cluster: kind, user: kind}
+clusters:
+- name: kind
+  cluster: {server: "https://127.0.0.1:6443"}
+users:
+- name: kind
+  user: {token: secret}
+`
+
+// upgradeHistoryApp serves GetUpgradeHistory for the user in the X-User
+// header, with history recorded for the kubeconfig cluster "kind" and the
+// agent cluster "laptop" of user "owner"
+func upgradeHistoryApp(t *testing.T) *fiber.App {
+	t.Helper()
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	if err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	k8sClient, err := k8s.NewMultiClusterClient(kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k8sClient.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	tunnel := k8s.NewAgentTunnel("tunnel-1", "owner", "alice", func(interface{}) error { return nil })
+	t.Cleanup(tunnel.Close)
+	k8sClient.RegisterAgent(tunnel, protocol.TunnelRegisterPayload{
+		Hostname: "laptop",
+		Clusters: []protocol.ClusterInfo{{Name: "laptop", Context: "laptop"}},
+	})
+
+	db, err := store.NewSQLiteStore(filepath.Join(dir, "console.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, cluster := range []string{"kind", "laptop"} {
+		snapshot := &models.ClusterVersionSnapshot{Cluster: cluster, ControlPlaneVersion: "v1.31.0", KubeletVersions: map[string]int{}}
+		if _, err := db.AddVersionSnapshot(context.Background(), snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewUpgradeHandler(db, k8sClient)
+	t.Cleanup(h.Close)
+	app := fiber.New()
+	app.Get("/history", func(c *fiber.Ctx) error {
+		if user := c.Get("X-User"); user != "" {
+			c.SetUserContext(k8s.WithUser(c.UserContext(), user))
+		}
+		return h.GetUpgradeHistory(c)
+	})
+	return app
+}
+
+func TestGetUpgradeHistoryAccess(t *testing.T) {
+	app := upgradeHistoryApp(t)
+
+	tests := []struct {
+		name     string
+		user     string
+		query    string
+		status   int
+		clusters []string
+	}{
+		{"owner sees their agent cluster", "owner", "", fiber.StatusOK, []string{"kind", "laptop"}},
+		{"other users do not", "someone-else", "", fiber.StatusOK, []string{"kind"}},
+		{"no user sees no agent clusters", "", "", fiber.StatusOK, []string{"kind"}},
+		{"owner asks for their agent cluster", "owner", "?cluster=laptop", fiber.StatusOK, []string{"laptop"}},
+		{"another user asks for it", "someone-else", "?cluster=laptop", fiber.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/history"+tt.query, nil)
+			req.Header.Set("X-User", tt.user)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != fiber.StatusOK {
+				return
+			}
+
+			var body struct {
+				History []models.ClusterVersionSnapshot `json:"history"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			seen := map[string]bool{}
+			for _, h := range body.History {
+				seen[h.Cluster] = true
+			}
+			if len(seen) != len(tt.clusters) {
+				t.Fatalf("history covers %v, want %v", seen, tt.clusters)
+			}
+			for _, cluster := range tt.clusters {
+				if !seen[cluster] {
+					t.Fatalf("history covers %v, want %v", seen, tt.clusters)
+				}
+			}
+		})
+	}
+}
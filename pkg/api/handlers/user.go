@@ -20,7 +20,7 @@ func NewUserHandler(s store.Store) *UserHandler {
 // GetCurrentUser returns the current user
 func (h *UserHandler) GetCurrentUser(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
-	user, err := h.store.GetUser(userID)
+	user, err := h.store.GetUser(c.UserContext(), userID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user")
 	}
@@ -33,7 +33,7 @@ func (h *UserHandler) GetCurrentUser(c *fiber.Ctx) error {
 // UpdateCurrentUser updates the current user
 func (h *UserHandler) UpdateCurrentUser(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
-	user, err := h.store.GetUser(userID)
+	user, err := h.store.GetUser(c.UserContext(), userID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get user")
 	}
@@ -57,7 +57,7 @@ func (h *UserHandler) UpdateCurrentUser(c *fiber.Ctx) error {
 		user.SlackID = updates.SlackID
 	}
 
-	if err := h.store.UpdateUser(user); err != nil {
+	if err := h.store.UpdateUser(c.UserContext(), user); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update user")
 	}
 
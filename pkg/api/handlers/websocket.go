@@ -101,6 +101,13 @@ func (h *Hub) Run() {
 	}
 }
 
+// ClientCount returns the number of connected clients
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
 // Close shuts down the hub
 func (h *Hub) Close() {
 	close(h.done)
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// ClusterAccess scopes Kubernetes calls to the authenticated user, so they
+// only reach agent clusters registered by the user's own agents, and answers
+// 404 for a ?cluster= or :cluster naming another user's agent cluster. It
+// must run after JWTAuth.
+func ClusterAccess(k8sClient *k8s.MultiClusterClient) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if userID := GetUserID(c); userID != uuid.Nil {
+			c.SetUserContext(k8s.WithUser(c.UserContext(), userID.String()))
+		}
+		if k8sClient == nil {
+			return c.Next()
+		}
+		for _, cluster := range []string{c.Query("cluster"), c.Params("cluster")} {
+			if cluster != "" && !k8sClient.CanAccessCluster(c.UserContext(), cluster) {
+				return fiber.NewError(fiber.StatusNotFound, "Cluster not found: "+cluster)
+			}
+		}
+		return c.Next()
+	}
+}
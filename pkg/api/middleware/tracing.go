@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kubestellar/console/pkg/tracing"
+)
+
+// RequestIDKey is the locals key holding the request ID, as logged by the
+// logger middleware with ${locals:requestid}
+const RequestIDKey = "requestid"
+
+// RequestID assigns each request an ID, reusing an incoming X-Request-ID,
+// and echoes it in the response
+func RequestID() fiber.Handler {
+	return requestid.New(requestid.Config{ContextKey: RequestIDKey})
+}
+
+// GetRequestID returns the ID assigned by RequestID
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(RequestIDKey).(string)
+	return id
+}
+
+// Tracing starts a server span for each request, continuing any trace
+// propagated in the traceparent header. Handlers pass c.UserContext() on so
+// their Kubernetes, MCP and store calls become child spans. The span is
+// named after the route pattern once routing is done.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Fiber's strings alias buffers reused after the request, while the
+		// span is exported later, so attribute values are copied
+		method := utils.CopyString(c.Method())
+		path := utils.CopyString(c.Path())
+
+		ctx := tracing.Extract(c.UserContext(), requestHeaderCarrier{&c.Request().Header})
+		ctx, span := tracing.StartKind(ctx, method+" "+path, trace.SpanKindServer,
+			attribute.String("http.request.method", method),
+			attribute.String("url.path", path),
+			attribute.String("request.id", utils.CopyString(GetRequestID(c))),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+			span.RecordError(err)
+		}
+
+		route := c.Route().Path
+		if status == fiber.StatusNotFound && route == "/" && path != "/" {
+			route = "unmatched"
+		}
+		span.SetName(method + " " + route)
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fasthttp.StatusMessage(status))
+		}
+		return err
+	}
+}
+
+// requestHeaderCarrier adapts fasthttp request headers for propagation
+type requestHeaderCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (h requestHeaderCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+func (h requestHeaderCarrier) Set(key, value string) {
+	h.header.Set(key, value)
+}
+
+func (h requestHeaderCarrier) Keys() []string {
+	var keys []string
+	h.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
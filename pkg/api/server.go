@@ -9,6 +9,7 @@ import (
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
@@ -17,7 +18,9 @@ import (
 	"github.com/kubestellar/console/pkg/api/middleware"
 	"github.com/kubestellar/console/pkg/k8s"
 	"github.com/kubestellar/console/pkg/mcp"
+	"github.com/kubestellar/console/pkg/metrics"
 	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/tracing"
 )
 
 // Config holds server configuration
@@ -33,12 +36,16 @@ type Config struct {
 	KlaudeOpsPath    string
 	KlaudeDeployPath string
 	Kubeconfig       string
+	// MCPServersConfig is a YAML/JSON file of additional MCP servers
+	MCPServersConfig string
 	// Dev mode user settings (used when GitHub OAuth not configured)
 	DevUserLogin  string
 	DevUserEmail  string
 	DevUserAvatar string
 	// GitHub personal access token for dev mode profile lookup
 	GitHubToken   string
+	// TraceExporter selects where spans go: otlp, stdout or none
+	TraceExporter string
 }
 
 // Server represents the API server
@@ -49,10 +56,20 @@ type Server struct {
 	hub       *handlers.Hub
 	bridge    *mcp.Bridge
 	k8sClient *k8s.MultiClusterClient
+	// upgrades records the clusters' version history in the background
+	upgrades *handlers.UpgradeHandler
+	// shutdownTracing flushes buffered spans
+	shutdownTracing func(context.Context) error
 }
 
 // NewServer creates a new API server
 func NewServer(cfg Config) (*Server, error) {
+	// Initialize tracing first so clients created below are traced
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TraceExporter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+
 	// Initialize store
 	db, err := store.NewSQLiteStore(cfg.DatabasePath)
 	if err != nil {
@@ -123,18 +140,35 @@ func NewServer(cfg Config) (*Server, error) {
 
 	// Initialize MCP bridge (optional - starts in background)
 	var bridge *mcp.Bridge
-	if cfg.KlaudeOpsPath != "" || cfg.KlaudeDeployPath != "" {
+	var mcpServers []mcp.ServerConfig
+	if cfg.MCPServersConfig != "" {
+		servers, err := mcp.LoadServersConfig(cfg.MCPServersConfig)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			mcpServers = servers
+		}
+	}
+	if cfg.KlaudeOpsPath != "" || cfg.KlaudeDeployPath != "" || len(mcpServers) > 0 {
 		bridge = mcp.NewBridge(mcp.BridgeConfig{
 			KlaudeOpsPath:    cfg.KlaudeOpsPath,
 			KlaudeDeployPath: cfg.KlaudeDeployPath,
 			Kubeconfig:       cfg.Kubeconfig,
+			Servers:          mcpServers,
+		})
+		// Forward resource updates and list changes to the frontend
+		bridge.OnNotification(func(n mcp.Notification) {
+			if n.Method == "notifications/message" {
+				return
+			}
+			hub.BroadcastAll(handlers.Message{Type: "mcp_notification", Data: n})
 		})
 		// Start bridge in background
 		go func() {
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 			if err := bridge.Start(ctx); err != nil {
-				log.Printf("Warning: MCP bridge failed to start: %v", err)
+				log.Printf("Warning: MCP bridge failed to start: %v (retrying in the background)", err)
 			} else {
 				log.Println("MCP bridge started successfully")
 			}
@@ -148,6 +182,13 @@ func NewServer(cfg Config) (*Server, error) {
 		hub:       hub,
 		bridge:    bridge,
 		k8sClient: k8sClient,
+
+		shutdownTracing: shutdownTracing,
+	}
+
+	metrics.RegisterWebSocketClients(hub.ClientCount)
+	if k8sClient != nil {
+		metrics.RegisterHealthCacheAge(k8sClient.HealthCacheAges)
 	}
 
 	server.setupMiddleware()
@@ -160,9 +201,16 @@ func (s *Server) setupMiddleware() {
 	// Recovery middleware
 	s.app.Use(recover.New())
 
+	// Request IDs, echoed in X-Request-ID and logged below
+	s.app.Use(middleware.RequestID())
+
+	// Request metrics and traces
+	s.app.Use(middleware.Metrics())
+	s.app.Use(middleware.Tracing())
+
 	// Logger
 	s.app.Use(logger.New(logger.Config{
-		Format:     "${time} | ${status} | ${latency} | ${method} ${path}\n",
+		Format:     "${time} | ${status} | ${latency} | ${locals:requestid} | ${method} ${path}\n",
 		TimeFormat: "15:04:05",
 	}))
 
@@ -171,16 +219,24 @@ func (s *Server) setupMiddleware() {
 	s.app.Use(cors.New(cors.Config{
 		AllowOrigins:     s.config.FrontendURL,
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Request-ID,traceparent,tracestate",
+		ExposeHeaders:    "X-Request-ID",
 		AllowCredentials: true,
 	}))
 }
 
 func (s *Server) setupRoutes() {
-	// Health check
-	s.app.Get("/health", func(c *fiber.Ctx) error {
+	// Health checks: /health and /healthz report liveness, /readyz whether
+	// the store can serve requests
+	liveness := func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"status": "ok"})
-	})
+	}
+	s.app.Get("/health", liveness)
+	s.app.Get("/healthz", liveness)
+	s.app.Get("/readyz", s.readiness)
+
+	// Prometheus metrics
+	s.app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
 
 	// Auth routes (public)
 	auth := handlers.NewAuthHandler(s.store, handlers.AuthConfig{
@@ -200,11 +256,12 @@ func (s *Server) setupRoutes() {
 
 	// MCP routes - register BEFORE protected routes in dev mode
 	// This ensures they're accessible without auth for testing
-	mcpHandlers := handlers.NewMCPHandlers(s.bridge, s.k8sClient)
+	mcpHandlers := handlers.NewMCPHandlers(s.bridge, s.k8sClient, s.hub)
 	if s.config.DevMode {
 		s.app.Get("/api/mcp/status", mcpHandlers.GetStatus)
 		s.app.Get("/api/mcp/tools/ops", mcpHandlers.GetOpsTools)
 		s.app.Get("/api/mcp/tools/deploy", mcpHandlers.GetDeployTools)
+		s.app.Get("/api/mcp/tools/:name/schema", mcpHandlers.GetToolSchema)
 		s.app.Get("/api/mcp/clusters", mcpHandlers.ListClusters)
 		s.app.Get("/api/mcp/clusters/health", mcpHandlers.GetAllClusterHealth)
 		s.app.Get("/api/mcp/clusters/:cluster/health", mcpHandlers.GetClusterHealth)
@@ -213,15 +270,29 @@ func (s *Server) setupRoutes() {
 		s.app.Get("/api/mcp/deployment-issues", mcpHandlers.FindDeploymentIssues)
 		s.app.Get("/api/mcp/deployments", mcpHandlers.GetDeployments)
 		s.app.Get("/api/mcp/gpu-nodes", mcpHandlers.GetGPUNodes)
+		s.app.Get("/api/mcp/top-pods", mcpHandlers.GetTopPods)
 		s.app.Get("/api/mcp/events", mcpHandlers.GetEvents)
 		s.app.Get("/api/mcp/events/warnings", mcpHandlers.GetWarningEvents)
 		s.app.Get("/api/mcp/security-issues", mcpHandlers.CheckSecurityIssues)
 		s.app.Post("/api/mcp/tools/ops/call", mcpHandlers.CallOpsTool)
 		s.app.Post("/api/mcp/tools/deploy/call", mcpHandlers.CallDeployTool)
+		s.app.Get("/api/mcp/servers", mcpHandlers.ListServers)
+		s.app.Get("/api/mcp/servers/:name/tools", mcpHandlers.GetServerTools)
+		s.app.Post("/api/mcp/servers/:name/tools/:tool/call", mcpHandlers.CallServerTool)
+		s.app.Get("/api/mcp/servers/:name/resources", mcpHandlers.ListServerResources)
+		s.app.Get("/api/mcp/servers/:name/resources/read", mcpHandlers.ReadServerResource)
+		s.app.Post("/api/mcp/servers/:name/resources/subscribe", mcpHandlers.SubscribeServerResource)
+		s.app.Post("/api/mcp/servers/:name/resources/unsubscribe", mcpHandlers.UnsubscribeServerResource)
+		s.app.Get("/api/mcp/servers/:name/prompts", mcpHandlers.ListServerPrompts)
+		s.app.Post("/api/mcp/servers/:name/prompts/:prompt", mcpHandlers.GetServerPrompt)
+		s.app.Get("/api/mcp/servers/:name/logs", mcpHandlers.GetServerLogs)
 	}
 
 	// API routes (protected)
-	api := s.app.Group("/api", middleware.JWTAuth(s.config.JWTSecret))
+	// ClusterAccess keeps each user to their own agent clusters; routes with
+	// a :cluster parameter repeat it since group middleware has no params
+	clusterAccess := middleware.ClusterAccess(s.k8sClient)
+	api := s.app.Group("/api", middleware.JWTAuth(s.config.JWTSecret), clusterAccess)
 
 	// User routes
 	user := handlers.NewUserHandler(s.store)
@@ -281,35 +352,92 @@ func (s *Server) setupRoutes() {
 	api.Post("/rbac/bindings", rbac.CreateRoleBinding)
 	api.Get("/permissions/summary", rbac.GetPermissionsSummary)
 	api.Post("/rbac/can-i", rbac.CheckCanI)
+	api.Get("/rbac/analysis", rbac.GetRBACAnalysis)
+	api.Get("/rbac/who-can", rbac.WhoCan)
 
 	// Namespace management routes (admin only)
 	namespaces := handlers.NewNamespaceHandler(s.store, s.k8sClient)
 	api.Get("/namespaces", namespaces.ListNamespaces)
 	api.Post("/namespaces", namespaces.CreateNamespace)
 	api.Delete("/namespaces/:name", namespaces.DeleteNamespace)
+	api.Get("/namespaces/:name/analysis", namespaces.GetNamespaceAnalysis)
 	api.Get("/namespaces/:name/access", namespaces.GetNamespaceAccess)
 	api.Post("/namespaces/:name/access", namespaces.GrantNamespaceAccess)
 	api.Delete("/namespaces/:name/access/:binding", namespaces.RevokeNamespaceAccess)
 
+	// Cluster inventory routes
+	clusters := handlers.NewClusterHandler(s.k8sClient)
+	api.Get("/clusters/capacity", clusters.GetAllClusterCapacity)
+	upgrades := handlers.NewUpgradeHandler(s.store, s.k8sClient)
+	s.upgrades = upgrades
+	go upgrades.Run()
+	api.Get("/clusters/upgrade", upgrades.GetAllUpgradeStatus)
+	api.Get("/clusters/upgrade/history", upgrades.GetUpgradeHistory)
+	api.Get("/clusters/:cluster/upgrade", clusterAccess, upgrades.GetClusterUpgradeStatus)
+	api.Get("/clusters/:cluster/capacity", clusterAccess, clusters.GetClusterCapacity)
+	api.Get("/clusters/:cluster/nodes/usage", clusterAccess, clusters.GetNodeUsage)
+
+	// Application routes (workloads grouped across clusters)
+	apps := handlers.NewAppHandler(s.k8sClient)
+	api.Get("/apps", apps.ListApps)
+	api.Get("/apps/:name", apps.GetApp)
+
+	// Rollout routes (Deployments, StatefulSets and DaemonSets)
+	rollouts := handlers.NewRolloutHandler(s.store, s.k8sClient, s.hub)
+	api.Get("/rollouts", rollouts.ListRollouts)
+	for _, resource := range k8s.RolloutResources {
+		workloads := api.Group("/"+resource, handlers.WorkloadResource(resource))
+		workloads.Get("/:ns/:name/rollout", rollouts.GetRollout)
+		workloads.Post("/:ns/:name/rollback", rollouts.Rollback)
+		workloads.Post("/:ns/:name/pause", rollouts.Pause)
+		workloads.Post("/:ns/:name/resume", rollouts.Resume)
+		workloads.Post("/:ns/:name/restart", rollouts.Restart)
+	}
+
+	// Pod Security Standards routes
+	security := handlers.NewSecurityHandler(s.store, s.k8sClient)
+	api.Get("/security/posture", security.GetPodSecurityPosture)
+	api.Get("/security/rules", security.ListPSSRules)
+	api.Get("/security/secrets", security.GetSecretHygiene)
+	api.Get("/security/suppressions", security.ListSuppressions)
+	api.Post("/security/suppressions", security.CreateSuppression)
+	api.Delete("/security/suppressions/:id", security.DeleteSuppression)
+
+	// Policy engine routes (Gatekeeper and Kyverno)
+	policy := handlers.NewPolicyHandler(s.k8sClient)
+	api.Get("/policy/violations", policy.ListViolations)
+
 	// MCP routes (cluster operations via klaude and direct k8s)
 	// In production, these are protected (dev mode routes registered above)
 	if !s.config.DevMode {
 		api.Get("/mcp/status", mcpHandlers.GetStatus)
 		api.Get("/mcp/tools/ops", mcpHandlers.GetOpsTools)
 		api.Get("/mcp/tools/deploy", mcpHandlers.GetDeployTools)
+		api.Get("/mcp/tools/:name/schema", mcpHandlers.GetToolSchema)
 		api.Get("/mcp/clusters", mcpHandlers.ListClusters)
 		api.Get("/mcp/clusters/health", mcpHandlers.GetAllClusterHealth)
-		api.Get("/mcp/clusters/:cluster/health", mcpHandlers.GetClusterHealth)
+		api.Get("/mcp/clusters/:cluster/health", clusterAccess, mcpHandlers.GetClusterHealth)
 		api.Get("/mcp/pods", mcpHandlers.GetPods)
 		api.Get("/mcp/pod-issues", mcpHandlers.FindPodIssues)
 		api.Get("/mcp/deployment-issues", mcpHandlers.FindDeploymentIssues)
 		api.Get("/mcp/deployments", mcpHandlers.GetDeployments)
 		api.Get("/mcp/gpu-nodes", mcpHandlers.GetGPUNodes)
+		api.Get("/mcp/top-pods", mcpHandlers.GetTopPods)
 		api.Get("/mcp/events", mcpHandlers.GetEvents)
 		api.Get("/mcp/events/warnings", mcpHandlers.GetWarningEvents)
 		api.Get("/mcp/security-issues", mcpHandlers.CheckSecurityIssues)
 		api.Post("/mcp/tools/ops/call", mcpHandlers.CallOpsTool)
 		api.Post("/mcp/tools/deploy/call", mcpHandlers.CallDeployTool)
+		api.Get("/mcp/servers", mcpHandlers.ListServers)
+		api.Get("/mcp/servers/:name/tools", mcpHandlers.GetServerTools)
+		api.Post("/mcp/servers/:name/tools/:tool/call", mcpHandlers.CallServerTool)
+		api.Get("/mcp/servers/:name/resources", mcpHandlers.ListServerResources)
+		api.Get("/mcp/servers/:name/resources/read", mcpHandlers.ReadServerResource)
+		api.Post("/mcp/servers/:name/resources/subscribe", mcpHandlers.SubscribeServerResource)
+		api.Post("/mcp/servers/:name/resources/unsubscribe", mcpHandlers.UnsubscribeServerResource)
+		api.Get("/mcp/servers/:name/prompts", mcpHandlers.ListServerPrompts)
+		api.Post("/mcp/servers/:name/prompts/:prompt", mcpHandlers.GetServerPrompt)
+		api.Get("/mcp/servers/:name/logs", mcpHandlers.GetServerLogs)
 	}
 
 	// GitOps routes (drift detection and sync)
@@ -328,6 +456,12 @@ func (s *Server) setupRoutes() {
 		api.Post("/gitops/sync", gitopsHandlers.Sync)
 	}
 
+	// Reverse tunnels from kkc-agent (authenticated with the user's console token)
+	agentTunnels := handlers.NewAgentTunnelHandler(s.k8sClient)
+	api.Get("/agents", agentTunnels.ListAgents)
+	api.Use("/agent/tunnel", middleware.WebSocketUpgrade())
+	api.Get("/agent/tunnel", websocket.New(agentTunnels.HandleConnection))
+
 	// WebSocket for real-time updates
 	s.app.Use("/ws", middleware.WebSocketUpgrade())
 	s.app.Get("/ws", websocket.New(func(c *websocket.Conn) {
@@ -343,6 +477,42 @@ func (s *Server) setupRoutes() {
 	}
 }
 
+// readinessTimeout bounds the checks behind /readyz
+const readinessTimeout = 2 * time.Second
+
+// readiness reports 503 until the store answers. The Kubernetes client is
+// reported but does not gate readiness: a console without a kubeconfig still
+// serves login, settings and agent-connected clusters, and a pod that never
+// becomes ready would hide all of that.
+func (s *Server) readiness(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	checks := fiber.Map{}
+	ready := true
+
+	if err := s.store.Ping(ctx); err != nil {
+		checks["store"] = err.Error()
+		ready = false
+	} else {
+		checks["store"] = "ok"
+	}
+
+	switch {
+	case s.k8sClient == nil:
+		checks["k8s"] = "client not initialized"
+	case !s.k8sClient.IsLoaded():
+		checks["k8s"] = "kubeconfig not loaded"
+	default:
+		checks["k8s"] = "ok"
+	}
+
+	if !ready {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "not ready", "checks": checks})
+	}
+	return c.JSON(fiber.Map{"status": "ok", "checks": checks})
+}
+
 // Start starts the server
 func (s *Server) Start() error {
 	addr := fmt.Sprintf(":%d", s.config.Port)
@@ -350,9 +520,15 @@ func (s *Server) Start() error {
 	return s.app.Listen(addr)
 }
 
+// tracingShutdownTimeout bounds flushing spans on shutdown
+const tracingShutdownTimeout = 5 * time.Second
+
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown() error {
 	s.hub.Close()
+	if s.upgrades != nil {
+		s.upgrades.Close()
+	}
 	if s.k8sClient != nil {
 		s.k8sClient.StopWatching()
 	}
@@ -364,7 +540,13 @@ func (s *Server) Shutdown() error {
 	if err := s.store.Close(); err != nil {
 		return err
 	}
-	return s.app.Shutdown()
+	if err := s.app.Shutdown(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	return s.shutdownTracing(ctx)
 }
 
 func customErrorHandler(c *fiber.Ctx, err error) error {
@@ -416,6 +598,8 @@ func LoadConfigFromEnv() Config {
 		KlaudeOpsPath:    getEnvOrDefault("KLAUDE_OPS_PATH", "klaude-ops"),
 		KlaudeDeployPath: getEnvOrDefault("KLAUDE_DEPLOY_PATH", "klaude-deploy"),
 		Kubeconfig:       os.Getenv("KUBECONFIG"),
+		MCPServersConfig: os.Getenv("MCP_SERVERS_CONFIG"),
+		TraceExporter:    os.Getenv("OTEL_TRACES_EXPORTER"),
 		// Dev mode user settings
 		DevUserLogin:  getEnvOrDefault("DEV_USER_LOGIN", "dev-user"),
 		DevUserEmail:  getEnvOrDefault("DEV_USER_EMAIL", "dev@localhost"),
@@ -41,7 +41,7 @@ func (s *Service) AnalyzeAndSuggestSwaps(ctx context.Context, userID uuid.UUID)
 	var suggestions []SwapSuggestion
 
 	// Get user's current dashboard and cards
-	dashboards, err := s.store.GetUserDashboards(userID)
+	dashboards, err := s.store.GetUserDashboards(ctx, userID)
 	if err != nil || len(dashboards) == 0 {
 		return suggestions, nil
 	}
@@ -59,7 +59,7 @@ func (s *Service) AnalyzeAndSuggestSwaps(ctx context.Context, userID uuid.UUID)
 	}
 
 	// Get current cards
-	cards, err := s.store.GetDashboardCards(dashboard.ID)
+	cards, err := s.store.GetDashboardCards(ctx, dashboard.ID)
 	if err != nil {
 		return suggestions, err
 	}
@@ -93,45 +93,66 @@ func (s *Service) getClusterState(ctx context.Context) ClusterState {
 		return state
 	}
 
-	clusters, err := s.k8sClient.ListClusters(ctx)
+	// Clusters are checked in parallel; one that fails or times out is left
+	// out rather than delaying the suggestions
+	result, err := k8s.FanOutAll(ctx, s.k8sClient, k8s.FanOutOptions{}, func(ctx context.Context, cluster string) ([]ClusterState, error) {
+		return []ClusterState{s.getSingleClusterState(ctx, cluster)}, nil
+	})
 	if err != nil {
 		return state
 	}
 
-	for _, cluster := range clusters {
-		// Check pod issues
-		podIssues, err := s.k8sClient.FindPodIssues(ctx, cluster.Name, "")
-		if err == nil && len(podIssues) > 0 {
-			state.HasPodIssues = true
-			state.PodIssueCount += len(podIssues)
-		}
+	for _, cs := range result.Items {
+		state.HasPodIssues = state.HasPodIssues || cs.HasPodIssues
+		state.PodIssueCount += cs.PodIssueCount
+		state.HasDeploymentIssues = state.HasDeploymentIssues || cs.HasDeploymentIssues
+		state.DeploymentIssueCount += cs.DeploymentIssueCount
+		state.HasSecurityIssues = state.HasSecurityIssues || cs.HasSecurityIssues
+		state.SecurityIssueCount += cs.SecurityIssueCount
+		state.HasGPUNodes = state.HasGPUNodes || cs.HasGPUNodes
+		state.GPUNodeCount += cs.GPUNodeCount
+		state.WarningEventCount += cs.WarningEventCount
+	}
 
-		// Check deployment issues
-		deployIssues, err := s.k8sClient.FindDeploymentIssues(ctx, cluster.Name, "")
-		if err == nil && len(deployIssues) > 0 {
-			state.HasDeploymentIssues = true
-			state.DeploymentIssueCount += len(deployIssues)
-		}
+	return state
+}
 
-		// TODO: Check security issues once CheckSecurityIssues is available
-		// securityIssues, err := s.k8sClient.CheckSecurityIssues(ctx, cluster.Name, "")
-		// if err == nil && len(securityIssues) > 0 {
-		// 	state.HasSecurityIssues = true
-		// 	state.SecurityIssueCount += len(securityIssues)
-		// }
-
-		// Check GPU nodes
-		gpuNodes, err := s.k8sClient.GetGPUNodes(ctx, cluster.Name)
-		if err == nil && len(gpuNodes) > 0 {
-			state.HasGPUNodes = true
-			state.GPUNodeCount += len(gpuNodes)
-		}
+// getSingleClusterState checks one cluster; checks that fail are skipped
+func (s *Service) getSingleClusterState(ctx context.Context, cluster string) ClusterState {
+	state := ClusterState{}
 
-		// Check warning events
-		events, err := s.k8sClient.GetWarningEvents(ctx, cluster.Name, "", 100)
-		if err == nil {
-			state.WarningEventCount += len(events)
-		}
+	// Check pod issues
+	podIssues, err := s.k8sClient.FindPodIssues(ctx, cluster, "")
+	if err == nil && len(podIssues) > 0 {
+		state.HasPodIssues = true
+		state.PodIssueCount += len(podIssues)
+	}
+
+	// Check deployment issues
+	deployIssues, err := s.k8sClient.FindDeploymentIssues(ctx, cluster, "")
+	if err == nil && len(deployIssues) > 0 {
+		state.HasDeploymentIssues = true
+		state.DeploymentIssueCount += len(deployIssues)
+	}
+
+	// TODO: Check security issues once CheckSecurityIssues is available
+	// securityIssues, err := s.k8sClient.CheckSecurityIssues(ctx, cluster, "")
+	// if err == nil && len(securityIssues) > 0 {
+	// 	state.HasSecurityIssues = true
+	// 	state.SecurityIssueCount += len(securityIssues)
+	// }
+
+	// Check GPU nodes
+	gpuNodes, err := s.k8sClient.GetGPUNodes(ctx, cluster)
+	if err == nil && len(gpuNodes) > 0 {
+		state.HasGPUNodes = true
+		state.GPUNodeCount += len(gpuNodes)
+	}
+
+	// Check warning events
+	events, err := s.k8sClient.GetWarningEvents(ctx, cluster, "", 100)
+	if err == nil {
+		state.WarningEventCount += len(events)
 	}
 
 	return state
@@ -233,7 +254,7 @@ func (s *Service) CreatePendingSwap(ctx context.Context, userID uuid.UUID, sugge
 		Status:        models.SwapStatusPending,
 	}
 
-	if err := s.store.CreatePendingSwap(swap); err != nil {
+	if err := s.store.CreatePendingSwap(ctx, swap); err != nil {
 		return nil, err
 	}
 
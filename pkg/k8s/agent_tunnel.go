@@ -0,0 +1,361 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	"github.com/kubestellar/console/pkg/metrics"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// agentTunnelHost is a placeholder host for clusters reached through an agent
+// tunnel; the tunnel transport ignores it and only forwards the path
+const agentTunnelHost = "http://kkc-agent.tunnel"
+
+// ErrAgentDisconnected is returned for requests to an agent whose tunnel is closed
+var ErrAgentDisconnected = errors.New("agent tunnel disconnected")
+
+// ErrClusterNotFound is returned for agent clusters registered by another
+// user, so their existence is not revealed
+var ErrClusterNotFound = errors.New("cluster not found")
+
+type userContextKey struct{}
+
+// WithUser records the console user a request is made for. Agent clusters
+// are only listed and reachable for the user whose agent registered them,
+// so contexts without a user see none.
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, userID)
+}
+
+func userFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userContextKey{}).(string)
+	return userID
+}
+
+// AgentTunnel forwards Kubernetes API requests to a kkc-agent over the
+// WebSocket the agent opened to the console
+type AgentTunnel struct {
+	ID string
+	// OwnerID is the console user whose agent opened the tunnel, the only
+	// user its clusters are visible to; Owner is their login
+	OwnerID  string
+	Owner    string
+	Hostname string
+	Version  string
+
+	writeMu   sync.Mutex
+	writeJSON func(v interface{}) error
+
+	mu        sync.Mutex
+	pending   map[string]chan protocol.TunnelResponse
+	seq       atomic.Int64
+	closed    chan struct{}
+	closeOnce sync.Once
+	connected time.Time
+}
+
+// AgentTunnelInfo describes a connected agent
+type AgentTunnelInfo struct {
+	ID          string   `json:"id"`
+	Owner       string   `json:"owner,omitempty"`
+	Hostname    string   `json:"hostname"`
+	Version     string   `json:"version"`
+	Clusters    []string `json:"clusters"`
+	ConnectedAt string   `json:"connectedAt"`
+}
+
+// agentCluster is a cluster registered by an agent
+type agentCluster struct {
+	tunnel  *AgentTunnel
+	context string
+	server  string
+}
+
+// visibleTo reports whether the caller in ctx may use an agent cluster
+func (ac *agentCluster) visibleTo(ctx context.Context) bool {
+	userID := userFromContext(ctx)
+	return userID != "" && userID == ac.tunnel.OwnerID
+}
+
+// NewAgentTunnel creates a tunnel for the agent of a console user that sends
+// frames with writeJSON. The caller must feed responses read from the
+// connection to HandleResponse and call Close when the connection ends.
+func NewAgentTunnel(id, ownerID, owner string, writeJSON func(v interface{}) error) *AgentTunnel {
+	return &AgentTunnel{
+		ID:        id,
+		OwnerID:   ownerID,
+		Owner:     owner,
+		writeJSON: writeJSON,
+		pending:   make(map[string]chan protocol.TunnelResponse),
+		closed:    make(chan struct{}),
+		connected: time.Now(),
+	}
+}
+
+// HandleResponse routes a tunnel response to the waiting request
+func (t *AgentTunnel) HandleResponse(id string, resp protocol.TunnelResponse) {
+	t.mu.Lock()
+	ch, ok := t.pending[id]
+	delete(t.pending, id)
+	t.mu.Unlock()
+
+	if ok {
+		ch <- resp
+	}
+}
+
+// Close fails all in-flight requests and rejects new ones
+func (t *AgentTunnel) Close() {
+	t.closeOnce.Do(func() { close(t.closed) })
+}
+
+// roundTrip sends a request to the agent and waits for its response
+func (t *AgentTunnel) roundTrip(req *http.Request, contextName string) (*http.Response, error) {
+	select {
+	case <-t.closed:
+		return nil, ErrAgentDisconnected
+	default:
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	id := strconv.FormatInt(t.seq.Add(1), 10)
+	respCh := make(chan protocol.TunnelResponse, 1)
+	t.mu.Lock()
+	t.pending[id] = respCh
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+	}()
+
+	t.writeMu.Lock()
+	err := t.writeJSON(protocol.Message{
+		ID:   id,
+		Type: protocol.TypeTunnelRequest,
+		Payload: protocol.TunnelRequest{
+			Context: contextName,
+			Method:  req.Method,
+			Path:    req.URL.RequestURI(),
+			Headers: req.Header,
+			Body:    body,
+		},
+	})
+	t.writeMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tunnel request: %w", err)
+	}
+
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case <-t.closed:
+		return nil, ErrAgentDisconnected
+	case resp := <-respCh:
+		if resp.Error != "" {
+			return nil, fmt.Errorf("agent %s: %s", t.ID, resp.Error)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+			StatusCode:    resp.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header(resp.Headers),
+			Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+			ContentLength: int64(len(resp.Body)),
+			Request:       req,
+		}, nil
+	}
+}
+
+// tunnelTransport is an http.RoundTripper bound to one agent context
+type tunnelTransport struct {
+	tunnel  *AgentTunnel
+	context string
+}
+
+func (rt *tunnelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.tunnel.roundTrip(req, rt.context)
+}
+
+// HandleAgentFrame processes a raw frame read from an agent tunnel connection.
+// It returns true if the frame (re-)registered the agent's clusters.
+func (m *MultiClusterClient) HandleAgentFrame(t *AgentTunnel, data []byte) (bool, error) {
+	var frame struct {
+		ID      string               `json:"id"`
+		Type    protocol.MessageType `json:"type"`
+		Payload json.RawMessage      `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &frame); err != nil {
+		return false, fmt.Errorf("invalid tunnel frame: %w", err)
+	}
+
+	switch frame.Type {
+	case protocol.TypeTunnelResponse:
+		var resp protocol.TunnelResponse
+		if err := json.Unmarshal(frame.Payload, &resp); err != nil {
+			return false, fmt.Errorf("invalid tunnel response: %w", err)
+		}
+		t.HandleResponse(frame.ID, resp)
+		return false, nil
+	case protocol.TypeTunnelRegister:
+		var reg protocol.TunnelRegisterPayload
+		if err := json.Unmarshal(frame.Payload, &reg); err != nil {
+			return false, fmt.Errorf("invalid tunnel registration: %w", err)
+		}
+		names := m.RegisterAgent(t, reg)
+		log.Printf("Agent %s (%s) registered %d clusters: %v", t.ID, reg.Hostname, len(names), names)
+		return true, nil
+	}
+	return false, nil
+}
+
+// RegisterAgent registers (or replaces) the clusters reachable through an
+// agent tunnel. Clusters use the agent's context name unless it collides with
+// an existing cluster, in which case "<context>@<hostname>" is used. It
+// returns the registered cluster names.
+func (m *MultiClusterClient) RegisterAgent(t *AgentTunnel, reg protocol.TunnelRegisterPayload) []string {
+	m.mu.Lock()
+	m.removeAgentClustersLocked(t)
+	t.Hostname = reg.Hostname
+	t.Version = reg.Version
+
+	var names []string
+	for _, c := range reg.Clusters {
+		name := c.Context
+		inKubeconfig := false
+		if m.rawConfig != nil {
+			_, inKubeconfig = m.rawConfig.Contexts[name]
+		}
+		if _, taken := m.agentClusters[name]; taken || inKubeconfig || name == "in-cluster" {
+			name = c.Context + "@" + t.Hostname
+		}
+		m.agentClusters[name] = &agentCluster{tunnel: t, context: c.Context, server: c.Server}
+		names = append(names, name)
+	}
+	callback := m.onReload
+	m.mu.Unlock()
+
+	sort.Strings(names)
+	if callback != nil {
+		callback()
+	}
+	return names
+}
+
+// UnregisterAgent removes all clusters registered through a tunnel
+func (m *MultiClusterClient) UnregisterAgent(t *AgentTunnel) {
+	m.mu.Lock()
+	removed := m.removeAgentClustersLocked(t)
+	callback := m.onReload
+	m.mu.Unlock()
+
+	t.Close()
+	if removed > 0 && callback != nil {
+		callback()
+	}
+}
+
+func (m *MultiClusterClient) removeAgentClustersLocked(t *AgentTunnel) int {
+	removed := 0
+	for name, ac := range m.agentClusters {
+		if ac.tunnel != t {
+			continue
+		}
+		delete(m.agentClusters, name)
+		delete(m.clients, name)
+		delete(m.metricsClients, name)
+		delete(m.dynamicClients, name)
+		delete(m.discoveryClients, name)
+		delete(m.configs, name)
+		delete(m.healthCache, name)
+		delete(m.cacheTime, name)
+		removed++
+	}
+	return removed
+}
+
+// CanAccessCluster reports whether the caller in ctx may use a cluster: any
+// cluster except agent clusters registered by another user
+func (m *MultiClusterClient) CanAccessCluster(ctx context.Context, contextName string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ac, ok := m.agentClusters[contextName]
+	return !ok || ac.visibleTo(ctx)
+}
+
+// ListAgents returns the connected agents of the caller in ctx and their
+// clusters
+func (m *MultiClusterClient) ListAgents(ctx context.Context) []AgentTunnelInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byTunnel := make(map[*AgentTunnel]*AgentTunnelInfo)
+	var agents []*AgentTunnelInfo
+	for name, ac := range m.agentClusters {
+		if !ac.visibleTo(ctx) {
+			continue
+		}
+		info, ok := byTunnel[ac.tunnel]
+		if !ok {
+			info = &AgentTunnelInfo{
+				ID:          ac.tunnel.ID,
+				Owner:       ac.tunnel.Owner,
+				Hostname:    ac.tunnel.Hostname,
+				Version:     ac.tunnel.Version,
+				ConnectedAt: ac.tunnel.connected.Format(time.RFC3339),
+			}
+			byTunnel[ac.tunnel] = info
+			agents = append(agents, info)
+		}
+		info.Clusters = append(info.Clusters, name)
+	}
+
+	result := make([]AgentTunnelInfo, 0, len(agents))
+	for _, info := range agents {
+		sort.Strings(info.Clusters)
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// newAgentClient creates a clientset whose requests go through an agent
+// tunnel. Its metrics share the AgentCluster label, since /metrics is public.
+func newAgentClient(ac *agentCluster) (*kubernetes.Clientset, *rest.Config, error) {
+	config := &rest.Config{
+		Host:      agentTunnelHost,
+		Transport: &tunnelTransport{tunnel: ac.tunnel, context: ac.context},
+		Timeout:   10 * time.Second,
+	}
+	instrumentConfig(metrics.AgentCluster, config)
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, config, nil
+}
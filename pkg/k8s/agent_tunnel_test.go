@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+)
+
+// agentClient returns a client without a kubeconfig whose only cluster,
+// "laptop", is registered by the agent of user "owner"
+func agentClient(t *testing.T) *MultiClusterClient {
+	t.Helper()
+	m, err := NewMultiClusterClient(filepath.Join(t.TempDir(), "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tunnel := NewAgentTunnel("tunnel-1", "owner", "alice", func(interface{}) error { return nil })
+	t.Cleanup(tunnel.Close)
+	m.RegisterAgent(tunnel, protocol.TunnelRegisterPayload{
+		Hostname: "laptop",
+		Clusters: []protocol.ClusterInfo{{Name: "laptop", Context: "laptop", Server: "https://127.0.0.1:6443"}},
+	})
+	return m
+}
+
+func TestAgentClusterVisibility(t *testing.T) {
+	m := agentClient(t)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		visible bool
+	}{
+		{"owner", WithUser(context.Background(), "owner"), true},
+		{"other user", WithUser(context.Background(), "someone-else"), false},
+		// Background jobs and unauthenticated routes carry no user
+		{"no user", context.Background(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.CanAccessCluster(tt.ctx, "laptop"); got != tt.visible {
+				t.Errorf("CanAccessCluster = %v, want %v", got, tt.visible)
+			}
+			clusters, err := m.ListClusters(tt.ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if listed := len(clusters) == 1; listed != tt.visible {
+				t.Errorf("ListClusters = %+v", clusters)
+			}
+			if agents := m.ListAgents(tt.ctx); (len(agents) == 1) != tt.visible {
+				t.Errorf("ListAgents = %+v", agents)
+			}
+			_, err = m.GetClient(tt.ctx, "laptop")
+			if hidden := errors.Is(err, ErrClusterNotFound); hidden == tt.visible {
+				t.Errorf("GetClient error = %v", err)
+			}
+		})
+	}
+}
+
+func TestHealthCacheAgesHidesAgentClusters(t *testing.T) {
+	m := agentClient(t)
+	m.mu.Lock()
+	m.cacheTime["laptop"] = time.Now()
+	m.cacheTime["kind"] = time.Now()
+	m.mu.Unlock()
+
+	ages := m.HealthCacheAges()
+	if _, ok := ages["laptop"]; ok || len(ages) != 1 {
+		t.Errorf("ages = %v", ages)
+	}
+}
@@ -0,0 +1,460 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// How a workload was assigned to its application, in order of precedence
+const (
+	AppSourceKubeStellar = "kubestellar"
+	AppSourcePartOf      = "part-of"
+	AppSourceInstance    = "instance"
+	AppSourceName        = "name"
+	AppSourceHelm        = "helm"
+)
+
+// Health of a workload, cluster or application, from best to worst
+const (
+	AppHealthHealthy     = "healthy"
+	AppHealthProgressing = "progressing"
+	AppHealthDegraded    = "degraded"
+)
+
+// kubestellarGroupVersion serves BindingPolicies on KubeStellar workload
+// description spaces
+const kubestellarGroupVersion = "control.kubestellar.io/v1alpha1"
+
+var bindingPolicyGVR = schema.GroupVersionResource{Group: "control.kubestellar.io", Version: "v1alpha1", Resource: "bindingpolicies"}
+
+// AppWorkload is a Deployment, StatefulSet or DaemonSet of an application
+type AppWorkload struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Cluster   string   `json:"cluster"`
+	Desired   int32    `json:"desired"`
+	Ready     int32    `json:"ready"`
+	Updated   int32    `json:"updated"`
+	Health    string   `json:"health"`
+	Images    []string `json:"images"`
+
+	labels      map[string]string
+	helmRelease string
+}
+
+// ClusterWorkloads holds the workloads of a cluster and the KubeStellar
+// BindingPolicies it serves, if any
+type ClusterWorkloads struct {
+	Cluster         string
+	Workloads       []AppWorkload
+	BindingPolicies []BindingPolicy
+}
+
+// BindingPolicy is the part of a KubeStellar BindingPolicy that selects
+// workloads: objects matching any of its downsync object selectors
+type BindingPolicy struct {
+	Name      string
+	Selectors []labels.Selector
+}
+
+// AppCluster is an application's workloads in one cluster
+type AppCluster struct {
+	Cluster    string        `json:"cluster"`
+	Health     string        `json:"health"`
+	Namespaces []string      `json:"namespaces"`
+	Workloads  []AppWorkload `json:"workloads"`
+	// Images maps image repositories to the versions (tags or digests)
+	// running in this cluster
+	Images map[string][]string `json:"images"`
+}
+
+// ImageDrift is an image repository whose version differs between the
+// clusters running an application. DriftedClusters run a version other
+// than the most common one.
+type ImageDrift struct {
+	Image           string            `json:"image"`
+	Versions        map[string]string `json:"versions"`
+	DriftedClusters []string          `json:"driftedClusters"`
+}
+
+// Application groups the workloads of one app across clusters. Apps are
+// told apart by name, source and namespace; Namespace is empty for
+// KubeStellar apps, whose BindingPolicies may select several namespaces.
+type Application struct {
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	Namespace string `json:"namespace,omitempty"`
+	Health    string `json:"health"`
+	// Status counts the app's clusters by health
+	Status     map[string]int `json:"status"`
+	ClusterIDs []string       `json:"clusters"`
+	Clusters   []AppCluster   `json:"clusterDetails"`
+	Workloads  int            `json:"workloads"`
+	ImageDrift []ImageDrift   `json:"imageDrift"`
+	// Drifted reports whether any image version differs across clusters
+	Drifted bool `json:"drifted"`
+}
+
+// GetClusterWorkloads lists a cluster's Deployments, StatefulSets and
+// DaemonSets for grouping into applications, and its KubeStellar
+// BindingPolicies when the cluster is a workload description space
+func (m *MultiClusterClient) GetClusterWorkloads(ctx context.Context, contextName string) (*ClusterWorkloads, error) {
+	ctx, span := startSpan(ctx, "GetClusterWorkloads", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := m.GetDynamicClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return clusterWorkloads(ctx, client, client.Discovery(), dc, contextName)
+}
+
+// clusterWorkloads is GetClusterWorkloads with the clients already made
+func clusterWorkloads(ctx context.Context, client kubernetes.Interface, disc discovery.DiscoveryInterface, dc dynamic.Interface, contextName string) (*ClusterWorkloads, error) {
+	result := &ClusterWorkloads{Cluster: contextName, Workloads: []AppWorkload{}}
+
+	deployments, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		result.Workloads = append(result.Workloads, newAppWorkload(contextName, "Deployment", d.ObjectMeta, d.Spec.Template.Spec,
+			replicas(d.Spec.Replicas), d.Status.ReadyReplicas, d.Status.UpdatedReplicas, deploymentHealth(&d)))
+	}
+
+	statefulSets, err := client.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statefulSets.Items {
+		desired := replicas(s.Spec.Replicas)
+		result.Workloads = append(result.Workloads, newAppWorkload(contextName, "StatefulSet", s.ObjectMeta, s.Spec.Template.Spec,
+			desired, s.Status.ReadyReplicas, s.Status.UpdatedReplicas, replicaHealth(desired, s.Status.ReadyReplicas, s.Status.UpdatedReplicas)))
+	}
+
+	daemonSets, err := client.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range daemonSets.Items {
+		desired := d.Status.DesiredNumberScheduled
+		result.Workloads = append(result.Workloads, newAppWorkload(contextName, "DaemonSet", d.ObjectMeta, d.Spec.Template.Spec,
+			desired, d.Status.NumberReady, d.Status.UpdatedNumberScheduled, replicaHealth(desired, d.Status.NumberReady, d.Status.UpdatedNumberScheduled)))
+	}
+
+	policies, err := bindingPolicies(ctx, disc, dc)
+	if err != nil {
+		return nil, err
+	}
+	result.BindingPolicies = policies
+	return result, nil
+}
+
+func newAppWorkload(cluster, kind string, meta metav1.ObjectMeta, pod corev1.PodSpec, desired, ready, updated int32, health string) AppWorkload {
+	w := AppWorkload{
+		Kind:        kind,
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Cluster:     cluster,
+		Desired:     desired,
+		Ready:       ready,
+		Updated:     updated,
+		Health:      health,
+		Images:      []string{},
+		labels:      meta.Labels,
+		helmRelease: meta.Annotations["meta.helm.sh/release-name"],
+	}
+	for _, container := range pod.Containers {
+		w.Images = append(w.Images, container.Image)
+	}
+	return w
+}
+
+// deploymentHealth is degraded when the rollout exceeded its progress
+// deadline or replicas are missing, and progressing while a rollout is
+// under way
+func deploymentHealth(d *appsv1.Deployment) string {
+	for _, condition := range d.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return AppHealthDegraded
+		}
+	}
+	return replicaHealth(replicas(d.Spec.Replicas), d.Status.ReadyReplicas, d.Status.UpdatedReplicas)
+}
+
+func replicaHealth(desired, ready, updated int32) string {
+	switch {
+	case updated < desired:
+		return AppHealthProgressing
+	case ready < desired:
+		return AppHealthDegraded
+	default:
+		return AppHealthHealthy
+	}
+}
+
+// bindingPolicies reads the downsync object selectors of KubeStellar
+// BindingPolicies, or none when the cluster does not serve them
+func bindingPolicies(ctx context.Context, disc discovery.DiscoveryInterface, dc dynamic.Interface) ([]BindingPolicy, error) {
+	if _, err := disc.ServerResourcesForGroupVersion(kubestellarGroupVersion); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	list, err := dc.Resource(bindingPolicyGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var policies []BindingPolicy
+	for _, item := range list.Items {
+		policy := BindingPolicy{Name: item.GetName()}
+		clauses, _, _ := unstructured.NestedSlice(item.Object, "spec", "downsync")
+		for _, c := range clauses {
+			clause, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			selectors, _, _ := unstructured.NestedSlice(clause, "objectSelectors")
+			for _, s := range selectors {
+				raw, ok := s.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				var ls metav1.LabelSelector
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &ls); err != nil {
+					continue
+				}
+				selector, err := metav1.LabelSelectorAsSelector(&ls)
+				if err != nil || selector.Empty() {
+					// An empty selector would claim every workload
+					continue
+				}
+				policy.Selectors = append(policy.Selectors, selector)
+			}
+		}
+		if len(policy.Selectors) > 0 {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// appIdentity names the application of a workload: the KubeStellar
+// BindingPolicy selecting it, else its app.kubernetes.io part-of, instance
+// or name label, else its Helm release. Workloads with none of these are
+// not part of an application.
+func appIdentity(w *AppWorkload, policies []BindingPolicy) (name, source string) {
+	set := labels.Set(w.labels)
+	for _, policy := range policies {
+		for _, selector := range policy.Selectors {
+			if selector.Matches(set) {
+				return policy.Name, AppSourceKubeStellar
+			}
+		}
+	}
+	for _, candidate := range []struct{ label, source string }{
+		{"app.kubernetes.io/part-of", AppSourcePartOf},
+		{"app.kubernetes.io/instance", AppSourceInstance},
+		{"app.kubernetes.io/name", AppSourceName},
+	} {
+		if v := w.labels[candidate.label]; v != "" {
+			return v, candidate.source
+		}
+	}
+	if w.helmRelease != "" {
+		return w.helmRelease, AppSourceHelm
+	}
+	return "", ""
+}
+
+// GroupApplications groups the workloads of several clusters into
+// applications, with the health of each per cluster and overall, and the
+// images whose versions differ between clusters. BindingPolicies found on
+// any cluster apply to the workloads of all clusters, since KubeStellar
+// keeps the labels of the objects it downsyncs.
+func GroupApplications(clusters []ClusterWorkloads) []Application {
+	var policies []BindingPolicy
+	for _, cluster := range clusters {
+		policies = append(policies, cluster.BindingPolicies...)
+	}
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	apps := map[string]*Application{}
+	perCluster := map[string]map[string]*AppCluster{}
+	for _, cluster := range clusters {
+		for i := range cluster.Workloads {
+			w := &cluster.Workloads[i]
+			name, source := appIdentity(w, policies)
+			if name == "" {
+				continue
+			}
+			// Label values and Helm releases are only unique within a
+			// namespace, and a label and a release of the same name are
+			// different apps
+			namespace := w.Namespace
+			if source == AppSourceKubeStellar {
+				namespace = ""
+			}
+			key := source + "\x00" + namespace + "\x00" + name
+			app, ok := apps[key]
+			if !ok {
+				app = &Application{Name: name, Source: source, Namespace: namespace}
+				apps[key] = app
+				perCluster[key] = map[string]*AppCluster{}
+			}
+			app.Workloads++
+
+			ac, ok := perCluster[key][w.Cluster]
+			if !ok {
+				ac = &AppCluster{Cluster: w.Cluster, Health: AppHealthHealthy, Images: map[string][]string{}}
+				perCluster[key][w.Cluster] = ac
+			}
+			ac.Workloads = append(ac.Workloads, *w)
+			ac.Health = worseHealth(ac.Health, w.Health)
+			if !containsString(ac.Namespaces, w.Namespace) {
+				ac.Namespaces = append(ac.Namespaces, w.Namespace)
+			}
+			for _, image := range w.Images {
+				repo, version := splitImage(image)
+				if !containsString(ac.Images[repo], version) {
+					ac.Images[repo] = append(ac.Images[repo], version)
+				}
+			}
+		}
+	}
+
+	result := make([]Application, 0, len(apps))
+	for key, app := range apps {
+		app.Health = AppHealthHealthy
+		app.Status = map[string]int{AppHealthHealthy: 0, AppHealthProgressing: 0, AppHealthDegraded: 0}
+		for _, ac := range perCluster[key] {
+			sort.Strings(ac.Namespaces)
+			for repo := range ac.Images {
+				sort.Strings(ac.Images[repo])
+			}
+			app.Clusters = append(app.Clusters, *ac)
+			app.Status[ac.Health]++
+			app.Health = worseHealth(app.Health, ac.Health)
+		}
+		sort.Slice(app.Clusters, func(i, j int) bool {
+			return app.Clusters[i].Cluster < app.Clusters[j].Cluster
+		})
+		for _, ac := range app.Clusters {
+			app.ClusterIDs = append(app.ClusterIDs, ac.Cluster)
+		}
+		app.ImageDrift = imageDrift(app.Clusters)
+		app.Drifted = len(app.ImageDrift) > 0
+		result = append(result, *app)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Source < result[j].Source
+	})
+	return result
+}
+
+// imageDrift compares each image repository's versions across clusters
+func imageDrift(clusters []AppCluster) []ImageDrift {
+	versions := map[string]map[string]string{}
+	for _, ac := range clusters {
+		for repo, vs := range ac.Images {
+			if versions[repo] == nil {
+				versions[repo] = map[string]string{}
+			}
+			versions[repo][ac.Cluster] = strings.Join(vs, ",")
+		}
+	}
+
+	drift := []ImageDrift{}
+	for repo, byCluster := range versions {
+		counts := map[string]int{}
+		for _, v := range byCluster {
+			counts[v]++
+		}
+		if len(counts) < 2 {
+			continue
+		}
+		// The most common version is the reference; ties go to the
+		// greatest version string
+		common, best := "", 0
+		for v, n := range counts {
+			if n > best || n == best && v > common {
+				common, best = v, n
+			}
+		}
+		d := ImageDrift{Image: repo, Versions: byCluster, DriftedClusters: []string{}}
+		for cluster, v := range byCluster {
+			if v != common {
+				d.DriftedClusters = append(d.DriftedClusters, cluster)
+			}
+		}
+		sort.Strings(d.DriftedClusters)
+		drift = append(drift, d)
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		return drift[i].Image < drift[j].Image
+	})
+	return drift
+}
+
+// splitImage splits an image reference into its repository and its digest
+// or tag, which defaults to latest
+func splitImage(image string) (repo, version string) {
+	if repo, digest, ok := strings.Cut(image, "@"); ok {
+		return repo, digest
+	}
+	// A colon after the last slash separates the tag; earlier ones belong
+	// to a registry port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, "latest"
+}
+
+var healthRank = map[string]int{AppHealthHealthy: 0, AppHealthProgressing: 1, AppHealthDegraded: 2}
+
+func worseHealth(a, b string) string {
+	if healthRank[b] > healthRank[a] {
+		return b
+	}
+	return a
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func workload(cluster, namespace, name, image string, appLabels map[string]string, helmRelease string) AppWorkload {
+	return AppWorkload{
+		Kind:        "Deployment",
+		Name:        name,
+		Namespace:   namespace,
+		Cluster:     cluster,
+		Health:      AppHealthHealthy,
+		Images:      []string{image},
+		labels:      appLabels,
+		helmRelease: helmRelease,
+	}
+}
+
+func partOf(app string) map[string]string {
+	return map[string]string{"app.kubernetes.io/part-of": app}
+}
+
+func TestGroupApplications(t *testing.T) {
+	clusters := []ClusterWorkloads{
+		{Cluster: "east", Workloads: []AppWorkload{
+			workload("east", "team-a", "api", "shop:1.0", partOf("shop"), ""),
+			workload("east", "team-b", "api", "shop:2.0", partOf("shop"), ""),
+			workload("east", "team-a", "db", "postgres:16", nil, "shop"),
+			workload("east", "edge-1", "proxy", "envoy:1.30", map[string]string{"tier": "edge"}, ""),
+			workload("east", "team-a", "cron", "busybox", nil, ""),
+		}},
+		{Cluster: "west", Workloads: []AppWorkload{
+			workload("west", "team-a", "api", "shop:1.1", partOf("shop"), ""),
+			workload("west", "edge-2", "proxy", "envoy:1.30", map[string]string{"tier": "edge"}, ""),
+		}},
+	}
+	selector, _ := labels.Parse("tier=edge")
+	clusters[1].BindingPolicies = []BindingPolicy{{Name: "edge", Selectors: []labels.Selector{selector}}}
+
+	apps := GroupApplications(clusters)
+
+	type key struct{ name, source, namespace string }
+	byKey := map[key]Application{}
+	for _, app := range apps {
+		byKey[key{app.Name, app.Source, app.Namespace}] = app
+	}
+	if len(apps) != 4 || len(byKey) != 4 {
+		t.Fatalf("apps = %+v", apps)
+	}
+
+	// The same part-of label in two namespaces is two apps
+	teamA, ok := byKey[key{"shop", AppSourcePartOf, "team-a"}]
+	if !ok || teamA.Workloads != 2 || len(teamA.ClusterIDs) != 2 || !teamA.Drifted {
+		t.Errorf("shop in team-a = %+v", teamA)
+	}
+	teamB, ok := byKey[key{"shop", AppSourcePartOf, "team-b"}]
+	if !ok || teamB.Workloads != 1 || teamB.Drifted {
+		t.Errorf("shop in team-b = %+v", teamB)
+	}
+	// A Helm release named like a label value is a different app
+	if helm, ok := byKey[key{"shop", AppSourceHelm, "team-a"}]; !ok || helm.Workloads != 1 {
+		t.Errorf("shop helm release = %+v", helm)
+	}
+	// A BindingPolicy groups its workloads across namespaces
+	edge, ok := byKey[key{"edge", AppSourceKubeStellar, ""}]
+	if !ok || edge.Workloads != 2 || len(edge.ClusterIDs) != 2 {
+		t.Errorf("edge = %+v", edge)
+	}
+
+	// Sorted by name, then namespace and source
+	if apps[0].Name != "edge" || apps[1].Source != AppSourceHelm || apps[2].Namespace != "team-a" || apps[3].Namespace != "team-b" {
+		t.Errorf("order = %+v", apps)
+	}
+}
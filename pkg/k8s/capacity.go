@@ -0,0 +1,276 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Amounts are in the resource's base unit (bytes for memory and storage,
+// counts for pods and extended resources), except CPU, which is in
+// millicores.
+
+// ResourceAmounts is one resource's capacity and use. Capacity and
+// Allocatable are omitted in namespace rollups.
+type ResourceAmounts struct {
+	Capacity    int64 `json:"capacity,omitempty"`
+	Allocatable int64 `json:"allocatable,omitempty"`
+	Requested   int64 `json:"requested"`
+	Limits      int64 `json:"limits"`
+}
+
+// add sums b into a
+func (a *ResourceAmounts) add(b ResourceAmounts) {
+	a.Capacity += b.Capacity
+	a.Allocatable += b.Allocatable
+	a.Requested += b.Requested
+	a.Limits += b.Limits
+}
+
+// ResourceMap holds ResourceAmounts by resource name, such as cpu, memory,
+// ephemeral-storage, pods or amd.com/gpu
+type ResourceMap map[string]ResourceAmounts
+
+func (m ResourceMap) add(name string, amounts ResourceAmounts) {
+	sum := m[name]
+	sum.add(amounts)
+	m[name] = sum
+}
+
+// NodeCapacity is a node's allocatable resources and what its pods request
+type NodeCapacity struct {
+	Name          string      `json:"name"`
+	Cluster       string      `json:"cluster"`
+	Ready         bool        `json:"ready"`
+	Unschedulable bool        `json:"unschedulable,omitempty"`
+	Roles         []string    `json:"roles,omitempty"`
+	InstanceType  string      `json:"instanceType,omitempty"`
+	Pods          int         `json:"pods"`
+	Resources     ResourceMap `json:"resources"`
+}
+
+// NamespaceCapacity is what the scheduled pods of a namespace request
+type NamespaceCapacity struct {
+	Namespace string      `json:"namespace"`
+	Cluster   string      `json:"cluster"`
+	Pods      int         `json:"pods"`
+	Resources ResourceMap `json:"resources"`
+}
+
+// ClusterCapacity rolls a cluster's node and namespace capacity up
+type ClusterCapacity struct {
+	Cluster    string              `json:"cluster"`
+	Nodes      []NodeCapacity      `json:"nodes"`
+	Namespaces []NamespaceCapacity `json:"namespaces"`
+	// PendingPods are pods not yet scheduled, which use no node capacity
+	PendingPods int         `json:"pendingPods"`
+	Totals      ResourceMap `json:"totals"`
+}
+
+// gpuResourcePrefixes are extended resource names that are GPUs
+var gpuResourcePrefixes = []string{"nvidia.com/gpu", "amd.com/gpu", "gpu.intel.com/"}
+
+// IsGPUResource reports whether an extended resource name is a GPU
+func IsGPUResource(name string) bool {
+	for _, prefix := range gpuResourcePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClusterCapacity returns per-node allocatable resources against the
+// requests and limits of the pods running there, with namespace and
+// cluster rollups. It lists the nodes and pods once each.
+func (m *MultiClusterClient) GetClusterCapacity(ctx context.Context, contextName string) (*ClusterCapacity, error) {
+	ctx, span := startSpan(ctx, "GetClusterCapacity", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := clusterCapacity(ctx, client, contextName)
+	return result, err
+}
+
+// clusterCapacity is GetClusterCapacity for any clientset, also returning
+// the listed nodes
+func clusterCapacity(ctx context.Context, client kubernetes.Interface, contextName string) (*ClusterCapacity, *corev1.NodeList, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	// Finished pods release their resources
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &ClusterCapacity{Cluster: contextName, Totals: ResourceMap{}}
+
+	nodeIndex := make(map[string]int, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nc := NodeCapacity{
+			Name:          node.Name,
+			Cluster:       contextName,
+			Ready:         nodeReady(&node),
+			Unschedulable: node.Spec.Unschedulable,
+			Roles:         nodeRoles(&node),
+			InstanceType:  node.Labels[corev1.LabelInstanceTypeStable],
+			Resources:     ResourceMap{},
+		}
+		for name, quantity := range node.Status.Allocatable {
+			amounts := nc.Resources[string(name)]
+			amounts.Allocatable = quantityAmount(name, quantity)
+			nc.Resources[string(name)] = amounts
+		}
+		for name, quantity := range node.Status.Capacity {
+			amounts := nc.Resources[string(name)]
+			amounts.Capacity = quantityAmount(name, quantity)
+			nc.Resources[string(name)] = amounts
+		}
+		nodeIndex[node.Name] = len(result.Nodes)
+		result.Nodes = append(result.Nodes, nc)
+	}
+
+	namespaces := map[string]*NamespaceCapacity{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" {
+			result.PendingPods++
+			continue
+		}
+
+		usage := podResources(pod)
+		if n, ok := nodeIndex[pod.Spec.NodeName]; ok {
+			node := &result.Nodes[n]
+			node.Pods++
+			for name, amounts := range usage {
+				node.Resources.add(name, amounts)
+			}
+		}
+
+		ns, ok := namespaces[pod.Namespace]
+		if !ok {
+			ns = &NamespaceCapacity{Namespace: pod.Namespace, Cluster: contextName, Resources: ResourceMap{}}
+			namespaces[pod.Namespace] = ns
+		}
+		ns.Pods++
+		for name, amounts := range usage {
+			ns.Resources.add(name, amounts)
+		}
+	}
+
+	for _, node := range result.Nodes {
+		for name, amounts := range node.Resources {
+			result.Totals.add(name, amounts)
+		}
+	}
+	for _, ns := range namespaces {
+		result.Namespaces = append(result.Namespaces, *ns)
+	}
+	sort.Slice(result.Namespaces, func(i, j int) bool {
+		return result.Namespaces[i].Namespace < result.Namespaces[j].Namespace
+	})
+	if result.Nodes == nil {
+		result.Nodes = []NodeCapacity{}
+	}
+	if result.Namespaces == nil {
+		result.Namespaces = []NamespaceCapacity{}
+	}
+
+	return result, nodes, nil
+}
+
+// podResources returns a pod's effective requests and limits as the
+// scheduler counts them: the larger of its containers' sum and its largest
+// init container, plus pod overhead. The pod itself counts as one "pods".
+func podResources(pod *corev1.Pod) ResourceMap {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResourceList(requests, container.Resources.Requests)
+		addResourceList(limits, container.Resources.Limits)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		maxResourceList(requests, container.Resources.Requests)
+		maxResourceList(limits, container.Resources.Limits)
+	}
+	addResourceList(requests, pod.Spec.Overhead)
+	addResourceList(limits, pod.Spec.Overhead)
+
+	usage := ResourceMap{string(corev1.ResourcePods): {Requested: 1}}
+	for name, quantity := range requests {
+		usage.add(string(name), ResourceAmounts{Requested: quantityAmount(name, quantity)})
+	}
+	for name, quantity := range limits {
+		usage.add(string(name), ResourceAmounts{Limits: quantityAmount(name, quantity)})
+	}
+	return usage
+}
+
+func addResourceList(sum, list corev1.ResourceList) {
+	for name, quantity := range list {
+		total := sum[name]
+		total.Add(quantity)
+		sum[name] = total
+	}
+}
+
+func maxResourceList(max, list corev1.ResourceList) {
+	for name, quantity := range list {
+		if current, ok := max[name]; !ok || quantity.Cmp(current) > 0 {
+			max[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// quantityAmount converts a quantity to millicores for CPU and to its base
+// unit otherwise
+func quantityAmount(name corev1.ResourceName, quantity resource.Quantity) int64 {
+	if name == corev1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// nodeRoles reads the node-role.kubernetes.io/<role> labels
+func nodeRoles(node *corev1.Node) []string {
+	var roles []string
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, "node-role.kubernetes.io/"); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// TotalCapacity sums the totals of several clusters
+func TotalCapacity(clusters []ClusterCapacity) ResourceMap {
+	totals := ResourceMap{}
+	for _, cluster := range clusters {
+		for name, amounts := range cluster.Totals {
+			totals.add(name, amounts)
+		}
+	}
+	return totals
+}
@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func container(requests, limits corev1.ResourceList) corev1.Container {
+	return corev1.Container{Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits}}
+}
+
+func TestPodResources(t *testing.T) {
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want ResourceMap
+	}{
+		{
+			name: "containers are summed",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				container(resources("100m", "64Mi"), resources("200m", "128Mi")),
+				container(resources("200m", "64Mi"), nil),
+			}},
+			want: ResourceMap{
+				"pods":   {Requested: 1},
+				"cpu":    {Requested: 300, Limits: 200},
+				"memory": {Requested: 128 << 20, Limits: 128 << 20},
+			},
+		},
+		{
+			name: "the largest init container wins over a smaller sum",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					container(resources("500m", "32Mi"), nil),
+					container(resources("100m", "16Mi"), nil),
+				},
+				Containers: []corev1.Container{
+					container(resources("100m", "64Mi"), nil),
+					container(resources("100m", "64Mi"), nil),
+				},
+			},
+			want: ResourceMap{
+				"pods":   {Requested: 1},
+				"cpu":    {Requested: 500},
+				"memory": {Requested: 128 << 20},
+			},
+		},
+		{
+			name: "overhead is added to requests and limits",
+			spec: corev1.PodSpec{
+				Overhead:   resources("50m", "10Mi"),
+				Containers: []corev1.Container{container(resources("100m", "64Mi"), resources("100m", "64Mi"))},
+			},
+			want: ResourceMap{
+				"pods":   {Requested: 1},
+				"cpu":    {Requested: 150, Limits: 150},
+				"memory": {Requested: 74 << 20, Limits: 74 << 20},
+			},
+		},
+		{
+			name: "extended resources",
+			spec: corev1.PodSpec{Containers: []corev1.Container{container(
+				corev1.ResourceList{"amd.com/gpu": resource.MustParse("2")},
+				corev1.ResourceList{"amd.com/gpu": resource.MustParse("2")},
+			)}},
+			want: ResourceMap{
+				"pods":        {Requested: 1},
+				"amd.com/gpu": {Requested: 2, Limits: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := podResources(&corev1.Pod{Spec: tt.spec})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("podResources = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsGPUResource(t *testing.T) {
+	tests := map[string]bool{
+		"nvidia.com/gpu":     true,
+		"amd.com/gpu":        true,
+		"gpu.intel.com/i915": true,
+		"gpu.intel.com/xe":   true,
+		"intel.com/sriov":    false,
+		"cpu":                false,
+	}
+	for name, want := range tests {
+		if got := IsGPUResource(name); got != want {
+			t.Errorf("IsGPUResource(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func capacityNode(name string, allocatable corev1.ResourceList) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"node-role.kubernetes.io/worker": ""}},
+		Status: corev1.NodeStatus{
+			Capacity:    allocatable,
+			Allocatable: allocatable,
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func scheduledPod(namespace, name, node string, requests corev1.ResourceList) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			NodeName:   node,
+			Containers: []corev1.Container{container(requests, nil)},
+		},
+	}
+}
+
+func TestClusterCapacity(t *testing.T) {
+	gpuNode := resources("8", "32Gi")
+	gpuNode["gpu.intel.com/i915"] = resource.MustParse("4")
+	gpuRequest := resources("1", "1Gi")
+	gpuRequest["gpu.intel.com/i915"] = resource.MustParse("1")
+
+	client := fake.NewSimpleClientset(
+		capacityNode("node-1", resources("4", "16Gi")),
+		capacityNode("node-2", gpuNode),
+		scheduledPod("team-a", "web", "node-1", resources("500m", "1Gi")),
+		scheduledPod("team-a", "train", "node-2", gpuRequest),
+		scheduledPod("team-b", "api", "node-1", resources("250m", "512Mi")),
+		// Pending pods use no node or namespace capacity
+		scheduledPod("team-b", "queued", "", resources("4", "4Gi")),
+	)
+
+	result, nodes, err := clusterCapacity(context.Background(), client, "kind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes.Items) != 2 || result.PendingPods != 1 {
+		t.Fatalf("nodes %d, pending %d", len(nodes.Items), result.PendingPods)
+	}
+
+	node1 := result.Nodes[0]
+	if node1.Name != "node-1" || node1.Pods != 2 || !node1.Ready || !reflect.DeepEqual(node1.Roles, []string{"worker"}) {
+		t.Errorf("node-1 = %+v", node1)
+	}
+	if cpu := node1.Resources["cpu"]; cpu != (ResourceAmounts{Capacity: 4000, Allocatable: 4000, Requested: 750}) {
+		t.Errorf("node-1 cpu = %+v", cpu)
+	}
+	if gpu := result.Nodes[1].Resources["gpu.intel.com/i915"]; gpu != (ResourceAmounts{Capacity: 4, Allocatable: 4, Requested: 1}) {
+		t.Errorf("node-2 gpu = %+v", gpu)
+	}
+
+	if len(result.Namespaces) != 2 {
+		t.Fatalf("namespaces = %+v", result.Namespaces)
+	}
+	teamA, teamB := result.Namespaces[0], result.Namespaces[1]
+	if teamA.Namespace != "team-a" || teamA.Pods != 2 || teamA.Resources["cpu"] != (ResourceAmounts{Requested: 1500}) {
+		t.Errorf("team-a = %+v", teamA)
+	}
+	if teamB.Pods != 1 || teamB.Resources["memory"] != (ResourceAmounts{Requested: 512 << 20}) {
+		t.Errorf("team-b = %+v", teamB)
+	}
+
+	wantTotals := map[string]ResourceAmounts{
+		"cpu":                {Capacity: 12000, Allocatable: 12000, Requested: 1750},
+		"pods":               {Requested: 3},
+		"gpu.intel.com/i915": {Capacity: 4, Allocatable: 4, Requested: 1},
+	}
+	for name, want := range wantTotals {
+		if got := result.Totals[name]; got != want {
+			t.Errorf("total %s = %+v, want %+v", name, got, want)
+		}
+	}
+
+	fleet := TotalCapacity([]ClusterCapacity{*result, *result})
+	if fleet["cpu"].Requested != 3500 {
+		t.Errorf("fleet cpu = %+v", fleet["cpu"])
+	}
+}
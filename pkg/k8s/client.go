@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -12,12 +13,21 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/kubestellar/console/pkg/metrics"
+	"github.com/kubestellar/console/pkg/tracing"
 )
 
 // MultiClusterClient manages connections to multiple Kubernetes clusters
@@ -25,6 +35,9 @@ type MultiClusterClient struct {
 	mu              sync.RWMutex
 	kubeconfig      string
 	clients         map[string]*kubernetes.Clientset
+	metricsClients  map[string]metricsclient.Interface // metrics.k8s.io clients, built from configs
+	dynamicClients  map[string]dynamic.Interface       // Dynamic clients for CRDs, built from configs
+	discoveryClients map[string]*cachedDiscovery       // In-memory discovery caches, built from clients
 	configs         map[string]*rest.Config
 	rawConfig       *api.Config
 	healthCache     map[string]*ClusterHealth
@@ -34,6 +47,7 @@ type MultiClusterClient struct {
 	stopWatch       chan struct{}
 	onReload        func() // Callback when config is reloaded
 	inClusterConfig *rest.Config // In-cluster config when running inside k8s
+	agentClusters   map[string]*agentCluster // Clusters reached through kkc-agent tunnels
 }
 
 // ClusterInfo represents basic cluster information
@@ -75,6 +89,17 @@ type PodInfo struct {
 	Restarts  int    `json:"restarts"`
 	Age       string `json:"age"`
 	Node      string `json:"node,omitempty"`
+	// CreatedAt is the pod's creation time, for sorting by age
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PodList is one page of pods with the API server's continue token
+type PodList struct {
+	Items    []PodInfo
+	Continue string
+	// RemainingItemCount is the number of pods after this page, when the
+	// API server reports it
+	RemainingItemCount *int64
 }
 
 // PodIssue represents a pod with issues
@@ -98,6 +123,8 @@ type Event struct {
 	Cluster   string `json:"cluster,omitempty"`
 	Count     int32  `json:"count"`
 	Age       string `json:"age,omitempty"`
+	// LastSeen is when the event last occurred, for sorting
+	LastSeen time.Time `json:"lastSeen"`
 }
 
 // DeploymentIssue represents a deployment with issues
@@ -158,10 +185,14 @@ func NewMultiClusterClient(kubeconfig string) (*MultiClusterClient, error) {
 	client := &MultiClusterClient{
 		kubeconfig:  kubeconfig,
 		clients:     make(map[string]*kubernetes.Clientset),
+		metricsClients: make(map[string]metricsclient.Interface),
+		dynamicClients: make(map[string]dynamic.Interface),
+		discoveryClients: make(map[string]*cachedDiscovery),
 		configs:     make(map[string]*rest.Config),
 		healthCache: make(map[string]*ClusterHealth),
 		cacheTTL:    30 * time.Second,
 		cacheTime:   make(map[string]time.Time),
+		agentClusters: make(map[string]*agentCluster),
 	}
 
 	// Try to detect if we're running in-cluster
@@ -187,6 +218,9 @@ func (m *MultiClusterClient) LoadConfig() error {
 			log.Println("No kubeconfig file, using in-cluster config only")
 			m.rawConfig = nil
 			m.clients = make(map[string]*kubernetes.Clientset)
+			m.metricsClients = make(map[string]metricsclient.Interface)
+			m.dynamicClients = make(map[string]dynamic.Interface)
+			m.discoveryClients = make(map[string]*cachedDiscovery)
 			m.configs = make(map[string]*rest.Config)
 			m.healthCache = make(map[string]*ClusterHealth)
 			m.cacheTime = make(map[string]time.Time)
@@ -202,6 +236,9 @@ func (m *MultiClusterClient) LoadConfig() error {
 	m.rawConfig = config
 	// Clear cached clients when config reloads
 	m.clients = make(map[string]*kubernetes.Clientset)
+	m.metricsClients = make(map[string]metricsclient.Interface)
+	m.dynamicClients = make(map[string]dynamic.Interface)
+	m.discoveryClients = make(map[string]*cachedDiscovery)
 	m.configs = make(map[string]*rest.Config)
 	m.healthCache = make(map[string]*ClusterHealth)
 	m.cacheTime = make(map[string]time.Time)
@@ -303,6 +340,9 @@ func (m *MultiClusterClient) SetOnReload(callback func()) {
 
 // ListClusters returns all clusters from kubeconfig
 func (m *MultiClusterClient) ListClusters(ctx context.Context) ([]ClusterInfo, error) {
+	ctx, span := startSpan(ctx, "ListClusters", "")
+	defer span.End()
+
 	m.mu.RLock()
 	rawConfig := m.rawConfig
 	inClusterConfig := m.inClusterConfig
@@ -310,7 +350,13 @@ func (m *MultiClusterClient) ListClusters(ctx context.Context) ([]ClusterInfo, e
 
 	if rawConfig == nil && inClusterConfig == nil {
 		if err := m.LoadConfig(); err != nil {
-			return nil, err
+			// Agent-registered clusters are still usable without a kubeconfig
+			m.mu.RLock()
+			hasAgents := len(m.agentClusters) > 0
+			m.mu.RUnlock()
+			if !hasAgents {
+				return nil, err
+			}
 		}
 		m.mu.RLock()
 		rawConfig = m.rawConfig
@@ -356,6 +402,21 @@ func (m *MultiClusterClient) ListClusters(ctx context.Context) ([]ClusterInfo, e
 		}
 	}
 
+	// Add clusters registered by the caller's connected agents
+	m.mu.RLock()
+	for name, ac := range m.agentClusters {
+		if !ac.visibleTo(ctx) {
+			continue
+		}
+		clusters = append(clusters, ClusterInfo{
+			Name:    name,
+			Context: ac.context,
+			Server:  ac.server,
+			Source:  "agent",
+		})
+	}
+	m.mu.RUnlock()
+
 	// Sort by name
 	sort.Slice(clusters, func(i, j int) bool {
 		return clusters[i].Name < clusters[j].Name
@@ -365,8 +426,12 @@ func (m *MultiClusterClient) ListClusters(ctx context.Context) ([]ClusterInfo, e
 }
 
 // GetClient returns a kubernetes client for the specified context
-func (m *MultiClusterClient) GetClient(contextName string) (*kubernetes.Clientset, error) {
+func (m *MultiClusterClient) GetClient(ctx context.Context, contextName string) (*kubernetes.Clientset, error) {
 	m.mu.RLock()
+	if ac, ok := m.agentClusters[contextName]; ok && !ac.visibleTo(ctx) {
+		m.mu.RUnlock()
+		return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, contextName)
+	}
 	if client, ok := m.clients[contextName]; ok {
 		m.mu.RUnlock()
 		return client, nil
@@ -382,6 +447,20 @@ func (m *MultiClusterClient) GetClient(contextName string) (*kubernetes.Clientse
 		return client, nil
 	}
 
+	// Clusters registered by an agent are reached through its tunnel
+	if ac, ok := m.agentClusters[contextName]; ok {
+		if !ac.visibleTo(ctx) {
+			return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, contextName)
+		}
+		client, config, err := newAgentClient(ac)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for agent cluster %s: %w", contextName, err)
+		}
+		m.clients[contextName] = client
+		m.configs[contextName] = config
+		return client, nil
+	}
+
 	var config *rest.Config
 	var err error
 
@@ -400,6 +479,7 @@ func (m *MultiClusterClient) GetClient(contextName string) (*kubernetes.Clientse
 
 	// Set reasonable timeouts
 	config.Timeout = 10 * time.Second
+	instrumentConfig(contextName, config)
 
 	client, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -411,6 +491,114 @@ func (m *MultiClusterClient) GetClient(contextName string) (*kubernetes.Clientse
 	return client, nil
 }
 
+// GetDynamicClient returns a dynamic client for the specified context, for
+// custom resources such as policy engines' CRDs
+func (m *MultiClusterClient) GetDynamicClient(ctx context.Context, contextName string) (dynamic.Interface, error) {
+	// GetClient builds and caches the context's config
+	if _, err := m.GetClient(ctx, contextName); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if dc, ok := m.dynamicClients[contextName]; ok {
+		return dc, nil
+	}
+	config, ok := m.configs[contextName]
+	if !ok {
+		return nil, fmt.Errorf("no config for context %s", contextName)
+	}
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client for context %s: %w", contextName, err)
+	}
+	if m.dynamicClients == nil {
+		m.dynamicClients = make(map[string]dynamic.Interface)
+	}
+	m.dynamicClients[contextName] = dc
+	return dc, nil
+}
+
+// discoveryCacheTTL is how long discovered API resources are reused before
+// the server is asked again, e.g. for CRDs installed since
+const discoveryCacheTTL = 10 * time.Minute
+
+// cachedDiscovery is a discovery client that keeps what it discovered in
+// memory
+type cachedDiscovery struct {
+	discovery.CachedDiscoveryInterface
+	created time.Time
+}
+
+// GetDiscoveryClient returns a discovery client for the specified context
+// that answers from memory for discoveryCacheTTL, so repeated lookups do
+// not sweep every API group of the server
+func (m *MultiClusterClient) GetDiscoveryClient(ctx context.Context, contextName string) (discovery.DiscoveryInterface, error) {
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cached, ok := m.discoveryClients[contextName]; ok {
+		if time.Since(cached.created) > discoveryCacheTTL {
+			cached.Invalidate()
+			cached.created = time.Now()
+		}
+		return cached, nil
+	}
+	cached := &cachedDiscovery{CachedDiscoveryInterface: memory.NewMemCacheClient(client.Discovery()), created: time.Now()}
+	if m.discoveryClients == nil {
+		m.discoveryClients = make(map[string]*cachedDiscovery)
+	}
+	m.discoveryClients[contextName] = cached
+	return cached, nil
+}
+
+// instrumentConfig records API requests made with config in the metrics of
+// the cluster and traces them as client spans
+func instrumentConfig(contextName string, config *rest.Config) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return tracing.Transport(metrics.InstrumentRoundTripper(contextName, rt))
+	})
+}
+
+// startSpan starts the span of a MultiClusterClient method. cluster is
+// empty for methods covering all clusters.
+func startSpan(ctx context.Context, method, cluster string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("k8s.method", method)}
+	if cluster != "" {
+		attrs = append(attrs, attribute.String("k8s.cluster", cluster))
+	}
+	return tracing.Start(ctx, "k8s "+method, attrs...)
+}
+
+// HealthCacheAges returns how old each cluster's cached health result is
+func (m *MultiClusterClient) HealthCacheAges() map[string]time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ages := make(map[string]time.Duration, len(m.cacheTime))
+	for cluster, at := range m.cacheTime {
+		// /metrics is public, and agent cluster names are private
+		if _, ok := m.agentClusters[cluster]; ok {
+			continue
+		}
+		ages[cluster] = time.Since(at)
+	}
+	return ages
+}
+
+// IsLoaded reports whether a kubeconfig or in-cluster config has been loaded
+func (m *MultiClusterClient) IsLoaded() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.rawConfig != nil || m.inClusterConfig != nil
+}
+
 // classifyError determines the error type from an error message
 func classifyError(errMsg string) string {
 	lowerMsg := strings.ToLower(errMsg)
@@ -457,6 +645,13 @@ func classifyError(errMsg string) string {
 
 // GetClusterHealth returns health status for a cluster
 func (m *MultiClusterClient) GetClusterHealth(ctx context.Context, contextName string) (*ClusterHealth, error) {
+	ctx, span := startSpan(ctx, "GetClusterHealth", contextName)
+	defer span.End()
+
+	if !m.CanAccessCluster(ctx, contextName) {
+		return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, contextName)
+	}
+
 	// Check cache
 	m.mu.RLock()
 	if health, ok := m.healthCache[contextName]; ok {
@@ -469,7 +664,7 @@ func (m *MultiClusterClient) GetClusterHealth(ctx context.Context, contextName s
 
 	now := time.Now().Format(time.RFC3339)
 
-	client, err := m.GetClient(contextName)
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		errMsg := err.Error()
 		return &ClusterHealth{
@@ -532,17 +727,34 @@ func (m *MultiClusterClient) GetClusterHealth(ctx context.Context, contextName s
 
 // GetPods returns pods for a namespace/cluster
 func (m *MultiClusterClient) GetPods(ctx context.Context, contextName, namespace string) ([]PodInfo, error) {
-	client, err := m.GetClient(contextName)
+	list, err := m.ListPods(ctx, contextName, namespace, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
+	return list.Items, nil
+}
 
-	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+// ListPods returns the pods selected by opts, one page at a time when
+// opts.Limit is set
+func (m *MultiClusterClient) ListPods(ctx context.Context, contextName, namespace string, opts metav1.ListOptions) (*PodList, error) {
+	ctx, span := startSpan(ctx, "ListPods", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
 
-	var result []PodInfo
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PodList{
+		Items:              make([]PodInfo, 0, len(pods.Items)),
+		Continue:           pods.Continue,
+		RemainingItemCount: pods.RemainingItemCount,
+	}
 	for _, pod := range pods.Items {
 		ready := 0
 		total := len(pod.Spec.Containers)
@@ -555,7 +767,7 @@ func (m *MultiClusterClient) GetPods(ctx context.Context, contextName, namespace
 			restarts += int(cs.RestartCount)
 		}
 
-		result = append(result, PodInfo{
+		result.Items = append(result.Items, PodInfo{
 			Name:      pod.Name,
 			Namespace: pod.Namespace,
 			Cluster:   contextName,
@@ -564,6 +776,7 @@ func (m *MultiClusterClient) GetPods(ctx context.Context, contextName, namespace
 			Restarts:  restarts,
 			Age:       formatDuration(time.Since(pod.CreationTimestamp.Time)),
 			Node:      pod.Spec.NodeName,
+			CreatedAt: pod.CreationTimestamp.Time,
 		})
 	}
 
@@ -572,7 +785,10 @@ func (m *MultiClusterClient) GetPods(ctx context.Context, contextName, namespace
 
 // FindPodIssues returns pods with issues
 func (m *MultiClusterClient) FindPodIssues(ctx context.Context, contextName, namespace string) ([]PodIssue, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "FindPodIssues", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -634,64 +850,48 @@ func (m *MultiClusterClient) FindPodIssues(ctx context.Context, contextName, nam
 
 // GetEvents returns events from a cluster
 func (m *MultiClusterClient) GetEvents(ctx context.Context, contextName, namespace string, limit int) ([]Event, error) {
-	client, err := m.GetClient(contextName)
-	if err != nil {
-		return nil, err
-	}
-
-	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
-		Limit: int64(limit),
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// Sort by last timestamp descending
-	sort.Slice(events.Items, func(i, j int) bool {
-		return events.Items[i].LastTimestamp.After(events.Items[j].LastTimestamp.Time)
-	})
-
-	var result []Event
-	for i, event := range events.Items {
-		if limit > 0 && i >= limit {
-			break
-		}
-		result = append(result, Event{
-			Type:      event.Type,
-			Reason:    event.Reason,
-			Message:   event.Message,
-			Object:    fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name),
-			Namespace: event.Namespace,
-			Cluster:   contextName,
-			Count:     event.Count,
-			Age:       formatDuration(time.Since(event.LastTimestamp.Time)),
-		})
-	}
-
-	return result, nil
+	return m.listEvents(ctx, "GetEvents", contextName, namespace, metav1.ListOptions{Limit: int64(limit)}, limit)
 }
 
 // GetWarningEvents returns warning events from a cluster
 func (m *MultiClusterClient) GetWarningEvents(ctx context.Context, contextName, namespace string, limit int) ([]Event, error) {
-	client, err := m.GetClient(contextName)
+	return m.listEvents(ctx, "GetWarningEvents", contextName, namespace, metav1.ListOptions{FieldSelector: "type=Warning"}, limit)
+}
+
+// ListEvents returns all events selected by opts, most recent first
+func (m *MultiClusterClient) ListEvents(ctx context.Context, contextName, namespace string, opts metav1.ListOptions) ([]Event, error) {
+	return m.listEvents(ctx, "ListEvents", contextName, namespace, opts, 0)
+}
+
+// listEvents lists events, sorts them most recent first and keeps at most
+// limit of them (all when limit is 0)
+func (m *MultiClusterClient) listEvents(ctx context.Context, method, contextName, namespace string, opts metav1.ListOptions, limit int) ([]Event, error) {
+	ctx, span := startSpan(ctx, method, contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
 
-	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
-		FieldSelector: "type=Warning",
-	})
+	events, err := client.CoreV1().Events(namespace).List(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	return recentEvents(contextName, events.Items, limit), nil
+}
+
+// recentEvents converts events, most recent first, keeping at most limit
+// of them (all when limit is 0)
+func recentEvents(contextName string, events []corev1.Event, limit int) []Event {
 	// Sort by last timestamp descending
-	sort.Slice(events.Items, func(i, j int) bool {
-		return events.Items[i].LastTimestamp.After(events.Items[j].LastTimestamp.Time)
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].LastTimestamp.After(events[j].LastTimestamp.Time)
 	})
 
 	var result []Event
-	for i, event := range events.Items {
+	for i, event := range events {
 		if limit > 0 && i >= limit {
 			break
 		}
@@ -704,33 +904,41 @@ func (m *MultiClusterClient) GetWarningEvents(ctx context.Context, contextName,
 			Cluster:   contextName,
 			Count:     event.Count,
 			Age:       formatDuration(time.Since(event.LastTimestamp.Time)),
+			LastSeen:  event.LastTimestamp.Time,
 		})
 	}
-
-	return result, nil
+	return result
 }
 
 // GetGPUNodes returns nodes with GPU resources
 func (m *MultiClusterClient) GetGPUNodes(ctx context.Context, contextName string) ([]GPUNode, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "GetGPUNodes", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
-
-	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	// Allocated GPUs come from the capacity computed over a single pod list
+	capacity, nodes, err := clusterCapacity(ctx, client, contextName)
 	if err != nil {
 		return nil, err
 	}
+	usage := make(map[string]ResourceMap, len(capacity.Nodes))
+	for _, node := range capacity.Nodes {
+		usage[node.Name] = node.Resources
+	}
 
 	var gpuNodes []GPUNode
 	for _, node := range nodes.Items {
-		// Check for nvidia.com/gpu in allocatable resources
-		gpuQuantity, hasGPU := node.Status.Allocatable["nvidia.com/gpu"]
-		if !hasGPU {
-			continue
+		// Count NVIDIA, AMD and Intel GPUs in allocatable resources
+		gpuCount, allocated := 0, 0
+		for name, amounts := range usage[node.Name] {
+			if IsGPUResource(name) {
+				gpuCount += int(amounts.Allocatable)
+				allocated += int(amounts.Requested)
+			}
 		}
-
-		gpuCount := int(gpuQuantity.Value())
 		if gpuCount == 0 {
 			continue
 		}
@@ -739,26 +947,12 @@ func (m *MultiClusterClient) GetGPUNodes(ctx context.Context, contextName string
 		gpuType := "GPU"
 		if label, ok := node.Labels["nvidia.com/gpu.product"]; ok {
 			gpuType = label
+		} else if label, ok := node.Labels["amd.com/gpu.product-name"]; ok {
+			gpuType = label
 		} else if label, ok := node.Labels["accelerator"]; ok {
 			gpuType = label
 		}
 
-		// Get allocated GPUs by checking pods on this node
-		pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
-			FieldSelector: fmt.Sprintf("spec.nodeName=%s", node.Name),
-		})
-
-		allocated := 0
-		if err == nil {
-			for _, pod := range pods.Items {
-				for _, container := range pod.Spec.Containers {
-					if gpuReq, ok := container.Resources.Requests["nvidia.com/gpu"]; ok {
-						allocated += int(gpuReq.Value())
-					}
-				}
-			}
-		}
-
 		gpuNodes = append(gpuNodes, GPUNode{
 			Name:         node.Name,
 			Cluster:      contextName,
@@ -773,7 +967,10 @@ func (m *MultiClusterClient) GetGPUNodes(ctx context.Context, contextName string
 
 // FindDeploymentIssues returns deployments with issues
 func (m *MultiClusterClient) FindDeploymentIssues(ctx context.Context, contextName, namespace string) ([]DeploymentIssue, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "FindDeploymentIssues", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -827,7 +1024,10 @@ func (m *MultiClusterClient) FindDeploymentIssues(ctx context.Context, contextNa
 
 // GetDeployments returns all deployments with rollout status
 func (m *MultiClusterClient) GetDeployments(ctx context.Context, contextName, namespace string) ([]Deployment, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "GetDeployments", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -903,35 +1103,40 @@ func (m *MultiClusterClient) GetDeployments(ctx context.Context, contextName, na
 
 // GetAllClusterHealth returns health status for all clusters
 func (m *MultiClusterClient) GetAllClusterHealth(ctx context.Context) ([]ClusterHealth, error) {
-	clusters, err := m.ListClusters(ctx)
+	ctx, span := startSpan(ctx, "GetAllClusterHealth", "")
+	defer span.End()
+
+	result, err := FanOutAll(ctx, m, FanOutOptions{}, func(ctx context.Context, cluster string) ([]ClusterHealth, error) {
+		health, err := m.GetClusterHealth(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		return []ClusterHealth{*health}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	results := make([]ClusterHealth, 0, len(clusters))
-
-	for _, cluster := range clusters {
-		wg.Add(1)
-		go func(c ClusterInfo) {
-			defer wg.Done()
-			health, _ := m.GetClusterHealth(ctx, c.Name)
-			if health != nil {
-				mu.Lock()
-				results = append(results, *health)
-				mu.Unlock()
-			}
-		}(cluster)
+	// Clusters that failed or missed the deadline are reported unreachable
+	now := time.Now().Format(time.RFC3339)
+	for _, ce := range result.Errors {
+		result.Items = append(result.Items, ClusterHealth{
+			Cluster:      ce.Cluster,
+			ErrorType:    classifyError(ce.Error),
+			ErrorMessage: ce.Error,
+			Issues:       []string{fmt.Sprintf("Failed to check health: %s", ce.Error)},
+			CheckedAt:    now,
+		})
 	}
-
-	wg.Wait()
-	return results, nil
+	return result.Items, nil
 }
 
 // CheckSecurityIssues finds pods with security misconfigurations
 func (m *MultiClusterClient) CheckSecurityIssues(ctx context.Context, contextName, namespace string) ([]SecurityIssue, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "CheckSecurityIssues", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -941,8 +1146,14 @@ func (m *MultiClusterClient) CheckSecurityIssues(ctx context.Context, contextNam
 		return nil, err
 	}
 
+	return podSecurityIssues(contextName, pods.Items), nil
+}
+
+// podSecurityIssues checks pods for privileged or root containers, missing
+// security contexts and shared host namespaces
+func podSecurityIssues(contextName string, pods []corev1.Pod) []SecurityIssue {
 	var issues []SecurityIssue
-	for _, pod := range pods.Items {
+	for _, pod := range pods {
 		for _, container := range pod.Spec.Containers {
 			sc := container.SecurityContext
 			podSC := pod.Spec.SecurityContext
@@ -1015,7 +1226,7 @@ func (m *MultiClusterClient) CheckSecurityIssues(ctx context.Context, contextNam
 		}
 	}
 
-	return issues, nil
+	return issues
 }
 
 func formatDuration(d time.Duration) string {
@@ -0,0 +1,150 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// DefaultFanOutConcurrency is the most clusters queried at once
+	DefaultFanOutConcurrency = 8
+	// DefaultClusterTimeout bounds each cluster's part of a fan-out, so an
+	// unreachable cluster cannot stall the whole response
+	DefaultClusterTimeout = 8 * time.Second
+)
+
+// FanOutOptions bounds a query across clusters. Zero values use the
+// defaults.
+type FanOutOptions struct {
+	Concurrency    int
+	ClusterTimeout time.Duration
+}
+
+// ClusterError reports a cluster whose part of a fan-out failed
+type ClusterError struct {
+	Cluster  string `json:"cluster"`
+	Error    string `json:"error"`
+	TimedOut bool   `json:"timedOut,omitempty"`
+}
+
+// FanOutStatus summarizes which clusters answered a fan-out
+type FanOutStatus struct {
+	// Clusters is the number of clusters queried
+	Clusters int            `json:"clusterCount"`
+	Errors   []ClusterError `json:"clusterErrors"`
+}
+
+// TimedOut returns the number of clusters that missed their deadline
+func (s FanOutStatus) TimedOut() int {
+	n := 0
+	for _, e := range s.Errors {
+		if e.TimedOut {
+			n++
+		}
+	}
+	return n
+}
+
+// FanOutResult holds the items gathered from the clusters that answered, in
+// cluster order
+type FanOutResult[T any] struct {
+	Items []T
+	FanOutStatus
+}
+
+// FanOut calls fn for each cluster with at most opts.Concurrency calls in
+// flight, each under its own opts.ClusterTimeout deadline. Failed and late
+// clusters are reported in Errors and the other clusters' items returned.
+// A cluster that misses its deadline is given up on even if fn ignores its
+// context.
+func FanOut[T any](ctx context.Context, clusters []string, opts FanOutOptions, fn func(ctx context.Context, cluster string) ([]T, error)) FanOutResult[T] {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultFanOutConcurrency
+	}
+	timeout := opts.ClusterTimeout
+	if timeout <= 0 {
+		timeout = DefaultClusterTimeout
+	}
+
+	type outcome struct {
+		items []T
+		err   error
+	}
+	outcomes := make([]outcome, len(clusters))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func(i int, cluster string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				outcomes[i].err = ctx.Err()
+				return
+			}
+
+			clusterCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			// Buffered so a call that outlives its deadline can finish
+			done := make(chan outcome, 1)
+			go func() {
+				items, err := fn(clusterCtx, cluster)
+				done <- outcome{items, err}
+			}()
+			select {
+			case o := <-done:
+				outcomes[i] = o
+			case <-clusterCtx.Done():
+				outcomes[i].err = clusterCtx.Err()
+			}
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	result := FanOutResult[T]{
+		Items:        []T{},
+		FanOutStatus: FanOutStatus{Clusters: len(clusters), Errors: []ClusterError{}},
+	}
+	for i, o := range outcomes {
+		if o.err != nil {
+			result.Errors = append(result.Errors, ClusterError{
+				Cluster:  clusters[i],
+				Error:    o.err.Error(),
+				TimedOut: errors.Is(o.err, context.DeadlineExceeded),
+			})
+			continue
+		}
+		result.Items = append(result.Items, o.items...)
+	}
+	return result
+}
+
+// FanOutAll runs FanOut over every cluster in the kubeconfig
+func FanOutAll[T any](ctx context.Context, m *MultiClusterClient, opts FanOutOptions, fn func(ctx context.Context, cluster string) ([]T, error)) (FanOutResult[T], error) {
+	ctx, span := startSpan(ctx, "FanOutAll", "")
+	defer span.End()
+
+	clusters, err := m.ListClusters(ctx)
+	if err != nil {
+		return FanOutResult[T]{}, err
+	}
+	names := make([]string, len(clusters))
+	for i, cl := range clusters {
+		names[i] = cl.Name
+	}
+	result := FanOut(ctx, names, opts, fn)
+	span.SetAttributes(
+		attribute.Int("k8s.clusters", result.Clusters),
+		attribute.Int("k8s.cluster_errors", len(result.Errors)),
+	)
+	return result, nil
+}
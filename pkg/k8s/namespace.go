@@ -0,0 +1,534 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// namespaceWarningEvents is how many recent warning events an analysis keeps
+const namespaceWarningEvents = 20
+
+// WorkloadHealth counts the workloads of a kind and names the unhealthy ones
+type WorkloadHealth struct {
+	Kind      string   `json:"kind"`
+	Total     int      `json:"total"`
+	Healthy   int      `json:"healthy"`
+	Unhealthy []string `json:"unhealthy,omitempty"`
+}
+
+// PodSummary counts a namespace's pods by phase
+type PodSummary struct {
+	Total    int            `json:"total"`
+	Phases   map[string]int `json:"phases"`
+	NotReady int            `json:"notReady"`
+	Restarts int            `json:"restarts"`
+}
+
+// QuotaResource is one resource of a ResourceQuota. Ratio is used over hard.
+type QuotaResource struct {
+	Resource string  `json:"resource"`
+	Hard     string  `json:"hard"`
+	Used     string  `json:"used"`
+	Ratio    float64 `json:"ratio"`
+}
+
+// QuotaUsage is a ResourceQuota's usage
+type QuotaUsage struct {
+	Name      string          `json:"name"`
+	Resources []QuotaResource `json:"resources"`
+}
+
+// LimitRangeLimit is one resource constraint of a LimitRange
+type LimitRangeLimit struct {
+	Type           string `json:"type"`
+	Resource       string `json:"resource"`
+	Min            string `json:"min,omitempty"`
+	Max            string `json:"max,omitempty"`
+	Default        string `json:"default,omitempty"`
+	DefaultRequest string `json:"defaultRequest,omitempty"`
+}
+
+// LimitRangeSummary is a LimitRange's constraints
+type LimitRangeSummary struct {
+	Name   string            `json:"name"`
+	Limits []LimitRangeLimit `json:"limits"`
+}
+
+// PVCStatus is a PersistentVolumeClaim's binding and size
+type PVCStatus struct {
+	Name         string   `json:"name"`
+	Phase        string   `json:"phase"`
+	StorageClass string   `json:"storageClass,omitempty"`
+	VolumeName   string   `json:"volumeName,omitempty"`
+	AccessModes  []string `json:"accessModes,omitempty"`
+	Requested    string   `json:"requested,omitempty"`
+	Capacity     string   `json:"capacity,omitempty"`
+}
+
+// NamespaceSubject is a subject granted a role in a namespace. ClusterWide
+// subjects are bound by a ClusterRoleBinding and hold the role everywhere.
+type NamespaceSubject struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	Role        string `json:"role"`
+	RoleKind    string `json:"roleKind"`
+	Binding     string `json:"binding"`
+	ClusterWide bool   `json:"clusterWide"`
+}
+
+// NetworkPolicyCoverage reports which pods network policies select. Pods no
+// policy selects accept and send any traffic.
+type NetworkPolicyCoverage struct {
+	Policies           []string `json:"policies"`
+	DefaultDenyIngress bool     `json:"defaultDenyIngress"`
+	DefaultDenyEgress  bool     `json:"defaultDenyEgress"`
+	PodsCovered        int      `json:"podsCovered"`
+	PodsTotal          int      `json:"podsTotal"`
+	Uncovered          []string `json:"uncovered,omitempty"`
+}
+
+// NamespaceAnalysis is a deep view of one namespace. Errors maps resources
+// that could not be listed, typically for lack of permission, to the
+// error; their sections are left empty.
+type NamespaceAnalysis struct {
+	Namespace       string                `json:"namespace"`
+	Cluster         string                `json:"cluster"`
+	Status          string                `json:"status"`
+	Labels          map[string]string     `json:"labels,omitempty"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	Workloads       []WorkloadHealth      `json:"workloads"`
+	Pods            PodSummary            `json:"pods"`
+	Quotas          []QuotaUsage          `json:"quotas"`
+	LimitRanges     []LimitRangeSummary   `json:"limitRanges"`
+	PVCs            []PVCStatus           `json:"pvcs"`
+	WarningEvents   []Event               `json:"warningEvents"`
+	SecurityIssues  []SecurityIssue       `json:"securityIssues"`
+	Access          []NamespaceSubject    `json:"access"`
+	NetworkPolicies NetworkPolicyCoverage `json:"networkPolicies"`
+	Errors          map[string]string     `json:"errors,omitempty"`
+	AnalyzedAt      time.Time             `json:"analyzedAt"`
+}
+
+// AnalyzeNamespace lists the resources of a namespace once each, in
+// parallel, and derives workload health, quota and PVC usage, security
+// issues, access and network policy coverage from them
+func (m *MultiClusterClient) AnalyzeNamespace(ctx context.Context, contextName, namespace string) (*NamespaceAnalysis, error) {
+	ctx, span := startSpan(ctx, "AnalyzeNamespace", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return analyzeNamespace(ctx, client, contextName, namespace)
+}
+
+// analyzeNamespace is AnalyzeNamespace with the clientset already made
+func analyzeNamespace(ctx context.Context, client kubernetes.Interface, contextName, namespace string) (*NamespaceAnalysis, error) {
+	ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pods         *corev1.PodList
+		deployments  *appsv1.DeploymentList
+		statefulSets *appsv1.StatefulSetList
+		daemonSets   *appsv1.DaemonSetList
+		jobs         *batchv1.JobList
+		cronJobs     *batchv1.CronJobList
+		quotas       *corev1.ResourceQuotaList
+		limitRanges  *corev1.LimitRangeList
+		pvcs         *corev1.PersistentVolumeClaimList
+		events       *corev1.EventList
+		roleBindings *rbacv1.RoleBindingList
+		clusterRBs   *rbacv1.ClusterRoleBindingList
+		netpols      *networkingv1.NetworkPolicyList
+	)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := map[string]string{}
+	list := func(resource string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				mu.Lock()
+				errs[resource] = err.Error()
+				mu.Unlock()
+			}
+		}()
+	}
+
+	opts := metav1.ListOptions{}
+	list("pods", func() (err error) {
+		pods, err = client.CoreV1().Pods(namespace).List(ctx, opts)
+		return err
+	})
+	list("deployments", func() (err error) {
+		deployments, err = client.AppsV1().Deployments(namespace).List(ctx, opts)
+		return err
+	})
+	list("statefulsets", func() (err error) {
+		statefulSets, err = client.AppsV1().StatefulSets(namespace).List(ctx, opts)
+		return err
+	})
+	list("daemonsets", func() (err error) {
+		daemonSets, err = client.AppsV1().DaemonSets(namespace).List(ctx, opts)
+		return err
+	})
+	list("jobs", func() (err error) {
+		jobs, err = client.BatchV1().Jobs(namespace).List(ctx, opts)
+		return err
+	})
+	list("cronjobs", func() (err error) {
+		cronJobs, err = client.BatchV1().CronJobs(namespace).List(ctx, opts)
+		return err
+	})
+	list("resourcequotas", func() (err error) {
+		quotas, err = client.CoreV1().ResourceQuotas(namespace).List(ctx, opts)
+		return err
+	})
+	list("limitranges", func() (err error) {
+		limitRanges, err = client.CoreV1().LimitRanges(namespace).List(ctx, opts)
+		return err
+	})
+	list("persistentvolumeclaims", func() (err error) {
+		pvcs, err = client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, opts)
+		return err
+	})
+	list("events", func() (err error) {
+		events, err = client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=Warning"})
+		return err
+	})
+	list("rolebindings", func() (err error) {
+		roleBindings, err = client.RbacV1().RoleBindings(namespace).List(ctx, opts)
+		return err
+	})
+	list("clusterrolebindings", func() (err error) {
+		clusterRBs, err = client.RbacV1().ClusterRoleBindings().List(ctx, opts)
+		return err
+	})
+	list("networkpolicies", func() (err error) {
+		netpols, err = client.NetworkingV1().NetworkPolicies(namespace).List(ctx, opts)
+		return err
+	})
+	wg.Wait()
+
+	result := &NamespaceAnalysis{
+		Namespace:      namespace,
+		Cluster:        contextName,
+		Status:         string(ns.Status.Phase),
+		Labels:         ns.Labels,
+		CreatedAt:      ns.CreationTimestamp.Time,
+		Workloads:      []WorkloadHealth{},
+		Pods:           PodSummary{Phases: map[string]int{}},
+		Quotas:         []QuotaUsage{},
+		LimitRanges:    []LimitRangeSummary{},
+		PVCs:           []PVCStatus{},
+		WarningEvents:  []Event{},
+		SecurityIssues: []SecurityIssue{},
+		Access:         []NamespaceSubject{},
+		NetworkPolicies: NetworkPolicyCoverage{
+			Policies: []string{},
+		},
+		AnalyzedAt: time.Now(),
+	}
+	if len(errs) > 0 {
+		result.Errors = errs
+	}
+
+	if deployments != nil {
+		w := WorkloadHealth{Kind: "Deployment"}
+		for _, d := range deployments.Items {
+			w.add(d.Name, d.Status.ReadyReplicas >= replicas(d.Spec.Replicas))
+		}
+		result.Workloads = append(result.Workloads, w)
+	}
+	if statefulSets != nil {
+		w := WorkloadHealth{Kind: "StatefulSet"}
+		for _, s := range statefulSets.Items {
+			w.add(s.Name, s.Status.ReadyReplicas >= replicas(s.Spec.Replicas))
+		}
+		result.Workloads = append(result.Workloads, w)
+	}
+	if daemonSets != nil {
+		w := WorkloadHealth{Kind: "DaemonSet"}
+		for _, d := range daemonSets.Items {
+			w.add(d.Name, d.Status.NumberReady >= d.Status.DesiredNumberScheduled)
+		}
+		result.Workloads = append(result.Workloads, w)
+	}
+	if jobs != nil {
+		w := WorkloadHealth{Kind: "Job"}
+		for _, j := range jobs.Items {
+			w.add(j.Name, !jobFailed(&j))
+		}
+		result.Workloads = append(result.Workloads, w)
+	}
+	if cronJobs != nil {
+		// A CronJob's health shows in its Jobs
+		w := WorkloadHealth{Kind: "CronJob"}
+		for _, cj := range cronJobs.Items {
+			w.add(cj.Name, true)
+		}
+		result.Workloads = append(result.Workloads, w)
+	}
+
+	if pods != nil {
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			result.Pods.Total++
+			result.Pods.Phases[string(pod.Status.Phase)]++
+			if pod.Status.Phase == corev1.PodRunning && !podReady(pod) {
+				result.Pods.NotReady++
+			}
+			for _, cs := range pod.Status.ContainerStatuses {
+				result.Pods.Restarts += int(cs.RestartCount)
+			}
+		}
+		if issues := podSecurityIssues(contextName, pods.Items); issues != nil {
+			result.SecurityIssues = issues
+		}
+	}
+
+	if quotas != nil {
+		for _, q := range quotas.Items {
+			usage := QuotaUsage{Name: q.Name, Resources: []QuotaResource{}}
+			for name, hard := range q.Status.Hard {
+				used := q.Status.Used[name]
+				r := QuotaResource{Resource: string(name), Hard: hard.String(), Used: used.String()}
+				if h := hard.AsApproximateFloat64(); h > 0 {
+					r.Ratio = used.AsApproximateFloat64() / h
+				}
+				usage.Resources = append(usage.Resources, r)
+			}
+			sort.Slice(usage.Resources, func(i, j int) bool {
+				return usage.Resources[i].Resource < usage.Resources[j].Resource
+			})
+			result.Quotas = append(result.Quotas, usage)
+		}
+	}
+
+	if limitRanges != nil {
+		for _, lr := range limitRanges.Items {
+			summary := LimitRangeSummary{Name: lr.Name, Limits: []LimitRangeLimit{}}
+			for _, item := range lr.Spec.Limits {
+				summary.Limits = append(summary.Limits, limitRangeLimits(item)...)
+			}
+			result.LimitRanges = append(result.LimitRanges, summary)
+		}
+	}
+
+	if pvcs != nil {
+		for _, pvc := range pvcs.Items {
+			status := PVCStatus{
+				Name:       pvc.Name,
+				Phase:      string(pvc.Status.Phase),
+				VolumeName: pvc.Spec.VolumeName,
+			}
+			if pvc.Spec.StorageClassName != nil {
+				status.StorageClass = *pvc.Spec.StorageClassName
+			}
+			for _, mode := range pvc.Spec.AccessModes {
+				status.AccessModes = append(status.AccessModes, string(mode))
+			}
+			if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+				status.Requested = q.String()
+			}
+			if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+				status.Capacity = q.String()
+			}
+			result.PVCs = append(result.PVCs, status)
+		}
+	}
+
+	if events != nil {
+		if recent := recentEvents(contextName, events.Items, namespaceWarningEvents); recent != nil {
+			result.WarningEvents = recent
+		}
+	}
+
+	if roleBindings != nil {
+		for _, rb := range roleBindings.Items {
+			result.Access = append(result.Access, namespaceSubjects(rb.Name, rb.RoleRef, rb.Subjects, false)...)
+		}
+	}
+	if clusterRBs != nil {
+		for _, crb := range clusterRBs.Items {
+			// Skip system bindings, as ListClusterRoleBindings does
+			if isSystemRole(crb.Name) {
+				continue
+			}
+			result.Access = append(result.Access, namespaceSubjects(crb.Name, crb.RoleRef, crb.Subjects, true)...)
+		}
+	}
+	sort.SliceStable(result.Access, func(i, j int) bool {
+		a, b := result.Access[i], result.Access[j]
+		if a.ClusterWide != b.ClusterWide {
+			return !a.ClusterWide
+		}
+		return a.Kind+"/"+a.Name < b.Kind+"/"+b.Name
+	})
+
+	if netpols != nil {
+		result.NetworkPolicies = networkPolicyCoverage(netpols.Items, pods)
+	}
+
+	return result, nil
+}
+
+func (w *WorkloadHealth) add(name string, healthy bool) {
+	w.Total++
+	if healthy {
+		w.Healthy++
+	} else {
+		w.Unhealthy = append(w.Unhealthy, name)
+	}
+}
+
+// replicas returns a workload's desired replicas, which default to one
+func replicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+func jobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// limitRangeLimits flattens a LimitRange item into one limit per resource
+func limitRangeLimits(item corev1.LimitRangeItem) []LimitRangeLimit {
+	byResource := map[corev1.ResourceName]*LimitRangeLimit{}
+	get := func(name corev1.ResourceName) *LimitRangeLimit {
+		if l, ok := byResource[name]; ok {
+			return l
+		}
+		l := &LimitRangeLimit{Type: string(item.Type), Resource: string(name)}
+		byResource[name] = l
+		return l
+	}
+	for name, q := range item.Min {
+		get(name).Min = q.String()
+	}
+	for name, q := range item.Max {
+		get(name).Max = q.String()
+	}
+	for name, q := range item.Default {
+		get(name).Default = q.String()
+	}
+	for name, q := range item.DefaultRequest {
+		get(name).DefaultRequest = q.String()
+	}
+
+	limits := make([]LimitRangeLimit, 0, len(byResource))
+	for _, l := range byResource {
+		limits = append(limits, *l)
+	}
+	sort.Slice(limits, func(i, j int) bool {
+		return limits[i].Resource < limits[j].Resource
+	})
+	return limits
+}
+
+func namespaceSubjects(binding string, role rbacv1.RoleRef, subjects []rbacv1.Subject, clusterWide bool) []NamespaceSubject {
+	result := make([]NamespaceSubject, 0, len(subjects))
+	for _, s := range subjects {
+		result = append(result, NamespaceSubject{
+			Kind:        s.Kind,
+			Name:        s.Name,
+			Namespace:   s.Namespace,
+			Role:        role.Name,
+			RoleKind:    role.Kind,
+			Binding:     binding,
+			ClusterWide: clusterWide,
+		})
+	}
+	return result
+}
+
+// networkPolicyCoverage finds default-deny policies and the live pods no
+// policy selects
+func networkPolicyCoverage(policies []networkingv1.NetworkPolicy, pods *corev1.PodList) NetworkPolicyCoverage {
+	coverage := NetworkPolicyCoverage{Policies: []string{}}
+
+	var selectors []labels.Selector
+	for _, policy := range policies {
+		coverage.Policies = append(coverage.Policies, policy.Name)
+
+		selectsAll := len(policy.Spec.PodSelector.MatchLabels) == 0 && len(policy.Spec.PodSelector.MatchExpressions) == 0
+		for _, t := range policy.Spec.PolicyTypes {
+			switch {
+			case t == networkingv1.PolicyTypeIngress && selectsAll && len(policy.Spec.Ingress) == 0:
+				coverage.DefaultDenyIngress = true
+			case t == networkingv1.PolicyTypeEgress && selectsAll && len(policy.Spec.Egress) == 0:
+				coverage.DefaultDenyEgress = true
+			}
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil {
+			continue
+		}
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(coverage.Policies)
+
+	if pods == nil {
+		return coverage
+	}
+	for _, pod := range pods.Items {
+		// Finished pods send and receive no traffic
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		// Host network pods are not subject to network policies
+		if pod.Spec.HostNetwork {
+			continue
+		}
+		coverage.PodsTotal++
+		covered := false
+		for _, selector := range selectors {
+			if selector.Matches(labels.Set(pod.Labels)) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			coverage.PodsCovered++
+		} else {
+			coverage.Uncovered = append(coverage.Uncovered, pod.Name)
+		}
+	}
+	sort.Strings(coverage.Uncovered)
+	return coverage
+}
@@ -0,0 +1,714 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// Pod Security Standards profiles. Restricted includes every baseline rule.
+const (
+	PSSBaseline   = "baseline"
+	PSSRestricted = "restricted"
+)
+
+// pssSeverityRank orders rule severities
+var pssSeverityRank = map[string]int{"critical": 3, "high": 2, "medium": 1}
+
+// pssEnforceLabel is the namespace label Pod Security Admission enforces
+const pssEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+// PSSRule is a Pod Security Standards control
+type PSSRule struct {
+	ID          string `json:"id"`
+	Profile     string `json:"profile"`
+	Title       string `json:"title"`
+	Severity    string `json:"severity"` // critical, high, medium
+	Remediation string `json:"remediation"`
+
+	// check returns a detail for each violation in the pod
+	check func(pod *corev1.Pod) []string
+}
+
+// PSSViolation is a rule violated by the pods of a workload
+type PSSViolation struct {
+	RuleID      string `json:"ruleId"`
+	Profile     string `json:"profile"`
+	Severity    string `json:"severity"`
+	Title       string `json:"title"`
+	Remediation string `json:"remediation"`
+	Cluster     string `json:"cluster"`
+	Namespace   string `json:"namespace"`
+	// Kind and Name are the workload owning the pods, or the pod itself
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Pods       int      `json:"pods"`
+	Details    []string `json:"details"`
+	Suppressed bool     `json:"suppressed"`
+}
+
+// PSSProfileScore is how many of a namespace's workloads meet a profile
+type PSSProfileScore struct {
+	Compliant int `json:"compliant"`
+	// Score is the percentage of workloads compliant, 100 with none
+	Score int `json:"score"`
+}
+
+// PSSNamespacePosture is a namespace's compliance with each profile,
+// not counting suppressed violations
+type PSSNamespacePosture struct {
+	Namespace string `json:"namespace"`
+	// Enforced is the Pod Security Admission level the namespace enforces
+	Enforced   string          `json:"enforced,omitempty"`
+	Workloads  int             `json:"workloads"`
+	Baseline   PSSProfileScore `json:"baseline"`
+	Restricted PSSProfileScore `json:"restricted"`
+	Violations int             `json:"violations"`
+	Suppressed int             `json:"suppressed"`
+}
+
+// PSSPosture is the Pod Security Standards posture of a cluster
+type PSSPosture struct {
+	Cluster    string                `json:"cluster"`
+	Namespaces []PSSNamespacePosture `json:"namespaces"`
+	Violations []PSSViolation        `json:"violations"`
+	Baseline   PSSProfileScore       `json:"baseline"`
+	Restricted PSSProfileScore       `json:"restricted"`
+}
+
+// PSSRules returns the Pod Security Standards rules, baseline first
+func PSSRules() []PSSRule {
+	return pssRules
+}
+
+// EvaluatePodSecurity checks the pods of a namespace, or all namespaces,
+// against the baseline and restricted profiles. Violations matching a
+// suppression are reported but do not count against compliance.
+func (m *MultiClusterClient) EvaluatePodSecurity(ctx context.Context, contextName, namespace string, suppressions []models.PSSSuppression) (*PSSPosture, error) {
+	ctx, span := startSpan(ctx, "EvaluatePodSecurity", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return evaluatePodSecurity(ctx, client, contextName, namespace, suppressions)
+}
+
+func evaluatePodSecurity(ctx context.Context, client kubernetes.Interface, contextName, namespace string, suppressions []models.PSSSuppression) (*PSSPosture, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// The enforced level is informational, so missing access is not fatal
+	enforced := map[string]string{}
+	if namespace != "" {
+		if ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err == nil {
+			enforced[ns.Name] = ns.Labels[pssEnforceLabel]
+		}
+	} else if namespaces, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err == nil {
+		for _, ns := range namespaces.Items {
+			enforced[ns.Name] = ns.Labels[pssEnforceLabel]
+		}
+	}
+
+	type workloadKey struct{ namespace, kind, name string }
+	workloads := map[workloadKey]bool{}
+	violations := map[string]*PSSViolation{} // by workload and rule
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		kind, name := podWorkload(pod)
+		key := workloadKey{pod.Namespace, kind, name}
+		workloads[key] = true
+
+		for _, rule := range pssRules {
+			details := rule.check(pod)
+			if len(details) == 0 {
+				continue
+			}
+			vkey := pod.Namespace + "/" + kind + "/" + name + "/" + rule.ID
+			if v, ok := violations[vkey]; ok {
+				v.Pods++
+				continue
+			}
+			violations[vkey] = &PSSViolation{
+				RuleID:      rule.ID,
+				Profile:     rule.Profile,
+				Severity:    rule.Severity,
+				Title:       rule.Title,
+				Remediation: rule.Remediation,
+				Cluster:     contextName,
+				Namespace:   pod.Namespace,
+				Kind:        kind,
+				Name:        name,
+				Pods:        1,
+				Details:     details,
+				Suppressed:  pssSuppressed(suppressions, contextName, pod.Namespace, rule.ID),
+			}
+		}
+	}
+
+	posture := &PSSPosture{Cluster: contextName, Namespaces: []PSSNamespacePosture{}, Violations: []PSSViolation{}}
+	byNamespace := map[string]*PSSNamespacePosture{}
+	nsPosture := func(name string) *PSSNamespacePosture {
+		p, ok := byNamespace[name]
+		if !ok {
+			p = &PSSNamespacePosture{Namespace: name, Enforced: enforced[name]}
+			byNamespace[name] = p
+		}
+		return p
+	}
+
+	// failing[workload] is the highest profile the workload fails
+	failing := map[workloadKey]string{}
+	for _, v := range violations {
+		posture.Violations = append(posture.Violations, *v)
+		p := nsPosture(v.Namespace)
+		if v.Suppressed {
+			p.Suppressed++
+			continue
+		}
+		p.Violations++
+		key := workloadKey{v.Namespace, v.Kind, v.Name}
+		if v.Profile == PSSBaseline || failing[key] == "" {
+			failing[key] = v.Profile
+		}
+	}
+
+	total := 0
+	baselineOK, restrictedOK := 0, 0
+	for key := range workloads {
+		p := nsPosture(key.namespace)
+		p.Workloads++
+		total++
+		switch failing[key] {
+		case "":
+			p.Baseline.Compliant++
+			p.Restricted.Compliant++
+			baselineOK++
+			restrictedOK++
+		case PSSRestricted:
+			p.Baseline.Compliant++
+			baselineOK++
+		}
+	}
+	for _, p := range byNamespace {
+		p.Baseline.Score = complianceScore(p.Baseline.Compliant, p.Workloads)
+		p.Restricted.Score = complianceScore(p.Restricted.Compliant, p.Workloads)
+		posture.Namespaces = append(posture.Namespaces, *p)
+	}
+	posture.Baseline = PSSProfileScore{Compliant: baselineOK, Score: complianceScore(baselineOK, total)}
+	posture.Restricted = PSSProfileScore{Compliant: restrictedOK, Score: complianceScore(restrictedOK, total)}
+
+	sort.Slice(posture.Namespaces, func(i, j int) bool {
+		return posture.Namespaces[i].Namespace < posture.Namespaces[j].Namespace
+	})
+	sort.Slice(posture.Violations, func(i, j int) bool {
+		a, b := posture.Violations[i], posture.Violations[j]
+		if a.Suppressed != b.Suppressed {
+			return !a.Suppressed
+		}
+		if a.Severity != b.Severity {
+			return pssSeverityRank[a.Severity] > pssSeverityRank[b.Severity]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.RuleID < b.RuleID
+	})
+	return posture, nil
+}
+
+func complianceScore(compliant, total int) int {
+	if total == 0 {
+		return 100
+	}
+	return compliant * 100 / total
+}
+
+// pssSuppressed reports whether a rule is suppressed in a namespace; a
+// suppression without a cluster applies to every cluster
+func pssSuppressed(suppressions []models.PSSSuppression, cluster, namespace, ruleID string) bool {
+	for _, s := range suppressions {
+		if s.RuleID == ruleID && s.Namespace == namespace && (s.Cluster == "" || s.Cluster == cluster) {
+			return true
+		}
+	}
+	return false
+}
+
+// podWorkload returns the workload controlling a pod, resolving
+// ReplicaSets to their Deployment, or the pod itself
+func podWorkload(pod *corev1.Pod) (kind, name string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod", pod.Name
+	}
+	if owner.Kind == "ReplicaSet" {
+		if hash := pod.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+// podContainer is a container, init container or ephemeral container
+type podContainer struct {
+	kind            string
+	name            string
+	securityContext *corev1.SecurityContext
+	ports           []corev1.ContainerPort
+}
+
+func (c podContainer) String() string {
+	return fmt.Sprintf("%s %q", c.kind, c.name)
+}
+
+func podContainers(spec *corev1.PodSpec) []podContainer {
+	var containers []podContainer
+	for _, c := range spec.InitContainers {
+		containers = append(containers, podContainer{"initContainer", c.Name, c.SecurityContext, c.Ports})
+	}
+	for _, c := range spec.Containers {
+		containers = append(containers, podContainer{"container", c.Name, c.SecurityContext, c.Ports})
+	}
+	for _, c := range spec.EphemeralContainers {
+		containers = append(containers, podContainer{"ephemeralContainer", c.Name, c.SecurityContext, c.Ports})
+	}
+	return containers
+}
+
+// isWindowsPod reports whether a pod runs on Windows, which the restricted
+// Linux-only controls exempt
+func isWindowsPod(pod *corev1.Pod) bool {
+	return pod.Spec.OS != nil && pod.Spec.OS.Name == corev1.Windows
+}
+
+// baselineCapabilities are the capabilities baseline allows adding
+var baselineCapabilities = map[corev1.Capability]bool{
+	"AUDIT_WRITE": true, "CHOWN": true, "DAC_OVERRIDE": true, "FOWNER": true,
+	"FSETID": true, "KILL": true, "MKNOD": true, "NET_BIND_SERVICE": true,
+	"SETFCAP": true, "SETGID": true, "SETPCAP": true, "SETUID": true, "SYS_CHROOT": true,
+}
+
+// safeSysctls are the sysctls baseline allows
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":              true,
+	"net.ipv4.ip_local_port_range":        true,
+	"net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.tcp_syncookies":             true,
+	"net.ipv4.ping_group_range":           true,
+	"net.ipv4.ip_local_reserved_ports":    true,
+	"net.ipv4.tcp_keepalive_time":         true,
+	"net.ipv4.tcp_fin_timeout":            true,
+	"net.ipv4.tcp_keepalive_intvl":        true,
+	"net.ipv4.tcp_keepalive_probes":       true,
+}
+
+// allowedSELinuxTypes are the SELinux types baseline allows
+var allowedSELinuxTypes = map[string]bool{
+	"": true, "container_t": true, "container_init_t": true, "container_kvm_t": true, "container_engine_t": true,
+}
+
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+var pssRules = []PSSRule{
+	{
+		ID:          "baseline-host-namespaces",
+		Profile:     PSSBaseline,
+		Title:       "Host namespaces",
+		Severity:    "high",
+		Remediation: "Remove hostNetwork, hostPID and hostIPC from the pod spec, or set them to false.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			if pod.Spec.HostNetwork {
+				details = append(details, "hostNetwork is true")
+			}
+			if pod.Spec.HostPID {
+				details = append(details, "hostPID is true")
+			}
+			if pod.Spec.HostIPC {
+				details = append(details, "hostIPC is true")
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-privileged",
+		Profile:     PSSBaseline,
+		Title:       "Privileged containers",
+		Severity:    "critical",
+		Remediation: "Remove securityContext.privileged from every container, or set it to false.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				if sc := c.securityContext; sc != nil && sc.Privileged != nil && *sc.Privileged {
+					details = append(details, c.String()+" is privileged")
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-capabilities",
+		Profile:     PSSBaseline,
+		Title:       "Added capabilities",
+		Severity:    "high",
+		Remediation: "Only add capabilities from the default set (e.g. NET_BIND_SERVICE, CHOWN); remove others such as SYS_ADMIN or NET_ADMIN.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				if c.securityContext == nil || c.securityContext.Capabilities == nil {
+					continue
+				}
+				for _, capability := range c.securityContext.Capabilities.Add {
+					if !baselineCapabilities[capability] {
+						details = append(details, fmt.Sprintf("%s adds %s", c, capability))
+					}
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-hostpath-volumes",
+		Profile:     PSSBaseline,
+		Title:       "HostPath volumes",
+		Severity:    "high",
+		Remediation: "Replace hostPath volumes with emptyDir, a PersistentVolumeClaim, or a CSI volume.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			for _, v := range pod.Spec.Volumes {
+				if v.HostPath != nil {
+					details = append(details, fmt.Sprintf("volume %q mounts host path %s", v.Name, v.HostPath.Path))
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-host-ports",
+		Profile:     PSSBaseline,
+		Title:       "Host ports",
+		Severity:    "medium",
+		Remediation: "Remove hostPort from container ports and expose the pod through a Service instead.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				for _, port := range c.ports {
+					if port.HostPort != 0 {
+						details = append(details, fmt.Sprintf("%s uses host port %d", c, port.HostPort))
+					}
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-apparmor",
+		Profile:     PSSBaseline,
+		Title:       "AppArmor profile overridden",
+		Severity:    "medium",
+		Remediation: "Use the RuntimeDefault or a Localhost AppArmor profile instead of Unconfined.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			for key, value := range pod.Annotations {
+				if strings.HasPrefix(key, appArmorAnnotationPrefix) && value != "runtime/default" && !strings.HasPrefix(value, "localhost/") {
+					details = append(details, fmt.Sprintf("container %q uses AppArmor profile %s", strings.TrimPrefix(key, appArmorAnnotationPrefix), value))
+				}
+			}
+			if sc := pod.Spec.SecurityContext; sc != nil && sc.AppArmorProfile != nil && sc.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+				details = append(details, "pod AppArmor profile is Unconfined")
+			}
+			for _, c := range podContainers(&pod.Spec) {
+				if sc := c.securityContext; sc != nil && sc.AppArmorProfile != nil && sc.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+					details = append(details, c.String()+" AppArmor profile is Unconfined")
+				}
+			}
+			sort.Strings(details)
+			return details
+		},
+	},
+	{
+		ID:          "baseline-selinux",
+		Profile:     PSSBaseline,
+		Title:       "Custom SELinux options",
+		Severity:    "medium",
+		Remediation: "Remove seLinuxOptions user and role, and only use the container_t, container_init_t, container_kvm_t or container_engine_t types.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			checkOptions := func(where string, o *corev1.SELinuxOptions) {
+				if o == nil {
+					return
+				}
+				if !allowedSELinuxTypes[o.Type] {
+					details = append(details, fmt.Sprintf("%s uses SELinux type %s", where, o.Type))
+				}
+				if o.User != "" || o.Role != "" {
+					details = append(details, where+" sets a SELinux user or role")
+				}
+			}
+			if pod.Spec.SecurityContext != nil {
+				checkOptions("pod", pod.Spec.SecurityContext.SELinuxOptions)
+			}
+			for _, c := range podContainers(&pod.Spec) {
+				if c.securityContext != nil {
+					checkOptions(c.String(), c.securityContext.SELinuxOptions)
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-proc-mount",
+		Profile:     PSSBaseline,
+		Title:       "Unmasked /proc mount",
+		Severity:    "medium",
+		Remediation: "Remove securityContext.procMount or set it to Default.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				if sc := c.securityContext; sc != nil && sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+					details = append(details, fmt.Sprintf("%s uses procMount %s", c, *sc.ProcMount))
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-seccomp",
+		Profile:     PSSBaseline,
+		Title:       "Seccomp disabled",
+		Severity:    "medium",
+		Remediation: "Set seccompProfile.type to RuntimeDefault or Localhost instead of Unconfined.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			if sc := pod.Spec.SecurityContext; sc != nil && sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+				details = append(details, "pod seccomp profile is Unconfined")
+			}
+			for _, c := range podContainers(&pod.Spec) {
+				if sc := c.securityContext; sc != nil && sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+					details = append(details, c.String()+" seccomp profile is Unconfined")
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-sysctls",
+		Profile:     PSSBaseline,
+		Title:       "Unsafe sysctls",
+		Severity:    "medium",
+		Remediation: "Only set sysctls from the safe set, such as net.ipv4.ip_local_port_range or net.ipv4.tcp_syncookies.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			if sc := pod.Spec.SecurityContext; sc != nil {
+				for _, sysctl := range sc.Sysctls {
+					if !safeSysctls[sysctl.Name] {
+						details = append(details, "pod sets unsafe sysctl "+sysctl.Name)
+					}
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "baseline-host-process",
+		Profile:     PSSBaseline,
+		Title:       "Windows HostProcess containers",
+		Severity:    "high",
+		Remediation: "Remove windowsOptions.hostProcess or set it to false.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			if sc := pod.Spec.SecurityContext; sc != nil && sc.WindowsOptions != nil && sc.WindowsOptions.HostProcess != nil && *sc.WindowsOptions.HostProcess {
+				details = append(details, "pod runs as a Windows HostProcess")
+			}
+			for _, c := range podContainers(&pod.Spec) {
+				if sc := c.securityContext; sc != nil && sc.WindowsOptions != nil && sc.WindowsOptions.HostProcess != nil && *sc.WindowsOptions.HostProcess {
+					details = append(details, c.String()+" runs as a Windows HostProcess")
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "restricted-volume-types",
+		Profile:     PSSRestricted,
+		Title:       "Volume types",
+		Severity:    "medium",
+		Remediation: "Only use configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected or secret volumes.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			for _, v := range pod.Spec.Volumes {
+				vs := v.VolumeSource
+				if vs.ConfigMap != nil || vs.CSI != nil || vs.DownwardAPI != nil || vs.EmptyDir != nil ||
+					vs.Ephemeral != nil || vs.PersistentVolumeClaim != nil || vs.Projected != nil || vs.Secret != nil {
+					continue
+				}
+				details = append(details, fmt.Sprintf("volume %q has a restricted type", v.Name))
+			}
+			return details
+		},
+	},
+	{
+		ID:          "restricted-privilege-escalation",
+		Profile:     PSSRestricted,
+		Title:       "Privilege escalation allowed",
+		Severity:    "medium",
+		Remediation: "Set securityContext.allowPrivilegeEscalation to false in every container.",
+		check: func(pod *corev1.Pod) []string {
+			if isWindowsPod(pod) {
+				return nil
+			}
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				if sc := c.securityContext; sc == nil || sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+					details = append(details, c.String()+" does not set allowPrivilegeEscalation to false")
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "restricted-run-as-non-root",
+		Profile:     PSSRestricted,
+		Title:       "Not required to run as non-root",
+		Severity:    "medium",
+		Remediation: "Set securityContext.runAsNonRoot to true on the pod or every container.",
+		check: func(pod *corev1.Pod) []string {
+			podNonRoot := pod.Spec.SecurityContext != nil && pod.Spec.SecurityContext.RunAsNonRoot != nil && *pod.Spec.SecurityContext.RunAsNonRoot
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				nonRoot := podNonRoot
+				if sc := c.securityContext; sc != nil && sc.RunAsNonRoot != nil {
+					nonRoot = *sc.RunAsNonRoot
+				}
+				if !nonRoot {
+					details = append(details, c.String()+" may run as root")
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "restricted-run-as-user",
+		Profile:     PSSRestricted,
+		Title:       "Runs as root user",
+		Severity:    "high",
+		Remediation: "Set securityContext.runAsUser to a non-zero UID, or remove it and build the image with a non-root USER.",
+		check: func(pod *corev1.Pod) []string {
+			var details []string
+			if sc := pod.Spec.SecurityContext; sc != nil && sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+				details = append(details, "pod runs as UID 0")
+			}
+			for _, c := range podContainers(&pod.Spec) {
+				if sc := c.securityContext; sc != nil && sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+					details = append(details, c.String()+" runs as UID 0")
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "restricted-seccomp",
+		Profile:     PSSRestricted,
+		Title:       "Seccomp profile not set",
+		Severity:    "medium",
+		Remediation: "Set securityContext.seccompProfile.type to RuntimeDefault on the pod, or on every container.",
+		check: func(pod *corev1.Pod) []string {
+			if isWindowsPod(pod) {
+				return nil
+			}
+			allowed := func(p *corev1.SeccompProfile) bool {
+				return p.Type == corev1.SeccompProfileTypeRuntimeDefault || p.Type == corev1.SeccompProfileTypeLocalhost
+			}
+			var podProfile *corev1.SeccompProfile
+			if pod.Spec.SecurityContext != nil {
+				podProfile = pod.Spec.SecurityContext.SeccompProfile
+			}
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				profile := podProfile
+				if sc := c.securityContext; sc != nil && sc.SeccompProfile != nil {
+					profile = sc.SeccompProfile
+				}
+				if profile == nil || !allowed(profile) {
+					details = append(details, c.String()+" has no RuntimeDefault or Localhost seccomp profile")
+				}
+			}
+			return details
+		},
+	},
+	{
+		ID:          "restricted-capabilities",
+		Profile:     PSSRestricted,
+		Title:       "Capabilities not dropped",
+		Severity:    "medium",
+		Remediation: "Drop ALL capabilities in every container and add back only NET_BIND_SERVICE if needed.",
+		check: func(pod *corev1.Pod) []string {
+			if isWindowsPod(pod) {
+				return nil
+			}
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				var caps *corev1.Capabilities
+				if c.securityContext != nil {
+					caps = c.securityContext.Capabilities
+				}
+				if caps == nil || !containsCapability(caps.Drop, "ALL") {
+					details = append(details, c.String()+" does not drop ALL capabilities")
+				}
+				if caps == nil {
+					continue
+				}
+				for _, capability := range caps.Add {
+					if capability != "NET_BIND_SERVICE" {
+						details = append(details, fmt.Sprintf("%s adds %s", c, capability))
+					}
+				}
+			}
+			return details
+		},
+	},
+	{
+		// Not part of the upstream restricted profile, but a writable root
+		// filesystem lets a compromised container modify its own binaries
+		ID:          "restricted-read-only-root-filesystem",
+		Profile:     PSSRestricted,
+		Title:       "Writable root filesystem",
+		Severity:    "medium",
+		Remediation: "Set securityContext.readOnlyRootFilesystem to true in every container and mount an emptyDir for paths the app writes to.",
+		check: func(pod *corev1.Pod) []string {
+			if isWindowsPod(pod) {
+				return nil
+			}
+			var details []string
+			for _, c := range podContainers(&pod.Spec) {
+				if sc := c.securityContext; sc == nil || sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+					details = append(details, c.String()+" has a writable root filesystem")
+				}
+			}
+			return details
+		},
+	},
+}
+
+func containsCapability(caps []corev1.Capability, capability corev1.Capability) bool {
+	for _, c := range caps {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
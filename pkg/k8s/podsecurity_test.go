@@ -0,0 +1,219 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pssFixturePod returns a pod that meets the restricted profile
+func pssFixturePod() *corev1.Pod {
+	secure := func() *corev1.SecurityContext {
+		return &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr(false),
+			ReadOnlyRootFilesystem:   ptr(true),
+			RunAsNonRoot:             ptr(true),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "fixture", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   ptr(true),
+				RunAsUser:      ptr(int64(1000)),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+			InitContainers: []corev1.Container{{Name: "init", Image: "busybox", SecurityContext: secure()}},
+			Containers: []corev1.Container{{
+				Name:            "app",
+				Image:           "nginx",
+				SecurityContext: secure(),
+				Ports:           []corev1.ContainerPort{{ContainerPort: 8080}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         "config",
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}}},
+			}},
+		},
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// pssFixtures turn pssFixturePod into a pod that violates each rule and
+// one that comes close but complies
+var pssFixtures = map[string]struct {
+	fail, pass func(pod *corev1.Pod)
+}{
+	"baseline-host-namespaces": {
+		fail: func(pod *corev1.Pod) { pod.Spec.HostIPC = true },
+		pass: func(pod *corev1.Pod) { pod.Spec.HostNetwork = false },
+	},
+	"baseline-privileged": {
+		fail: func(pod *corev1.Pod) { pod.Spec.InitContainers[0].SecurityContext.Privileged = ptr(true) },
+		pass: func(pod *corev1.Pod) { pod.Spec.Containers[0].SecurityContext.Privileged = ptr(false) },
+	},
+	"baseline-capabilities": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+				Name:            "debug",
+				SecurityContext: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}}},
+			}}}
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].SecurityContext.Capabilities.Add = []corev1.Capability{"NET_BIND_SERVICE", "CHOWN"}
+		},
+	},
+	"baseline-hostpath-volumes": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{Name: "docker", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"}}})
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+		},
+	},
+	"baseline-host-ports": {
+		fail: func(pod *corev1.Pod) { pod.Spec.Containers[0].Ports[0].HostPort = 8080 },
+		pass: func(pod *corev1.Pod) { pod.Spec.Containers[0].Ports[0].HostPort = 0 },
+	},
+	"baseline-apparmor": {
+		fail: func(pod *corev1.Pod) {
+			pod.Annotations = map[string]string{appArmorAnnotationPrefix + "app": "unconfined"}
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Annotations = map[string]string{appArmorAnnotationPrefix + "app": "runtime/default"}
+			pod.Spec.Containers[0].SecurityContext.AppArmorProfile = &corev1.AppArmorProfile{Type: corev1.AppArmorProfileTypeRuntimeDefault}
+		},
+	},
+	"baseline-selinux": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].SecurityContext.SELinuxOptions = &corev1.SELinuxOptions{Type: "spc_t"}
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.SELinuxOptions = &corev1.SELinuxOptions{Type: "container_t", Level: "s0:c123,c456"}
+		},
+	},
+	"baseline-proc-mount": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].SecurityContext.ProcMount = ptr(corev1.UnmaskedProcMount)
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].SecurityContext.ProcMount = ptr(corev1.DefaultProcMount)
+		},
+	},
+	"baseline-seccomp": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.InitContainers[0].SecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].SecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: ptr("profiles/app.json")}
+		},
+	},
+	"baseline-sysctls": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.Sysctls = []corev1.Sysctl{{Name: "kernel.msgmax", Value: "65536"}}
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.Sysctls = []corev1.Sysctl{{Name: "net.ipv4.tcp_syncookies", Value: "1"}}
+		},
+	},
+	"baseline-host-process": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.WindowsOptions = &corev1.WindowsSecurityContextOptions{HostProcess: ptr(true)}
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.WindowsOptions = &corev1.WindowsSecurityContextOptions{HostProcess: ptr(false)}
+		},
+	},
+	"restricted-volume-types": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{Name: "share", VolumeSource: corev1.VolumeSource{NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/"}}})
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{Name: "token", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{}}})
+		},
+	},
+	"restricted-privilege-escalation": {
+		fail: func(pod *corev1.Pod) { pod.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = nil },
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.OS = &corev1.PodOS{Name: corev1.Windows}
+			pod.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = nil
+		},
+	},
+	"restricted-run-as-non-root": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.RunAsNonRoot = ptr(false)
+			pod.Spec.Containers[0].SecurityContext.RunAsNonRoot = nil
+		},
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.RunAsNonRoot = nil
+		},
+	},
+	"restricted-run-as-user": {
+		fail: func(pod *corev1.Pod) { pod.Spec.Containers[0].SecurityContext.RunAsUser = ptr(int64(0)) },
+		pass: func(pod *corev1.Pod) { pod.Spec.Containers[0].SecurityContext.RunAsUser = ptr(int64(65534)) },
+	},
+	"restricted-seccomp": {
+		fail: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.SeccompProfile = nil
+			pod.Spec.Containers[0].SecurityContext.SeccompProfile = nil
+		},
+		pass: func(pod *corev1.Pod) {
+			// Containers set their own profile, so the pod needs none
+			pod.Spec.SecurityContext.SeccompProfile = nil
+		},
+	},
+	"restricted-capabilities": {
+		fail: func(pod *corev1.Pod) { pod.Spec.InitContainers[0].SecurityContext.Capabilities = nil },
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].SecurityContext.Capabilities.Add = []corev1.Capability{"NET_BIND_SERVICE"}
+		},
+	},
+	"restricted-read-only-root-filesystem": {
+		fail: func(pod *corev1.Pod) { pod.Spec.InitContainers[0].SecurityContext.ReadOnlyRootFilesystem = ptr(false) },
+		pass: func(pod *corev1.Pod) {
+			pod.Spec.OS = &corev1.PodOS{Name: corev1.Windows}
+			pod.Spec.Containers[0].SecurityContext.ReadOnlyRootFilesystem = nil
+		},
+	},
+}
+
+func TestPSSRules(t *testing.T) {
+	seen := map[string]bool{}
+	for _, rule := range pssRules {
+		t.Run(rule.ID, func(t *testing.T) {
+			if seen[rule.ID] {
+				t.Fatal("duplicate rule ID")
+			}
+			seen[rule.ID] = true
+
+			if details := rule.check(pssFixturePod()); len(details) > 0 {
+				t.Errorf("base fixture violates the rule: %v", details)
+			}
+			fixture, ok := pssFixtures[rule.ID]
+			if !ok {
+				t.Fatal("no fixtures")
+			}
+			failing := pssFixturePod()
+			fixture.fail(failing)
+			if len(rule.check(failing)) == 0 {
+				t.Error("failing fixture was not flagged")
+			}
+			passing := pssFixturePod()
+			fixture.pass(passing)
+			if details := rule.check(passing); len(details) > 0 {
+				t.Errorf("passing fixture was flagged: %v", details)
+			}
+		})
+	}
+	for id := range pssFixtures {
+		if !seen[id] {
+			t.Errorf("fixtures for unknown rule %s", id)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// Policy engines
+const (
+	PolicyEngineGatekeeper = "gatekeeper"
+	PolicyEngineKyverno    = "kyverno"
+)
+
+// gatekeeperConstraintsGroupVersion serves one resource per constraint
+// template, each listing that template's constraints
+const gatekeeperConstraintsGroupVersion = "constraints.gatekeeper.sh/v1beta1"
+
+var (
+	policyReportGVR         = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "policyreports"}
+	clusterPolicyReportGVR  = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "clusterpolicyreports"}
+	kyvernoPolicyGVR        = schema.GroupVersionResource{Group: "kyverno.io", Version: "v1", Resource: "policies"}
+	kyvernoClusterPolicyGVR = schema.GroupVersionResource{Group: "kyverno.io", Version: "v1", Resource: "clusterpolicies"}
+)
+
+// PolicyViolation is a resource violating a Gatekeeper constraint or
+// failing a Kyverno policy rule
+type PolicyViolation struct {
+	Engine    string `json:"engine"`
+	Policy    string `json:"policy"`
+	Rule      string `json:"rule,omitempty"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace,omitempty"`
+	// Resource is the violating object as Kind/name
+	Resource string `json:"resource"`
+	Message  string `json:"message"`
+	// EnforcementAction is deny, dryrun or warn for Gatekeeper and enforce
+	// or audit for Kyverno
+	EnforcementAction string `json:"enforcementAction"`
+	Severity          string `json:"severity,omitempty"`
+}
+
+// PolicySummary counts a policy's violations. Gatekeeper reports only the
+// first violations of a constraint (20 by default), so Violations can
+// exceed the violations listed.
+type PolicySummary struct {
+	Engine            string   `json:"engine"`
+	Policy            string   `json:"policy"`
+	EnforcementAction string   `json:"enforcementAction"`
+	Violations        int      `json:"violations"`
+	Clusters          []string `json:"clusters"`
+}
+
+// ClusterPolicyViolations holds a cluster's violations and the engines
+// found there. Errors maps engines to what could not be read, typically for
+// lack of permission; the rest of their results is kept.
+type ClusterPolicyViolations struct {
+	Cluster    string            `json:"cluster"`
+	Engines    []string          `json:"engines"`
+	Violations []PolicyViolation `json:"violations"`
+	Policies   []PolicySummary   `json:"policies"`
+	Errors     map[string]string `json:"errors,omitempty"`
+}
+
+// addError records that part of an engine's results could not be read
+func (v *ClusterPolicyViolations) addError(engine, what string, err error) {
+	if v.Errors == nil {
+		v.Errors = map[string]string{}
+	}
+	msg := what + ": " + err.Error()
+	if existing := v.Errors[engine]; existing != "" {
+		msg = existing + "; " + msg
+	}
+	v.Errors[engine] = msg
+}
+
+// GetPolicyViolations reads Gatekeeper constraint status and Kyverno policy
+// reports. Engines that are not installed are skipped and unreadable
+// resources recorded in Errors; namespace, when set, keeps only violations
+// in that namespace.
+func (m *MultiClusterClient) GetPolicyViolations(ctx context.Context, contextName, namespace string) (*ClusterPolicyViolations, error) {
+	ctx, span := startSpan(ctx, "GetPolicyViolations", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := m.GetDynamicClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return policyViolations(ctx, client.Discovery(), dc, contextName, namespace), nil
+}
+
+// policyViolations is GetPolicyViolations with the clients already made
+func policyViolations(ctx context.Context, disc discovery.DiscoveryInterface, dc dynamic.Interface, contextName, namespace string) *ClusterPolicyViolations {
+	result := &ClusterPolicyViolations{
+		Cluster:    contextName,
+		Engines:    []string{},
+		Violations: []PolicyViolation{},
+		Policies:   []PolicySummary{},
+	}
+
+	if gatekeeperViolations(ctx, disc, dc, result, namespace) {
+		result.Engines = append(result.Engines, PolicyEngineGatekeeper)
+	}
+	if kyvernoViolations(ctx, dc, result, namespace) {
+		result.Engines = append(result.Engines, PolicyEngineKyverno)
+	}
+	return result
+}
+
+// gatekeeperViolations discovers the constraint kinds and reads each
+// constraint's audit results from status.violations. It reports whether
+// Gatekeeper is installed.
+func gatekeeperViolations(ctx context.Context, disc discovery.DiscoveryInterface, dc dynamic.Interface, result *ClusterPolicyViolations, namespace string) bool {
+	resources, err := disc.ServerResourcesForGroupVersion(gatekeeperConstraintsGroupVersion)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			result.addError(PolicyEngineGatekeeper, "discovery", err)
+		}
+		return false
+	}
+
+	gv, _ := schema.ParseGroupVersion(gatekeeperConstraintsGroupVersion)
+	for _, r := range resources.APIResources {
+		// Skip subresources such as <kind>/status
+		if strings.Contains(r.Name, "/") {
+			continue
+		}
+		constraints, err := dc.Resource(gv.WithResource(r.Name)).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			result.addError(PolicyEngineGatekeeper, r.Name, err)
+			continue
+		}
+
+		for _, constraint := range constraints.Items {
+			policy := constraint.GetKind() + "/" + constraint.GetName()
+			action, _, _ := unstructured.NestedString(constraint.Object, "spec", "enforcementAction")
+			if action == "" {
+				action = "deny"
+			}
+
+			total, _, _ := unstructured.NestedInt64(constraint.Object, "status", "totalViolations")
+			violations, _, _ := unstructured.NestedSlice(constraint.Object, "status", "violations")
+			listed := 0
+			for _, v := range violations {
+				violation, ok := v.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				ns, _ := violation["namespace"].(string)
+				if namespace != "" && ns != namespace {
+					continue
+				}
+				kind, _ := violation["kind"].(string)
+				name, _ := violation["name"].(string)
+				message, _ := violation["message"].(string)
+				// Each audited violation records the action that applied to it
+				violationAction, _ := violation["enforcementAction"].(string)
+				if violationAction == "" {
+					violationAction = action
+				}
+				result.Violations = append(result.Violations, PolicyViolation{
+					Engine:            PolicyEngineGatekeeper,
+					Policy:            policy,
+					Cluster:           result.Cluster,
+					Namespace:         ns,
+					Resource:          kind + "/" + name,
+					Message:           message,
+					EnforcementAction: violationAction,
+				})
+				listed++
+			}
+
+			count := listed
+			// The total is only known for the whole cluster
+			if namespace == "" && int(total) > count {
+				count = int(total)
+			}
+			if count > 0 {
+				result.Policies = append(result.Policies, PolicySummary{
+					Engine:            PolicyEngineGatekeeper,
+					Policy:            policy,
+					EnforcementAction: action,
+					Violations:        count,
+					Clusters:          []string{result.Cluster},
+				})
+			}
+		}
+	}
+	return true
+}
+
+// kyvernoViolations reads the failed results of PolicyReports and
+// ClusterPolicyReports, with enforcement actions from the Kyverno policies.
+// It reports whether policy reports are served.
+func kyvernoViolations(ctx context.Context, dc dynamic.Interface, result *ClusterPolicyViolations, namespace string) bool {
+	var items []unstructured.Unstructured
+	found := false
+	reports, err := dc.Resource(policyReportGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	switch {
+	case err == nil:
+		found = true
+		items = reports.Items
+	case !apierrors.IsNotFound(err):
+		found = true
+		result.addError(PolicyEngineKyverno, policyReportGVR.Resource, err)
+	}
+	if namespace == "" {
+		clusterReports, err := dc.Resource(clusterPolicyReportGVR).List(ctx, metav1.ListOptions{})
+		switch {
+		case err == nil:
+			found = true
+			items = append(items, clusterReports.Items...)
+		case !apierrors.IsNotFound(err):
+			found = true
+			result.addError(PolicyEngineKyverno, clusterPolicyReportGVR.Resource, err)
+		}
+	}
+	if !found {
+		return false
+	}
+
+	actions := kyvernoActions(ctx, dc)
+	counts := map[string]int{}
+	var order []string
+	for _, report := range items {
+		results, _, _ := unstructured.NestedSlice(report.Object, "results")
+		// Reports of Kyverno 1.11+ cover a single resource named in scope
+		scope, _, _ := unstructured.NestedMap(report.Object, "scope")
+
+		for _, r := range results {
+			entry, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			outcome, _ := entry["result"].(string)
+			if outcome != "fail" && outcome != "warn" && outcome != "error" {
+				continue
+			}
+			policy, _ := entry["policy"].(string)
+			rule, _ := entry["rule"].(string)
+			message, _ := entry["message"].(string)
+			severity, _ := entry["severity"].(string)
+
+			subjects, _ := entry["resources"].([]interface{})
+			if len(subjects) == 0 && scope != nil {
+				subjects = []interface{}{scope}
+			}
+			if len(subjects) == 0 {
+				subjects = []interface{}{map[string]interface{}{"namespace": report.GetNamespace()}}
+			}
+
+			action := actions[policyKey(report.GetNamespace(), policy)]
+			if action == "" {
+				action = actions[policyKey("", policy)]
+			}
+			if action == "" {
+				action = "audit"
+			}
+
+			for _, s := range subjects {
+				subject, _ := s.(map[string]interface{})
+				kind, _ := subject["kind"].(string)
+				name, _ := subject["name"].(string)
+				ns, _ := subject["namespace"].(string)
+				if namespace != "" && ns != namespace {
+					continue
+				}
+				resource := kind + "/" + name
+				if kind == "" && name == "" {
+					resource = ""
+				}
+				result.Violations = append(result.Violations, PolicyViolation{
+					Engine:            PolicyEngineKyverno,
+					Policy:            policy,
+					Rule:              rule,
+					Cluster:           result.Cluster,
+					Namespace:         ns,
+					Resource:          resource,
+					Message:           message,
+					EnforcementAction: action,
+					Severity:          severity,
+				})
+				key := policy + "\x00" + action
+				if counts[key] == 0 {
+					order = append(order, key)
+				}
+				counts[key]++
+			}
+		}
+	}
+
+	for _, key := range order {
+		policy, action, _ := strings.Cut(key, "\x00")
+		result.Policies = append(result.Policies, PolicySummary{
+			Engine:            PolicyEngineKyverno,
+			Policy:            policy,
+			EnforcementAction: action,
+			Violations:        counts[key],
+			Clusters:          []string{result.Cluster},
+		})
+	}
+	return true
+}
+
+// kyvernoActions maps Kyverno policies (by namespace and name) to their
+// validationFailureAction, lower-cased. Unreadable policies are skipped.
+func kyvernoActions(ctx context.Context, dc dynamic.Interface) map[string]string {
+	actions := map[string]string{}
+	for _, gvr := range []schema.GroupVersionResource{kyvernoClusterPolicyGVR, kyvernoPolicyGVR} {
+		policies, err := dc.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			continue
+		}
+		for _, policy := range policies.Items {
+			action, _, _ := unstructured.NestedString(policy.Object, "spec", "validationFailureAction")
+			if action != "" {
+				actions[policyKey(policy.GetNamespace(), policy.GetName())] = strings.ToLower(action)
+			}
+		}
+	}
+	return actions
+}
+
+func policyKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// MergePolicySummaries combines the per-cluster summaries of the same
+// policy and enforcement action, most violations first
+func MergePolicySummaries(clusters []ClusterPolicyViolations) []PolicySummary {
+	merged := map[string]*PolicySummary{}
+	for _, cluster := range clusters {
+		for _, summary := range cluster.Policies {
+			key := summary.Engine + "\x00" + summary.Policy + "\x00" + summary.EnforcementAction
+			if existing, ok := merged[key]; ok {
+				existing.Violations += summary.Violations
+				existing.Clusters = append(existing.Clusters, summary.Clusters...)
+				continue
+			}
+			copied := summary
+			copied.Clusters = append([]string(nil), summary.Clusters...)
+			merged[key] = &copied
+		}
+	}
+
+	result := make([]PolicySummary, 0, len(merged))
+	for _, summary := range merged {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Violations != result[j].Violations {
+			return result[i].Violations > result[j].Violations
+		}
+		return result[i].Policy < result[j].Policy
+	})
+	return result
+}
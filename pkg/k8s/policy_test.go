@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var requiredLabelsGVR = schema.GroupVersionResource{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Resource: "k8srequiredlabels"}
+
+// gatekeeperDiscovery serves the constraint kinds of resources
+func gatekeeperDiscovery(resources ...string) *fakediscovery.FakeDiscovery {
+	list := &metav1.APIResourceList{GroupVersion: gatekeeperConstraintsGroupVersion}
+	for _, r := range resources {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: r}, metav1.APIResource{Name: r + "/status"})
+	}
+	return &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{list}}}
+}
+
+var policyListKinds = map[schema.GroupVersionResource]string{
+	requiredLabelsGVR: "K8sRequiredLabelsList",
+	{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Resource: "k8sallowedrepos"}: "K8sAllowedReposList",
+	policyReportGVR:         "PolicyReportList",
+	clusterPolicyReportGVR:  "ClusterPolicyReportList",
+	kyvernoPolicyGVR:        "PolicyList",
+	kyvernoClusterPolicyGVR: "ClusterPolicyList",
+}
+
+// policyDynamicClient serves a K8sRequiredLabels constraint and a Kyverno
+// policy report
+func policyDynamicClient(t *testing.T) *dynamicfake.FakeDynamicClient {
+	t.Helper()
+	dc := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), policyListKinds)
+	// The tracker cannot guess the resource of constraint kinds
+	if err := dc.Tracker().Create(requiredLabelsGVR, requiredLabelsConstraint(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := dc.Tracker().Create(policyReportGVR, policyReport(), "default"); err != nil {
+		t.Fatal(err)
+	}
+	return dc
+}
+
+func forbid(dc *dynamicfake.FakeDynamicClient, resource string) {
+	dc.PrependReactor("list", resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "", nil)
+	})
+}
+
+func requiredLabelsConstraint() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gatekeeperConstraintsGroupVersion,
+		"kind":       "K8sRequiredLabels",
+		"metadata":   map[string]interface{}{"name": "owner"},
+		"spec":       map[string]interface{}{"enforcementAction": "dryrun"},
+		"status": map[string]interface{}{
+			"totalViolations": int64(3),
+			"violations": []interface{}{
+				map[string]interface{}{"kind": "Namespace", "name": "team-a", "message": "missing owner"},
+			},
+		},
+	}}
+}
+
+func policyReport() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "wgpolicyk8s.io/v1alpha2",
+		"kind":       "PolicyReport",
+		"metadata":   map[string]interface{}{"name": "report", "namespace": "default"},
+		"scope":      map[string]interface{}{"kind": "Pod", "name": "web", "namespace": "default"},
+		"results": []interface{}{
+			map[string]interface{}{"policy": "require-limits", "rule": "limits", "result": "fail", "message": "no limits"},
+			map[string]interface{}{"policy": "require-limits", "rule": "requests", "result": "pass"},
+		},
+	}}
+}
+
+func TestPolicyViolations(t *testing.T) {
+	dc := policyDynamicClient(t)
+	result := policyViolations(context.Background(), gatekeeperDiscovery("k8srequiredlabels"), dc, "kind", "")
+
+	if strings.Join(result.Engines, ",") != "gatekeeper,kyverno" || len(result.Errors) != 0 {
+		t.Fatalf("engines %v, errors %v", result.Engines, result.Errors)
+	}
+	if len(result.Violations) != 2 {
+		t.Fatalf("violations = %+v", result.Violations)
+	}
+	gk, kv := result.Violations[0], result.Violations[1]
+	if gk.Policy != "K8sRequiredLabels/owner" || gk.Resource != "Namespace/team-a" || gk.EnforcementAction != "dryrun" {
+		t.Errorf("gatekeeper violation = %+v", gk)
+	}
+	if kv.Policy != "require-limits" || kv.Rule != "limits" || kv.Resource != "Pod/web" || kv.EnforcementAction != "audit" {
+		t.Errorf("kyverno violation = %+v", kv)
+	}
+	// Gatekeeper lists only some violations; the summary keeps the total
+	if len(result.Policies) != 2 || result.Policies[0].Violations != 3 {
+		t.Errorf("policies = %+v", result.Policies)
+	}
+}
+
+func TestPolicyViolationsNoEngines(t *testing.T) {
+	disc := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	dc := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), policyListKinds)
+	for _, resource := range []string{"policyreports", "clusterpolicyreports"} {
+		dc.PrependReactor("list", resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: resource}, "")
+		})
+	}
+
+	result := policyViolations(context.Background(), disc, dc, "kind", "")
+	if len(result.Engines) != 0 || len(result.Errors) != 0 || len(result.Violations) != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestPolicyViolationsKeepsReadableResults(t *testing.T) {
+	dc := policyDynamicClient(t)
+	forbid(dc, "k8sallowedrepos")
+	forbid(dc, "clusterpolicyreports")
+
+	result := policyViolations(context.Background(), gatekeeperDiscovery("k8sallowedrepos", "k8srequiredlabels"), dc, "kind", "")
+
+	if strings.Join(result.Engines, ",") != "gatekeeper,kyverno" {
+		t.Fatalf("engines = %v", result.Engines)
+	}
+	if len(result.Violations) != 2 {
+		t.Fatalf("readable violations dropped: %+v", result.Violations)
+	}
+	if !strings.HasPrefix(result.Errors[PolicyEngineGatekeeper], "k8sallowedrepos: ") {
+		t.Errorf("gatekeeper error = %q", result.Errors[PolicyEngineGatekeeper])
+	}
+	if !strings.HasPrefix(result.Errors[PolicyEngineKyverno], "clusterpolicyreports: ") {
+		t.Errorf("kyverno error = %q", result.Errors[PolicyEngineKyverno])
+	}
+}
@@ -15,7 +15,10 @@ import (
 
 // ListServiceAccounts returns all service accounts in a cluster
 func (m *MultiClusterClient) ListServiceAccounts(ctx context.Context, contextName, namespace string) ([]models.K8sServiceAccount, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "ListServiceAccounts", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +53,7 @@ func (m *MultiClusterClient) ListServiceAccounts(ctx context.Context, contextNam
 
 // getServiceAccountRoles returns the roles bound to a service account
 func (m *MultiClusterClient) getServiceAccountRoles(ctx context.Context, contextName, namespace, saName string) ([]string, error) {
-	client, err := m.GetClient(contextName)
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +89,10 @@ func (m *MultiClusterClient) getServiceAccountRoles(ctx context.Context, context
 
 // ListRoles returns all Roles in a namespace
 func (m *MultiClusterClient) ListRoles(ctx context.Context, contextName, namespace string) ([]models.K8sRole, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "ListRoles", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +118,10 @@ func (m *MultiClusterClient) ListRoles(ctx context.Context, contextName, namespa
 
 // ListClusterRoles returns all ClusterRoles
 func (m *MultiClusterClient) ListClusterRoles(ctx context.Context, contextName string, includeSystem bool) ([]models.K8sRole, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "ListClusterRoles", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +167,10 @@ func isSystemRole(name string) bool {
 
 // ListRoleBindings returns all RoleBindings in a namespace
 func (m *MultiClusterClient) ListRoleBindings(ctx context.Context, contextName, namespace string) ([]models.K8sRoleBinding, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "ListRoleBindings", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +211,10 @@ func (m *MultiClusterClient) ListRoleBindings(ctx context.Context, contextName,
 
 // ListClusterRoleBindings returns all ClusterRoleBindings
 func (m *MultiClusterClient) ListClusterRoleBindings(ctx context.Context, contextName string, includeSystem bool) ([]models.K8sRoleBinding, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "ListClusterRoleBindings", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -244,7 +259,10 @@ func (m *MultiClusterClient) ListClusterRoleBindings(ctx context.Context, contex
 
 // CheckClusterAdminAccess checks if the current user has cluster-admin access
 func (m *MultiClusterClient) CheckClusterAdminAccess(ctx context.Context, contextName string) (bool, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "CheckClusterAdminAccess", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return false, err
 	}
@@ -270,7 +288,16 @@ func (m *MultiClusterClient) CheckClusterAdminAccess(ctx context.Context, contex
 
 // CheckPermission checks if the current user can perform an action
 func (m *MultiClusterClient) CheckPermission(ctx context.Context, contextName, verb, resource, namespace string) (bool, error) {
-	client, err := m.GetClient(contextName)
+	return m.CheckGroupPermission(ctx, contextName, verb, "", resource, namespace)
+}
+
+// CheckGroupPermission checks if the current user can perform an action on
+// a resource of an API group, such as "apps" for deployments
+func (m *MultiClusterClient) CheckGroupPermission(ctx context.Context, contextName, verb, group, resource, namespace string) (bool, error) {
+	ctx, span := startSpan(ctx, "CheckPermission", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return false, err
 	}
@@ -279,6 +306,7 @@ func (m *MultiClusterClient) CheckPermission(ctx context.Context, contextName, v
 		Spec: authv1.SelfSubjectAccessReviewSpec{
 			ResourceAttributes: &authv1.ResourceAttributes{
 				Verb:      verb,
+				Group:     group,
 				Resource:  resource,
 				Namespace: namespace,
 			},
@@ -295,6 +323,9 @@ func (m *MultiClusterClient) CheckPermission(ctx context.Context, contextName, v
 
 // GetClusterPermissions returns the current user's permissions on a cluster
 func (m *MultiClusterClient) GetClusterPermissions(ctx context.Context, contextName string) (*models.ClusterPermissions, error) {
+	ctx, span := startSpan(ctx, "GetClusterPermissions", contextName)
+	defer span.End()
+
 	perms := &models.ClusterPermissions{
 		Cluster: contextName,
 	}
@@ -320,7 +351,10 @@ func (m *MultiClusterClient) GetClusterPermissions(ctx context.Context, contextN
 
 // CreateServiceAccount creates a new ServiceAccount
 func (m *MultiClusterClient) CreateServiceAccount(ctx context.Context, contextName, namespace, name string) (*models.K8sServiceAccount, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "CreateServiceAccount", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -347,7 +381,10 @@ func (m *MultiClusterClient) CreateServiceAccount(ctx context.Context, contextNa
 
 // CreateRoleBinding creates a new RoleBinding
 func (m *MultiClusterClient) CreateRoleBinding(ctx context.Context, req models.CreateRoleBindingRequest) error {
-	client, err := m.GetClient(req.Cluster)
+	ctx, span := startSpan(ctx, "CreateRoleBinding", req.Cluster)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, req.Cluster)
 	if err != nil {
 		return err
 	}
@@ -397,7 +434,10 @@ func (m *MultiClusterClient) CreateRoleBinding(ctx context.Context, req models.C
 
 // DeleteServiceAccount deletes a ServiceAccount
 func (m *MultiClusterClient) DeleteServiceAccount(ctx context.Context, contextName, namespace, name string) error {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "DeleteServiceAccount", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return err
 	}
@@ -407,7 +447,10 @@ func (m *MultiClusterClient) DeleteServiceAccount(ctx context.Context, contextNa
 
 // DeleteRoleBinding deletes a RoleBinding or ClusterRoleBinding
 func (m *MultiClusterClient) DeleteRoleBinding(ctx context.Context, contextName, namespace, name string, isCluster bool) error {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "DeleteRoleBinding", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return err
 	}
@@ -420,56 +463,69 @@ func (m *MultiClusterClient) DeleteRoleBinding(ctx context.Context, contextName,
 
 // GetAllClusterPermissions returns permissions for all clusters
 func (m *MultiClusterClient) GetAllClusterPermissions(ctx context.Context) ([]models.ClusterPermissions, error) {
-	clusters, err := m.ListClusters(ctx)
-	if err != nil {
-		return nil, err
-	}
+	ctx, span := startSpan(ctx, "GetAllClusterPermissions", "")
+	defer span.End()
 
-	var result []models.ClusterPermissions
-	for _, cluster := range clusters {
-		perms, err := m.GetClusterPermissions(ctx, cluster.Name)
+	result, err := FanOutAll(ctx, m, FanOutOptions{}, func(ctx context.Context, cluster string) ([]models.ClusterPermissions, error) {
+		perms, err := m.GetClusterPermissions(ctx, cluster)
 		if err != nil {
-			// Include error info in the result
-			result = append(result, models.ClusterPermissions{
-				Cluster: cluster.Name,
-			})
-			continue
+			return nil, err
 		}
-		result = append(result, *perms)
+		return []models.ClusterPermissions{*perms}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	// Clusters that could not be checked are listed without permissions
+	for _, ce := range result.Errors {
+		result.Items = append(result.Items, models.ClusterPermissions{Cluster: ce.Cluster})
+	}
+	return result.Items, nil
 }
 
 // CountServiceAccountsAllClusters returns total SA count across all clusters
 func (m *MultiClusterClient) CountServiceAccountsAllClusters(ctx context.Context) (int, []string, error) {
-	clusters, err := m.ListClusters(ctx)
-	if err != nil {
-		return 0, nil, err
-	}
+	ctx, span := startSpan(ctx, "CountServiceAccountsAllClusters", "")
+	defer span.End()
 
-	total := 0
-	var clusterNames []string
-	for _, cluster := range clusters {
-		sas, err := m.ListServiceAccounts(ctx, cluster.Name, "")
+	type clusterCount struct {
+		cluster string
+		count   int
+	}
+	result, err := FanOutAll(ctx, m, FanOutOptions{}, func(ctx context.Context, cluster string) ([]clusterCount, error) {
+		sas, err := m.ListServiceAccounts(ctx, cluster, "")
 		if err != nil {
-			continue
+			return nil, err
 		}
 		// Don't count system service accounts
+		count := 0
 		for _, sa := range sas {
 			if sa.Namespace != "kube-system" && sa.Namespace != "kube-public" && sa.Namespace != "kube-node-lease" {
-				total++
+				count++
 			}
 		}
-		clusterNames = append(clusterNames, cluster.Name)
+		return []clusterCount{{cluster, count}}, nil
+	})
+	if err != nil {
+		return 0, nil, err
 	}
 
+	total := 0
+	var clusterNames []string
+	for _, cc := range result.Items {
+		total += cc.count
+		clusterNames = append(clusterNames, cc.cluster)
+	}
 	return total, clusterNames, nil
 }
 
 // GetAllK8sUsers returns all unique users/subjects across role bindings
 func (m *MultiClusterClient) GetAllK8sUsers(ctx context.Context, contextName string) ([]models.K8sUser, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "GetAllK8sUsers", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -526,7 +582,10 @@ type CanIResult struct {
 
 // CheckCanI performs a SelfSubjectAccessReview and returns detailed result
 func (m *MultiClusterClient) CheckCanI(ctx context.Context, contextName string, req models.CanIRequest) (*CanIResult, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "CheckCanI", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -569,6 +628,9 @@ type PermissionsSummary struct {
 
 // GetPermissionsSummary returns a comprehensive permission summary for a cluster
 func (m *MultiClusterClient) GetPermissionsSummary(ctx context.Context, contextName string) (*PermissionsSummary, error) {
+	ctx, span := startSpan(ctx, "GetPermissionsSummary", contextName)
+	defer span.End()
+
 	summary := &PermissionsSummary{
 		Cluster: contextName,
 	}
@@ -612,7 +674,7 @@ func (m *MultiClusterClient) GetPermissionsSummary(ctx context.Context, contextN
 
 // listAllNamespaces returns all namespace names in a cluster
 func (m *MultiClusterClient) listAllNamespaces(ctx context.Context, contextName string) ([]string, error) {
-	client, err := m.GetClient(contextName)
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -631,7 +693,7 @@ func (m *MultiClusterClient) listAllNamespaces(ctx context.Context, contextName
 
 // getAccessibleNamespaces finds namespaces user can access when they can't list all
 func (m *MultiClusterClient) getAccessibleNamespaces(ctx context.Context, contextName string) ([]string, error) {
-	client, err := m.GetClient(contextName)
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -657,30 +719,33 @@ func (m *MultiClusterClient) getAccessibleNamespaces(ctx context.Context, contex
 
 // GetAllPermissionsSummaries returns permission summaries for all clusters
 func (m *MultiClusterClient) GetAllPermissionsSummaries(ctx context.Context) ([]PermissionsSummary, error) {
-	clusters, err := m.ListClusters(ctx)
-	if err != nil {
-		return nil, err
-	}
+	ctx, span := startSpan(ctx, "GetAllPermissionsSummaries", "")
+	defer span.End()
 
-	var summaries []PermissionsSummary
-	for _, cluster := range clusters {
-		summary, err := m.GetPermissionsSummary(ctx, cluster.Name)
+	result, err := FanOutAll(ctx, m, FanOutOptions{}, func(ctx context.Context, cluster string) ([]PermissionsSummary, error) {
+		summary, err := m.GetPermissionsSummary(ctx, cluster)
 		if err != nil {
-			// Include partial info on error
-			summaries = append(summaries, PermissionsSummary{
-				Cluster: cluster.Name,
-			})
-			continue
+			return nil, err
 		}
-		summaries = append(summaries, *summary)
+		return []PermissionsSummary{*summary}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return summaries, nil
+	// Include partial info for clusters that failed
+	for _, ce := range result.Errors {
+		result.Items = append(result.Items, PermissionsSummary{Cluster: ce.Cluster})
+	}
+	return result.Items, nil
 }
 
 // ListNamespacesWithDetails returns namespaces with details for a cluster
 func (m *MultiClusterClient) ListNamespacesWithDetails(ctx context.Context, contextName string) ([]models.NamespaceDetails, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "ListNamespacesWithDetails", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -705,7 +770,10 @@ func (m *MultiClusterClient) ListNamespacesWithDetails(ctx context.Context, cont
 
 // CreateNamespace creates a new namespace in a cluster
 func (m *MultiClusterClient) CreateNamespace(ctx context.Context, contextName, name string, labels map[string]string) (*models.NamespaceDetails, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "CreateNamespace", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return nil, err
 	}
@@ -733,7 +801,10 @@ func (m *MultiClusterClient) CreateNamespace(ctx context.Context, contextName, n
 
 // DeleteNamespace deletes a namespace from a cluster
 func (m *MultiClusterClient) DeleteNamespace(ctx context.Context, contextName, name string) error {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "DeleteNamespace", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return err
 	}
@@ -743,7 +814,10 @@ func (m *MultiClusterClient) DeleteNamespace(ctx context.Context, contextName, n
 
 // GrantNamespaceAccess creates a RoleBinding to grant access to a namespace
 func (m *MultiClusterClient) GrantNamespaceAccess(ctx context.Context, contextName, namespace string, req models.GrantNamespaceAccessRequest) (string, error) {
-	client, err := m.GetClient(contextName)
+	ctx, span := startSpan(ctx, "GrantNamespaceAccess", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,570 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RBAC risk severities, highest first
+const (
+	RBACRiskCritical = "critical"
+	RBACRiskHigh     = "high"
+	RBACRiskMedium   = "medium"
+	RBACRiskLow      = "low"
+)
+
+// rbacRiskWeights is how much each finding adds to a subject's risk score
+var rbacRiskWeights = map[string]int{
+	RBACRiskCritical: 40,
+	RBACRiskHigh:     15,
+	RBACRiskMedium:   5,
+	RBACRiskLow:      1,
+}
+
+// RBACSubject is a user, group or service account named in bindings
+type RBACSubject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RBACGrant is a role granted to a subject by one binding
+type RBACGrant struct {
+	Binding     string `json:"binding"`
+	BindingKind string `json:"bindingKind"`
+	// Namespace the grant applies in; empty for cluster-wide grants
+	Namespace string              `json:"namespace,omitempty"`
+	RoleKind  string              `json:"roleKind"`
+	RoleName  string              `json:"roleName"`
+	Rules     []rbacv1.PolicyRule `json:"rules"`
+}
+
+// RBACRisk is a dangerous permission held by a subject
+type RBACRisk struct {
+	Severity  string            `json:"severity"`
+	Reason    string            `json:"reason"`
+	Binding   string            `json:"binding"`
+	Namespace string            `json:"namespace,omitempty"`
+	Rule      rbacv1.PolicyRule `json:"rule"`
+}
+
+// SubjectPermissions is a subject's effective permissions: every role
+// bound to it, with aggregated ClusterRoles resolved, and the risks found
+type SubjectPermissions struct {
+	RBACSubject
+	Grants []RBACGrant `json:"grants"`
+	Risks  []RBACRisk  `json:"risks"`
+	// RiskScore is 0-100, the weighted sum of the subject's risks
+	RiskScore int    `json:"riskScore"`
+	RiskLevel string `json:"riskLevel"`
+}
+
+// OrphanedBinding is a binding to a role or service account that does not
+// exist
+type OrphanedBinding struct {
+	Binding     string `json:"binding"`
+	BindingKind string `json:"bindingKind"`
+	Namespace   string `json:"namespace,omitempty"`
+	Missing     string `json:"missing"`
+}
+
+// RBACSummary counts the RBAC objects and findings of a cluster
+type RBACSummary struct {
+	Subjects     int `json:"subjects"`
+	Roles        int `json:"roles"`
+	ClusterRoles int `json:"clusterRoles"`
+	Bindings     int `json:"bindings"`
+	Critical     int `json:"critical"`
+	High         int `json:"high"`
+	Medium       int `json:"medium"`
+	Orphaned     int `json:"orphaned"`
+}
+
+// RBACAnalysis is the RBAC analysis of a cluster, subjects riskiest first
+type RBACAnalysis struct {
+	Cluster  string               `json:"cluster"`
+	Subjects []SubjectPermissions `json:"subjects"`
+	Orphaned []OrphanedBinding    `json:"orphaned"`
+	Summary  RBACSummary          `json:"summary"`
+}
+
+// AnalyzeRBAC computes each subject's effective permissions in a cluster
+// and flags risky grants and orphaned bindings. System subjects and
+// bindings are left out unless includeSystem is set.
+func (m *MultiClusterClient) AnalyzeRBAC(ctx context.Context, contextName string, includeSystem bool) (*RBACAnalysis, error) {
+	ctx, span := startSpan(ctx, "AnalyzeRBAC", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := loadRBAC(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return analyzeRBAC(contextName, snapshot, includeSystem), nil
+}
+
+// rbacSnapshot is the RBAC state of a cluster
+type rbacSnapshot struct {
+	roles               map[string]*rbacv1.Role // by namespace/name
+	clusterRoles        map[string]*rbacv1.ClusterRole
+	roleBindings        []rbacv1.RoleBinding
+	clusterRoleBindings []rbacv1.ClusterRoleBinding
+	serviceAccounts     map[string]*corev1.ServiceAccount // by namespace/name
+
+	aggregated map[string][]rbacv1.PolicyRule // resolved aggregated ClusterRoles
+}
+
+// rbacBinding is a RoleBinding or ClusterRoleBinding
+type rbacBinding struct {
+	Kind      string
+	Name      string
+	Namespace string // empty for ClusterRoleBindings
+	RoleRef   rbacv1.RoleRef
+	Subjects  []rbacv1.Subject
+}
+
+func loadRBAC(ctx context.Context, client kubernetes.Interface) (*rbacSnapshot, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	s := &rbacSnapshot{
+		roles:           make(map[string]*rbacv1.Role),
+		clusterRoles:    make(map[string]*rbacv1.ClusterRole),
+		serviceAccounts: make(map[string]*corev1.ServiceAccount),
+		aggregated:      make(map[string][]rbacv1.PolicyRule),
+	}
+	run := func(list func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := list(); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	run(func() error {
+		roles, err := client.RbacV1().Roles("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("list roles: %w", err)
+		}
+		for i := range roles.Items {
+			s.roles[roles.Items[i].Namespace+"/"+roles.Items[i].Name] = &roles.Items[i]
+		}
+		return nil
+	})
+	run(func() error {
+		clusterRoles, err := client.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("list cluster roles: %w", err)
+		}
+		for i := range clusterRoles.Items {
+			s.clusterRoles[clusterRoles.Items[i].Name] = &clusterRoles.Items[i]
+		}
+		return nil
+	})
+	run(func() error {
+		bindings, err := client.RbacV1().RoleBindings("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("list role bindings: %w", err)
+		}
+		s.roleBindings = bindings.Items
+		return nil
+	})
+	run(func() error {
+		bindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("list cluster role bindings: %w", err)
+		}
+		s.clusterRoleBindings = bindings.Items
+		return nil
+	})
+	run(func() error {
+		serviceAccounts, err := client.CoreV1().ServiceAccounts("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("list service accounts: %w", err)
+		}
+		for i := range serviceAccounts.Items {
+			sa := &serviceAccounts.Items[i]
+			s.serviceAccounts[sa.Namespace+"/"+sa.Name] = sa
+		}
+		return nil
+	})
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return s, nil
+}
+
+// bindings returns the RoleBindings followed by the ClusterRoleBindings
+func (s *rbacSnapshot) bindings() []rbacBinding {
+	result := make([]rbacBinding, 0, len(s.roleBindings)+len(s.clusterRoleBindings))
+	for _, rb := range s.roleBindings {
+		result = append(result, rbacBinding{Kind: "RoleBinding", Name: rb.Name, Namespace: rb.Namespace, RoleRef: rb.RoleRef, Subjects: rb.Subjects})
+	}
+	for _, crb := range s.clusterRoleBindings {
+		result = append(result, rbacBinding{Kind: "ClusterRoleBinding", Name: crb.Name, RoleRef: crb.RoleRef, Subjects: crb.Subjects})
+	}
+	return result
+}
+
+// roleRules returns the rules of the role a binding refers to, and whether
+// the role exists
+func (s *rbacSnapshot) roleRules(b rbacBinding) ([]rbacv1.PolicyRule, bool) {
+	if b.RoleRef.Kind == "Role" {
+		role, ok := s.roles[b.Namespace+"/"+b.RoleRef.Name]
+		if !ok {
+			return nil, false
+		}
+		return role.Rules, true
+	}
+	return s.clusterRoleRules(b.RoleRef.Name, map[string]bool{})
+}
+
+// clusterRoleRules returns a ClusterRole's rules, computing those of
+// aggregated ClusterRoles from the roles their selectors match rather than
+// relying on the aggregation controller having caught up
+func (s *rbacSnapshot) clusterRoleRules(name string, visiting map[string]bool) ([]rbacv1.PolicyRule, bool) {
+	role, ok := s.clusterRoles[name]
+	if !ok {
+		return nil, false
+	}
+	if role.AggregationRule == nil || len(role.AggregationRule.ClusterRoleSelectors) == 0 {
+		return role.Rules, true
+	}
+	if rules, ok := s.aggregated[name]; ok {
+		return rules, true
+	}
+	if visiting[name] {
+		return role.Rules, true
+	}
+	visiting[name] = true
+
+	names := make([]string, 0, len(s.clusterRoles))
+	for other := range s.clusterRoles {
+		names = append(names, other)
+	}
+	sort.Strings(names)
+
+	seen := map[string]bool{}
+	rules := []rbacv1.PolicyRule{}
+	add := func(rule rbacv1.PolicyRule) {
+		key := rule.String()
+		if !seen[key] {
+			seen[key] = true
+			rules = append(rules, rule)
+		}
+	}
+	for _, other := range names {
+		if other == name {
+			continue
+		}
+		if !s.aggregates(name, other) {
+			continue
+		}
+		otherRules, _ := s.clusterRoleRules(other, visiting)
+		for _, rule := range otherRules {
+			add(rule)
+		}
+	}
+	// Keep rules written into the role itself, as the controller would
+	// have put them there
+	for _, rule := range role.Rules {
+		add(rule)
+	}
+
+	s.aggregated[name] = rules
+	return rules, true
+}
+
+// aggregates reports whether an aggregated ClusterRole selects another
+func (s *rbacSnapshot) aggregates(aggregator, name string) bool {
+	role := s.clusterRoles[aggregator]
+	other := s.clusterRoles[name]
+	if role == nil || other == nil || role.AggregationRule == nil {
+		return false
+	}
+	for i := range role.AggregationRule.ClusterRoleSelectors {
+		selector, err := metav1.LabelSelectorAsSelector(&role.AggregationRule.ClusterRoleSelectors[i])
+		if err == nil && selector.Matches(labels.Set(other.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// subjectNamespace returns the namespace of a binding subject; service
+// accounts in RoleBindings default to the binding's namespace
+func subjectNamespace(subject rbacv1.Subject, b rbacBinding) string {
+	if subject.Kind != rbacv1.ServiceAccountKind {
+		return ""
+	}
+	if subject.Namespace == "" {
+		return b.Namespace
+	}
+	return subject.Namespace
+}
+
+func isSystemSubject(subject RBACSubject) bool {
+	return strings.HasPrefix(subject.Name, "system:") ||
+		(subject.Kind == rbacv1.ServiceAccountKind && strings.HasPrefix(subject.Namespace, "kube-"))
+}
+
+func analyzeRBAC(contextName string, s *rbacSnapshot, includeSystem bool) *RBACAnalysis {
+	analysis := &RBACAnalysis{
+		Cluster:  contextName,
+		Subjects: []SubjectPermissions{},
+		Orphaned: []OrphanedBinding{},
+		Summary: RBACSummary{
+			Roles:        len(s.roles),
+			ClusterRoles: len(s.clusterRoles),
+			Bindings:     len(s.roleBindings) + len(s.clusterRoleBindings),
+		},
+	}
+
+	subjects := map[RBACSubject]*SubjectPermissions{}
+	for _, b := range s.bindings() {
+		if !includeSystem && isSystemRole(b.Name) {
+			continue
+		}
+
+		rules, ok := s.roleRules(b)
+		if !ok {
+			analysis.Orphaned = append(analysis.Orphaned, OrphanedBinding{
+				Binding:     b.Name,
+				BindingKind: b.Kind,
+				Namespace:   b.Namespace,
+				Missing:     b.RoleRef.Kind + " " + b.RoleRef.Name,
+			})
+		}
+
+		grant := RBACGrant{
+			Binding:     b.Name,
+			BindingKind: b.Kind,
+			Namespace:   b.Namespace,
+			RoleKind:    b.RoleRef.Kind,
+			RoleName:    b.RoleRef.Name,
+			Rules:       rules,
+		}
+		if grant.Rules == nil {
+			grant.Rules = []rbacv1.PolicyRule{}
+		}
+		risks := ruleRisks(grant)
+
+		for _, subject := range b.Subjects {
+			key := RBACSubject{Kind: subject.Kind, Name: subject.Name, Namespace: subjectNamespace(subject, b)}
+			if subject.Kind == rbacv1.ServiceAccountKind && s.serviceAccounts[key.Namespace+"/"+key.Name] == nil {
+				analysis.Orphaned = append(analysis.Orphaned, OrphanedBinding{
+					Binding:     b.Name,
+					BindingKind: b.Kind,
+					Namespace:   b.Namespace,
+					Missing:     "ServiceAccount " + key.Namespace + "/" + key.Name,
+				})
+			}
+			if !includeSystem && isSystemSubject(key) {
+				continue
+			}
+
+			sp, ok := subjects[key]
+			if !ok {
+				sp = &SubjectPermissions{RBACSubject: key, Grants: []RBACGrant{}, Risks: []RBACRisk{}}
+				subjects[key] = sp
+			}
+			sp.Grants = append(sp.Grants, grant)
+			sp.Risks = append(sp.Risks, risks...)
+		}
+	}
+
+	for _, sp := range subjects {
+		sp.RiskScore, sp.RiskLevel = riskScore(sp.Risks)
+		switch sp.RiskLevel {
+		case RBACRiskCritical:
+			analysis.Summary.Critical++
+		case RBACRiskHigh:
+			analysis.Summary.High++
+		case RBACRiskMedium:
+			analysis.Summary.Medium++
+		}
+		analysis.Subjects = append(analysis.Subjects, *sp)
+	}
+	sort.Slice(analysis.Subjects, func(i, j int) bool {
+		a, b := analysis.Subjects[i], analysis.Subjects[j]
+		if a.RiskScore != b.RiskScore {
+			return a.RiskScore > b.RiskScore
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	analysis.Summary.Subjects = len(analysis.Subjects)
+	analysis.Summary.Orphaned = len(analysis.Orphaned)
+	return analysis
+}
+
+// ruleRisks returns the dangerous permissions in a grant's rules
+func ruleRisks(grant RBACGrant) []RBACRisk {
+	clusterWide := grant.Namespace == ""
+	// scoped picks the severity for cluster-wide or namespaced grants
+	scoped := func(cluster, namespaced string) string {
+		if clusterWide {
+			return cluster
+		}
+		return namespaced
+	}
+
+	var risks []RBACRisk
+	for _, rule := range grant.Rules {
+		add := func(severity, reason string) {
+			// Rules limited to named objects are less dangerous
+			if len(rule.ResourceNames) > 0 {
+				severity = lowerSeverity(severity)
+			}
+			risks = append(risks, RBACRisk{
+				Severity:  severity,
+				Reason:    reason,
+				Binding:   grant.Binding,
+				Namespace: grant.Namespace,
+				Rule:      rule,
+			})
+		}
+
+		if len(rule.Resources) == 0 {
+			if containsString(rule.Verbs, "*") && containsString(rule.NonResourceURLs, "*") {
+				add(RBACRiskMedium, "Wildcard access to non-resource URLs")
+			}
+			continue
+		}
+
+		verbsAll := containsString(rule.Verbs, rbacv1.VerbAll)
+		resourcesAll := containsString(rule.Resources, rbacv1.ResourceAll)
+		groupsAll := containsString(rule.APIGroups, rbacv1.APIGroupAll)
+		if verbsAll && resourcesAll && groupsAll {
+			add(scoped(RBACRiskCritical, RBACRiskHigh), scoped("Full access to all resources (cluster-admin equivalent)", "Full access to all resources in the namespace"))
+			continue
+		}
+		if verbsAll || resourcesAll {
+			add(RBACRiskHigh, "Wildcard "+wildcardKind(verbsAll, resourcesAll)+" in rule")
+		}
+
+		for _, resource := range []string{"roles", "clusterroles"} {
+			for _, verb := range []string{"escalate", "bind"} {
+				if ruleAllows(rule, verb, rbacv1.GroupName, resource) {
+					add(scoped(RBACRiskCritical, RBACRiskHigh), fmt.Sprintf("Can %s %s to gain permissions it does not hold", verb, resource))
+				}
+			}
+		}
+		for _, resource := range []string{"users", "groups", "serviceaccounts", "uids", "userextras/*"} {
+			group := ""
+			if resource == "uids" || resource == "userextras/*" {
+				group = "authentication.k8s.io"
+			}
+			if ruleAllows(rule, "impersonate", group, resource) {
+				add(RBACRiskCritical, "Can impersonate "+resource)
+				break
+			}
+		}
+		for _, verb := range []string{"get", "list", "watch"} {
+			if ruleAllows(rule, verb, "", "secrets") {
+				add(scoped(RBACRiskHigh, RBACRiskMedium), "Can read secrets")
+				break
+			}
+		}
+		for _, resource := range []string{"pods/exec", "pods/attach"} {
+			// kubectl exec and attach use create, or get over websockets
+			if ruleAllows(rule, "create", "", resource) || ruleAllows(rule, "get", "", resource) {
+				add(scoped(RBACRiskHigh, RBACRiskMedium), "Can run commands in pods via "+resource)
+				break
+			}
+		}
+		// nodes are cluster-scoped, so only ClusterRoleBindings grant this
+		if clusterWide && (ruleAllows(rule, "get", "", "nodes/proxy") || ruleAllows(rule, "create", "", "nodes/proxy")) {
+			add(RBACRiskCritical, "Can reach the kubelet API via nodes/proxy")
+		}
+	}
+	return risks
+}
+
+func wildcardKind(verbs, resources bool) string {
+	switch {
+	case verbs && resources:
+		return "verbs and resources"
+	case verbs:
+		return "verbs"
+	}
+	return "resources"
+}
+
+// ruleAllows reports whether a rule grants verb on a resource, which may
+// name a subresource as "resource/subresource"
+func ruleAllows(rule rbacv1.PolicyRule, verb, group, resource string) bool {
+	if !containsString(rule.Verbs, verb) && !containsString(rule.Verbs, rbacv1.VerbAll) {
+		return false
+	}
+	if !containsString(rule.APIGroups, group) && !containsString(rule.APIGroups, rbacv1.APIGroupAll) {
+		return false
+	}
+	for _, r := range rule.Resources {
+		if r == rbacv1.ResourceAll || r == resource {
+			return true
+		}
+		// "*/exec" grants the exec subresource of any resource
+		if i := strings.Index(resource, "/"); i >= 0 && r == "*"+resource[i:] {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerSeverity(severity string) string {
+	switch severity {
+	case RBACRiskCritical:
+		return RBACRiskHigh
+	case RBACRiskHigh:
+		return RBACRiskMedium
+	}
+	return RBACRiskLow
+}
+
+// riskScore returns a subject's 0-100 score and its highest risk severity
+func riskScore(risks []RBACRisk) (int, string) {
+	score := 0
+	level := RBACRiskLow
+	seen := map[string]bool{}
+	for _, risk := range risks {
+		// The same finding from several rules of one binding counts once
+		key := risk.Binding + "/" + risk.Namespace + "/" + risk.Reason
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		score += rbacRiskWeights[risk.Severity]
+		if rbacRiskWeights[risk.Severity] > rbacRiskWeights[level] {
+			level = risk.Severity
+		}
+	}
+	return min(score, 100), level
+}
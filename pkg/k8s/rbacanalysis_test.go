@@ -0,0 +1,238 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func rule(verbs []string, group string, resources ...string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{Verbs: verbs, APIGroups: []string{group}, Resources: resources}
+}
+
+func clusterRole(name string, labels map[string]string, aggregate map[string]string, rules ...rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}, Rules: rules}
+	if aggregate != nil {
+		role.AggregationRule = &rbacv1.AggregationRule{ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: aggregate}}}
+	}
+	return role
+}
+
+func clusterRoleBinding(name, role string, subjects ...rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role},
+		Subjects:   subjects,
+	}
+}
+
+func roleBinding(namespace, name, role string, subjects ...rbacv1.Subject) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role},
+		Subjects:   subjects,
+	}
+}
+
+func userSubject(name string) rbacv1.Subject {
+	return rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: name}
+}
+
+// rbacFixtures is a cluster where:
+//   - alice is a cluster admin through admin-all
+//   - prometheus in monitoring holds the aggregated monitoring ClusterRole,
+//     which reads secrets through monitoring-secrets and pod logs through
+//     the nested aggregated monitoring-logs
+//   - bob and the missing service account ci can exec into pods and read
+//     secrets in team-a
+//   - carol can escalate only the viewer Role in team-a
+//   - the devs group is bound to a ClusterRole that does not exist
+//   - loop-a and loop-b aggregate each other
+func rbacFixtures() []runtime.Object {
+	aggregateToMonitoring := map[string]string{"rbac.example.com/aggregate-to-monitoring": "true"}
+	readPods := rule([]string{"get", "list"}, "", "pods")
+
+	return []runtime.Object{
+		clusterRole("admin-all", nil, nil, rule([]string{"*"}, "*", "*")),
+		// The controller has copied monitoring-metrics' rules in, but not
+		// the others
+		clusterRole("monitoring", nil, aggregateToMonitoring, readPods),
+		clusterRole("monitoring-metrics", aggregateToMonitoring, nil, readPods),
+		clusterRole("monitoring-secrets", aggregateToMonitoring, nil, rule([]string{"list"}, "", "secrets")),
+		clusterRole("monitoring-logs", aggregateToMonitoring, map[string]string{"rbac.example.com/aggregate-to-monitoring-logs": "true"}),
+		clusterRole("pod-logs", map[string]string{"rbac.example.com/aggregate-to-monitoring-logs": "true"}, nil, rule([]string{"get"}, "", "pods/log")),
+		clusterRole("loop-a", map[string]string{"loop": "a"}, map[string]string{"loop": "b"}, rule([]string{"get"}, "", "configmaps")),
+		clusterRole("loop-b", map[string]string{"loop": "b"}, map[string]string{"loop": "a"}, rule([]string{"get"}, "", "services")),
+		clusterRole("system:event-reader", nil, nil, rule([]string{"get", "list", "watch"}, "", "events")),
+
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "team-a"},
+			Rules: []rbacv1.PolicyRule{
+				rule([]string{"create"}, "", "pods/exec"),
+				rule([]string{"get"}, "", "secrets"),
+			},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "escalator", Namespace: "team-a"},
+			Rules: []rbacv1.PolicyRule{{
+				Verbs:         []string{"escalate"},
+				APIGroups:     []string{rbacv1.GroupName},
+				Resources:     []string{"roles"},
+				ResourceNames: []string{"viewer"},
+			}},
+		},
+
+		clusterRoleBinding("ops-admin", "admin-all", userSubject("alice")),
+		clusterRoleBinding("monitoring", "monitoring", rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "prometheus", Namespace: "monitoring"}),
+		clusterRoleBinding("ghost-role", "does-not-exist", rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "devs"}),
+		clusterRoleBinding("system:event-readers", "system:event-reader", userSubject("system:kube-scheduler")),
+		// Service accounts in RoleBindings default to the binding's namespace
+		roleBinding("team-a", "deployers", "deployer", rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "ci"}, userSubject("bob")),
+		roleBinding("team-a", "escalators", "escalator", userSubject("carol")),
+
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "prometheus", Namespace: "monitoring"}},
+	}
+}
+
+// rbacFixtureSnapshot loads rbacFixtures through a fake clientset
+func rbacFixtureSnapshot(t *testing.T) *rbacSnapshot {
+	t.Helper()
+	snapshot, err := loadRBAC(context.Background(), fake.NewSimpleClientset(rbacFixtures()...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+func TestClusterRoleRules(t *testing.T) {
+	s := rbacFixtureSnapshot(t)
+
+	tests := []struct {
+		role string
+		want []rbacv1.PolicyRule
+	}{
+		{"admin-all", []rbacv1.PolicyRule{rule([]string{"*"}, "*", "*")}},
+		// Aggregated roles are expanded in name order, nested ones
+		// included, with the rules already copied in kept only once
+		{"monitoring", []rbacv1.PolicyRule{
+			rule([]string{"get"}, "", "pods/log"),
+			rule([]string{"get", "list"}, "", "pods"),
+			rule([]string{"list"}, "", "secrets"),
+		}},
+		{"monitoring-logs", []rbacv1.PolicyRule{rule([]string{"get"}, "", "pods/log")}},
+		// A cycle stops at the role already being expanded
+		{"loop-a", []rbacv1.PolicyRule{
+			rule([]string{"get"}, "", "configmaps"),
+			rule([]string{"get"}, "", "services"),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			rules, ok := s.clusterRoleRules(tt.role, map[string]bool{})
+			if !ok {
+				t.Fatal("role not found")
+			}
+			if !reflect.DeepEqual(rules, tt.want) {
+				t.Fatalf("rules = %v, want %v", rules, tt.want)
+			}
+		})
+	}
+
+	if _, ok := s.clusterRoleRules("does-not-exist", map[string]bool{}); ok {
+		t.Error("a missing role was found")
+	}
+}
+
+func TestAnalyzeRBAC(t *testing.T) {
+	analysis := analyzeRBAC("kind", rbacFixtureSnapshot(t), false)
+
+	type subject struct {
+		Subject RBACSubject
+		Score   int
+		Level   string
+		Reasons []string
+	}
+	var got []subject
+	for _, sp := range analysis.Subjects {
+		var reasons []string
+		for _, risk := range sp.Risks {
+			reasons = append(reasons, risk.Severity+": "+risk.Reason)
+		}
+		got = append(got, subject{sp.RBACSubject, sp.RiskScore, sp.RiskLevel, reasons})
+	}
+	want := []subject{
+		{RBACSubject{Kind: "User", Name: "alice"}, 40, RBACRiskCritical, []string{"critical: Full access to all resources (cluster-admin equivalent)"}},
+		{RBACSubject{Kind: "ServiceAccount", Name: "prometheus", Namespace: "monitoring"}, 15, RBACRiskHigh, []string{"high: Can read secrets"}},
+		{RBACSubject{Kind: "ServiceAccount", Name: "ci", Namespace: "team-a"}, 10, RBACRiskMedium, []string{"medium: Can run commands in pods via pods/exec", "medium: Can read secrets"}},
+		{RBACSubject{Kind: "User", Name: "bob"}, 10, RBACRiskMedium, []string{"medium: Can run commands in pods via pods/exec", "medium: Can read secrets"}},
+		// Limiting the rule to named roles lowers the severity
+		{RBACSubject{Kind: "User", Name: "carol"}, 5, RBACRiskMedium, []string{"medium: Can escalate roles to gain permissions it does not hold"}},
+		{RBACSubject{Kind: "Group", Name: "devs"}, 0, RBACRiskLow, nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("subjects =\n%+v\nwant\n%+v", got, want)
+	}
+
+	var orphaned []string
+	for _, o := range analysis.Orphaned {
+		orphaned = append(orphaned, o.BindingKind+" "+o.Binding+": "+o.Missing)
+	}
+	sort.Strings(orphaned)
+	wantOrphaned := []string{
+		"ClusterRoleBinding ghost-role: ClusterRole does-not-exist",
+		"RoleBinding deployers: ServiceAccount team-a/ci",
+	}
+	if !reflect.DeepEqual(orphaned, wantOrphaned) {
+		t.Fatalf("orphaned = %v, want %v", orphaned, wantOrphaned)
+	}
+
+	wantSummary := RBACSummary{Subjects: 6, Roles: 2, ClusterRoles: 9, Bindings: 6, Critical: 1, High: 1, Medium: 3, Orphaned: 2}
+	if analysis.Summary != wantSummary {
+		t.Errorf("summary = %+v, want %+v", analysis.Summary, wantSummary)
+	}
+
+	withSystem := analyzeRBAC("kind", rbacFixtureSnapshot(t), true)
+	found := false
+	for _, sp := range withSystem.Subjects {
+		found = found || sp.Name == "system:kube-scheduler"
+	}
+	if !found || withSystem.Summary.Subjects != 7 {
+		t.Errorf("system subjects were not included: %+v", withSystem.Summary)
+	}
+}
+
+func TestRuleRisks(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		rule      rbacv1.PolicyRule
+		want      []string
+	}{
+		{"namespace admin", "team-a", rule([]string{"*"}, "*", "*"), []string{"high: Full access to all resources in the namespace"}},
+		{"wildcard verbs", "", rule([]string{"*"}, "", "configmaps"), []string{"high: Wildcard verbs in rule"}},
+		{"bind cluster roles", "", rule([]string{"bind"}, rbacv1.GroupName, "clusterroles"), []string{"critical: Can bind clusterroles to gain permissions it does not hold"}},
+		{"impersonation", "team-a", rule([]string{"impersonate"}, "", "serviceaccounts"), []string{"critical: Can impersonate serviceaccounts"}},
+		{"exec into any resource", "", rule([]string{"get"}, "", "*/exec"), []string{"high: Can run commands in pods via pods/exec"}},
+		{"nodes/proxy", "", rule([]string{"get"}, "", "nodes/proxy"), []string{"critical: Can reach the kubelet API via nodes/proxy"}},
+		{"nonresource wildcard", "", rbacv1.PolicyRule{Verbs: []string{"*"}, NonResourceURLs: []string{"*"}}, []string{"medium: Wildcard access to non-resource URLs"}},
+		{"harmless", "", rule([]string{"get", "list"}, "", "pods"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, risk := range ruleRisks(RBACGrant{Binding: "b", Namespace: tt.namespace, Rules: []rbacv1.PolicyRule{tt.rule}}) {
+				got = append(got, risk.Severity+": "+risk.Reason)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("risks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,602 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Workload resources with rollouts
+const (
+	ResourceDeployments  = "deployments"
+	ResourceStatefulSets = "statefulsets"
+	ResourceDaemonSets   = "daemonsets"
+)
+
+// RolloutResources lists the workload resources with rollouts
+var RolloutResources = []string{ResourceDeployments, ResourceStatefulSets, ResourceDaemonSets}
+
+// Rollout phases
+const (
+	RolloutComplete    = "complete"
+	RolloutProgressing = "progressing"
+	RolloutPaused      = "paused"
+	// RolloutStalled means the rollout stopped making progress: a
+	// Deployment exceeded its progress deadline, or a tracker saw no
+	// progress for a while
+	RolloutStalled = "stalled"
+)
+
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+// ErrRolloutUnsupported is returned for actions a workload kind does not
+// support, such as pausing a StatefulSet
+var ErrRolloutUnsupported = errors.New("not supported for this workload kind")
+
+// ErrRolloutPaused is returned when rolling back a paused Deployment
+var ErrRolloutPaused = errors.New("rollout is paused; resume it first")
+
+// ErrRevisionNotFound is returned when rolling back to a revision that is
+// not in the workload's history
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// RolloutStatus is the progress of a workload's current rollout
+type RolloutStatus struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+	// Revision is a Deployment's revision; StatefulSet and DaemonSet
+	// revisions are only in the history
+	Revision  int64 `json:"revision"`
+	Desired   int32 `json:"desired"`
+	Updated   int32 `json:"updated"`
+	Ready     int32 `json:"ready"`
+	Available int32 `json:"available"`
+	// Progress is the percentage of desired replicas updated and available
+	Progress int      `json:"progress"`
+	Paused   bool     `json:"paused"`
+	Phase    string   `json:"phase"`
+	Message  string   `json:"message,omitempty"`
+	Images   []string `json:"images"`
+}
+
+// WorkloadRevision is a revision in a workload's history, from a
+// Deployment's ReplicaSets or a StatefulSet's or DaemonSet's
+// ControllerRevisions
+type WorkloadRevision struct {
+	Revision    int64     `json:"revision"`
+	Name        string    `json:"name"`
+	Images      []string  `json:"images"`
+	ChangeCause string    `json:"changeCause,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// Replicas is only known for Deployment revisions
+	Replicas int32 `json:"replicas"`
+	Current  bool  `json:"current"`
+}
+
+// ListRollouts returns the rollout status of a cluster's Deployments,
+// StatefulSets and DaemonSets
+func (m *MultiClusterClient) ListRollouts(ctx context.Context, contextName, namespace string) ([]RolloutStatus, error) {
+	ctx, span := startSpan(ctx, "ListRollouts", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return listRollouts(ctx, client, contextName, namespace)
+}
+
+// listRollouts is ListRollouts with the clientset already made
+func listRollouts(ctx context.Context, client kubernetes.Interface, contextName, namespace string) ([]RolloutStatus, error) {
+	result := []RolloutStatus{}
+
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		result = append(result, deploymentRollout(contextName, &deployments.Items[i]))
+	}
+
+	statefulSets, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		result = append(result, statefulSetRollout(contextName, &statefulSets.Items[i]))
+	}
+
+	daemonSets, err := client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		result = append(result, daemonSetRollout(contextName, &daemonSets.Items[i]))
+	}
+
+	return result, nil
+}
+
+// GetRolloutStatus returns the rollout status of one workload; resource is
+// one of RolloutResources
+func (m *MultiClusterClient) GetRolloutStatus(ctx context.Context, contextName, resource, namespace, name string) (*RolloutStatus, error) {
+	ctx, span := startSpan(ctx, "GetRolloutStatus", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return rolloutStatus(ctx, client, contextName, resource, namespace, name)
+}
+
+func rolloutStatus(ctx context.Context, client kubernetes.Interface, contextName, resource, namespace, name string) (*RolloutStatus, error) {
+	var status RolloutStatus
+	switch resource {
+	case ResourceDeployments:
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		status = deploymentRollout(contextName, d)
+	case ResourceStatefulSets:
+		s, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		status = statefulSetRollout(contextName, s)
+	case ResourceDaemonSets:
+		d, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		status = daemonSetRollout(contextName, d)
+	default:
+		return nil, fmt.Errorf("unknown workload resource %q", resource)
+	}
+	return &status, nil
+}
+
+func deploymentRollout(contextName string, d *appsv1.Deployment) RolloutStatus {
+	desired := replicas(d.Spec.Replicas)
+	status := RolloutStatus{
+		Kind:      "Deployment",
+		Name:      d.Name,
+		Namespace: d.Namespace,
+		Cluster:   contextName,
+		Revision:  parseRevision(d.Annotations[revisionAnnotation]),
+		Desired:   desired,
+		Updated:   d.Status.UpdatedReplicas,
+		Ready:     d.Status.ReadyReplicas,
+		Available: d.Status.AvailableReplicas,
+		Paused:    d.Spec.Paused,
+		Images:    podImages(d.Spec.Template.Spec),
+	}
+	status.Progress = rolloutProgress(desired, status.Updated, status.Available)
+
+	// The same checks as kubectl rollout status
+	switch {
+	case d.Spec.Paused:
+		status.Phase = RolloutPaused
+	case d.Generation > d.Status.ObservedGeneration:
+		status.Phase = RolloutProgressing
+		status.Message = "waiting for the rollout to be observed"
+	default:
+		for _, condition := range d.Status.Conditions {
+			if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+				status.Phase = RolloutStalled
+				status.Message = condition.Message
+				return status
+			}
+		}
+		switch {
+		case d.Status.UpdatedReplicas < desired:
+			status.Phase = RolloutProgressing
+			status.Message = fmt.Sprintf("%d of %d new replicas updated", d.Status.UpdatedReplicas, desired)
+		case d.Status.Replicas > d.Status.UpdatedReplicas:
+			status.Phase = RolloutProgressing
+			status.Message = fmt.Sprintf("%d old replicas pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+		case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+			status.Phase = RolloutProgressing
+			status.Message = fmt.Sprintf("%d of %d updated replicas available", d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+		default:
+			status.Phase = RolloutComplete
+		}
+	}
+	return status
+}
+
+func statefulSetRollout(contextName string, s *appsv1.StatefulSet) RolloutStatus {
+	desired := replicas(s.Spec.Replicas)
+	status := RolloutStatus{
+		Kind:      "StatefulSet",
+		Name:      s.Name,
+		Namespace: s.Namespace,
+		Cluster:   contextName,
+		Desired:   desired,
+		Updated:   s.Status.UpdatedReplicas,
+		Ready:     s.Status.ReadyReplicas,
+		Available: s.Status.AvailableReplicas,
+		Images:    podImages(s.Spec.Template.Spec),
+	}
+	status.Progress = rolloutProgress(desired, status.Updated, status.Available)
+
+	partition := int32(0)
+	if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		partition = *ru.Partition
+	}
+	switch {
+	case s.Generation > s.Status.ObservedGeneration:
+		status.Phase = RolloutProgressing
+		status.Message = "waiting for the rollout to be observed"
+	case s.Status.ReadyReplicas < desired:
+		status.Phase = RolloutProgressing
+		status.Message = fmt.Sprintf("%d of %d replicas ready", s.Status.ReadyReplicas, desired)
+	case s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType:
+		// Pods are only replaced when deleted, so there is nothing to wait for
+		status.Phase = RolloutComplete
+	case partition > 0 && s.Status.UpdatedReplicas < desired-partition:
+		status.Phase = RolloutProgressing
+		status.Message = fmt.Sprintf("%d of %d replicas above partition %d updated", s.Status.UpdatedReplicas, desired-partition, partition)
+	case partition == 0 && s.Status.UpdateRevision != s.Status.CurrentRevision:
+		status.Phase = RolloutProgressing
+		status.Message = fmt.Sprintf("%d of %d replicas updated", s.Status.UpdatedReplicas, desired)
+	default:
+		status.Phase = RolloutComplete
+	}
+	return status
+}
+
+func daemonSetRollout(contextName string, d *appsv1.DaemonSet) RolloutStatus {
+	desired := d.Status.DesiredNumberScheduled
+	status := RolloutStatus{
+		Kind:      "DaemonSet",
+		Name:      d.Name,
+		Namespace: d.Namespace,
+		Cluster:   contextName,
+		Desired:   desired,
+		Updated:   d.Status.UpdatedNumberScheduled,
+		Ready:     d.Status.NumberReady,
+		Available: d.Status.NumberAvailable,
+		Images:    podImages(d.Spec.Template.Spec),
+	}
+	status.Progress = rolloutProgress(desired, status.Updated, status.Available)
+
+	switch {
+	case d.Generation > d.Status.ObservedGeneration:
+		status.Phase = RolloutProgressing
+		status.Message = "waiting for the rollout to be observed"
+	case d.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType:
+		status.Phase = RolloutComplete
+	case d.Status.UpdatedNumberScheduled < desired:
+		status.Phase = RolloutProgressing
+		status.Message = fmt.Sprintf("%d of %d pods updated", d.Status.UpdatedNumberScheduled, desired)
+	case d.Status.NumberAvailable < desired:
+		status.Phase = RolloutProgressing
+		status.Message = fmt.Sprintf("%d of %d updated pods available", d.Status.NumberAvailable, desired)
+	default:
+		status.Phase = RolloutComplete
+	}
+	return status
+}
+
+// rolloutProgress is the percentage of desired replicas both updated and
+// available
+func rolloutProgress(desired, updated, available int32) int {
+	if desired <= 0 {
+		return 100
+	}
+	done := min(updated, available)
+	return int(min(100, done*100/desired))
+}
+
+func podImages(spec corev1.PodSpec) []string {
+	images := make([]string, 0, len(spec.Containers))
+	for _, container := range spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+func parseRevision(s string) int64 {
+	revision, _ := strconv.ParseInt(s, 10, 64)
+	return revision
+}
+
+// GetRolloutHistory returns a workload's revisions, newest first
+func (m *MultiClusterClient) GetRolloutHistory(ctx context.Context, contextName, resource, namespace, name string) ([]WorkloadRevision, error) {
+	ctx, span := startSpan(ctx, "GetRolloutHistory", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	revisions, _, err := rolloutHistory(ctx, client, resource, namespace, name)
+	return revisions, err
+}
+
+// revisionSource is where a revision's pod template lives
+type revisionSource struct {
+	replicaSet *appsv1.ReplicaSet
+	data       []byte // ControllerRevision data, a strategic merge patch
+}
+
+// rolloutHistory returns the revisions of a workload, newest first, with
+// their pod templates by revision for rollbacks
+func rolloutHistory(ctx context.Context, client kubernetes.Interface, resource, namespace, name string) ([]WorkloadRevision, map[int64]revisionSource, error) {
+	var (
+		uid      types.UID
+		selector *metav1.LabelSelector
+		current  string
+	)
+	switch resource {
+	case ResourceDeployments:
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		uid, selector = d.UID, d.Spec.Selector
+		current = d.Annotations[revisionAnnotation]
+	case ResourceStatefulSets:
+		s, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		uid, selector = s.UID, s.Spec.Selector
+		current = s.Status.UpdateRevision
+	case ResourceDaemonSets:
+		d, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		uid, selector = d.UID, d.Spec.Selector
+	default:
+		return nil, nil, fmt.Errorf("unknown workload resource %q", resource)
+	}
+
+	ls, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := metav1.ListOptions{LabelSelector: ls.String()}
+
+	revisions := []WorkloadRevision{}
+	sources := map[int64]revisionSource{}
+	if resource == ResourceDeployments {
+		replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range replicaSets.Items {
+			rs := &replicaSets.Items[i]
+			if !ownedBy(rs.OwnerReferences, uid) {
+				continue
+			}
+			revision := parseRevision(rs.Annotations[revisionAnnotation])
+			revisions = append(revisions, WorkloadRevision{
+				Revision:    revision,
+				Name:        rs.Name,
+				Images:      podImages(rs.Spec.Template.Spec),
+				ChangeCause: rs.Annotations[changeCauseAnnotation],
+				CreatedAt:   rs.CreationTimestamp.Time,
+				Replicas:    rs.Status.Replicas,
+				Current:     rs.Annotations[revisionAnnotation] == current,
+			})
+			sources[revision] = revisionSource{replicaSet: rs}
+		}
+	} else {
+		controllerRevisions, err := client.AppsV1().ControllerRevisions(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range controllerRevisions.Items {
+			cr := &controllerRevisions.Items[i]
+			if !ownedBy(cr.OwnerReferences, uid) {
+				continue
+			}
+			revisions = append(revisions, WorkloadRevision{
+				Revision:    cr.Revision,
+				Name:        cr.Name,
+				Images:      revisionImages(cr.Data.Raw),
+				ChangeCause: cr.Annotations[changeCauseAnnotation],
+				CreatedAt:   cr.CreationTimestamp.Time,
+				Current:     current != "" && cr.Name == current,
+			})
+			sources[cr.Revision] = revisionSource{data: cr.Data.Raw}
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	// DaemonSets do not name their current revision; it is the newest
+	if current == "" && len(revisions) > 0 {
+		revisions[0].Current = true
+	}
+	return revisions, sources, nil
+}
+
+func ownedBy(refs []metav1.OwnerReference, uid types.UID) bool {
+	for _, ref := range refs {
+		if ref.Controller != nil && *ref.Controller && ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// revisionImages reads the container images of a ControllerRevision's pod
+// template patch
+func revisionImages(data []byte) []string {
+	var patch struct {
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []struct {
+						Image string `json:"image"`
+					} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	images := []string{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return images
+	}
+	for _, container := range patch.Spec.Template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// RollbackWorkload restores the pod template of an earlier revision, the
+// previous one when revision is 0, as kubectl rollout undo does. It
+// returns the revision rolled back to.
+func (m *MultiClusterClient) RollbackWorkload(ctx context.Context, contextName, resource, namespace, name string, revision int64) (int64, error) {
+	ctx, span := startSpan(ctx, "RollbackWorkload", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return 0, err
+	}
+	return rollbackWorkload(ctx, client, resource, namespace, name, revision)
+}
+
+func rollbackWorkload(ctx context.Context, client kubernetes.Interface, resource, namespace, name string, revision int64) (int64, error) {
+	revisions, sources, err := rolloutHistory(ctx, client, resource, namespace, name)
+	if err != nil {
+		return 0, err
+	}
+
+	if revision == 0 {
+		// The newest revision that is not current
+		for _, r := range revisions {
+			if !r.Current {
+				revision = r.Revision
+				break
+			}
+		}
+		if revision == 0 {
+			return 0, fmt.Errorf("%w: no previous revision", ErrRevisionNotFound)
+		}
+	}
+	source, ok := sources[revision]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrRevisionNotFound, revision)
+	}
+
+	switch resource {
+	case ResourceDeployments:
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if d.Spec.Paused {
+				return ErrRolloutPaused
+			}
+			template := source.replicaSet.Spec.Template.DeepCopy()
+			delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+			d.Spec.Template = *template
+			if cause := source.replicaSet.Annotations[changeCauseAnnotation]; cause != "" {
+				if d.Annotations == nil {
+					d.Annotations = map[string]string{}
+				}
+				d.Annotations[changeCauseAnnotation] = cause
+			}
+			_, err = client.AppsV1().Deployments(namespace).Update(ctx, d, metav1.UpdateOptions{})
+			return err
+		})
+	case ResourceStatefulSets:
+		_, err = client.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, source.data, metav1.PatchOptions{})
+	case ResourceDaemonSets:
+		_, err = client.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, source.data, metav1.PatchOptions{})
+	}
+	if err != nil {
+		return 0, err
+	}
+	return revision, nil
+}
+
+// PauseRollout pauses or resumes a Deployment's rollout. StatefulSets and
+// DaemonSets cannot be paused.
+func (m *MultiClusterClient) PauseRollout(ctx context.Context, contextName, resource, namespace, name string, paused bool) error {
+	ctx, span := startSpan(ctx, "PauseRollout", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return err
+	}
+	return pauseRollout(ctx, client, resource, namespace, name, paused)
+}
+
+func pauseRollout(ctx context.Context, client kubernetes.Interface, resource, namespace, name string, paused bool) error {
+	if resource != ResourceDeployments {
+		return ErrRolloutUnsupported
+	}
+	patch := fmt.Sprintf(`{"spec":{"paused":%t}}`, paused)
+	_, err := client.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// RestartWorkload replaces a workload's pods by annotating its pod
+// template, as kubectl rollout restart does
+func (m *MultiClusterClient) RestartWorkload(ctx context.Context, contextName, resource, namespace, name string) error {
+	ctx, span := startSpan(ctx, "RestartWorkload", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return err
+	}
+	return restartWorkload(ctx, client, resource, namespace, name, time.Now())
+}
+
+// restartWorkload is RestartWorkload with the clientset already made,
+// restarting as of now
+func restartWorkload(ctx context.Context, client kubernetes.Interface, resource, namespace, name string, now time.Time) error {
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{restartedAtAnnotation: now.Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	switch resource {
+	case ResourceDeployments:
+		_, err = client.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case ResourceStatefulSets:
+		_, err = client.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case ResourceDaemonSets:
+		_, err = client.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return fmt.Errorf("unknown workload resource %q", resource)
+	}
+	return err
+}
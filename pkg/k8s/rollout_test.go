@@ -0,0 +1,543 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func rolloutTemplate(app, image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": app}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: app, Image: image}}},
+	}
+}
+
+func rolloutMeta(name string, generation int64) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid"), Generation: generation}
+}
+
+func controllerRef(uid types.UID) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{UID: uid, Controller: ptr(true)}}
+}
+
+func rolloutDeployment(revision string, status appsv1.DeploymentStatus) *appsv1.Deployment {
+	d := &appsv1.Deployment{
+		ObjectMeta: rolloutMeta("web", 2),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr(int32(3)),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: rolloutTemplate("web", "web:3"),
+		},
+		Status: status,
+	}
+	if revision != "" {
+		d.Annotations = map[string]string{revisionAnnotation: revision}
+	}
+	return d
+}
+
+func rolloutStatefulSet(strategy appsv1.StatefulSetUpdateStrategy, status appsv1.StatefulSetStatus) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: rolloutMeta("db", 2),
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:       ptr(int32(3)),
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Template:       rolloutTemplate("db", "db:2"),
+			UpdateStrategy: strategy,
+		},
+		Status: status,
+	}
+}
+
+func rolloutDaemonSet(strategy appsv1.DaemonSetUpdateStrategyType, status appsv1.DaemonSetStatus) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: rolloutMeta("agent", 2),
+		Spec: appsv1.DaemonSetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+			Template:       rolloutTemplate("agent", "agent:2"),
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: strategy},
+		},
+		Status: status,
+	}
+}
+
+func TestRolloutStatus(t *testing.T) {
+	rollingUpdate := appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+	partitioned := appsv1.StatefulSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: ptr(int32(1))},
+	}
+	onDelete := appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+
+	tests := []struct {
+		name     string
+		resource string
+		object   runtime.Object
+		phase    string
+		message  string
+		progress int
+	}{
+		{
+			name:     "deployment complete",
+			resource: ResourceDeployments,
+			object:   rolloutDeployment("4", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3}),
+			phase:    RolloutComplete,
+			progress: 100,
+		},
+		{
+			name:     "deployment not yet observed",
+			resource: ResourceDeployments,
+			object:   rolloutDeployment("4", appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			phase:    RolloutProgressing,
+			message:  "waiting for the rollout to be observed",
+			progress: 100,
+		},
+		{
+			name:     "deployment updating",
+			resource: ResourceDeployments,
+			object:   rolloutDeployment("4", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3}),
+			phase:    RolloutProgressing,
+			message:  "1 of 3 new replicas updated",
+			progress: 33,
+		},
+		{
+			name:     "deployment terminating old replicas",
+			resource: ResourceDeployments,
+			object:   rolloutDeployment("4", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			phase:    RolloutProgressing,
+			message:  "1 old replicas pending termination",
+			progress: 100,
+		},
+		{
+			name:     "deployment waiting for availability",
+			resource: ResourceDeployments,
+			object:   rolloutDeployment("4", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2}),
+			phase:    RolloutProgressing,
+			message:  "2 of 3 updated replicas available",
+			progress: 66,
+		},
+		{
+			name:     "deployment past its progress deadline",
+			resource: ResourceDeployments,
+			object: rolloutDeployment("4", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded", Message: `ReplicaSet "web-4" has timed out progressing.`},
+			}}),
+			phase:   RolloutStalled,
+			message: `ReplicaSet "web-4" has timed out progressing.`,
+		},
+		{
+			name:     "deployment paused",
+			resource: ResourceDeployments,
+			object: func() runtime.Object {
+				d := rolloutDeployment("4", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3})
+				d.Spec.Paused = true
+				return d
+			}(),
+			phase:    RolloutPaused,
+			progress: 33,
+		},
+		{
+			name:     "statefulset complete",
+			resource: ResourceStatefulSets,
+			object:   rolloutStatefulSet(rollingUpdate, appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3, CurrentRevision: "db-2", UpdateRevision: "db-2"}),
+			phase:    RolloutComplete,
+			progress: 100,
+		},
+		{
+			name:     "statefulset waiting for ready replicas",
+			resource: ResourceStatefulSets,
+			object:   rolloutStatefulSet(rollingUpdate, appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2, CurrentRevision: "db-2", UpdateRevision: "db-2"}),
+			phase:    RolloutProgressing,
+			message:  "2 of 3 replicas ready",
+			progress: 66,
+		},
+		{
+			name:     "statefulset updating",
+			resource: ResourceStatefulSets,
+			object:   rolloutStatefulSet(rollingUpdate, appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 1, AvailableReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			phase:    RolloutProgressing,
+			message:  "1 of 3 replicas updated",
+			progress: 33,
+		},
+		{
+			name:     "statefulset updating above a partition",
+			resource: ResourceStatefulSets,
+			object:   rolloutStatefulSet(partitioned, appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 1, AvailableReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			phase:    RolloutProgressing,
+			message:  "1 of 2 replicas above partition 1 updated",
+			progress: 33,
+		},
+		{
+			name:     "statefulset done up to its partition",
+			resource: ResourceStatefulSets,
+			object:   rolloutStatefulSet(partitioned, appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 2, AvailableReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			phase:    RolloutComplete,
+			progress: 66,
+		},
+		{
+			name:     "statefulset with OnDelete has nothing to wait for",
+			resource: ResourceStatefulSets,
+			object:   rolloutStatefulSet(onDelete, appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 0, AvailableReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			phase:    RolloutComplete,
+		},
+		{
+			name:     "daemonset complete",
+			resource: ResourceDaemonSets,
+			object:   rolloutDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 4, UpdatedNumberScheduled: 4, NumberReady: 4, NumberAvailable: 4}),
+			phase:    RolloutComplete,
+			progress: 100,
+		},
+		{
+			name:     "daemonset updating",
+			resource: ResourceDaemonSets,
+			object:   rolloutDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 4, UpdatedNumberScheduled: 1, NumberReady: 4, NumberAvailable: 4}),
+			phase:    RolloutProgressing,
+			message:  "1 of 4 pods updated",
+			progress: 25,
+		},
+		{
+			name:     "daemonset waiting for availability",
+			resource: ResourceDaemonSets,
+			object:   rolloutDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 4, UpdatedNumberScheduled: 4, NumberReady: 3, NumberAvailable: 3}),
+			phase:    RolloutProgressing,
+			message:  "3 of 4 updated pods available",
+			progress: 75,
+		},
+		{
+			name:     "daemonset with OnDelete has nothing to wait for",
+			resource: ResourceDaemonSets,
+			object:   rolloutDaemonSet(appsv1.OnDeleteDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 4, UpdatedNumberScheduled: 1, NumberReady: 4, NumberAvailable: 4}),
+			phase:    RolloutComplete,
+			progress: 25,
+		},
+		{
+			name:     "daemonset with OnDelete not yet observed",
+			resource: ResourceDaemonSets,
+			object:   rolloutDaemonSet(appsv1.OnDeleteDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 4, UpdatedNumberScheduled: 4, NumberAvailable: 4}),
+			phase:    RolloutProgressing,
+			message:  "waiting for the rollout to be observed",
+			progress: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tt.object.(metav1.Object).GetName()
+			status, err := rolloutStatus(context.Background(), fake.NewSimpleClientset(tt.object), "kind", tt.resource, "default", name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Phase != tt.phase || status.Message != tt.message || status.Progress != tt.progress {
+				t.Fatalf("phase %q, message %q, progress %d; want %q, %q, %d", status.Phase, status.Message, status.Progress, tt.phase, tt.message, tt.progress)
+			}
+			if status.Cluster != "kind" || status.Name != name {
+				t.Errorf("status = %+v", status)
+			}
+		})
+	}
+
+	if _, err := rolloutStatus(context.Background(), fake.NewSimpleClientset(), "kind", "cronjobs", "default", "backup"); err == nil {
+		t.Error("an unknown resource was accepted")
+	}
+}
+
+func TestListRollouts(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		rolloutDeployment("1", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}),
+		rolloutStatefulSet(appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}, appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3}),
+		rolloutDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2}),
+	)
+	rollouts, err := listRollouts(context.Background(), client, "kind", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, r := range rollouts {
+		kinds = append(kinds, r.Kind+"/"+r.Name)
+	}
+	if want := []string{"Deployment/web", "StatefulSet/db", "DaemonSet/agent"}; !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("rollouts = %v, want %v", kinds, want)
+	}
+	if rollouts[0].Revision != 1 || !reflect.DeepEqual(rollouts[0].Images, []string{"web:3"}) {
+		t.Errorf("deployment = %+v", rollouts[0])
+	}
+}
+
+func replicaSetRevision(revision, image, cause string, owner types.UID) *appsv1.ReplicaSet {
+	template := rolloutTemplate("web", image)
+	template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = "hash-" + revision
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-" + revision,
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "web"},
+			Annotations:     map[string]string{revisionAnnotation: revision},
+			OwnerReferences: controllerRef(owner),
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: template},
+	}
+	if cause != "" {
+		rs.Annotations[changeCauseAnnotation] = cause
+	}
+	return rs
+}
+
+func controllerRevision(app string, revision int64, image string, owner types.UID) *appsv1.ControllerRevision {
+	data := `{"spec":{"template":{"$patch":"replace","metadata":{"labels":{"app":"` + app + `"}},"spec":{"containers":[{"name":"` + app + `","image":"` + image + `"}]}}}}`
+	return &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            app + "-" + strconv.FormatInt(revision, 10),
+			Namespace:       "default",
+			Labels:          map[string]string{"app": app},
+			OwnerReferences: controllerRef(owner),
+		},
+		Data:     runtime.RawExtension{Raw: []byte(data)},
+		Revision: revision,
+	}
+}
+
+// rolloutHistoryFixtures are a Deployment at revision 2 of 3, a
+// StatefulSet updating to revision 2 and a DaemonSet at revision 2, with a
+// ReplicaSet and a ControllerRevision owned by something else
+func rolloutHistoryFixtures() []runtime.Object {
+	deployment := rolloutDeployment("2", appsv1.DeploymentStatus{ObservedGeneration: 2})
+	deployment.Spec.Template = rolloutTemplate("web", "web:2")
+	statefulSet := rolloutStatefulSet(appsv1.StatefulSetUpdateStrategy{}, appsv1.StatefulSetStatus{ObservedGeneration: 2, UpdateRevision: "db-2"})
+	daemonSet := rolloutDaemonSet(appsv1.OnDeleteDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2})
+	return []runtime.Object{
+		deployment,
+		replicaSetRevision("1", "web:1", "first", deployment.UID),
+		replicaSetRevision("3", "web:3", "", deployment.UID),
+		replicaSetRevision("2", "web:2", "second", deployment.UID),
+		replicaSetRevision("9", "web:9", "", "someone-else"),
+		statefulSet,
+		controllerRevision("db", 1, "db:1", statefulSet.UID),
+		controllerRevision("db", 2, "db:2", statefulSet.UID),
+		daemonSet,
+		controllerRevision("agent", 1, "agent:1", daemonSet.UID),
+		controllerRevision("agent", 2, "agent:2", daemonSet.UID),
+		controllerRevision("agent", 3, "agent:3", "someone-else"),
+	}
+}
+
+func TestRolloutHistory(t *testing.T) {
+	client := fake.NewSimpleClientset(rolloutHistoryFixtures()...)
+
+	type revision struct {
+		Revision int64
+		Image    string
+		Cause    string
+		Current  bool
+	}
+	tests := []struct {
+		resource, name string
+		want           []revision
+	}{
+		{ResourceDeployments, "web", []revision{{3, "web:3", "", false}, {2, "web:2", "second", true}, {1, "web:1", "first", false}}},
+		{ResourceStatefulSets, "db", []revision{{2, "db:2", "", true}, {1, "db:1", "", false}}},
+		// DaemonSets do not name their current revision, so it is the newest
+		{ResourceDaemonSets, "agent", []revision{{2, "agent:2", "", true}, {1, "agent:1", "", false}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			revisions, sources, err := rolloutHistory(context.Background(), client, tt.resource, "default", tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []revision
+			for _, r := range revisions {
+				got = append(got, revision{r.Revision, r.Images[0], r.ChangeCause, r.Current})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("history = %+v, want %+v", got, tt.want)
+			}
+			if len(sources) != len(tt.want) {
+				t.Errorf("%d sources for %d revisions", len(sources), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestRollbackWorkload(t *testing.T) {
+	t.Run("deployment to the previous revision", func(t *testing.T) {
+		client := fake.NewSimpleClientset(rolloutHistoryFixtures()...)
+		// Revision 2 is current, so the newest other one is 3
+		revision, err := rollbackWorkload(context.Background(), client, ResourceDeployments, "default", "web", 0)
+		if err != nil || revision != 3 {
+			t.Fatalf("rolled back to %d, %v", revision, err)
+		}
+		d, err := client.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.Spec.Template.Spec.Containers[0].Image; got != "web:3" {
+			t.Errorf("image = %s, want web:3", got)
+		}
+		if _, ok := d.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+			t.Error("the ReplicaSet's pod-template-hash label was copied")
+		}
+	})
+
+	t.Run("deployment to a revision with a change cause", func(t *testing.T) {
+		client := fake.NewSimpleClientset(rolloutHistoryFixtures()...)
+		if _, err := rollbackWorkload(context.Background(), client, ResourceDeployments, "default", "web", 1); err != nil {
+			t.Fatal(err)
+		}
+		d, err := client.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Spec.Template.Spec.Containers[0].Image != "web:1" || d.Annotations[changeCauseAnnotation] != "first" {
+			t.Errorf("deployment = %+v", d)
+		}
+	})
+
+	t.Run("statefulset patch", func(t *testing.T) {
+		client := fake.NewSimpleClientset(rolloutHistoryFixtures()...)
+		revision, err := rollbackWorkload(context.Background(), client, ResourceStatefulSets, "default", "db", 0)
+		if err != nil || revision != 1 {
+			t.Fatalf("rolled back to %d, %v", revision, err)
+		}
+		s, err := client.AppsV1().StatefulSets("default").Get(context.Background(), "db", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := podImages(s.Spec.Template.Spec); !reflect.DeepEqual(got, []string{"db:1"}) {
+			t.Errorf("images = %v, want [db:1]", got)
+		}
+	})
+
+	t.Run("daemonset patch", func(t *testing.T) {
+		client := fake.NewSimpleClientset(rolloutHistoryFixtures()...)
+		if _, err := rollbackWorkload(context.Background(), client, ResourceDaemonSets, "default", "agent", 1); err != nil {
+			t.Fatal(err)
+		}
+		d, err := client.AppsV1().DaemonSets("default").Get(context.Background(), "agent", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := podImages(d.Spec.Template.Spec); !reflect.DeepEqual(got, []string{"agent:1"}) {
+			t.Errorf("images = %v, want [agent:1]", got)
+		}
+	})
+
+	failures := []struct {
+		name     string
+		objects  []runtime.Object
+		resource string
+		workload string
+		revision int64
+		err      error
+	}{
+		{"missing revision", rolloutHistoryFixtures(), ResourceDeployments, "web", 7, ErrRevisionNotFound},
+		// Revision 3 belongs to a DaemonSet this one does not own
+		{"another owner's revision", rolloutHistoryFixtures(), ResourceDaemonSets, "agent", 3, ErrRevisionNotFound},
+		{"no previous revision", []runtime.Object{rolloutDeployment("1", appsv1.DeploymentStatus{})}, ResourceDeployments, "web", 0, ErrRevisionNotFound},
+		{"paused deployment", func() []runtime.Object {
+			objects := rolloutHistoryFixtures()
+			objects[0].(*appsv1.Deployment).Spec.Paused = true
+			return objects
+		}(), ResourceDeployments, "web", 1, ErrRolloutPaused},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.objects...)
+			if _, err := rollbackWorkload(context.Background(), client, tt.resource, "default", tt.workload, tt.revision); !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			for _, action := range client.Actions() {
+				if verb := action.GetVerb(); verb == "update" || verb == "patch" {
+					t.Fatalf("the workload was changed: %v", action)
+				}
+			}
+		})
+	}
+}
+
+func TestPauseRollout(t *testing.T) {
+	client := fake.NewSimpleClientset(rolloutHistoryFixtures()...)
+	for _, paused := range []bool{true, false} {
+		if err := pauseRollout(context.Background(), client, ResourceDeployments, "default", "web", paused); err != nil {
+			t.Fatal(err)
+		}
+		d, err := client.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Spec.Paused != paused {
+			t.Fatalf("paused = %v, want %v", d.Spec.Paused, paused)
+		}
+	}
+
+	for _, resource := range []string{ResourceStatefulSets, ResourceDaemonSets} {
+		if err := pauseRollout(context.Background(), client, resource, "default", "db", true); !errors.Is(err, ErrRolloutUnsupported) {
+			t.Errorf("pausing %s: error = %v, want ErrRolloutUnsupported", resource, err)
+		}
+	}
+}
+
+func TestRestartWorkload(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	client := fake.NewSimpleClientset(rolloutHistoryFixtures()...)
+	ctx := context.Background()
+
+	templates := map[string]func() (corev1.PodTemplateSpec, error){
+		"web": func() (corev1.PodTemplateSpec, error) {
+			if err := restartWorkload(ctx, client, ResourceDeployments, "default", "web", now); err != nil {
+				return corev1.PodTemplateSpec{}, err
+			}
+			d, err := client.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+			if err != nil {
+				return corev1.PodTemplateSpec{}, err
+			}
+			return d.Spec.Template, nil
+		},
+		"db": func() (corev1.PodTemplateSpec, error) {
+			if err := restartWorkload(ctx, client, ResourceStatefulSets, "default", "db", now); err != nil {
+				return corev1.PodTemplateSpec{}, err
+			}
+			s, err := client.AppsV1().StatefulSets("default").Get(ctx, "db", metav1.GetOptions{})
+			if err != nil {
+				return corev1.PodTemplateSpec{}, err
+			}
+			return s.Spec.Template, nil
+		},
+		"agent": func() (corev1.PodTemplateSpec, error) {
+			if err := restartWorkload(ctx, client, ResourceDaemonSets, "default", "agent", now); err != nil {
+				return corev1.PodTemplateSpec{}, err
+			}
+			d, err := client.AppsV1().DaemonSets("default").Get(ctx, "agent", metav1.GetOptions{})
+			if err != nil {
+				return corev1.PodTemplateSpec{}, err
+			}
+			return d.Spec.Template, nil
+		},
+	}
+	for name, restart := range templates {
+		template, err := restart()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := template.Annotations[restartedAtAnnotation]; got != "2026-10-19T12:00:00Z" {
+			t.Errorf("%s restartedAt = %q", name, got)
+		}
+		// The rest of the template is left alone
+		if template.Labels["app"] != name || len(template.Spec.Containers) != 1 {
+			t.Errorf("%s template = %+v", name, template)
+		}
+	}
+
+	if err := restartWorkload(ctx, client, "cronjobs", "default", "backup", now); err == nil {
+		t.Error("an unknown resource was accepted")
+	}
+}
@@ -0,0 +1,355 @@
+package k8s
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Secret hygiene finding types
+const (
+	SecretFindingLegacyToken  = "legacy-sa-token"
+	SecretFindingAutomount    = "automount-powerful-sa"
+	SecretFindingUnusedSA     = "unused-service-account"
+	SecretFindingMissingPull  = "missing-pull-secret"
+	SecretFindingUnusedPull   = "unused-pull-secret"
+	SecretFindingCertExpiring = "tls-expiring"
+	SecretFindingCertExpired  = "tls-expired"
+	SecretFindingCertInvalid  = "tls-invalid"
+)
+
+// DefaultCertExpiryDays is how soon a TLS certificate must expire to be
+// reported when no window is given
+const DefaultCertExpiryDays = 30
+
+// legacyTokenLastUsedLabel is set by the API server on legacy token
+// secrets when they are used, to the day
+const legacyTokenLastUsedLabel = "kubernetes.io/legacy-token-last-used"
+
+// SecretFinding is a hygiene problem with a Secret or ServiceAccount
+type SecretFinding struct {
+	Type        string `json:"type"`
+	Severity    string `json:"severity"` // one of the RBACRisk* severities
+	Kind        string `json:"kind"`     // Secret or ServiceAccount
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+	// ExpiresAt is the certificate expiry of TLS findings
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// ReferencedBy names the pods and service accounts that use a missing
+	// pull secret or mount a powerful token
+	ReferencedBy []string `json:"referencedBy,omitempty"`
+}
+
+// SecretHygieneSummary counts what was scanned and the findings by severity
+type SecretHygieneSummary struct {
+	Secrets         int `json:"secrets"`
+	ServiceAccounts int `json:"serviceAccounts"`
+	Critical        int `json:"critical"`
+	High            int `json:"high"`
+	Medium          int `json:"medium"`
+	Low             int `json:"low"`
+}
+
+// SecretHygieneReport is the secret hygiene of a cluster, worst first
+type SecretHygieneReport struct {
+	Cluster    string               `json:"cluster"`
+	ExpiryDays int                  `json:"expiryDays"`
+	Findings   []SecretFinding      `json:"findings"`
+	Summary    SecretHygieneSummary `json:"summary"`
+}
+
+// ScanSecretHygiene reports legacy service account tokens, tokens mounted
+// for powerful service accounts, unused service accounts, missing or
+// unused image pull secrets, and TLS certificates expiring within
+// expiryDays. System namespaces are left out unless includeSystem is set.
+func (m *MultiClusterClient) ScanSecretHygiene(ctx context.Context, contextName string, expiryDays int, includeSystem bool) (*SecretHygieneReport, error) {
+	ctx, span := startSpan(ctx, "ScanSecretHygiene", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return scanSecretHygiene(ctx, client, contextName, expiryDays, includeSystem, time.Now())
+}
+
+func scanSecretHygiene(ctx context.Context, client kubernetes.Interface, contextName string, expiryDays int, includeSystem bool, now time.Time) (*SecretHygieneReport, error) {
+	snapshot, err := loadRBAC(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := client.CoreV1().Secrets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list secrets: %w", err)
+	}
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	report := &SecretHygieneReport{Cluster: contextName, ExpiryDays: expiryDays, Findings: []SecretFinding{}}
+	skip := func(namespace string) bool {
+		return !includeSystem && strings.HasPrefix(namespace, "kube-")
+	}
+
+	// Service accounts holding critical or high risk permissions
+	powerful := map[string]SubjectPermissions{}
+	for _, sp := range analyzeRBAC(contextName, snapshot, true).Subjects {
+		if sp.Kind == "ServiceAccount" && (sp.RiskLevel == RBACRiskCritical || sp.RiskLevel == RBACRiskHigh) {
+			powerful[sp.Namespace+"/"+sp.Name] = sp
+		}
+	}
+
+	// Who uses each service account and pull secret, by namespace/name
+	saPods := map[string][]string{}
+	pullRefs := map[string][]string{}
+	for _, pod := range pods.Items {
+		saName := pod.Spec.ServiceAccountName
+		if saName == "" {
+			saName = "default"
+		}
+		saKey := pod.Namespace + "/" + saName
+		// A pod can opt out of the token its service account would mount
+		if pod.Spec.AutomountServiceAccountToken == nil || *pod.Spec.AutomountServiceAccountToken {
+			saPods[saKey] = append(saPods[saKey], "Pod "+pod.Name)
+		} else if _, ok := saPods[saKey]; !ok {
+			saPods[saKey] = []string{}
+		}
+		for _, ref := range pod.Spec.ImagePullSecrets {
+			key := pod.Namespace + "/" + ref.Name
+			pullRefs[key] = append(pullRefs[key], "Pod "+pod.Name)
+		}
+	}
+
+	saNames := make([]string, 0, len(snapshot.serviceAccounts))
+	for key := range snapshot.serviceAccounts {
+		saNames = append(saNames, key)
+	}
+	sort.Strings(saNames)
+	for _, key := range saNames {
+		sa := snapshot.serviceAccounts[key]
+		for _, ref := range sa.ImagePullSecrets {
+			refKey := sa.Namespace + "/" + ref.Name
+			pullRefs[refKey] = append(pullRefs[refKey], "ServiceAccount "+sa.Name)
+		}
+		if skip(sa.Namespace) {
+			continue
+		}
+		report.Summary.ServiceAccounts++
+
+		mountedBy, used := saPods[key]
+		sp, isPowerful := powerful[key]
+		if !used && sa.Name != "default" {
+			severity := RBACRiskLow
+			if isPowerful {
+				severity = RBACRiskMedium
+			}
+			report.Findings = append(report.Findings, SecretFinding{
+				Type:        SecretFindingUnusedSA,
+				Severity:    severity,
+				Kind:        "ServiceAccount",
+				Namespace:   sa.Namespace,
+				Name:        sa.Name,
+				Message:     "No pod runs as this service account",
+				Remediation: "Delete the service account and its bindings if nothing outside the cluster uses it",
+			})
+		}
+		if isPowerful && (sa.AutomountServiceAccountToken == nil || *sa.AutomountServiceAccountToken) {
+			report.Findings = append(report.Findings, SecretFinding{
+				Type:         SecretFindingAutomount,
+				Severity:     sp.RiskLevel,
+				Kind:         "ServiceAccount",
+				Namespace:    sa.Namespace,
+				Name:         sa.Name,
+				Message:      "Token is mounted automatically for an account with risky permissions: " + riskReasons(sp.Risks),
+				Remediation:  "Set automountServiceAccountToken: false on the service account and mount a token only in pods that call the API",
+				ReferencedBy: mountedBy,
+			})
+		}
+	}
+
+	secretKeys := map[string]bool{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		secretKeys[secret.Namespace+"/"+secret.Name] = true
+		if skip(secret.Namespace) {
+			continue
+		}
+		report.Summary.Secrets++
+
+		switch secret.Type {
+		case corev1.SecretTypeServiceAccountToken:
+			report.Findings = append(report.Findings, legacyTokenFinding(secret, powerful, now))
+		case corev1.SecretTypeDockerConfigJson, corev1.SecretTypeDockercfg:
+			if len(pullRefs[secret.Namespace+"/"+secret.Name]) == 0 {
+				report.Findings = append(report.Findings, SecretFinding{
+					Type:        SecretFindingUnusedPull,
+					Severity:    RBACRiskLow,
+					Kind:        "Secret",
+					Namespace:   secret.Namespace,
+					Name:        secret.Name,
+					Message:     "No pod or service account uses this image pull secret",
+					Remediation: "Delete the secret, or rotate the registry credentials if they are no longer needed",
+				})
+			}
+		}
+		if _, ok := secret.Data[corev1.TLSCertKey]; ok || secret.Type == corev1.SecretTypeTLS {
+			if finding := certFinding(secret, expiryDays, now); finding != nil {
+				report.Findings = append(report.Findings, *finding)
+			}
+		}
+	}
+
+	for key, refs := range pullRefs {
+		namespace, name, _ := strings.Cut(key, "/")
+		if secretKeys[key] || skip(namespace) {
+			continue
+		}
+		sort.Strings(refs)
+		report.Findings = append(report.Findings, SecretFinding{
+			Type:         SecretFindingMissingPull,
+			Severity:     RBACRiskMedium,
+			Kind:         "Secret",
+			Namespace:    namespace,
+			Name:         name,
+			Message:      "Image pull secret does not exist",
+			Remediation:  "Create the secret or remove it from imagePullSecrets; pulls from private registries will fail",
+			ReferencedBy: refs,
+		})
+	}
+
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Severity != b.Severity {
+			return rbacRiskWeights[a.Severity] > rbacRiskWeights[b.Severity]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Type < b.Type
+	})
+	for _, f := range report.Findings {
+		switch f.Severity {
+		case RBACRiskCritical:
+			report.Summary.Critical++
+		case RBACRiskHigh:
+			report.Summary.High++
+		case RBACRiskMedium:
+			report.Summary.Medium++
+		default:
+			report.Summary.Low++
+		}
+	}
+	return report, nil
+}
+
+// legacyTokenFinding reports a long-lived token secret, more severe when
+// its service account is powerful
+func legacyTokenFinding(secret *corev1.Secret, powerful map[string]SubjectPermissions, now time.Time) SecretFinding {
+	saName := secret.Annotations[corev1.ServiceAccountNameKey]
+	age := int(now.Sub(secret.CreationTimestamp.Time).Hours() / 24)
+	message := fmt.Sprintf("Long-lived token for service account %q, created %d days ago", saName, age)
+	if lastUsed := secret.Labels[legacyTokenLastUsedLabel]; lastUsed != "" {
+		message += ", last used " + lastUsed
+	}
+
+	severity := RBACRiskMedium
+	if sp, ok := powerful[secret.Namespace+"/"+saName]; ok {
+		severity = sp.RiskLevel
+		message += "; the account has risky permissions: " + riskReasons(sp.Risks)
+	}
+	return SecretFinding{
+		Type:        SecretFindingLegacyToken,
+		Severity:    severity,
+		Kind:        "Secret",
+		Namespace:   secret.Namespace,
+		Name:        secret.Name,
+		Message:     message,
+		Remediation: "Delete the secret and use short-lived tokens from the TokenRequest API (kubectl create token) instead",
+	}
+}
+
+// certFinding parses the leaf certificate of a TLS secret and reports it
+// if it is unreadable, expired or expires within expiryDays
+func certFinding(secret *corev1.Secret, expiryDays int, now time.Time) *SecretFinding {
+	finding := &SecretFinding{
+		Kind:      "Secret",
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+	}
+
+	cert, err := parseLeafCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		finding.Type = SecretFindingCertInvalid
+		finding.Severity = RBACRiskLow
+		finding.Message = "Certificate cannot be read: " + err.Error()
+		finding.Remediation = "Store a PEM encoded certificate in " + corev1.TLSCertKey
+		return finding
+	}
+
+	expires := cert.NotAfter
+	finding.ExpiresAt = &expires
+	name := cert.Subject.CommonName
+	if name == "" && len(cert.DNSNames) > 0 {
+		name = cert.DNSNames[0]
+	}
+	remaining := expires.Sub(now)
+	switch {
+	case remaining <= 0:
+		finding.Type = SecretFindingCertExpired
+		finding.Severity = RBACRiskCritical
+		finding.Message = fmt.Sprintf("Certificate for %q expired on %s", name, expires.Format(time.DateOnly))
+	case remaining <= time.Duration(expiryDays)*24*time.Hour:
+		finding.Type = SecretFindingCertExpiring
+		finding.Severity = RBACRiskHigh
+		days := int(math.Ceil(remaining.Hours() / 24))
+		finding.Message = fmt.Sprintf("Certificate for %q expires in %d days, on %s", name, days, expires.Format(time.DateOnly))
+	default:
+		return nil
+	}
+	finding.Remediation = "Renew the certificate, or check that cert-manager or the issuing process is renewing it"
+	return finding
+}
+
+// parseLeafCertificate returns the first certificate of a PEM bundle
+func parseLeafCertificate(data []byte) (*x509.Certificate, error) {
+	for len(data) > 0 {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+		data = rest
+	}
+	return nil, fmt.Errorf("no PEM certificate found")
+}
+
+// riskReasons lists the distinct critical and high RBAC risks of a subject
+func riskReasons(risks []RBACRisk) string {
+	var reasons []string
+	seen := map[string]bool{}
+	for _, risk := range risks {
+		if (risk.Severity != RBACRiskCritical && risk.Severity != RBACRiskHigh) || seen[risk.Reason] {
+			continue
+		}
+		seen[risk.Reason] = true
+		reasons = append(reasons, risk.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,281 @@
+package k8s
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// hygieneNow is the time secret hygiene scans run at in tests
+var hygieneNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+// certPEM returns a PEM encoded self-signed certificate
+func certPEM(t *testing.T, commonName string, dnsNames []string, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func hygieneSecret(namespace, name string, secretType corev1.SecretType, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Type:       secretType,
+		Data:       data,
+	}
+}
+
+func legacyToken(namespace, name, serviceAccount string, created time.Time, lastUsed string) *corev1.Secret {
+	secret := hygieneSecret(namespace, name, corev1.SecretTypeServiceAccountToken, nil)
+	secret.Annotations = map[string]string{corev1.ServiceAccountNameKey: serviceAccount}
+	secret.CreationTimestamp = metav1.NewTime(created)
+	if lastUsed != "" {
+		secret.Labels = map[string]string{legacyTokenLastUsedLabel: lastUsed}
+	}
+	return secret
+}
+
+func hygienePod(namespace, name, serviceAccount string, pullSecrets ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.PodSpec{ServiceAccountName: serviceAccount},
+	}
+	for _, name := range pullSecrets {
+		pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+	return pod
+}
+
+// secretHygieneFixtures adds to rbacFixtures, where prometheus in
+// monitoring can read secrets cluster-wide:
+//   - legacy tokens for prometheus and the unprivileged builder
+//   - builder, which no pod runs as and which names a missing pull secret
+//   - runner, whose only pod opts out of the token
+//   - a used, an unused and a missing pull secret in team-b
+//   - expired, expiring, valid chained and unreadable certificates
+//   - an unused service account and a token in kube-system
+func secretHygieneFixtures(t *testing.T) []runtime.Object {
+	keyBlock := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")})
+	expiring := append(keyBlock, certPEM(t, "", []string{"expiring.example.com"}, hygieneNow.AddDate(0, 0, 10))...)
+	// The CA after the leaf has expired, but only the leaf counts
+	chained := append(certPEM(t, "valid.example.com", nil, hygieneNow.AddDate(0, 0, 200)), certPEM(t, "Example CA", nil, hygieneNow.AddDate(0, 0, -1))...)
+
+	optOut := hygienePod("team-b", "runner-0", "runner")
+	optOut.Spec.AutomountServiceAccountToken = ptr(false)
+
+	return append(rbacFixtures(),
+		&corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "team-b"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "missing-creds"}},
+		},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "team-b"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "runner", Namespace: "team-b"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "leftover", Namespace: "kube-system"}},
+
+		hygienePod("monitoring", "prometheus-0", "prometheus"),
+		hygienePod("team-b", "web", "", "registry", "missing-creds"),
+		optOut,
+
+		legacyToken("monitoring", "prometheus-token", "prometheus", hygieneNow.AddDate(0, 0, -100), "2026-09-01"),
+		legacyToken("team-b", "builder-token", "builder", hygieneNow.AddDate(0, 0, -3), ""),
+		legacyToken("kube-system", "leftover-token", "leftover", hygieneNow.AddDate(-2, 0, 0), ""),
+		hygieneSecret("team-b", "registry", corev1.SecretTypeDockerConfigJson, nil),
+		hygieneSecret("team-b", "old-registry", corev1.SecretTypeDockercfg, nil),
+		hygieneSecret("team-b", "expired", corev1.SecretTypeTLS, map[string][]byte{
+			corev1.TLSCertKey: certPEM(t, "expired.example.com", nil, hygieneNow.AddDate(0, 0, -18)),
+		}),
+		hygieneSecret("team-b", "expiring", corev1.SecretTypeTLS, map[string][]byte{corev1.TLSCertKey: expiring}),
+		// Opaque secrets with a tls.crt key are checked too
+		hygieneSecret("team-b", "chained", corev1.SecretTypeOpaque, map[string][]byte{corev1.TLSCertKey: chained}),
+		hygieneSecret("team-b", "broken", corev1.SecretTypeTLS, map[string][]byte{corev1.TLSCertKey: []byte("not a certificate")}),
+	)
+}
+
+func TestScanSecretHygiene(t *testing.T) {
+	client := fake.NewSimpleClientset(secretHygieneFixtures(t)...)
+	report, err := scanSecretHygiene(context.Background(), client, "kind", DefaultCertExpiryDays, false, hygieneNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, f.Severity+" "+f.Type+" "+f.Namespace+"/"+f.Name)
+	}
+	want := []string{
+		"critical tls-expired team-b/expired",
+		// The legacy token and automount of a powerful account take its
+		// severity
+		"high automount-powerful-sa monitoring/prometheus",
+		"high legacy-sa-token monitoring/prometheus-token",
+		"high tls-expiring team-b/expiring",
+		"medium legacy-sa-token team-b/builder-token",
+		"medium missing-pull-secret team-b/missing-creds",
+		"low tls-invalid team-b/broken",
+		"low unused-service-account team-b/builder",
+		"low unused-pull-secret team-b/old-registry",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("findings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	byName := map[string]SecretFinding{}
+	for _, f := range report.Findings {
+		byName[f.Type+" "+f.Name] = f
+	}
+	if f := byName["automount-powerful-sa prometheus"]; !reflect.DeepEqual(f.ReferencedBy, []string{"Pod prometheus-0"}) || !strings.HasSuffix(f.Message, "Can read secrets") {
+		t.Errorf("automount finding = %+v", f)
+	}
+	if f := byName["legacy-sa-token prometheus-token"]; f.Message != `Long-lived token for service account "prometheus", created 100 days ago, last used 2026-09-01; the account has risky permissions: Can read secrets` {
+		t.Errorf("legacy token message = %q", f.Message)
+	}
+	if f := byName["missing-pull-secret missing-creds"]; !reflect.DeepEqual(f.ReferencedBy, []string{"Pod web", "ServiceAccount builder"}) {
+		t.Errorf("missing pull secret referenced by %v", f.ReferencedBy)
+	}
+
+	wantSummary := SecretHygieneSummary{Secrets: 8, ServiceAccounts: 4, Critical: 1, High: 3, Medium: 2, Low: 3}
+	if report.Summary != wantSummary {
+		t.Errorf("summary = %+v, want %+v", report.Summary, wantSummary)
+	}
+
+	withSystem, err := scanSecretHygiene(context.Background(), client, "kind", DefaultCertExpiryDays, true, hygieneNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withSystem.Summary.Secrets != 9 || withSystem.Summary.ServiceAccounts != 5 || len(withSystem.Findings) != len(want)+2 {
+		t.Errorf("with system namespaces: %+v, %d findings", withSystem.Summary, len(withSystem.Findings))
+	}
+}
+
+func TestCertFinding(t *testing.T) {
+	tests := []struct {
+		name string
+		cert []byte
+		// typ is empty when nothing should be reported
+		typ     string
+		message string
+	}{
+		{
+			name:    "expired",
+			cert:    certPEM(t, "expired.example.com", nil, hygieneNow.Add(-time.Hour)),
+			typ:     SecretFindingCertExpired,
+			message: `Certificate for "expired.example.com" expired on 2026-10-19`,
+		},
+		{
+			name:    "expiring, named by its first DNS name",
+			cert:    certPEM(t, "", []string{"api.example.com", "www.example.com"}, hygieneNow.Add(9*24*time.Hour+time.Hour)),
+			typ:     SecretFindingCertExpiring,
+			message: `Certificate for "api.example.com" expires in 10 days, on 2026-10-28`,
+		},
+		{
+			name:    "expiring at the end of the window",
+			cert:    certPEM(t, "edge.example.com", nil, hygieneNow.AddDate(0, 0, 30)),
+			typ:     SecretFindingCertExpiring,
+			message: `Certificate for "edge.example.com" expires in 30 days, on 2026-11-18`,
+		},
+		{
+			name: "valid past the window",
+			cert: certPEM(t, "valid.example.com", nil, hygieneNow.AddDate(0, 0, 31)),
+		},
+		{
+			name: "the leaf of a chain",
+			cert: append(certPEM(t, "leaf.example.com", nil, hygieneNow.AddDate(1, 0, 0)), certPEM(t, "Expired CA", nil, hygieneNow.AddDate(0, 0, -1))...),
+		},
+		{
+			name:    "not PEM",
+			cert:    []byte("not a certificate"),
+			typ:     SecretFindingCertInvalid,
+			message: "Certificate cannot be read: no PEM certificate found",
+		},
+		{
+			name:    "PEM without a certificate",
+			cert:    pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}),
+			typ:     SecretFindingCertInvalid,
+			message: "Certificate cannot be read: no PEM certificate found",
+		},
+		{
+			name: "corrupt certificate",
+			cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}),
+			typ:  SecretFindingCertInvalid,
+		},
+		{
+			name:    "missing",
+			typ:     SecretFindingCertInvalid,
+			message: "Certificate cannot be read: no PEM certificate found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := hygieneSecret("default", "tls", corev1.SecretTypeTLS, map[string][]byte{corev1.TLSCertKey: tt.cert})
+			finding := certFinding(secret, DefaultCertExpiryDays, hygieneNow)
+			if tt.typ == "" {
+				if finding != nil {
+					t.Fatalf("unexpected finding %+v", finding)
+				}
+				return
+			}
+			if finding == nil || finding.Type != tt.typ {
+				t.Fatalf("finding = %+v, want %s", finding, tt.typ)
+			}
+			if tt.message != "" && finding.Message != tt.message {
+				t.Errorf("message = %q, want %q", finding.Message, tt.message)
+			}
+			if (finding.ExpiresAt == nil) != (tt.typ == SecretFindingCertInvalid) {
+				t.Errorf("expiresAt = %v", finding.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestParseLeafCertificate(t *testing.T) {
+	leaf := certPEM(t, "leaf.example.com", nil, hygieneNow)
+	ca := certPEM(t, "Example CA", nil, hygieneNow)
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")})
+
+	for name, bundle := range map[string][]byte{
+		"leaf only":           leaf,
+		"leaf then CA":        append(append([]byte{}, leaf...), ca...),
+		"key before the leaf": append(append([]byte{}, key...), leaf...),
+	} {
+		cert, err := parseLeafCertificate(bundle)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if cert.Subject.CommonName != "leaf.example.com" {
+			t.Errorf("%s: parsed %q", name, cert.Subject.CommonName)
+		}
+	}
+
+	// Text around the blocks, as in openssl output, is ignored
+	withText := append([]byte("subject=CN = leaf.example.com\n"), leaf...)
+	if _, err := parseLeafCertificate(withText); err != nil {
+		t.Errorf("leading text: %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Upgrade phases of a cluster
+const (
+	// UpgradePhaseCurrent means every kubelet runs the control plane version
+	UpgradePhaseCurrent = "current"
+	// UpgradePhasePending means the control plane has been upgraded and the
+	// nodes, all on the same older version, have not
+	UpgradePhasePending = "pending"
+	// UpgradePhaseInProgress means nodes run mixed kubelet versions, or
+	// nodes are cordoned while behind the control plane
+	UpgradePhaseInProgress = "in-progress"
+)
+
+// NodeVersion is a node's kubelet and runtime versions and their skew from
+// the control plane
+type NodeVersion struct {
+	Name             string `json:"name"`
+	Cluster          string `json:"cluster"`
+	KubeletVersion   string `json:"kubeletVersion"`
+	ContainerRuntime string `json:"containerRuntime"`
+	OSImage          string `json:"osImage,omitempty"`
+	KernelVersion    string `json:"kernelVersion,omitempty"`
+	Ready            bool   `json:"ready"`
+	Cordoned         bool   `json:"cordoned"`
+	// MinorSkew is how many minor versions the kubelet is behind the API
+	// server; negative when it is ahead
+	MinorSkew     int    `json:"minorSkew"`
+	SkewSupported bool   `json:"skewSupported"`
+	SkewReason    string `json:"skewReason,omitempty"`
+}
+
+// ClusterUpgradeStatus is a cluster's version skew and upgrade progress
+type ClusterUpgradeStatus struct {
+	Cluster             string        `json:"cluster"`
+	ControlPlaneVersion string        `json:"controlPlaneVersion"`
+	Platform            string        `json:"platform,omitempty"`
+	Phase               string        `json:"phase"`
+	Nodes               []NodeVersion `json:"nodes"`
+	// KubeletVersions counts nodes per kubelet version
+	KubeletVersions map[string]int `json:"kubeletVersions"`
+	// NodesUpgraded counts kubelets on the control plane version
+	NodesUpgraded  int `json:"nodesUpgraded"`
+	NodesOutOfSkew int `json:"nodesOutOfSkew"`
+	CordonedNodes  int `json:"cordonedNodes"`
+}
+
+// maxKubeletSkew is how many minor versions a kubelet may be older than the
+// API server: three since Kubernetes 1.28, two before
+func maxKubeletSkew(apiServer *version.Version) uint {
+	if apiServer.Major() > 1 || apiServer.Minor() >= 28 {
+		return 3
+	}
+	return 2
+}
+
+// GetUpgradeStatus compares each node's kubelet with the API server version
+// against the Kubernetes version skew policy and infers whether an upgrade
+// is under way
+func (m *MultiClusterClient) GetUpgradeStatus(ctx context.Context, contextName string) (*ClusterUpgradeStatus, error) {
+	ctx, span := startSpan(ctx, "GetUpgradeStatus", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return upgradeStatus(contextName, info.GitVersion, info.Platform, nodes.Items)
+}
+
+// upgradeStatus computes the upgrade status of nodes against the API server
+// version
+func upgradeStatus(contextName, apiServerVersion, platform string, nodes []corev1.Node) (*ClusterUpgradeStatus, error) {
+	apiServer, err := version.ParseGeneric(apiServerVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unparseable API server version %q: %w", apiServerVersion, err)
+	}
+	maxSkew := maxKubeletSkew(apiServer)
+
+	status := &ClusterUpgradeStatus{
+		Cluster:             contextName,
+		ControlPlaneVersion: apiServerVersion,
+		Platform:            platform,
+		Nodes:               []NodeVersion{},
+		KubeletVersions:     map[string]int{},
+	}
+
+	behind := 0
+	// Distinct kubelet releases, ignoring distribution build suffixes
+	releases := map[string]bool{}
+	for i := range nodes {
+		node := &nodes[i]
+		info := node.Status.NodeInfo
+		nv := NodeVersion{
+			Name:             node.Name,
+			Cluster:          contextName,
+			KubeletVersion:   info.KubeletVersion,
+			ContainerRuntime: info.ContainerRuntimeVersion,
+			OSImage:          info.OSImage,
+			KernelVersion:    info.KernelVersion,
+			Ready:            nodeReady(node),
+			Cordoned:         node.Spec.Unschedulable,
+			SkewSupported:    true,
+		}
+		status.KubeletVersions[info.KubeletVersion]++
+		if nv.Cordoned {
+			status.CordonedNodes++
+		}
+
+		kubelet, err := version.ParseGeneric(info.KubeletVersion)
+		switch {
+		case err != nil:
+			nv.SkewSupported = false
+			nv.SkewReason = fmt.Sprintf("unparseable kubelet version %q", info.KubeletVersion)
+		case kubelet.Major() != apiServer.Major():
+			nv.SkewSupported = false
+			nv.SkewReason = "kubelet major version differs from the API server"
+		default:
+			releases[fmt.Sprintf("%d.%d.%d", kubelet.Major(), kubelet.Minor(), kubelet.Patch())] = true
+			nv.MinorSkew = int(apiServer.Minor()) - int(kubelet.Minor())
+			switch {
+			case nv.MinorSkew < 0:
+				nv.SkewSupported = false
+				nv.SkewReason = "kubelet is newer than the API server"
+			case nv.MinorSkew > int(maxSkew):
+				nv.SkewSupported = false
+				nv.SkewReason = fmt.Sprintf("kubelet is %d minor versions behind the API server; at most %d are supported", nv.MinorSkew, maxSkew)
+			}
+			if kubelet.LessThan(apiServer) && !sameRelease(kubelet, apiServer) {
+				behind++
+			} else {
+				status.NodesUpgraded++
+			}
+		}
+		if !nv.SkewSupported {
+			status.NodesOutOfSkew++
+		}
+		status.Nodes = append(status.Nodes, nv)
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool {
+		return status.Nodes[i].Name < status.Nodes[j].Name
+	})
+
+	switch {
+	case len(releases) > 1:
+		status.Phase = UpgradePhaseInProgress
+	case behind > 0 && status.CordonedNodes > 0:
+		status.Phase = UpgradePhaseInProgress
+	case behind > 0:
+		status.Phase = UpgradePhasePending
+	default:
+		status.Phase = UpgradePhaseCurrent
+	}
+
+	return status, nil
+}
+
+// sameRelease reports whether two versions share major, minor and patch,
+// ignoring distribution suffixes such as -eks-1234
+func sameRelease(a, b *version.Version) bool {
+	return a.Major() == b.Major() && a.Minor() == b.Minor() && a.Patch() == b.Patch()
+}
@@ -0,0 +1,282 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Usage is read from the metrics.k8s.io API served by metrics-server. CPU
+// is in millicores and memory in bytes, as in ResourceAmounts.
+
+// ResourceUsage compares a resource's usage with its request and limit.
+// The ratios are omitted when no request or limit is set.
+type ResourceUsage struct {
+	Usage        int64   `json:"usage"`
+	Request      int64   `json:"request"`
+	Limit        int64   `json:"limit"`
+	RequestRatio float64 `json:"requestRatio,omitempty"`
+	LimitRatio   float64 `json:"limitRatio,omitempty"`
+}
+
+func newResourceUsage(usage, request, limit int64) ResourceUsage {
+	u := ResourceUsage{Usage: usage, Request: request, Limit: limit}
+	if request > 0 {
+		u.RequestRatio = float64(usage) / float64(request)
+	}
+	if limit > 0 {
+		u.LimitRatio = float64(usage) / float64(limit)
+	}
+	return u
+}
+
+// PodUsage is a pod's CPU and memory usage against its requests and limits
+type PodUsage struct {
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace"`
+	Cluster   string        `json:"cluster"`
+	Node      string        `json:"node,omitempty"`
+	Status    string        `json:"status"`
+	Restarts  int           `json:"restarts"`
+	CPU       ResourceUsage `json:"cpu"`
+	Memory    ResourceUsage `json:"memory"`
+}
+
+// PodUsageList holds the pods of a cluster with their usage.
+// MetricsAvailable is false when the cluster serves no metrics.k8s.io API
+// (typically metrics-server is not installed); usage is then zero and only
+// restarts can be ranked.
+type PodUsageList struct {
+	Cluster          string     `json:"cluster"`
+	Items            []PodUsage `json:"items"`
+	MetricsAvailable bool       `json:"metricsAvailable"`
+	MetricsError     string     `json:"metricsError,omitempty"`
+}
+
+// NodeUsage is a node's CPU and memory usage against its allocatable
+// resources, reported as the usage's Limit
+type NodeUsage struct {
+	Name    string        `json:"name"`
+	Cluster string        `json:"cluster"`
+	CPU     ResourceUsage `json:"cpu"`
+	Memory  ResourceUsage `json:"memory"`
+}
+
+// NodeUsageList holds the nodes of a cluster with their usage, see
+// PodUsageList for MetricsAvailable
+type NodeUsageList struct {
+	Cluster          string      `json:"cluster"`
+	Items            []NodeUsage `json:"items"`
+	MetricsAvailable bool        `json:"metricsAvailable"`
+	MetricsError     string      `json:"metricsError,omitempty"`
+}
+
+// Top pod rankings
+const (
+	TopByCPU      = "cpu"
+	TopByMemory   = "memory"
+	TopByRestarts = "restarts"
+)
+
+// GetMetricsClient returns a metrics.k8s.io client for the specified context
+func (m *MultiClusterClient) GetMetricsClient(ctx context.Context, contextName string) (metricsclient.Interface, error) {
+	// GetClient builds and caches the context's config
+	if _, err := m.GetClient(ctx, contextName); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if mc, ok := m.metricsClients[contextName]; ok {
+		return mc, nil
+	}
+	config, ok := m.configs[contextName]
+	if !ok {
+		return nil, fmt.Errorf("no config for context %s", contextName)
+	}
+	mc, err := metricsclient.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client for context %s: %w", contextName, err)
+	}
+	if m.metricsClients == nil {
+		m.metricsClients = make(map[string]metricsclient.Interface)
+	}
+	m.metricsClients[contextName] = mc
+	return mc, nil
+}
+
+// GetPodUsage returns the running pods of a cluster joined with their
+// PodMetrics
+func (m *MultiClusterClient) GetPodUsage(ctx context.Context, contextName, namespace string) (*PodUsageList, error) {
+	ctx, span := startSpan(ctx, "GetPodUsage", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := m.GetMetricsClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return podUsage(ctx, client, mc, contextName, namespace)
+}
+
+// podUsage is GetPodUsage for any clientsets; the tests pass fakes
+func podUsage(ctx context.Context, client kubernetes.Interface, mc metricsclient.Interface, contextName, namespace string) (*PodUsageList, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase=Running",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PodUsageList{Cluster: contextName, Items: []PodUsage{}}
+
+	usage := map[string]corev1.ResourceList{}
+	podMetrics, err := mc.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	switch {
+	case err == nil:
+		result.MetricsAvailable = true
+		for _, pm := range podMetrics.Items {
+			total := corev1.ResourceList{}
+			for _, container := range pm.Containers {
+				addResourceList(total, container.Usage)
+			}
+			usage[pm.Namespace+"/"+pm.Name] = total
+		}
+	case metricsUnavailable(err):
+		result.MetricsError = err.Error()
+	default:
+		return nil, err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		restarts := 0
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts += int(cs.RestartCount)
+		}
+
+		resources := podResources(pod)
+		used := usage[pod.Namespace+"/"+pod.Name]
+		cpu, memory := used[corev1.ResourceCPU], used[corev1.ResourceMemory]
+		result.Items = append(result.Items, PodUsage{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Cluster:   contextName,
+			Node:      pod.Spec.NodeName,
+			Status:    string(pod.Status.Phase),
+			Restarts:  restarts,
+			CPU: newResourceUsage(cpu.MilliValue(),
+				resources[string(corev1.ResourceCPU)].Requested, resources[string(corev1.ResourceCPU)].Limits),
+			Memory: newResourceUsage(memory.Value(),
+				resources[string(corev1.ResourceMemory)].Requested, resources[string(corev1.ResourceMemory)].Limits),
+		})
+	}
+
+	return result, nil
+}
+
+// GetNodeUsage returns the nodes of a cluster joined with their NodeMetrics
+func (m *MultiClusterClient) GetNodeUsage(ctx context.Context, contextName string) (*NodeUsageList, error) {
+	ctx, span := startSpan(ctx, "GetNodeUsage", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := m.GetMetricsClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return nodeUsage(ctx, client, mc, contextName)
+}
+
+// nodeUsage is GetNodeUsage for any clientsets; the tests pass fakes
+func nodeUsage(ctx context.Context, client kubernetes.Interface, mc metricsclient.Interface, contextName string) (*NodeUsageList, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NodeUsageList{Cluster: contextName, Items: []NodeUsage{}}
+
+	usage := map[string]corev1.ResourceList{}
+	nodeMetrics, err := mc.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	switch {
+	case err == nil:
+		result.MetricsAvailable = true
+		for _, nm := range nodeMetrics.Items {
+			usage[nm.Name] = nm.Usage
+		}
+	case metricsUnavailable(err):
+		result.MetricsError = err.Error()
+	default:
+		return nil, err
+	}
+
+	for _, node := range nodes.Items {
+		used := usage[node.Name]
+		cpu, memory := used[corev1.ResourceCPU], used[corev1.ResourceMemory]
+		allocatable := node.Status.Allocatable
+		result.Items = append(result.Items, NodeUsage{
+			Name:    node.Name,
+			Cluster: contextName,
+			CPU:     newResourceUsage(cpu.MilliValue(), 0, allocatable.Cpu().MilliValue()),
+			Memory:  newResourceUsage(memory.Value(), 0, allocatable.Memory().Value()),
+		})
+	}
+
+	return result, nil
+}
+
+// metricsUnavailable reports whether a metrics.k8s.io error means the API
+// is not served or not readable, rather than a failed request
+func metricsUnavailable(err error) bool {
+	return apierrors.IsNotFound(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsForbidden(err) ||
+		apierrors.IsMethodNotSupported(err)
+}
+
+// TopPods sorts pods by CPU or memory usage or by restarts, highest first,
+// and keeps at most n of them (all when n is 0)
+func TopPods(pods []PodUsage, by string, n int) ([]PodUsage, error) {
+	var key func(p PodUsage) int64
+	switch by {
+	case TopByCPU:
+		key = func(p PodUsage) int64 { return p.CPU.Usage }
+	case TopByMemory:
+		key = func(p PodUsage) int64 { return p.Memory.Usage }
+	case TopByRestarts:
+		key = func(p PodUsage) int64 { return int64(p.Restarts) }
+	default:
+		return nil, fmt.Errorf("cannot rank pods by %q; use %s, %s or %s", by, TopByCPU, TopByMemory, TopByRestarts)
+	}
+
+	sort.SliceStable(pods, func(i, j int) bool {
+		if a, b := key(pods[i]), key(pods[j]); a != b {
+			return a > b
+		}
+		if pods[i].Cluster != pods[j].Cluster {
+			return pods[i].Cluster < pods[j].Cluster
+		}
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	if n > 0 && len(pods) > n {
+		pods = pods[:n]
+	}
+	return pods, nil
+}
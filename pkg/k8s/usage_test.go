@@ -0,0 +1,211 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// The fake metrics clientset lists PodMetrics and NodeMetrics under the
+// "pods" and "nodes" resources, so objects are added with those
+var (
+	podMetricsResource  = metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+	nodeMetricsResource = metricsv1beta1.SchemeGroupVersion.WithResource("nodes")
+)
+
+func resources(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func runningPod(namespace, name string, restarts int32, requests, limits corev1.ResourceList) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{{
+				Name:      "app",
+				Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: restarts}},
+		},
+	}
+}
+
+func addMetrics(t *testing.T, mc *metricsfake.Clientset, gvr schema.GroupVersionResource, obj runtime.Object, namespace string) {
+	t.Helper()
+	if err := mc.Tracker().Create(gvr, obj, namespace); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func metricsNotFound(mc *metricsfake.Clientset) {
+	mc.PrependReactor("list", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "metrics.k8s.io", Resource: "pods"}, "")
+	})
+}
+
+func TestPodUsage(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		runningPod("default", "web", 2, resources("200m", "256Mi"), resources("400m", "512Mi")),
+		runningPod("default", "batch", 0, nil, nil),
+	)
+	mc := metricsfake.NewSimpleClientset()
+	addMetrics(t, mc, podMetricsResource, &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "app", Usage: resources("100m", "128Mi")},
+			{Name: "sidecar", Usage: resources("50m", "64Mi")},
+		},
+	}, "default")
+
+	list, err := podUsage(context.Background(), client, mc, "kind", "")
+	if err != nil {
+		t.Fatalf("podUsage: %v", err)
+	}
+	if !list.MetricsAvailable || list.MetricsError != "" || len(list.Items) != 2 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	byName := map[string]PodUsage{}
+	for _, p := range list.Items {
+		byName[p.Name] = p
+	}
+	web := byName["web"]
+	if web.Cluster != "kind" || web.Node != "node-1" || web.Restarts != 2 || web.Status != "Running" {
+		t.Fatalf("unexpected pod %+v", web)
+	}
+	wantCPU := ResourceUsage{Usage: 150, Request: 200, Limit: 400, RequestRatio: 0.75, LimitRatio: 0.375}
+	if web.CPU != wantCPU {
+		t.Fatalf("cpu = %+v, want %+v", web.CPU, wantCPU)
+	}
+	wantMemory := ResourceUsage{Usage: 192 << 20, Request: 256 << 20, Limit: 512 << 20, RequestRatio: 0.75, LimitRatio: 0.375}
+	if web.Memory != wantMemory {
+		t.Fatalf("memory = %+v, want %+v", web.Memory, wantMemory)
+	}
+
+	// No metrics and no requests or limits: zero usage and no ratios
+	if batch := byName["batch"]; batch.CPU != (ResourceUsage{}) || batch.Memory != (ResourceUsage{}) {
+		t.Fatalf("unexpected pod without metrics %+v", batch)
+	}
+}
+
+func TestPodUsageWithoutMetricsServer(t *testing.T) {
+	client := fake.NewSimpleClientset(runningPod("default", "web", 3, resources("200m", "256Mi"), nil))
+	mc := metricsfake.NewSimpleClientset()
+	metricsNotFound(mc)
+
+	list, err := podUsage(context.Background(), client, mc, "kind", "")
+	if err != nil {
+		t.Fatalf("podUsage: %v", err)
+	}
+	if list.MetricsAvailable || list.MetricsError == "" {
+		t.Fatalf("metrics reported available: %+v", list)
+	}
+	if len(list.Items) != 1 || list.Items[0].Restarts != 3 || list.Items[0].CPU.Usage != 0 {
+		t.Fatalf("unexpected items %+v", list.Items)
+	}
+}
+
+func TestPodUsageMetricsError(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	mc := metricsfake.NewSimpleClientset()
+	mc.PrependReactor("list", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewInternalError(context.DeadlineExceeded)
+	})
+
+	if _, err := podUsage(context.Background(), client, mc, "kind", ""); err == nil {
+		t.Fatal("podUsage hid a failed metrics request")
+	}
+}
+
+func TestNodeUsage(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     corev1.NodeStatus{Allocatable: resources("4", "8Gi")},
+	})
+	mc := metricsfake.NewSimpleClientset()
+	addMetrics(t, mc, nodeMetricsResource, &metricsv1beta1.NodeMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Usage:      resources("1", "2Gi"),
+	}, "")
+
+	list, err := nodeUsage(context.Background(), client, mc, "kind")
+	if err != nil {
+		t.Fatalf("nodeUsage: %v", err)
+	}
+	if !list.MetricsAvailable || len(list.Items) != 1 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	node := list.Items[0]
+	if node.CPU != (ResourceUsage{Usage: 1000, Limit: 4000, LimitRatio: 0.25}) {
+		t.Fatalf("cpu = %+v", node.CPU)
+	}
+	if node.Memory != (ResourceUsage{Usage: 2 << 30, Limit: 8 << 30, LimitRatio: 0.25}) {
+		t.Fatalf("memory = %+v", node.Memory)
+	}
+
+	mc = metricsfake.NewSimpleClientset()
+	metricsNotFound(mc)
+	list, err = nodeUsage(context.Background(), client, mc, "kind")
+	if err != nil {
+		t.Fatalf("nodeUsage without metrics-server: %v", err)
+	}
+	if list.MetricsAvailable || len(list.Items) != 1 || list.Items[0].CPU.Limit != 4000 {
+		t.Fatalf("unexpected list without metrics-server %+v", list)
+	}
+}
+
+func TestTopPods(t *testing.T) {
+	pods := []PodUsage{
+		{Name: "a", Namespace: "ns", Cluster: "c2", CPU: ResourceUsage{Usage: 100}, Memory: ResourceUsage{Usage: 300}, Restarts: 1},
+		{Name: "b", Namespace: "ns", Cluster: "c1", CPU: ResourceUsage{Usage: 300}, Memory: ResourceUsage{Usage: 100}, Restarts: 0},
+		{Name: "c", Namespace: "ns", Cluster: "c1", CPU: ResourceUsage{Usage: 100}, Memory: ResourceUsage{Usage: 200}, Restarts: 5},
+	}
+	names := func(pods []PodUsage) string {
+		s := ""
+		for _, p := range pods {
+			s += p.Name
+		}
+		return s
+	}
+
+	tests := []struct {
+		by   string
+		n    int
+		want string
+	}{
+		// Ties are broken by cluster, namespace and name
+		{TopByCPU, 0, "bca"},
+		{TopByMemory, 0, "acb"},
+		{TopByRestarts, 2, "ca"},
+	}
+	for _, tt := range tests {
+		got, err := TopPods(append([]PodUsage(nil), pods...), tt.by, tt.n)
+		if err != nil {
+			t.Fatalf("TopPods(%s): %v", tt.by, err)
+		}
+		if names(got) != tt.want {
+			t.Errorf("TopPods(%s, %d) = %s, want %s", tt.by, tt.n, names(got), tt.want)
+		}
+	}
+
+	if _, err := TopPods(pods, "disk", 0); err == nil {
+		t.Error("TopPods accepted an unknown ranking")
+	}
+}
@@ -0,0 +1,253 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// WhoCanRequest is an action to find the subjects allowed to perform
+type WhoCanRequest struct {
+	Verb string `json:"verb"`
+	// Resource may be qualified with its group ("deployments.apps") and
+	// name a subresource ("pods/exec")
+	Resource    string `json:"resource"`
+	Group       string `json:"group,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	// Namespace is empty for cluster-scoped resources or actions across
+	// all namespaces, which only ClusterRoleBindings grant
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the object name, matched against rules' resourceNames
+	Name string `json:"name,omitempty"`
+}
+
+// BindingPath is how a subject is granted an action: the binding, the role
+// it refers to, and the matching rule
+type BindingPath struct {
+	BindingKind string `json:"bindingKind"`
+	Binding     string `json:"binding"`
+	Namespace   string `json:"namespace,omitempty"`
+	RoleKind    string `json:"roleKind"`
+	RoleName    string `json:"roleName"`
+	// AggregatedFrom names the ClusterRole the rule came from when the
+	// bound role aggregates it
+	AggregatedFrom string            `json:"aggregatedFrom,omitempty"`
+	Rule           rbacv1.PolicyRule `json:"rule"`
+}
+
+// WhoCanSubject is a subject allowed to perform an action
+type WhoCanSubject struct {
+	RBACSubject
+	Paths []BindingPath `json:"paths"`
+}
+
+// WhoCanResult lists the subjects allowed to perform an action in a cluster
+type WhoCanResult struct {
+	Cluster  string          `json:"cluster"`
+	Request  WhoCanRequest   `json:"request"`
+	Subjects []WhoCanSubject `json:"subjects"`
+}
+
+// WhoCan finds the subjects whose RoleBindings and ClusterRoleBindings
+// allow an action, by evaluating RBAC rules the way the API server does.
+// Groups are reported as subjects; their members are not known.
+func (m *MultiClusterClient) WhoCan(ctx context.Context, contextName string, req WhoCanRequest) (*WhoCanResult, error) {
+	ctx, span := startSpan(ctx, "WhoCan", contextName)
+	defer span.End()
+
+	client, err := m.GetClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	disc, err := m.GetDiscoveryClient(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	req, err = resolveWhoCanRequest(disc, req)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := loadRBAC(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return whoCan(contextName, snapshot, req), nil
+}
+
+// resolveWhoCanRequest splits a qualified resource into resource, group
+// and subresource, and looks up the group of unqualified resources with
+// disc, which should cache what it discovers
+func resolveWhoCanRequest(disc discovery.DiscoveryInterface, req WhoCanRequest) (WhoCanRequest, error) {
+	if resource, sub, ok := strings.Cut(req.Resource, "/"); ok {
+		req.Resource, req.Subresource = resource, sub
+	}
+	if req.Group == "" && strings.Contains(req.Resource, ".") {
+		gr := schema.ParseGroupResource(req.Resource)
+		req.Resource, req.Group = gr.Resource, gr.Group
+	}
+	if req.Group != "" {
+		return req, nil
+	}
+	if req.Resource == rbacv1.ResourceAll {
+		req.Group = rbacv1.APIGroupAll
+		return req, nil
+	}
+
+	// Partial results still hold the groups that could be discovered
+	lists, err := disc.ServerPreferredResources()
+	for _, list := range lists {
+		gv, parseErr := schema.ParseGroupVersion(list.GroupVersion)
+		if parseErr != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if resource.Name == req.Resource {
+				req.Group = gv.Group
+				return req, nil
+			}
+		}
+	}
+	if err != nil {
+		return req, fmt.Errorf("discover resource %q: %w", req.Resource, err)
+	}
+	// Unknown resources, such as CRDs not installed, default to the core
+	// group as kubectl auth can-i does
+	return req, nil
+}
+
+func whoCan(contextName string, s *rbacSnapshot, req WhoCanRequest) *WhoCanResult {
+	result := &WhoCanResult{Cluster: contextName, Request: req, Subjects: []WhoCanSubject{}}
+	resource := req.Resource
+	if req.Subresource != "" {
+		resource += "/" + req.Subresource
+	}
+
+	subjects := map[RBACSubject]*WhoCanSubject{}
+	var order []RBACSubject
+	for _, b := range s.bindings() {
+		// RoleBindings only grant within their namespace
+		if b.Kind == "RoleBinding" && b.Namespace != req.Namespace {
+			continue
+		}
+
+		var paths []BindingPath
+		for _, source := range s.ruleSources(b) {
+			for _, rule := range source.rules {
+				if !ruleAllows(rule, req.Verb, req.Group, resource) || !ruleAllowsName(rule, req.Name) {
+					continue
+				}
+				path := BindingPath{
+					BindingKind: b.Kind,
+					Binding:     b.Name,
+					Namespace:   b.Namespace,
+					RoleKind:    b.RoleRef.Kind,
+					RoleName:    b.RoleRef.Name,
+					Rule:        rule,
+				}
+				if source.role != b.RoleRef.Name {
+					path.AggregatedFrom = source.role
+				}
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+
+		for _, subject := range b.Subjects {
+			key := RBACSubject{Kind: subject.Kind, Name: subject.Name, Namespace: subjectNamespace(subject, b)}
+			ws, ok := subjects[key]
+			if !ok {
+				ws = &WhoCanSubject{RBACSubject: key}
+				subjects[key] = ws
+				order = append(order, key)
+			}
+			ws.Paths = append(ws.Paths, paths...)
+		}
+	}
+
+	for _, key := range order {
+		result.Subjects = append(result.Subjects, *subjects[key])
+	}
+	sort.SliceStable(result.Subjects, func(i, j int) bool {
+		a, b := result.Subjects[i], result.Subjects[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
+
+// ruleSource is a set of rules and the role they are written in
+type ruleSource struct {
+	role  string
+	rules []rbacv1.PolicyRule
+}
+
+// ruleSources returns the rules of the role a binding refers to, grouped
+// by the ClusterRole each comes from when the role is aggregated
+func (s *rbacSnapshot) ruleSources(b rbacBinding) []ruleSource {
+	if b.RoleRef.Kind == "Role" {
+		rules, _ := s.roleRules(b)
+		return []ruleSource{{role: b.RoleRef.Name, rules: rules}}
+	}
+
+	role, ok := s.clusterRoles[b.RoleRef.Name]
+	if !ok {
+		return nil
+	}
+	if role.AggregationRule == nil || len(role.AggregationRule.ClusterRoleSelectors) == 0 {
+		return []ruleSource{{role: role.Name, rules: role.Rules}}
+	}
+
+	// Attribute each aggregated rule to the first ClusterRole defining it
+	aggregated, _ := s.clusterRoleRules(role.Name, map[string]bool{})
+	names := make([]string, 0, len(s.clusterRoles))
+	for name := range s.clusterRoles {
+		if name != role.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sources []ruleSource
+	for _, rule := range aggregated {
+		from := role.Name
+		for _, name := range names {
+			if containsRule(s.clusterRoles[name].Rules, rule) && s.aggregates(role.Name, name) {
+				from = name
+				break
+			}
+		}
+		sources = append(sources, ruleSource{role: from, rules: []rbacv1.PolicyRule{rule}})
+	}
+	return sources
+}
+
+func containsRule(rules []rbacv1.PolicyRule, rule rbacv1.PolicyRule) bool {
+	key := rule.String()
+	for _, r := range rules {
+		if r.String() == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleAllowsName reports whether a rule's resourceNames allow an object.
+// Rules limited to names never match requests without one, such as list.
+func ruleAllowsName(rule rbacv1.PolicyRule, name string) bool {
+	if len(rule.ResourceNames) == 0 {
+		return true
+	}
+	return name != "" && containsString(rule.ResourceNames, name)
+}
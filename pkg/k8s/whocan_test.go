@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestResolveWhoCanRequest(t *testing.T) {
+	fake := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods"}, {Name: "secrets"}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments"}}},
+	}}}
+	disc := memory.NewMemCacheClient(fake)
+
+	tests := []struct {
+		req  WhoCanRequest
+		want WhoCanRequest
+	}{
+		{WhoCanRequest{Verb: "get", Resource: "deployments"}, WhoCanRequest{Verb: "get", Resource: "deployments", Group: "apps"}},
+		{WhoCanRequest{Verb: "create", Resource: "pods/exec"}, WhoCanRequest{Verb: "create", Resource: "pods", Subresource: "exec"}},
+		{WhoCanRequest{Verb: "list", Resource: "widgets.example.com"}, WhoCanRequest{Verb: "list", Resource: "widgets", Group: "example.com"}},
+		// Unknown resources default to the core group
+		{WhoCanRequest{Verb: "get", Resource: "widgets"}, WhoCanRequest{Verb: "get", Resource: "widgets"}},
+		{WhoCanRequest{Verb: "get", Resource: "*"}, WhoCanRequest{Verb: "get", Resource: "*", Group: "*"}},
+	}
+	for _, tt := range tests {
+		got, err := resolveWhoCanRequest(disc, tt.req)
+		if err != nil {
+			t.Fatalf("resolve %+v: %v", tt.req, err)
+		}
+		if got != tt.want {
+			t.Errorf("resolve %+v = %+v, want %+v", tt.req, got, tt.want)
+		}
+	}
+
+	// Later lookups are answered from the cache
+	calls := len(fake.Actions())
+	if calls == 0 {
+		t.Fatal("discovery was never asked")
+	}
+	if _, err := resolveWhoCanRequest(disc, WhoCanRequest{Verb: "get", Resource: "secrets"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fake.Actions()) - calls; n != 0 {
+		t.Errorf("cached lookup made %d discovery requests", n)
+	}
+}
+
+func TestWhoCan(t *testing.T) {
+	s := rbacFixtureSnapshot(t)
+
+	tests := []struct {
+		name string
+		req  WhoCanRequest
+		// want is each subject with the bindings and aggregated roles that
+		// grant the action
+		want map[string][]string
+	}{
+		{
+			name: "cluster-wide through an aggregated role",
+			req:  WhoCanRequest{Verb: "list", Resource: "secrets"},
+			want: map[string][]string{
+				"ServiceAccount monitoring/prometheus": {"monitoring from monitoring-secrets"},
+				"User /alice":                          {"ops-admin"},
+			},
+		},
+		{
+			name: "RoleBindings only grant in their namespace",
+			req:  WhoCanRequest{Verb: "get", Resource: "secrets", Namespace: "team-a"},
+			want: map[string][]string{
+				"ServiceAccount team-a/ci": {"deployers"},
+				"User /alice":              {"ops-admin"},
+				"User /bob":                {"deployers"},
+			},
+		},
+		{
+			name: "subresources",
+			req:  WhoCanRequest{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: "team-a"},
+			want: map[string][]string{
+				"ServiceAccount team-a/ci": {"deployers"},
+				"User /alice":              {"ops-admin"},
+				"User /bob":                {"deployers"},
+			},
+		},
+		{
+			name: "resource names",
+			req:  WhoCanRequest{Verb: "escalate", Resource: "roles", Group: rbacv1.GroupName, Namespace: "team-a", Name: "viewer"},
+			want: map[string][]string{
+				"User /alice": {"ops-admin"},
+				"User /carol": {"escalators"},
+			},
+		},
+		{
+			name: "rules limited to names do not grant unnamed requests",
+			req:  WhoCanRequest{Verb: "escalate", Resource: "roles", Group: rbacv1.GroupName, Namespace: "team-a"},
+			want: map[string][]string{
+				"User /alice": {"ops-admin"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := whoCan("kind", s, tt.req)
+			got := map[string][]string{}
+			for _, subject := range result.Subjects {
+				key := subject.Kind + " " + subject.Namespace + "/" + subject.Name
+				for _, path := range subject.Paths {
+					binding := path.Binding
+					if path.AggregatedFrom != "" {
+						binding += " from " + path.AggregatedFrom
+					}
+					got[key] = append(got[key], binding)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("subjects = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,7 @@ package mcp
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
 	"os"
 	"sync"
 	"time"
@@ -11,10 +10,16 @@ import (
 
 // Bridge manages MCP client connections and provides a unified interface
 type Bridge struct {
-	opsClient    *Client
-	deployClient *Client
-	mu           sync.RWMutex
-	config       BridgeConfig
+	mu      sync.RWMutex
+	config  BridgeConfig
+	servers map[string]*managedServer
+	order   []string
+
+	onNotification func(Notification)
+
+	// ctx lives until Stop and bounds the supervisors
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // BridgeConfig holds configuration for the MCP bridge
@@ -22,6 +27,8 @@ type BridgeConfig struct {
 	KlaudeOpsPath    string
 	KlaudeDeployPath string
 	Kubeconfig       string
+	// Servers are additional MCP servers, typically from LoadServersConfig
+	Servers []ServerConfig
 }
 
 // ClusterInfo represents basic cluster information
@@ -84,156 +91,35 @@ type Event struct {
 
 // NewBridge creates a new MCP bridge
 func NewBridge(config BridgeConfig) *Bridge {
-	return &Bridge{
-		config: config,
-	}
-}
-
-// Start initializes and starts all MCP clients
-func (b *Bridge) Start(ctx context.Context) error {
-	var wg sync.WaitGroup
-	errCh := make(chan error, 2)
-
-	// Start klaude-ops if path is configured
-	if b.config.KlaudeOpsPath != "" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := b.startOpsClient(ctx); err != nil {
-				errCh <- fmt.Errorf("ops client: %w", err)
-			}
-		}()
-	}
-
-	// Start klaude-deploy if path is configured
-	if b.config.KlaudeDeployPath != "" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := b.startDeployClient(ctx); err != nil {
-				errCh <- fmt.Errorf("deploy client: %w", err)
-			}
-		}()
-	}
-
-	wg.Wait()
-	close(errCh)
-
-	// Collect any errors
-	var errs []error
-	for err := range errCh {
-		errs = append(errs, err)
-	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to start MCP clients: %v", errs)
-	}
-
-	return nil
-}
-
-// Stop stops all MCP clients
-func (b *Bridge) Stop() error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	var errs []error
-
-	if b.opsClient != nil {
-		if err := b.opsClient.Stop(); err != nil {
-			errs = append(errs, fmt.Errorf("ops client: %w", err))
-		}
-	}
-
-	if b.deployClient != nil {
-		if err := b.deployClient.Stop(); err != nil {
-			errs = append(errs, fmt.Errorf("deploy client: %w", err))
-		}
-	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("errors stopping clients: %v", errs)
-	}
-
-	return nil
-}
-
-func (b *Bridge) startOpsClient(ctx context.Context) error {
-	args := []string{"--mcp-server"}
-	if b.config.Kubeconfig != "" {
-		args = append(args, "--kubeconfig", b.config.Kubeconfig)
-	}
-
-	client, err := NewClient("klaude-ops", b.config.KlaudeOpsPath, args...)
-	if err != nil {
-		return err
-	}
-
-	if err := client.Start(ctx); err != nil {
-		return err
-	}
-
-	b.mu.Lock()
-	b.opsClient = client
-	b.mu.Unlock()
-
-	return nil
-}
-
-func (b *Bridge) startDeployClient(ctx context.Context) error {
-	args := []string{"--mcp"}
-	if b.config.Kubeconfig != "" {
-		args = append(args, "--kubeconfig", b.config.Kubeconfig)
-	}
-
-	client, err := NewClient("klaude-deploy", b.config.KlaudeDeployPath, args...)
-	if err != nil {
-		return err
-	}
-
-	if err := client.Start(ctx); err != nil {
-		return err
-	}
-
-	b.mu.Lock()
-	b.deployClient = client
-	b.mu.Unlock()
-
-	return nil
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Bridge{
+		config:  config,
+		servers: make(map[string]*managedServer),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for _, cfg := range config.serverConfigs() {
+		b.servers[cfg.Name] = &managedServer{config: cfg, subscriptions: make(map[string]bool)}
+		b.order = append(b.order, cfg.Name)
+	}
+	return b
 }
 
 // GetOpsTools returns the list of available ops tools
 func (b *Bridge) GetOpsTools() []Tool {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.opsClient == nil {
-		return nil
-	}
-	return b.opsClient.Tools()
+	tools, _ := b.GetTools(OpsServer)
+	return tools
 }
 
 // GetDeployTools returns the list of available deploy tools
 func (b *Bridge) GetDeployTools() []Tool {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.deployClient == nil {
-		return nil
-	}
-	return b.deployClient.Tools()
+	tools, _ := b.GetTools(DeployServer)
+	return tools
 }
 
 // ListClusters returns all discovered clusters
 func (b *Bridge) ListClusters(ctx context.Context) ([]ClusterInfo, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.opsClient == nil {
-		return nil, fmt.Errorf("ops client not available")
-	}
-
-	result, err := b.opsClient.CallTool(ctx, "list_clusters", map[string]interface{}{
+	result, err := b.CallTool(ctx, OpsServer, "list_clusters", map[string]interface{}{
 		"source": "all",
 	})
 	if err != nil {
@@ -245,19 +131,12 @@ func (b *Bridge) ListClusters(ctx context.Context) ([]ClusterInfo, error) {
 
 // GetClusterHealth returns health status for a cluster
 func (b *Bridge) GetClusterHealth(ctx context.Context, cluster string) (*ClusterHealth, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.opsClient == nil {
-		return nil, fmt.Errorf("ops client not available")
-	}
-
 	args := map[string]interface{}{}
 	if cluster != "" {
 		args["cluster"] = cluster
 	}
 
-	result, err := b.opsClient.CallTool(ctx, "get_cluster_health", args)
+	result, err := b.CallTool(ctx, OpsServer, "get_cluster_health", args)
 	if err != nil {
 		return nil, err
 	}
@@ -267,13 +146,6 @@ func (b *Bridge) GetClusterHealth(ctx context.Context, cluster string) (*Cluster
 
 // GetPods returns pods for a namespace/cluster
 func (b *Bridge) GetPods(ctx context.Context, cluster, namespace, labelSelector string) ([]PodInfo, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.opsClient == nil {
-		return nil, fmt.Errorf("ops client not available")
-	}
-
 	args := map[string]interface{}{}
 	if cluster != "" {
 		args["cluster"] = cluster
@@ -285,7 +157,7 @@ func (b *Bridge) GetPods(ctx context.Context, cluster, namespace, labelSelector
 		args["label_selector"] = labelSelector
 	}
 
-	result, err := b.opsClient.CallTool(ctx, "get_pods", args)
+	result, err := b.CallTool(ctx, OpsServer, "get_pods", args)
 	if err != nil {
 		return nil, err
 	}
@@ -295,13 +167,6 @@ func (b *Bridge) GetPods(ctx context.Context, cluster, namespace, labelSelector
 
 // FindPodIssues returns pods with issues
 func (b *Bridge) FindPodIssues(ctx context.Context, cluster, namespace string) ([]PodIssue, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.opsClient == nil {
-		return nil, fmt.Errorf("ops client not available")
-	}
-
 	args := map[string]interface{}{}
 	if cluster != "" {
 		args["cluster"] = cluster
@@ -310,7 +175,7 @@ func (b *Bridge) FindPodIssues(ctx context.Context, cluster, namespace string) (
 		args["namespace"] = namespace
 	}
 
-	result, err := b.opsClient.CallTool(ctx, "find_pod_issues", args)
+	result, err := b.CallTool(ctx, OpsServer, "find_pod_issues", args)
 	if err != nil {
 		return nil, err
 	}
@@ -320,13 +185,6 @@ func (b *Bridge) FindPodIssues(ctx context.Context, cluster, namespace string) (
 
 // GetEvents returns events from a cluster
 func (b *Bridge) GetEvents(ctx context.Context, cluster, namespace string, limit int) ([]Event, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.opsClient == nil {
-		return nil, fmt.Errorf("ops client not available")
-	}
-
 	args := map[string]interface{}{}
 	if cluster != "" {
 		args["cluster"] = cluster
@@ -338,23 +196,16 @@ func (b *Bridge) GetEvents(ctx context.Context, cluster, namespace string, limit
 		args["limit"] = limit
 	}
 
-	result, err := b.opsClient.CallTool(ctx, "get_events", args)
+	result, err := b.CallTool(ctx, OpsServer, "get_events", args)
 	if err != nil {
 		return nil, err
 	}
 
-	return b.parseEventsResult(result)
+	return b.parseEventsResult("get_events", result)
 }
 
 // GetWarningEvents returns warning events from a cluster
 func (b *Bridge) GetWarningEvents(ctx context.Context, cluster, namespace string, limit int) ([]Event, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.opsClient == nil {
-		return nil, fmt.Errorf("ops client not available")
-	}
-
 	args := map[string]interface{}{}
 	if cluster != "" {
 		args["cluster"] = cluster
@@ -366,151 +217,107 @@ func (b *Bridge) GetWarningEvents(ctx context.Context, cluster, namespace string
 		args["limit"] = limit
 	}
 
-	result, err := b.opsClient.CallTool(ctx, "get_warning_events", args)
+	result, err := b.CallTool(ctx, OpsServer, "get_warning_events", args)
 	if err != nil {
 		return nil, err
 	}
 
-	return b.parseEventsResult(result)
+	return b.parseEventsResult("get_warning_events", result)
 }
 
 // CallOpsTool calls any ops tool by name
 func (b *Bridge) CallOpsTool(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.opsClient == nil {
-		return nil, fmt.Errorf("ops client not available")
-	}
-
-	return b.opsClient.CallTool(ctx, name, args)
+	return b.CallTool(ctx, OpsServer, name, args)
 }
 
 // CallDeployTool calls any deploy tool by name
 func (b *Bridge) CallDeployTool(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	if b.deployClient == nil {
-		return nil, fmt.Errorf("deploy client not available")
-	}
-
-	return b.deployClient.CallTool(ctx, name, args)
+	return b.CallTool(ctx, DeployServer, name, args)
 }
 
-// Helper functions to parse tool results
+// Helper functions to parse tool results. Each returns a *ToolError when
+// the tool failed and a *ParseError when the result has no usable data.
 
 func (b *Bridge) parseClustersResult(result *CallToolResult) ([]ClusterInfo, error) {
-	if result.IsError {
-		return nil, fmt.Errorf("tool error: %s", result.Content[0].Text)
-	}
-
-	// Parse the text content as JSON
 	var clusters []ClusterInfo
-	for _, content := range result.Content {
-		if content.Type == "text" {
-			if err := json.Unmarshal([]byte(content.Text), &clusters); err != nil {
-				// Try to extract from formatted text
-				return b.parseClustersFromText(content.Text), nil
+	err := result.Decode("list_clusters", "clusters", &clusters)
+	if errors.Is(err, ErrUnparseableResult) {
+		// Older klaude-ops versions print a table instead of JSON
+		if text := result.Text(); text != "" {
+			parsed, textErr := parseClustersFromText(text)
+			if textErr != nil {
+				return nil, err
 			}
+			return parsed, nil
 		}
 	}
+	if err != nil {
+		return nil, err
+	}
 	return clusters, nil
 }
 
-func (b *Bridge) parseClustersFromText(text string) []ClusterInfo {
-	// Fallback parser for human-readable output
-	// This is a simplified parser - in production you'd want proper parsing
-	return []ClusterInfo{}
-}
-
 func (b *Bridge) parseHealthResult(result *CallToolResult) (*ClusterHealth, error) {
-	if result.IsError {
-		return nil, fmt.Errorf("tool error: %s", result.Content[0].Text)
-	}
-
 	var health ClusterHealth
-	for _, content := range result.Content {
-		if content.Type == "text" {
-			if err := json.Unmarshal([]byte(content.Text), &health); err != nil {
-				// Parse from text format
-				health.Healthy = true // Default assumption
-				return &health, nil
-			}
-		}
+	if err := result.Decode("get_cluster_health", "health", &health); err != nil {
+		return nil, err
+	}
+	if health.Cluster == "" {
+		return nil, result.parseError("get_cluster_health", errors.New("no cluster in health result"))
 	}
 	return &health, nil
 }
 
 func (b *Bridge) parsePodsResult(result *CallToolResult) ([]PodInfo, error) {
-	if result.IsError {
-		return nil, fmt.Errorf("tool error: %s", result.Content[0].Text)
-	}
-
-	var pods []PodInfo
-	for _, content := range result.Content {
-		if content.Type == "text" {
-			if err := json.Unmarshal([]byte(content.Text), &pods); err != nil {
-				return []PodInfo{}, nil
-			}
-		}
+	pods := []PodInfo{}
+	if err := result.Decode("get_pods", "pods", &pods); err != nil {
+		return nil, err
 	}
 	return pods, nil
 }
 
 func (b *Bridge) parsePodIssuesResult(result *CallToolResult) ([]PodIssue, error) {
-	if result.IsError {
-		return nil, fmt.Errorf("tool error: %s", result.Content[0].Text)
-	}
-
-	var issues []PodIssue
-	for _, content := range result.Content {
-		if content.Type == "text" {
-			if err := json.Unmarshal([]byte(content.Text), &issues); err != nil {
-				return []PodIssue{}, nil
-			}
-		}
+	issues := []PodIssue{}
+	if err := result.Decode("find_pod_issues", "issues", &issues); err != nil {
+		return nil, err
 	}
 	return issues, nil
 }
 
-func (b *Bridge) parseEventsResult(result *CallToolResult) ([]Event, error) {
-	if result.IsError {
-		return nil, fmt.Errorf("tool error: %s", result.Content[0].Text)
-	}
-
-	var events []Event
-	for _, content := range result.Content {
-		if content.Type == "text" {
-			if err := json.Unmarshal([]byte(content.Text), &events); err != nil {
-				return []Event{}, nil
-			}
-		}
+func (b *Bridge) parseEventsResult(tool string, result *CallToolResult) ([]Event, error) {
+	events := []Event{}
+	if err := result.Decode(tool, "events", &events); err != nil {
+		return nil, err
 	}
 	return events, nil
 }
 
 // Status returns the current status of the MCP bridge
 func (b *Bridge) Status() map[string]interface{} {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
+	servers := b.Servers()
 	status := map[string]interface{}{
-		"opsClient": map[string]interface{}{
-			"available": b.opsClient != nil && b.opsClient.IsReady(),
-			"toolCount": 0,
-		},
-		"deployClient": map[string]interface{}{
-			"available": b.deployClient != nil && b.deployClient.IsReady(),
-			"toolCount": 0,
-		},
-	}
-
-	if b.opsClient != nil && b.opsClient.IsReady() {
-		status["opsClient"].(map[string]interface{})["toolCount"] = len(b.opsClient.Tools())
-	}
-	if b.deployClient != nil && b.deployClient.IsReady() {
-		status["deployClient"].(map[string]interface{})["toolCount"] = len(b.deployClient.Tools())
+		"opsClient":    map[string]interface{}{"available": false, "toolCount": 0},
+		"deployClient": map[string]interface{}{"available": false, "toolCount": 0},
+		"servers":      servers,
+	}
+
+	for _, server := range servers {
+		key := ""
+		switch server.Name {
+		case OpsServer:
+			key = "opsClient"
+		case DeployServer:
+			key = "deployClient"
+		default:
+			continue
+		}
+		status[key] = map[string]interface{}{
+			"available":  server.Available,
+			"toolCount":  server.ToolCount,
+			"restarts":   server.Restarts,
+			"restarting": server.Restarting,
+			"lastError":  server.LastError,
+		}
 	}
 
 	return status
@@ -1,28 +1,111 @@
 package mcp
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"os/exec"
+	"log"
+	"math"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kubestellar/console/pkg/metrics"
+	"github.com/kubestellar/console/pkg/tracing"
 )
 
-// Client is a generic MCP client that communicates with an MCP server via stdio
+// ErrServerExited is matched (via errors.Is) by the error returned for calls
+// to an MCP server whose process has exited
+var ErrServerExited = errors.New("MCP server exited")
+
+// ExitError reports that an MCP server process exited. Pending and later
+// calls on the client fail with it.
+type ExitError struct {
+	Server string
+	Err    error
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("MCP server %s exited", e.Server)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Stderr != "" {
+		msg += " (" + e.Stderr + ")"
+	}
+	return msg
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// Is makes errors.Is(err, ErrServerExited) match any ExitError
+func (e *ExitError) Is(target error) bool { return target == ErrServerExited }
+
+const (
+	// DefaultRequestTimeout bounds requests other than tool calls
+	DefaultRequestTimeout = 30 * time.Second
+
+	// DefaultToolTimeout bounds tool calls without a per-tool timeout
+	DefaultToolTimeout = 2 * time.Minute
+
+	// cancelNotifyTimeout bounds sending notifications/cancelled
+	cancelNotifyTimeout = 5 * time.Second
+)
+
+// Timeouts sets how long a Client waits for responses. A zero duration
+// uses the default; a caller's earlier context deadline always wins.
+type Timeouts struct {
+	// Request bounds initialize, list, read and other non-tool requests
+	Request time.Duration
+	// Tool bounds tool calls
+	Tool time.Duration
+	// PerTool overrides Tool for individual tools
+	PerTool map[string]time.Duration
+}
+
+// CallStats counts a client's requests
+type CallStats struct {
+	InFlight  int64 `json:"inFlight"`
+	Total     int64 `json:"total"`
+	Errors    int64 `json:"errors"`
+	Timeouts  int64 `json:"timeouts"`
+	Cancelled int64 `json:"cancelled"`
+}
+
+// callCounters is the lock-free form of CallStats
+type callCounters struct {
+	inFlight, total, errors, timeouts, cancelled atomic.Int64
+}
+
+// Client is a generic MCP client that talks JSON-RPC to an MCP server over a
+// Transport (stdio, Streamable HTTP or SSE). A Client serves a single
+// connection; once Done is closed a new Client must be created.
 type Client struct {
-	name    string
-	cmd     *exec.Cmd
-	stdin   io.WriteCloser
-	stdout  *bufio.Reader
-	stderr  io.ReadCloser
+	name      string
+	transport Transport
+	timeouts  Timeouts
+	idSeq     atomic.Int64
+	ready     atomic.Bool
+	stats     callCounters
+
+	// mu guards pending, which maps request IDs to their reply channels
 	mu      sync.Mutex
-	idSeq   atomic.Int64
-	pending map[interface{}]chan *Response
-	tools   []Tool
-	ready   bool
+	pending map[int64]chan *Response
+
+	stateMu      sync.RWMutex
+	tools        []Tool
+	capabilities Capabilities
+	progress     map[string]func(Progress)
+
+	// onNotification receives server notifications not handled by the client
+	onNotification func(Notification)
 }
 
 // JSON-RPC types
@@ -51,18 +134,8 @@ type Tool struct {
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
 	InputSchema InputSchema `json:"inputSchema"`
-}
-
-type InputSchema struct {
-	Type       string              `json:"type"`
-	Properties map[string]Property `json:"properties,omitempty"`
-	Required   []string            `json:"required,omitempty"`
-}
-
-type Property struct {
-	Type        string   `json:"type"`
-	Description string   `json:"description,omitempty"`
-	Enum        []string `json:"enum,omitempty"`
+	// OutputSchema, when set, describes the tool's structuredContent
+	OutputSchema *Schema `json:"outputSchema,omitempty"`
 }
 
 type InitializeParams struct {
@@ -83,13 +156,25 @@ type InitializeResult struct {
 }
 
 type Capabilities struct {
-	Tools *ToolsCapability `json:"tools,omitempty"`
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	Logging   *struct{}            `json:"logging,omitempty"`
 }
 
 type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
 type ServerInfo struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
@@ -102,102 +187,226 @@ type ToolsListResult struct {
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta carries request metadata such as a progress token and the
+// W3C trace context of the caller
+type RequestMeta struct {
+	ProgressToken string `json:"progressToken,omitempty"`
+	Traceparent   string `json:"traceparent,omitempty"`
+	Tracestate    string `json:"tracestate,omitempty"`
+}
+
+// Notification is a message sent by a server without expecting a reply
+type Notification struct {
+	Server string          `json:"server"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Progress is the payload of notifications/progress
+type Progress struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+// LogMessage is the payload of notifications/message
+type LogMessage struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+	Time   time.Time   `json:"time"`
 }
 
 type CallToolResult struct {
 	Content []ContentItem `json:"content"`
-	IsError bool          `json:"isError,omitempty"`
+	// StructuredContent is the typed result of tools with an output schema
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
 }
 
+// ContentItem is one item of a tool result or prompt message: "text",
+// "image" or "audio" (base64 Data), "resource" (embedded Resource) or
+// "resource_link" (URI)
 type ContentItem struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	Resource *ResourceContents `json:"resource,omitempty"`
+	URI      string            `json:"uri,omitempty"`
+	Name     string            `json:"name,omitempty"`
 }
 
 // NewClient creates a new MCP client for the given binary
 func NewClient(name, binaryPath string, args ...string) (*Client, error) {
-	cmd := exec.Command(binaryPath, args...)
-
-	stdin, err := cmd.StdinPipe()
+	transport, err := NewStdioTransport(name, binaryPath, args, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+		return nil, err
 	}
+	return NewClientWithTransport(name, transport), nil
+}
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+// NewClientWithTransport creates a new MCP client over the given transport
+func NewClientWithTransport(name string, transport Transport) *Client {
+	return &Client{
+		name:      name,
+		transport: transport,
+		pending:   make(map[int64]chan *Response),
+		progress:  make(map[string]func(Progress)),
 	}
+}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
-	}
+// OnNotification sets the handler for server notifications that the client
+// does not consume itself (resource updates, log messages, list changes).
+// It must be called before Start.
+func (c *Client) OnNotification(fn func(Notification)) {
+	c.onNotification = fn
+}
 
-	client := &Client{
-		name:    name,
-		cmd:     cmd,
-		stdin:   stdin,
-		stdout:  bufio.NewReader(stdout),
-		stderr:  stderr,
-		pending: make(map[interface{}]chan *Response),
-	}
+// SetTimeouts configures response timeouts. It must be called before Start.
+func (c *Client) SetTimeouts(t Timeouts) {
+	c.timeouts = t
+}
 
-	return client, nil
+// Stats returns the client's request counters
+func (c *Client) Stats() CallStats {
+	return CallStats{
+		InFlight:  c.stats.inFlight.Load(),
+		Total:     c.stats.total.Load(),
+		Errors:    c.stats.errors.Load(),
+		Timeouts:  c.stats.timeouts.Load(),
+		Cancelled: c.stats.cancelled.Load(),
+	}
 }
 
-// Start starts the MCP server process and initializes the connection
+// Start connects to the MCP server and initializes the connection
 func (c *Client) Start(ctx context.Context) error {
-	if err := c.cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start %s: %w", c.name, err)
+	if err := c.transport.Start(ctx, c.handleMessage); err != nil {
+		return err
 	}
 
-	// Start reading responses
-	go c.readResponses()
-
 	// Initialize the connection
 	if err := c.initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize %s: %w", c.name, err)
 	}
 
+	// Streamable HTTP servers may push messages on a separate stream
+	if l, ok := c.transport.(interface{ Listen() }); ok {
+		l.Listen()
+	}
+
 	// Get available tools
 	if err := c.listTools(ctx); err != nil {
 		return fmt.Errorf("failed to list tools from %s: %w", c.name, err)
 	}
 
-	c.ready = true
+	c.ready.Store(true)
 	return nil
 }
 
-// Stop stops the MCP server process
+// Stop closes the connection, stopping the server process for stdio
 func (c *Client) Stop() error {
-	if c.cmd.Process != nil {
-		return c.cmd.Process.Kill()
-	}
-	return nil
+	c.ready.Store(false)
+	return c.transport.Close()
 }
 
 // IsReady returns whether the client is ready to accept requests
 func (c *Client) IsReady() bool {
-	return c.ready
+	return c.ready.Load() && c.transport.Err() == nil
+}
+
+// Done returns a channel that is closed when the server exits or the
+// connection is lost
+func (c *Client) Done() <-chan struct{} {
+	return c.transport.Done()
+}
+
+// Err returns why the connection ended (an error matching ErrServerExited),
+// or nil while it is up
+func (c *Client) Err() error {
+	return c.transport.Err()
 }
 
 // Tools returns the list of available tools
 func (c *Client) Tools() []Tool {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
 	return c.tools
 }
 
+// toolLabel is the metrics label for a tool call. Callers can pass any
+// name through, so names the server did not list share one label.
+func (c *Client) toolLabel(name string) string {
+	if _, ok := c.Tool(name); ok {
+		return name
+	}
+	return metrics.UnknownTool
+}
+
+// Tool returns the named tool from the last tools/list
+func (c *Client) Tool(name string) (Tool, bool) {
+	for _, tool := range c.Tools() {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// Capabilities returns the capabilities the server announced
+func (c *Client) Capabilities() Capabilities {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	return c.capabilities
+}
+
 // CallTool invokes a tool on the MCP server
 func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
-	if !c.ready {
+	return c.CallToolWithProgress(ctx, name, args, nil)
+}
+
+// CallToolWithProgress invokes a tool, passing progress notifications for
+// the call to onProgress
+func (c *Client) CallToolWithProgress(ctx context.Context, name string, args map[string]interface{}, onProgress func(Progress)) (*CallToolResult, error) {
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	if !c.IsReady() {
 		return nil, fmt.Errorf("client not ready")
 	}
 
+	// Arguments are checked against the tool's input schema so bad input is
+	// reported per field instead of as an opaque server error
+	if tool, ok := c.Tool(name); ok {
+		validated, err := tool.ValidateArguments(args)
+		if err != nil {
+			return nil, err
+		}
+		args = validated
+	}
+
 	params := CallToolParams{
 		Name:      name,
 		Arguments: args,
 	}
+	if onProgress != nil {
+		token := fmt.Sprintf("%s-%d", c.name, c.idSeq.Add(1))
+		params.Meta = &RequestMeta{ProgressToken: token}
+		c.stateMu.Lock()
+		c.progress[token] = onProgress
+		c.stateMu.Unlock()
+		defer func() {
+			c.stateMu.Lock()
+			delete(c.progress, token)
+			c.stateMu.Unlock()
+		}()
+	}
 
-	result, err := c.call(ctx, "tools/call", params)
+	result, err := c.request(ctx, "tools/call", params, c.toolTimeout(name))
 	if err != nil {
 		return nil, err
 	}
@@ -207,6 +416,15 @@ func (c *Client) CallTool(ctx context.Context, name string, args map[string]inte
 		return nil, fmt.Errorf("failed to parse tool result: %w", err)
 	}
 
+	if toolResult.IsError {
+		metrics.ObserveMCPToolError(c.name, c.toolLabel(name))
+	}
+	if tool, ok := c.Tool(name); ok && !toolResult.IsError {
+		if err := tool.ValidateOutput(&toolResult); err != nil {
+			return nil, err
+		}
+	}
+
 	return &toolResult, nil
 }
 
@@ -228,6 +446,9 @@ func (c *Client) initialize(ctx context.Context) error {
 	if err := json.Unmarshal(result, &initResult); err != nil {
 		return fmt.Errorf("failed to parse initialize result: %w", err)
 	}
+	c.stateMu.Lock()
+	c.capabilities = initResult.Capabilities
+	c.stateMu.Unlock()
 
 	// Send initialized notification
 	c.notify("notifications/initialized", nil)
@@ -246,13 +467,38 @@ func (c *Client) listTools(ctx context.Context) error {
 		return fmt.Errorf("failed to parse tools list: %w", err)
 	}
 
+	c.stateMu.Lock()
 	c.tools = toolsResult.Tools
+	c.stateMu.Unlock()
 	return nil
 }
 
 func (c *Client) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
-	id := c.idSeq.Add(1)
+	timeout := c.timeouts.Request
+	if timeout == 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return c.request(ctx, method, params, timeout)
+}
+
+// toolTimeout returns the response timeout for a tool
+func (c *Client) toolTimeout(name string) time.Duration {
+	if d, ok := c.timeouts.PerTool[name]; ok && d > 0 {
+		return d
+	}
+	if c.timeouts.Tool > 0 {
+		return c.timeouts.Tool
+	}
+	return DefaultToolTimeout
+}
 
+// request sends a request and waits up to timeout for its response. If the
+// wait is abandoned the server is told with notifications/cancelled.
+func (c *Client) request(ctx context.Context, method string, params interface{}, timeout time.Duration) (result json.RawMessage, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	id := c.idSeq.Add(1)
 	req := Request{
 		JSONRPC: "2.0",
 		ID:      id,
@@ -260,30 +506,151 @@ func (c *Client) call(ctx context.Context, method string, params interface{}) (j
 		Params:  params,
 	}
 
+	// Buffered so the reader never blocks on a caller that gave up
 	respCh := make(chan *Response, 1)
 	c.mu.Lock()
 	c.pending[id] = respCh
 	c.mu.Unlock()
 
+	tool := ""
+	if p, ok := params.(CallToolParams); ok {
+		tool = p.Name
+	}
+	toolLabel := ""
+	if tool != "" {
+		toolLabel = c.toolLabel(tool)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("mcp.server", c.name),
+		attribute.String("mcp.method", method),
+		attribute.Int64("jsonrpc.request_id", id),
+	}
+	if tool != "" {
+		attrs = append(attrs, attribute.String("mcp.tool", tool))
+	}
+	ctx, span := tracing.StartKind(ctx, "mcp "+method, trace.SpanKindClient, attrs...)
+	req.Params = withTraceContext(ctx, params)
+
+	start := time.Now()
+	c.stats.total.Add(1)
+	c.stats.inFlight.Add(1)
+	metrics.MCPCallStarted(c.name)
 	defer func() {
 		c.mu.Lock()
 		delete(c.pending, id)
 		c.mu.Unlock()
+
+		c.stats.inFlight.Add(-1)
+		metrics.MCPCallFinished(c.name)
+		outcome := "success"
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded):
+			c.stats.timeouts.Add(1)
+			outcome = "timeout"
+		case errors.Is(err, context.Canceled):
+			c.stats.cancelled.Add(1)
+			outcome = "cancelled"
+		default:
+			c.stats.errors.Add(1)
+			outcome = "error"
+		}
+		metrics.ObserveMCPCall(c.name, method, toolLabel, outcome, time.Since(start))
+		span.SetAttributes(attribute.String("mcp.outcome", outcome))
+		tracing.End(span, err)
 	}()
 
-	if err := c.send(req); err != nil {
+	if err := c.send(ctx, req); err != nil {
+		if ctx.Err() != nil {
+			return nil, c.abandon(ctx, id, method, timeout)
+		}
 		return nil, err
 	}
 
 	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
 	case resp := <-respCh:
-		if resp.Error != nil {
-			return nil, fmt.Errorf("RPC error %d: %s", resp.Error.Code, resp.Error.Message)
+		return resp.result()
+	case <-c.transport.Done():
+		// A response that arrived just before the connection closed wins
+		select {
+		case resp := <-respCh:
+			return resp.result()
+		default:
+			return nil, c.transport.Err()
 		}
-		return resp.Result, nil
+	case <-ctx.Done():
+		return nil, c.abandon(ctx, id, method, timeout)
+	}
+}
+
+// withTraceContext adds the trace context of ctx to the _meta of params, so
+// servers that trace can continue the caller's trace. params are returned
+// unchanged when there is no trace to continue.
+func withTraceContext(ctx context.Context, params interface{}) interface{} {
+	carrier := propagation.MapCarrier{}
+	tracing.Inject(ctx, carrier)
+	if carrier.Get("traceparent") == "" {
+		return params
+	}
+	meta := RequestMeta{Traceparent: carrier.Get("traceparent"), Tracestate: carrier.Get("tracestate")}
+
+	switch p := params.(type) {
+	case CallToolParams:
+		if p.Meta != nil {
+			meta.ProgressToken = p.Meta.ProgressToken
+		}
+		p.Meta = &meta
+		return p
+	case nil:
+		return map[string]interface{}{"_meta": meta}
+	}
+
+	// Other params are objects; _meta is added alongside their fields
+	data, err := json.Marshal(params)
+	if err != nil {
+		return params
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return params
 	}
+	if _, ok := fields["_meta"]; ok {
+		return params
+	}
+	if fields["_meta"], err = json.Marshal(meta); err != nil {
+		return params
+	}
+	return fields
+}
+
+// abandon tells the server a request is no longer wanted and explains why
+func (c *Client) abandon(ctx context.Context, id int64, method string, timeout time.Duration) error {
+	// initialize must not be cancelled; the connection is dropped instead
+	if method != "initialize" {
+		go func() {
+			notifyCtx, cancel := context.WithTimeout(context.Background(), cancelNotifyTimeout)
+			defer cancel()
+			c.sendRaw(notifyCtx, Request{
+				JSONRPC: "2.0",
+				Method:  "notifications/cancelled",
+				Params:  map[string]interface{}{"requestId": id, "reason": ctx.Err().Error()},
+			})
+		}()
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s on %s: no response within %s: %w", method, c.name, timeout, ctx.Err())
+	}
+	return ctx.Err()
+}
+
+// result returns the response result, or its JSON-RPC error
+func (r *Response) result() (json.RawMessage, error) {
+	if r.Error != nil {
+		return nil, fmt.Errorf("RPC error %d: %s", r.Error.Code, r.Error.Message)
+	}
+	return r.Result, nil
 }
 
 func (c *Client) notify(method string, params interface{}) error {
@@ -292,48 +659,138 @@ func (c *Client) notify(method string, params interface{}) error {
 		Method:  method,
 		Params:  params,
 	}
-	return c.send(req)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return c.send(ctx, req)
 }
 
-func (c *Client) send(req Request) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
+func (c *Client) send(ctx context.Context, req Request) error {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
+	return c.transport.Send(ctx, data)
+}
 
-	data = append(data, '\n')
-	if _, err := c.stdin.Write(data); err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+// handleMessage routes a message from the server: responses go to the
+// waiting caller, requests and notifications to handleServerMessage
+func (c *Client) handleMessage(data []byte) {
+	var msg struct {
+		Response
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
 	}
 
-	return nil
+	if msg.Method != "" {
+		c.handleServerMessage(msg.ID, msg.Method, msg.Params)
+		return
+	}
+
+	// Route response to waiting caller. The entry is removed under the lock
+	// and the send happens outside it; the channel is buffered, so a
+	// duplicate or late response is dropped rather than blocking the reader.
+	resp := msg.Response
+	id, ok := normalizeID(resp.ID)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	ch, ok := c.pending[id]
+	delete(c.pending, id)
+	c.mu.Unlock()
+	if ok {
+		select {
+		case ch <- &resp:
+		default:
+		}
+	}
 }
 
-func (c *Client) readResponses() {
-	for {
-		line, err := c.stdout.ReadBytes('\n')
-		if err != nil {
-			if err != io.EOF {
-				fmt.Printf("[%s] read error: %v\n", c.name, err)
-			}
-			return
+// normalizeID converts a decoded JSON-RPC ID to the int64 the client sent.
+// encoding/json decodes numbers into float64, and some servers echo IDs
+// as strings.
+func normalizeID(id interface{}) (int64, bool) {
+	switch v := id.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
 		}
+		return int64(v), true
+	case int64:
+		return v, true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
 
-		var resp Response
-		if err := json.Unmarshal(line, &resp); err != nil {
-			continue
+// handleServerMessage handles a request or notification from the server
+func (c *Client) handleServerMessage(id interface{}, method string, params json.RawMessage) {
+	// Requests from the server; only ping is supported. Reply off the read
+	// loop so a blocked write cannot stall it.
+	if id != nil {
+		reply := Response{JSONRPC: "2.0", ID: id, Result: json.RawMessage("{}")}
+		if method != "ping" {
+			reply = Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: -32601, Message: "method not found: " + method}}
 		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			c.sendRaw(ctx, reply)
+		}()
+		return
+	}
 
-		// Route response to waiting caller
-		if resp.ID != nil {
-			c.mu.Lock()
-			if ch, ok := c.pending[resp.ID]; ok {
-				ch <- &resp
+	switch method {
+	case "notifications/progress":
+		var p Progress
+		if err := json.Unmarshal(params, &p); err != nil {
+			return
+		}
+		c.stateMu.RLock()
+		fn := c.progress[fmt.Sprint(p.ProgressToken)]
+		c.stateMu.RUnlock()
+		if fn != nil {
+			fn(p)
+		}
+		return
+	case "notifications/tools/list_changed":
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := c.listTools(ctx); err != nil {
+				log.Printf("[%s] failed to refresh tools: %v", c.name, err)
 			}
-			c.mu.Unlock()
+			c.notifyHandler(method, params)
+		}()
+		return
+	case "notifications/message":
+		var m LogMessage
+		if err := json.Unmarshal(params, &m); err == nil {
+			log.Printf("[%s] %s: %v", c.name, m.Level, m.Data)
 		}
 	}
+	c.notifyHandler(method, params)
+}
+
+func (c *Client) notifyHandler(method string, params json.RawMessage) {
+	if c.onNotification != nil {
+		c.onNotification(Notification{Server: c.name, Method: method, Params: params})
+	}
+}
+
+// sendRaw sends a pre-built message such as a reply to a server request
+func (c *Client) sendRaw(ctx context.Context, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return c.transport.Send(ctx, data)
 }
@@ -0,0 +1,639 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Built-in server names
+const (
+	OpsServer    = "klaude-ops"
+	DeployServer = "klaude-deploy"
+)
+
+const (
+	// restartMinBackoff and restartMaxBackoff bound the delay between
+	// attempts to restart a crashed MCP server
+	restartMinBackoff = 1 * time.Second
+	restartMaxBackoff = 2 * time.Minute
+
+	// restartStablePeriod is how long a server must run before a crash
+	// resets the backoff
+	restartStablePeriod = 1 * time.Minute
+
+	// restartStartTimeout bounds initialize and tools/list on restart
+	restartStartTimeout = 30 * time.Second
+
+	// maxServerLogs is how many log messages are kept per server
+	maxServerLogs = 100
+)
+
+var (
+	// ErrServerNotFound is returned for a server name that is not configured
+	ErrServerNotFound = errors.New("MCP server not found")
+
+	// ErrServerNotAvailable is returned for a configured server that is
+	// disabled or not running
+	ErrServerNotAvailable = errors.New("MCP server not available")
+
+	// ErrToolNotFound is returned for a tool no running server offers
+	ErrToolNotFound = errors.New("MCP tool not found")
+)
+
+// Transport names for ServerConfig.Transport
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+	TransportSSE   = "sse"
+)
+
+// ServerConfig describes an MCP server. Stdio servers are started from
+// Command; "http" (Streamable HTTP) and "sse" (legacy HTTP+SSE) servers are
+// reached at URL. Command, Args, Env, URL and Headers values may reference
+// environment variables as $VAR or ${VAR}.
+type ServerConfig struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Transport   string            `json:"transport,omitempty"`
+	Command     string            `json:"command,omitempty"`
+	Args        []string          `json:"args,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled,omitempty"`
+	// RequestTimeout and ToolTimeout override DefaultRequestTimeout and
+	// DefaultToolTimeout; ToolTimeouts sets them for individual tools
+	RequestTimeout Duration            `json:"requestTimeout,omitempty"`
+	ToolTimeout    Duration            `json:"toolTimeout,omitempty"`
+	ToolTimeouts   map[string]Duration `json:"toolTimeouts,omitempty"`
+}
+
+// Duration is a time.Duration written as "90s" or "2m" in config files
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if parsed < 0 {
+		return fmt.Errorf("duration %q must not be negative", s)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// timeouts converts the configured timeouts for Client.SetTimeouts
+func (c ServerConfig) timeouts() Timeouts {
+	t := Timeouts{
+		Request: time.Duration(c.RequestTimeout),
+		Tool:    time.Duration(c.ToolTimeout),
+	}
+	if len(c.ToolTimeouts) > 0 {
+		t.PerTool = make(map[string]time.Duration, len(c.ToolTimeouts))
+		for tool, d := range c.ToolTimeouts {
+			t.PerTool[tool] = time.Duration(d)
+		}
+	}
+	return t
+}
+
+// IsEnabled reports whether the server should be started
+func (c ServerConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// TransportName returns the configured transport, defaulting to stdio
+func (c ServerConfig) TransportName() string {
+	if c.Transport == "" {
+		return TransportStdio
+	}
+	return c.Transport
+}
+
+// validate checks that the fields required by the transport are set
+func (c ServerConfig) validate() error {
+	switch c.TransportName() {
+	case TransportStdio:
+		if c.Command == "" {
+			return fmt.Errorf("MCP server %q: command is required", c.Name)
+		}
+	case TransportHTTP, TransportSSE:
+		if c.URL == "" {
+			return fmt.Errorf("MCP server %q: url is required", c.Name)
+		}
+	default:
+		return fmt.Errorf("MCP server %q: unknown transport %q", c.Name, c.Transport)
+	}
+	return nil
+}
+
+// serversFile is the on-disk format of the MCP servers config, YAML or JSON:
+//
+//	servers:
+//	  - name: my-server
+//	    command: my-mcp-server
+//	    args: ["--stdio"]
+//	    env: {API_TOKEN: "${MY_TOKEN}"}
+//	    enabled: true
+//	    toolTimeout: 5m
+//	    toolTimeouts: {quick_check: 10s}
+//	  - name: shared-ops
+//	    transport: http
+//	    url: https://klaude-ops.example.com/mcp
+//	    headers: {Authorization: "Bearer ${OPS_TOKEN}"}
+type serversFile struct {
+	Servers []ServerConfig `json:"servers"`
+}
+
+// LoadServersConfig reads MCP server definitions from a YAML or JSON file
+func LoadServersConfig(path string) ([]ServerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP servers config: %w", err)
+	}
+
+	var file serversFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse MCP servers config %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, cfg := range file.Servers {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("MCP server #%d: name is required", i+1)
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("MCP server %q: duplicate name", cfg.Name)
+		}
+		seen[cfg.Name] = true
+		if err := cfg.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return file.Servers, nil
+}
+
+// ServerStatus describes a registered MCP server
+type ServerStatus struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Transport   string     `json:"transport"`
+	Command     string     `json:"command,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	Enabled     bool       `json:"enabled"`
+	Available   bool       `json:"available"`
+	ToolCount   int        `json:"toolCount"`
+	Restarts    int        `json:"restarts"`
+	Restarting  bool       `json:"restarting,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	// Calls counts requests to the current connection
+	Calls CallStats `json:"calls"`
+}
+
+// managedServer is a registered MCP server and its supervised client
+type managedServer struct {
+	config ServerConfig
+	client *Client
+
+	restarts    int
+	restarting  bool
+	lastError   string
+	lastErrorAt time.Time
+	startedAt   time.Time
+
+	// logs holds the most recent notifications/message payloads
+	logs []LogMessage
+	// subscriptions are resource URIs to resubscribe after a restart
+	subscriptions map[string]bool
+}
+
+// serverConfigs returns the built-in klaude servers followed by the
+// configured ones; a configured server replaces a built-in of the same name
+func (c BridgeConfig) serverConfigs() []ServerConfig {
+	var kubeconfigArgs []string
+	if c.Kubeconfig != "" {
+		kubeconfigArgs = []string{"--kubeconfig", c.Kubeconfig}
+	}
+
+	byName := make(map[string]ServerConfig)
+	var names []string
+	add := func(cfg ServerConfig) {
+		if _, ok := byName[cfg.Name]; !ok {
+			names = append(names, cfg.Name)
+		}
+		byName[cfg.Name] = cfg
+	}
+
+	if c.KlaudeOpsPath != "" {
+		add(ServerConfig{
+			Name:        OpsServer,
+			Description: "Multi-cluster operations",
+			Command:     c.KlaudeOpsPath,
+			Args:        append([]string{"--mcp-server"}, kubeconfigArgs...),
+		})
+	}
+	if c.KlaudeDeployPath != "" {
+		add(ServerConfig{
+			Name:        DeployServer,
+			Description: "Deployment and GitOps",
+			Command:     c.KlaudeDeployPath,
+			Args:        append([]string{"--mcp"}, kubeconfigArgs...),
+		})
+	}
+	for _, cfg := range c.Servers {
+		add(cfg)
+	}
+
+	configs := make([]ServerConfig, 0, len(names))
+	for _, name := range names {
+		configs = append(configs, byName[name])
+	}
+	return configs
+}
+
+// Start initializes and starts all enabled MCP servers
+func (b *Bridge) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(b.order))
+
+	for _, name := range b.order {
+		s := b.servers[name]
+		if !s.config.IsEnabled() {
+			continue
+		}
+		wg.Add(1)
+		go func(s *managedServer) {
+			defer wg.Done()
+			client, err := startServer(ctx, s.config, b.handleNotification)
+			if err != nil {
+				b.mu.Lock()
+				s.restarting = true
+				s.lastError = err.Error()
+				s.lastErrorAt = time.Now()
+				b.mu.Unlock()
+				errCh <- fmt.Errorf("%s: %w", s.config.Name, err)
+				// Keep trying in the background, e.g. while the binary is
+				// installed or the remote server comes up
+				go b.supervise(s, nil)
+				return
+			}
+
+			b.mu.Lock()
+			s.client = client
+			s.startedAt = time.Now()
+			b.mu.Unlock()
+			go b.supervise(s, client)
+		}(s)
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	// Collect any errors
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to start MCP clients: %v", errs)
+	}
+
+	return nil
+}
+
+// Stop stops all MCP clients and their supervisors
+func (b *Bridge) Stop() error {
+	b.cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var errs []error
+	for _, name := range b.order {
+		if client := b.servers[name].client; client != nil {
+			if err := client.Stop(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors stopping clients: %v", errs)
+	}
+
+	return nil
+}
+
+// startServer connects to an MCP server and initializes it
+func startServer(ctx context.Context, cfg ServerConfig, onNotification func(Notification)) (*Client, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClientWithTransport(cfg.Name, transport)
+	client.SetTimeouts(cfg.timeouts())
+	client.OnNotification(onNotification)
+	if err := client.Start(ctx); err != nil {
+		client.Stop()
+		return nil, err
+	}
+	return client, nil
+}
+
+// newTransport creates the transport described by cfg
+func newTransport(cfg ServerConfig) (Transport, error) {
+	headers := make(map[string]string, len(cfg.Headers))
+	for key, value := range cfg.Headers {
+		headers[key] = os.ExpandEnv(value)
+	}
+
+	switch cfg.TransportName() {
+	case TransportHTTP:
+		return NewHTTPTransport(cfg.Name, os.ExpandEnv(cfg.URL), headers), nil
+	case TransportSSE:
+		return NewSSETransport(cfg.Name, os.ExpandEnv(cfg.URL), headers), nil
+	}
+
+	args := make([]string, len(cfg.Args))
+	for i, arg := range cfg.Args {
+		args[i] = os.ExpandEnv(arg)
+	}
+	var env []string
+	for key, value := range cfg.Env {
+		env = append(env, key+"="+os.ExpandEnv(value))
+	}
+	return NewStdioTransport(cfg.Name, os.ExpandEnv(cfg.Command), args, env)
+}
+
+// supervise waits for the server behind client to exit and restarts it with
+// exponential backoff, re-running initialize and tools/list, until Stop is
+// called. A nil client is a server whose first start failed; it is started
+// with the same backoff.
+func (b *Bridge) supervise(s *managedServer, client *Client) {
+	name := s.config.Name
+	backoff := restartMinBackoff
+	for {
+		started := client != nil
+		if started {
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-client.Done():
+			}
+			if b.ctx.Err() != nil {
+				return
+			}
+
+			b.mu.Lock()
+			if time.Since(s.startedAt) > restartStablePeriod {
+				backoff = restartMinBackoff
+			}
+			s.restarting = true
+			s.lastError = client.Err().Error()
+			s.lastErrorAt = time.Now()
+			b.mu.Unlock()
+			log.Printf("MCP %s: %v; restarting in %s", name, client.Err(), backoff)
+		}
+
+		for {
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > restartMaxBackoff {
+				backoff = restartMaxBackoff
+			}
+
+			ctx, cancel := context.WithTimeout(b.ctx, restartStartTimeout)
+			next, err := startServer(ctx, s.config, b.handleNotification)
+			if err == nil {
+				b.resubscribe(ctx, s, next)
+				cancel()
+				client = next
+				break
+			}
+			cancel()
+
+			b.mu.Lock()
+			s.lastError = err.Error()
+			s.lastErrorAt = time.Now()
+			b.mu.Unlock()
+			if b.ctx.Err() != nil {
+				return
+			}
+			log.Printf("MCP %s restart failed: %v; retrying in %s", name, err, backoff)
+		}
+
+		b.mu.Lock()
+		if b.ctx.Err() != nil {
+			b.mu.Unlock()
+			client.Stop()
+			return
+		}
+		s.client = client
+		if started {
+			s.restarts++
+		}
+		s.restarting = false
+		s.startedAt = time.Now()
+		restarts := s.restarts
+		b.mu.Unlock()
+		if started {
+			log.Printf("MCP %s restarted (%d tools, restart #%d)", name, len(client.Tools()), restarts)
+		} else {
+			log.Printf("MCP %s started (%d tools)", name, len(client.Tools()))
+		}
+	}
+}
+
+// client returns the running client for a server
+func (b *Bridge) client(name string) (*Client, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	s, ok := b.servers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrServerNotFound, name)
+	}
+	if s.client == nil {
+		return nil, fmt.Errorf("%w: %s", ErrServerNotAvailable, name)
+	}
+	return s.client, nil
+}
+
+// Servers returns the status of all registered MCP servers
+func (b *Bridge) Servers() []ServerStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	servers := make([]ServerStatus, 0, len(b.order))
+	for _, name := range b.order {
+		servers = append(servers, b.serverStatusLocked(b.servers[name]))
+	}
+	return servers
+}
+
+func (b *Bridge) serverStatusLocked(s *managedServer) ServerStatus {
+	status := ServerStatus{
+		Name:        s.config.Name,
+		Description: s.config.Description,
+		Transport:   s.config.TransportName(),
+		Command:     s.config.Command,
+		URL:         s.config.URL,
+		Enabled:     s.config.IsEnabled(),
+		Available:   s.client != nil && s.client.IsReady(),
+		Restarts:    s.restarts,
+		Restarting:  s.restarting,
+		LastError:   s.lastError,
+	}
+	if status.Available {
+		status.ToolCount = len(s.client.Tools())
+	}
+	if s.client != nil {
+		status.Calls = s.client.Stats()
+	}
+	if !s.lastErrorAt.IsZero() {
+		at := s.lastErrorAt
+		status.LastErrorAt = &at
+	}
+	return status
+}
+
+// GetTools returns the tools of a named server
+func (b *Bridge) GetTools(server string) ([]Tool, error) {
+	client, err := b.client(server)
+	if err != nil {
+		return nil, err
+	}
+	return client.Tools(), nil
+}
+
+// FindTool looks a tool up by name. An empty server searches all running
+// servers in registration order; the name of the server offering the tool
+// is returned.
+func (b *Bridge) FindTool(server, name string) (string, Tool, error) {
+	servers := []string{server}
+	if server == "" {
+		b.mu.RLock()
+		servers = append([]string(nil), b.order...)
+		b.mu.RUnlock()
+	}
+
+	for _, s := range servers {
+		client, err := b.client(s)
+		if err != nil {
+			if server != "" {
+				return "", Tool{}, err
+			}
+			continue
+		}
+		if tool, ok := client.Tool(name); ok {
+			return s, tool, nil
+		}
+	}
+	return "", Tool{}, fmt.Errorf("%w: %s", ErrToolNotFound, name)
+}
+
+// CallTool calls a tool on a named server
+func (b *Bridge) CallTool(ctx context.Context, server, tool string, args map[string]interface{}) (*CallToolResult, error) {
+	client, err := b.client(server)
+	if err != nil {
+		return nil, err
+	}
+	return client.CallTool(ctx, tool, args)
+}
+
+// CallToolWithProgress calls a tool on a named server, reporting the
+// server's progress notifications to onProgress
+func (b *Bridge) CallToolWithProgress(ctx context.Context, server, tool string, args map[string]interface{}, onProgress func(Progress)) (*CallToolResult, error) {
+	client, err := b.client(server)
+	if err != nil {
+		return nil, err
+	}
+	return client.CallToolWithProgress(ctx, tool, args, onProgress)
+}
+
+// OnNotification sets a callback for notifications from any server, such as
+// resource updates and list changes. It must be called before Start.
+func (b *Bridge) OnNotification(callback func(Notification)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onNotification = callback
+}
+
+// handleNotification records server log messages and forwards notifications
+// to the OnNotification callback
+func (b *Bridge) handleNotification(n Notification) {
+	b.mu.Lock()
+	if n.Method == "notifications/message" {
+		if s, ok := b.servers[n.Server]; ok {
+			var m LogMessage
+			if err := json.Unmarshal(n.Params, &m); err == nil {
+				m.Time = time.Now()
+				s.logs = append(s.logs, m)
+				if len(s.logs) > maxServerLogs {
+					s.logs = s.logs[len(s.logs)-maxServerLogs:]
+				}
+			}
+		}
+	}
+	callback := b.onNotification
+	b.mu.Unlock()
+
+	if callback != nil {
+		callback(n)
+	}
+}
+
+// resubscribe restores the resource subscriptions of a restarted server
+func (b *Bridge) resubscribe(ctx context.Context, s *managedServer, client *Client) {
+	b.mu.RLock()
+	uris := make([]string, 0, len(s.subscriptions))
+	for uri := range s.subscriptions {
+		uris = append(uris, uri)
+	}
+	b.mu.RUnlock()
+
+	for _, uri := range uris {
+		if err := client.SubscribeResource(ctx, uri); err != nil {
+			log.Printf("MCP %s: failed to resubscribe to %s: %v", s.config.Name, uri, err)
+		}
+	}
+}
+
+// Logs returns the recent log messages of a named server, oldest first
+func (b *Bridge) Logs(server string) ([]LogMessage, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	s, ok := b.servers[server]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrServerNotFound, server)
+	}
+	logs := make([]LogMessage, len(s.logs))
+	copy(logs, s.logs)
+	return logs, nil
+}
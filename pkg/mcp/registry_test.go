@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kubestellar/console/pkg/metrics"
+)
+
+// fakeServerPath is the stdio MCP server built from testdata/fakeserver
+var fakeServerPath string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "mcp-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fakeServerPath = filepath.Join(dir, "fakeserver")
+	build := exec.Command("go", "build", "-o", fakeServerPath, "./testdata/fakeserver")
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "build fake MCP server: %v\n", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func startBridge(t *testing.T, command string) (*Bridge, error) {
+	t.Helper()
+	b := NewBridge(BridgeConfig{Servers: []ServerConfig{{Name: "fake", Command: command}}})
+	t.Cleanup(func() { b.Stop() })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return b, b.Start(ctx)
+}
+
+// waitForStatus polls the fake server's status until cond holds
+func waitForStatus(t *testing.T, b *Bridge, what string, cond func(ServerStatus) bool) ServerStatus {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		status := b.Servers()[0]
+		if cond(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s; status %+v", what, status)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func callEcho(t *testing.T, b *Bridge, text string) {
+	t.Helper()
+	result, err := b.CallTool(context.Background(), "fake", "echo", map[string]interface{}{"text": text})
+	if err != nil {
+		t.Fatalf("echo: %v", err)
+	}
+	if len(result.Content) != 1 || result.Content[0].Text != text {
+		t.Fatalf("echo returned %+v, want %q", result.Content, text)
+	}
+}
+
+func TestBridgeStartsServer(t *testing.T) {
+	b, err := startBridge(t, fakeServerPath)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	status := b.Servers()[0]
+	if !status.Available || status.ToolCount != 2 || status.Restarting {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	callEcho(t, b, "hello")
+}
+
+func TestBridgeRestartsCrashedServer(t *testing.T) {
+	b, err := startBridge(t, fakeServerPath)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if _, err := b.CallTool(context.Background(), "fake", "crash", nil); !errors.Is(err, ErrServerExited) {
+		t.Fatalf("crash returned %v, want ErrServerExited", err)
+	}
+	status := waitForStatus(t, b, "restart", func(s ServerStatus) bool {
+		return s.Available && s.Restarts == 1
+	})
+	if status.LastError == "" || status.Restarting {
+		t.Fatalf("unexpected status after restart %+v", status)
+	}
+	callEcho(t, b, "after restart")
+}
+
+func TestBridgeRetriesFailedInitialStart(t *testing.T) {
+	// The binary appears only after the first start failed, as when the
+	// console starts before a server is installed
+	command := filepath.Join(t.TempDir(), "fakeserver")
+	b, err := startBridge(t, command)
+	if err == nil {
+		t.Fatal("Start succeeded without a server binary")
+	}
+
+	status := b.Servers()[0]
+	if status.Available || !status.Restarting || status.LastError == "" {
+		t.Fatalf("unexpected status after failed start %+v", status)
+	}
+
+	data, err := os.ReadFile(fakeServerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(command, data, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	status = waitForStatus(t, b, "start", func(s ServerStatus) bool { return s.Available })
+	if status.Restarts != 0 || status.Restarting {
+		t.Fatalf("unexpected status after start %+v", status)
+	}
+	callEcho(t, b, "late start")
+}
+
+func TestBridgeLabelsUnlistedTools(t *testing.T) {
+	b, err := startBridge(t, fakeServerPath)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	callEcho(t, b, "listed")
+	if _, err := b.CallTool(context.Background(), "fake", "no-such-tool", nil); err != nil {
+		t.Fatalf("unlisted tool: %v", err)
+	}
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := map[string]bool{}
+	for _, family := range families {
+		if family.GetName() != "kubestellar_console_mcp_calls_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "tool" {
+					tools[label.GetValue()] = true
+				}
+			}
+		}
+	}
+	if !tools["echo"] || !tools[metrics.UnknownTool] || tools["no-such-tool"] {
+		t.Fatalf("tool labels = %v", tools)
+	}
+}
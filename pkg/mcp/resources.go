@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNotSupported is returned for features the server did not announce
+var ErrNotSupported = errors.New("not supported by MCP server")
+
+// Resource is a piece of context an MCP server exposes by URI
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+type ResourcesListResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ResourceContents is the text or base64 blob content of a resource
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// Prompt is a prompt template offered by an MCP server
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+type PromptsListResult struct {
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+type PromptMessage struct {
+	Role    string      `json:"role"`
+	Content ContentItem `json:"content"`
+}
+
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// maxListPages bounds cursor pagination of list requests
+const maxListPages = 100
+
+// ListResources returns all resources of the server, following pagination.
+// Servers without the resources capability have none.
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	if c.Capabilities().Resources == nil {
+		return []Resource{}, nil
+	}
+
+	resources := []Resource{}
+	cursor := ""
+	for page := 0; page < maxListPages; page++ {
+		var result ResourcesListResult
+		if err := c.callInto(ctx, "resources/list", cursorParams(cursor), &result); err != nil {
+			return nil, err
+		}
+		resources = append(resources, result.Resources...)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	return resources, nil
+}
+
+// ReadResource returns the contents of a resource
+func (c *Client) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	if c.Capabilities().Resources == nil {
+		return nil, fmt.Errorf("resources: %w", ErrNotSupported)
+	}
+
+	var result ReadResourceResult
+	if err := c.callInto(ctx, "resources/read", map[string]string{"uri": uri}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SubscribeResource asks the server to send notifications/resources/updated
+// when the resource changes
+func (c *Client) SubscribeResource(ctx context.Context, uri string) error {
+	if caps := c.Capabilities().Resources; caps == nil || !caps.Subscribe {
+		return fmt.Errorf("resource subscriptions: %w", ErrNotSupported)
+	}
+	_, err := c.call(ctx, "resources/subscribe", map[string]string{"uri": uri})
+	return err
+}
+
+// UnsubscribeResource cancels a resource subscription
+func (c *Client) UnsubscribeResource(ctx context.Context, uri string) error {
+	if caps := c.Capabilities().Resources; caps == nil || !caps.Subscribe {
+		return fmt.Errorf("resource subscriptions: %w", ErrNotSupported)
+	}
+	_, err := c.call(ctx, "resources/unsubscribe", map[string]string{"uri": uri})
+	return err
+}
+
+// ListPrompts returns all prompts of the server, following pagination.
+// Servers without the prompts capability have none.
+func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
+	if c.Capabilities().Prompts == nil {
+		return []Prompt{}, nil
+	}
+
+	prompts := []Prompt{}
+	cursor := ""
+	for page := 0; page < maxListPages; page++ {
+		var result PromptsListResult
+		if err := c.callInto(ctx, "prompts/list", cursorParams(cursor), &result); err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, result.Prompts...)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	return prompts, nil
+}
+
+// GetPrompt renders a prompt with the given arguments
+func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]string) (*GetPromptResult, error) {
+	if c.Capabilities().Prompts == nil {
+		return nil, fmt.Errorf("prompts: %w", ErrNotSupported)
+	}
+
+	params := map[string]interface{}{"name": name}
+	if len(args) > 0 {
+		params["arguments"] = args
+	}
+	var result GetPromptResult
+	if err := c.callInto(ctx, "prompts/get", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// callInto makes a request and decodes its result into v
+func (c *Client) callInto(ctx context.Context, method string, params, v interface{}) error {
+	result, err := c.call(ctx, method, params)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(result, v); err != nil {
+		return fmt.Errorf("failed to parse %s result: %w", method, err)
+	}
+	return nil
+}
+
+func cursorParams(cursor string) interface{} {
+	if cursor == "" {
+		return nil
+	}
+	return map[string]string{"cursor": cursor}
+}
+
+// ListResources returns the resources of a named server
+func (b *Bridge) ListResources(ctx context.Context, server string) ([]Resource, error) {
+	client, err := b.client(server)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListResources(ctx)
+}
+
+// ReadResource reads a resource from a named server
+func (b *Bridge) ReadResource(ctx context.Context, server, uri string) (*ReadResourceResult, error) {
+	client, err := b.client(server)
+	if err != nil {
+		return nil, err
+	}
+	return client.ReadResource(ctx, uri)
+}
+
+// SubscribeResource subscribes to updates of a resource on a named server.
+// The subscription is restored when the server restarts.
+func (b *Bridge) SubscribeResource(ctx context.Context, server, uri string) error {
+	client, err := b.client(server)
+	if err != nil {
+		return err
+	}
+	if err := client.SubscribeResource(ctx, uri); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.servers[server].subscriptions[uri] = true
+	b.mu.Unlock()
+	return nil
+}
+
+// UnsubscribeResource cancels a resource subscription on a named server
+func (b *Bridge) UnsubscribeResource(ctx context.Context, server, uri string) error {
+	client, err := b.client(server)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	delete(b.servers[server].subscriptions, uri)
+	b.mu.Unlock()
+	return client.UnsubscribeResource(ctx, uri)
+}
+
+// ListPrompts returns the prompts of a named server
+func (b *Bridge) ListPrompts(ctx context.Context, server string) ([]Prompt, error) {
+	client, err := b.client(server)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListPrompts(ctx)
+}
+
+// GetPrompt renders a prompt of a named server
+func (b *Bridge) GetPrompt(ctx context.Context, server, name string, args map[string]string) (*GetPromptResult, error) {
+	client, err := b.client(server)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetPrompt(ctx, name, args)
+}
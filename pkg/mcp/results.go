@@ -0,0 +1,254 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrUnparseableResult is matched by ParseError
+var ErrUnparseableResult = errors.New("unparseable MCP tool result")
+
+// ToolError is returned when a tool reports failure with isError
+type ToolError struct {
+	Tool    string
+	Message string
+}
+
+func (e *ToolError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("tool %s failed", e.Tool)
+	}
+	return fmt.Sprintf("tool %s failed: %s", e.Tool, e.Message)
+}
+
+// ParseError is returned when a tool result holds no data in the expected
+// shape
+type ParseError struct {
+	Tool string
+	Err  error
+	// Snippet is the start of the result text, for diagnosis
+	Snippet string
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("failed to parse %s result: %v", e.Tool, e.Err)
+	if e.Snippet != "" {
+		msg += fmt.Sprintf(" (got %q)", e.Snippet)
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Is makes errors.Is(err, ErrUnparseableResult) match any ParseError
+func (e *ParseError) Is(target error) bool { return target == ErrUnparseableResult }
+
+// maxSnippetLen bounds ParseError.Snippet
+const maxSnippetLen = 200
+
+// Text returns the text items of the result joined by newlines
+func (r *CallToolResult) Text() string {
+	var parts []string
+	for _, item := range r.Content {
+		if item.Type == "text" && item.Text != "" {
+			parts = append(parts, item.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Decode unmarshals the result's data into v, preferring structuredContent
+// and otherwise trying each text item and embedded text resource that holds
+// JSON. When key is set, a value wrapped as {"<key>": ...} is unwrapped, as
+// structuredContent must be an object even for lists.
+func (r *CallToolResult) Decode(tool, key string, v interface{}) error {
+	if r.IsError {
+		return &ToolError{Tool: tool, Message: r.Text()}
+	}
+
+	var candidates [][]byte
+	if data := bytes.TrimSpace(r.StructuredContent); len(data) > 0 && string(data) != "null" {
+		candidates = append(candidates, data)
+	}
+	for _, item := range r.Content {
+		switch {
+		case item.Type == "text":
+			if data := []byte(strings.TrimSpace(item.Text)); looksLikeJSON(data) {
+				candidates = append(candidates, data)
+			}
+		case item.Type == "resource" && item.Resource != nil:
+			if data := []byte(strings.TrimSpace(item.Resource.Text)); looksLikeJSON(data) {
+				candidates = append(candidates, data)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return r.parseError(tool, errors.New("no JSON content"))
+	}
+
+	var lastErr error
+	for _, data := range candidates {
+		if lastErr = decodeUnwrapped(data, key, v); lastErr == nil {
+			return nil
+		}
+	}
+	return r.parseError(tool, lastErr)
+}
+
+func (r *CallToolResult) parseError(tool string, err error) *ParseError {
+	snippet := r.Text()
+	if len(snippet) > maxSnippetLen {
+		snippet = snippet[:maxSnippetLen] + "..."
+	}
+	return &ParseError{Tool: tool, Err: err, Snippet: snippet}
+}
+
+// decodeUnwrapped unmarshals data into v, or data[key] if data is an object
+// wrapping the value
+func decodeUnwrapped(data []byte, key string, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if err == nil || key == "" {
+		return err
+	}
+
+	var wrapper map[string]json.RawMessage
+	if json.Unmarshal(data, &wrapper) != nil {
+		return err
+	}
+	inner, ok := wrapper[key]
+	if !ok {
+		return fmt.Errorf("%w; no %q field", err, key)
+	}
+	return json.Unmarshal(inner, v)
+}
+
+func looksLikeJSON(data []byte) bool {
+	return len(data) > 0 && (data[0] == '{' || data[0] == '[') && json.Valid(data)
+}
+
+// parseTable parses column-aligned text as printed by kubectl: a header of
+// upper-case column names followed by one row per line. Cells are cut at
+// the header's column offsets, so empty cells are kept. Keys are the
+// lower-cased column names.
+func parseTable(text string) ([]map[string]string, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")
+
+	header := -1
+	for i, line := range lines {
+		if isTableHeader(line) {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return nil, errors.New("no table header found")
+	}
+
+	type column struct {
+		name  string
+		start int
+	}
+	var columns []column
+	line := lines[header]
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+		end := strings.IndexByte(line[i:], ' ')
+		if end < 0 {
+			end = len(line) - i
+		}
+		columns = append(columns, column{name: strings.ToLower(line[i : i+end]), start: i})
+		i += end
+	}
+
+	var rows []map[string]string
+	for _, line := range lines[header+1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		row := make(map[string]string, len(columns))
+		for i, col := range columns {
+			if col.start >= len(line) {
+				break
+			}
+			end := len(line)
+			if i+1 < len(columns) && columns[i+1].start < end {
+				end = columns[i+1].start
+			}
+			row[col.name] = strings.TrimSpace(line[col.start:end])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// isTableHeader reports whether a line looks like "NAME   CONTEXT   ..."
+func isTableHeader(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return false
+	}
+	for _, f := range fields {
+		for _, r := range f {
+			if !unicode.IsUpper(r) && r != '_' && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseClustersFromText parses the human-readable list_clusters output
+func parseClustersFromText(text string) ([]ClusterInfo, error) {
+	rows, err := parseTable(text)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make([]ClusterInfo, 0, len(rows))
+	for _, row := range rows {
+		name := firstOf(row, "name", "cluster")
+		if name == "" {
+			return nil, errors.New("table has no NAME column")
+		}
+		cluster := ClusterInfo{
+			Name:    name,
+			Context: firstOf(row, "context"),
+			Server:  firstOf(row, "server", "api-server", "apiserver"),
+			Source:  firstOf(row, "source"),
+			Healthy: isHealthyStatus(firstOf(row, "healthy", "status", "health")),
+		}
+		if cluster.Context == "" {
+			cluster.Context = name
+		}
+		cluster.NodeCount, _ = strconv.Atoi(firstOf(row, "nodes"))
+		cluster.PodCount, _ = strconv.Atoi(firstOf(row, "pods"))
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+func firstOf(row map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if v := row[key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func isHealthyStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "true", "yes", "healthy", "ready", "ok", "available", "✓":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func textItem(text string) ContentItem {
+	return ContentItem{Type: "text", Text: text}
+}
+
+func TestDecode(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name   string
+		result CallToolResult
+		key    string
+		want   []item
+		// err is matched with errors.Is when set
+		err error
+	}{
+		{
+			name: "structuredContent is preferred over text",
+			result: CallToolResult{
+				StructuredContent: json.RawMessage(`{"items": [{"name": "structured"}]}`),
+				Content:           []ContentItem{textItem(`[{"name": "text"}]`)},
+			},
+			key:  "items",
+			want: []item{{"structured"}},
+		},
+		{
+			name: "null structuredContent falls back to text",
+			result: CallToolResult{
+				StructuredContent: json.RawMessage(`null`),
+				Content:           []ContentItem{textItem(`[{"name": "text"}]`)},
+			},
+			want: []item{{"text"}},
+		},
+		{
+			name: "JSON in a later text item",
+			result: CallToolResult{Content: []ContentItem{
+				textItem("Found 1 pod:"),
+				textItem("  [{\"name\": \"web\"}]\n"),
+			}},
+			want: []item{{"web"}},
+		},
+		{
+			name: "JSON in an embedded resource",
+			result: CallToolResult{Content: []ContentItem{
+				{Type: "resource", Resource: &ResourceContents{URI: "k8s://pods", Text: `{"items": [{"name": "db"}]}`}},
+			}},
+			key:  "items",
+			want: []item{{"db"}},
+		},
+		{
+			name: "wrong shape falls through to the next candidate",
+			result: CallToolResult{
+				StructuredContent: json.RawMessage(`{"count": 1}`),
+				Content:           []ContentItem{textItem(`[{"name": "fallback"}]`)},
+			},
+			key:  "items",
+			want: []item{{"fallback"}},
+		},
+		{
+			name:   "prose only",
+			result: CallToolResult{Content: []ContentItem{textItem("no pods found")}},
+			err:    ErrUnparseableResult,
+		},
+		{
+			name:   "JSON of the wrong shape",
+			result: CallToolResult{Content: []ContentItem{textItem(`{"count": 1}`)}},
+			key:    "items",
+			err:    ErrUnparseableResult,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []item
+			err := tt.result.Decode("list_pods", tt.key, &got)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("decoded %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeToolError(t *testing.T) {
+	result := CallToolResult{
+		IsError:           true,
+		StructuredContent: json.RawMessage(`{"items": []}`),
+		Content:           []ContentItem{textItem("cluster kind is unreachable")},
+	}
+	var got []interface{}
+	err := result.Decode("list_pods", "items", &got)
+
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Tool != "list_pods" || toolErr.Message != "cluster kind is unreachable" {
+		t.Fatalf("error = %v, want the tool's message", err)
+	}
+	if errors.Is(err, ErrUnparseableResult) {
+		t.Error("a tool error was reported as unparseable")
+	}
+}
+
+func TestParseErrorSnippet(t *testing.T) {
+	result := CallToolResult{Content: []ContentItem{textItem(strings.Repeat("x", maxSnippetLen+50))}}
+	var got []interface{}
+	var parseErr *ParseError
+	if err := result.Decode("list_pods", "", &got); !errors.As(err, &parseErr) {
+		t.Fatalf("error = %v", err)
+	}
+	if len(parseErr.Snippet) != maxSnippetLen+len("...") {
+		t.Errorf("snippet is %d bytes", len(parseErr.Snippet))
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	text := strings.Join([]string{
+		"Clusters in kubeconfig:",
+		"",
+		"CURRENT   NAME     CLUSTER       NAMESPACE",
+		"*         kind     kind-kind     default",
+		"          prod     prod-east",
+		"          edge                   edge-ns",
+	}, "\n")
+
+	rows, err := parseTable(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"current": "*", "name": "kind", "cluster": "kind-kind", "namespace": "default"},
+		// A row ending early has no trailing cells
+		{"current": "", "name": "prod", "cluster": "prod-east"},
+		// Empty cells in the middle keep the columns aligned
+		{"current": "", "name": "edge", "cluster": "", "namespace": "edge-ns"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows = %v, want %v", rows, want)
+	}
+
+	if _, err := parseTable("no clusters are configured"); err == nil {
+		t.Error("parseTable accepted text without a header")
+	}
+}
+
+func TestParseClustersFromText(t *testing.T) {
+	text := strings.Join([]string{
+		"NAME    CONTEXT         SERVER                   STATUS    NODES",
+		"kind    kind-kind       https://127.0.0.1:6443   Healthy   1",
+		"prod                    https://prod:6443        Error     x",
+	}, "\n")
+
+	clusters, err := parseClustersFromText(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ClusterInfo{
+		{Name: "kind", Context: "kind-kind", Server: "https://127.0.0.1:6443", Healthy: true, NodeCount: 1},
+		// The context defaults to the name and bad counts are ignored
+		{Name: "prod", Context: "prod", Server: "https://prod:6443"},
+	}
+	if !reflect.DeepEqual(clusters, want) {
+		t.Fatalf("clusters = %+v, want %+v", clusters, want)
+	}
+
+	if _, err := parseClustersFromText("CONTEXT   SERVER\nkind      https://kind"); err == nil {
+		t.Error("a table without a NAME column was accepted")
+	}
+}
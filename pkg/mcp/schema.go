@@ -0,0 +1,482 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidArguments is matched by ValidationErrors for tool arguments
+	ErrInvalidArguments = errors.New("invalid tool arguments")
+
+	// ErrInvalidOutput is matched by ValidationErrors for structuredContent
+	// that does not match the tool's output schema
+	ErrInvalidOutput = errors.New("invalid tool output")
+)
+
+// Schema is the subset of JSON Schema used by MCP tool input schemas. It is
+// recursive, so nested objects and arrays of objects can be described and
+// validated.
+type Schema struct {
+	Type        SchemaType         `json:"type,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Const       interface{}        `json:"const,omitempty"`
+	Default     interface{}        `json:"default,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	AnyOf       []*Schema          `json:"anyOf,omitempty"`
+	OneOf       []*Schema          `json:"oneOf,omitempty"`
+	AllOf       []*Schema          `json:"allOf,omitempty"`
+	// AdditionalProperties is false, true or a schema for unlisted properties
+	AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+}
+
+// InputSchema is the schema of a tool's arguments object
+type InputSchema = Schema
+
+// SchemaType holds the JSON Schema "type" keyword, which may be a single
+// type name or a list of them
+type SchemaType []string
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("schema type must be a string or list of strings")
+	}
+	*t = list
+	return nil
+}
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// ValidationError is a single schema violation
+type ValidationError struct {
+	// Path locates the value, e.g. "spec.replicas" or "containers[0].image";
+	// it is empty for the arguments object itself
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors lists every violation found in a tool call's arguments
+type ValidationErrors struct {
+	Tool       string            `json:"tool"`
+	Violations []ValidationError `json:"violations"`
+	// Output is set when the tool's result, not its arguments, was invalid
+	Output bool `json:"-"`
+}
+
+func (e *ValidationErrors) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		if v.Path == "" {
+			parts[i] = v.Message
+		} else {
+			parts[i] = v.Path + ": " + v.Message
+		}
+	}
+	what := "arguments for"
+	if e.Output {
+		what = "structuredContent from"
+	}
+	return fmt.Sprintf("invalid %s %s: %s", what, e.Tool, strings.Join(parts, "; "))
+}
+
+// Is makes errors.Is match ErrInvalidArguments or, for results,
+// ErrInvalidOutput
+func (e *ValidationErrors) Is(target error) bool {
+	if e.Output {
+		return target == ErrInvalidOutput
+	}
+	return target == ErrInvalidArguments
+}
+
+// ValidateArguments checks args against the tool's input schema. Values
+// that arrive as strings from form inputs are coerced to the declared
+// number, integer or boolean type; the coerced arguments are returned. A
+// *ValidationErrors lists every violation.
+func (t Tool) ValidateArguments(args map[string]interface{}) (map[string]interface{}, error) {
+	// Round-trip through JSON so Go values (ints, structs) are checked the
+	// way the server will see them
+	normalized := map[string]interface{}{}
+	if len(args) > 0 {
+		data, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode arguments: %w", err)
+		}
+		if err := json.Unmarshal(data, &normalized); err != nil {
+			return nil, fmt.Errorf("failed to encode arguments: %w", err)
+		}
+	}
+
+	schema := t.InputSchema
+	if len(schema.Type) == 0 {
+		schema.Type = SchemaType{"object"}
+	}
+
+	v := &validator{}
+	result := v.validate(&schema, normalized, "")
+	if len(v.errs) > 0 {
+		return nil, &ValidationErrors{Tool: t.Name, Violations: v.errs}
+	}
+	coerced, _ := result.(map[string]interface{})
+	return coerced, nil
+}
+
+// ValidateOutput checks a result's structuredContent against the tool's
+// output schema. Tools with an output schema must return structuredContent.
+func (t Tool) ValidateOutput(result *CallToolResult) error {
+	if t.OutputSchema == nil {
+		return nil
+	}
+	if len(result.StructuredContent) == 0 {
+		return &ValidationErrors{Tool: t.Name, Output: true, Violations: []ValidationError{
+			{Message: "structuredContent is required by the output schema"},
+		}}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(result.StructuredContent, &value); err != nil {
+		return &ValidationErrors{Tool: t.Name, Output: true, Violations: []ValidationError{
+			{Message: "structuredContent is not valid JSON"},
+		}}
+	}
+
+	schema := *t.OutputSchema
+	if len(schema.Type) == 0 {
+		schema.Type = SchemaType{"object"}
+	}
+	v := &validator{strict: true}
+	v.validate(&schema, value, "")
+	if len(v.errs) > 0 {
+		return &ValidationErrors{Tool: t.Name, Output: true, Violations: v.errs}
+	}
+	return nil
+}
+
+type validator struct {
+	// strict disables coercion of string values
+	strict bool
+	errs   []ValidationError
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks value against s and returns it, coerced where needed
+func (v *validator) validate(s *Schema, value interface{}, path string) interface{} {
+	if s == nil {
+		return value
+	}
+
+	if len(s.Type) > 0 {
+		coerced, ok := coerceType(s.Type, value, v.strict)
+		if !ok {
+			v.fail(path, "expected %s, got %s", strings.Join(s.Type, " or "), jsonType(value))
+			return value
+		}
+		value = coerced
+	}
+
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		v.fail(path, "must be one of %s", formatValues(s.Enum))
+	}
+	if s.Const != nil && !equalValues(s.Const, value) {
+		v.fail(path, "must be %s", formatValues([]interface{}{s.Const}))
+	}
+
+	switch val := value.(type) {
+	case string:
+		v.checkString(s, val, path)
+	case float64:
+		v.checkNumber(s, val, path)
+	case map[string]interface{}:
+		value = v.checkObject(s, val, path)
+	case []interface{}:
+		value = v.checkArray(s, val, path)
+	}
+
+	for _, sub := range s.AllOf {
+		value = v.validate(sub, value, path)
+	}
+	if len(s.AnyOf) > 0 {
+		value = v.checkAlternatives(s.AnyOf, value, path, false)
+	}
+	if len(s.OneOf) > 0 {
+		value = v.checkAlternatives(s.OneOf, value, path, true)
+	}
+	return value
+}
+
+func (v *validator) checkString(s *Schema, val, path string) {
+	length := utf8.RuneCountInString(val)
+	if s.MinLength != nil && length < *s.MinLength {
+		v.fail(path, "must be at least %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(path, "must be at most %d characters", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			v.fail(path, "schema pattern %q is invalid", s.Pattern)
+		} else if !re.MatchString(val) {
+			v.fail(path, "must match pattern %s", s.Pattern)
+		}
+	}
+}
+
+func (v *validator) checkNumber(s *Schema, val float64, path string) {
+	if s.Minimum != nil && val < *s.Minimum {
+		v.fail(path, "must be >= %v", *s.Minimum)
+	}
+	if s.Maximum != nil && val > *s.Maximum {
+		v.fail(path, "must be <= %v", *s.Maximum)
+	}
+}
+
+func (v *validator) checkObject(s *Schema, obj map[string]interface{}, path string) map[string]interface{} {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(joinPath(path, name), "is required")
+		}
+	}
+
+	allowExtra, extraSchema := s.additionalProperties()
+
+	// Visit keys in order so violations are reported deterministically
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]interface{}, len(obj))
+	for _, key := range keys {
+		propPath := joinPath(path, key)
+		if prop, ok := s.Properties[key]; ok {
+			result[key] = v.validate(prop, obj[key], propPath)
+			continue
+		}
+		if !allowExtra {
+			v.fail(propPath, "unknown property")
+			continue
+		}
+		result[key] = v.validate(extraSchema, obj[key], propPath)
+	}
+	return result
+}
+
+func (v *validator) checkArray(s *Schema, arr []interface{}, path string) []interface{} {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		v.fail(path, "must have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		v.fail(path, "must have at most %d items", *s.MaxItems)
+	}
+
+	result := make([]interface{}, len(arr))
+	for i, item := range arr {
+		result[i] = v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+	}
+	return result
+}
+
+// checkAlternatives validates anyOf (one or more must match) and oneOf
+// (exactly one must match), returning the value as coerced by the first match
+func (v *validator) checkAlternatives(schemas []*Schema, value interface{}, path string, exactlyOne bool) interface{} {
+	matches := 0
+	result := value
+	for _, sub := range schemas {
+		alt := &validator{strict: v.strict}
+		coerced := alt.validate(sub, value, path)
+		if len(alt.errs) == 0 {
+			if matches == 0 {
+				result = coerced
+			}
+			matches++
+		}
+	}
+
+	switch {
+	case matches == 0:
+		v.fail(path, "does not match any allowed schema")
+	case exactlyOne && matches > 1:
+		v.fail(path, "matches %d schemas but must match exactly one", matches)
+	}
+	return result
+}
+
+// additionalProperties interprets the additionalProperties keyword; it
+// defaults to allowing any value
+func (s *Schema) additionalProperties() (bool, *Schema) {
+	raw := strings.TrimSpace(string(s.AdditionalProperties))
+	switch raw {
+	case "", "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	var extra Schema
+	if err := json.Unmarshal(s.AdditionalProperties, &extra); err != nil {
+		return true, nil
+	}
+	return true, &extra
+}
+
+// coerceType returns value if it already has one of the types, or the first
+// successful conversion of a string (as sent by form inputs)
+func coerceType(types SchemaType, value interface{}, strict bool) (interface{}, bool) {
+	for _, t := range types {
+		if hasType(t, value) {
+			return value, true
+		}
+	}
+
+	str, ok := value.(string)
+	if !ok || strict {
+		return value, false
+	}
+	str = strings.TrimSpace(str)
+	for _, t := range types {
+		switch t {
+		case "number":
+			if f, err := strconv.ParseFloat(str, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+				return f, true
+			}
+		case "integer":
+			if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+				return float64(i), true
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(str); err == nil {
+				return b, true
+			}
+		case "null":
+			if str == "" || str == "null" {
+				return nil, true
+			}
+		}
+	}
+	return value, false
+}
+
+func hasType(t string, value interface{}) bool {
+	switch t {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "null":
+		return value == nil
+	}
+	// Unknown type names do not constrain the value
+	return true
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if equalValues(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares decoded JSON values, treating all numbers alike
+func equalValues(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		data, _ := json.Marshal(value)
+		parts[i] = string(data)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// toolWithSchema returns a tool whose input schema is the given JSON
+func toolWithSchema(t *testing.T, schema string) Tool {
+	t.Helper()
+	tool := Tool{Name: "test"}
+	if err := json.Unmarshal([]byte(schema), &tool.InputSchema); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	return tool
+}
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		args   map[string]interface{}
+		// want is the coerced arguments when valid
+		want map[string]interface{}
+		// violations are the expected "path: message" pairs when invalid
+		violations []ValidationError
+	}{
+		{
+			name:   "string to number",
+			schema: `{"properties": {"ratio": {"type": "number"}}}`,
+			args:   map[string]interface{}{"ratio": " 0.5 "},
+			want:   map[string]interface{}{"ratio": 0.5},
+		},
+		{
+			name:   "string to integer",
+			schema: `{"properties": {"replicas": {"type": "integer"}}}`,
+			args:   map[string]interface{}{"replicas": "3"},
+			want:   map[string]interface{}{"replicas": float64(3)},
+		},
+		{
+			name:       "fractional string is not an integer",
+			schema:     `{"properties": {"replicas": {"type": "integer"}}}`,
+			args:       map[string]interface{}{"replicas": "1.5"},
+			violations: []ValidationError{{Path: "replicas", Message: "expected integer, got string"}},
+		},
+		{
+			name:       "non-finite number",
+			schema:     `{"properties": {"ratio": {"type": "number"}}}`,
+			args:       map[string]interface{}{"ratio": "Inf"},
+			violations: []ValidationError{{Path: "ratio", Message: "expected number, got string"}},
+		},
+		{
+			name:   "string to boolean",
+			schema: `{"properties": {"force": {"type": "boolean"}, "dryRun": {"type": "boolean"}}}`,
+			args:   map[string]interface{}{"force": "true", "dryRun": "0"},
+			want:   map[string]interface{}{"force": true, "dryRun": false},
+		},
+		{
+			name:       "unparseable boolean",
+			schema:     `{"properties": {"force": {"type": "boolean"}}}`,
+			args:       map[string]interface{}{"force": "yes"},
+			violations: []ValidationError{{Path: "force", Message: "expected boolean, got string"}},
+		},
+		{
+			name:   "type list keeps strings",
+			schema: `{"properties": {"port": {"type": ["string", "integer"]}}}`,
+			args:   map[string]interface{}{"port": "8080"},
+			want:   map[string]interface{}{"port": "8080"},
+		},
+		{
+			name:   "enum after coercion",
+			schema: `{"properties": {"level": {"type": "integer", "enum": [1, 2, 3]}}}`,
+			args:   map[string]interface{}{"level": "2"},
+			want:   map[string]interface{}{"level": float64(2)},
+		},
+		{
+			name:       "enum",
+			schema:     `{"properties": {"mode": {"type": "string", "enum": ["fast", "safe"]}}}`,
+			args:       map[string]interface{}{"mode": "reckless"},
+			violations: []ValidationError{{Path: "mode", Message: `must be one of "fast", "safe"`}},
+		},
+		{
+			name:       "required",
+			schema:     `{"required": ["cluster", "name"], "properties": {"cluster": {"type": "string"}, "name": {"type": "string"}}}`,
+			args:       map[string]interface{}{"cluster": "kind"},
+			violations: []ValidationError{{Path: "name", Message: "is required"}},
+		},
+		{
+			name:       "missing arguments object",
+			schema:     `{"required": ["cluster"]}`,
+			args:       nil,
+			violations: []ValidationError{{Path: "cluster", Message: "is required"}},
+		},
+		{
+			name: "nested objects",
+			schema: `{"properties": {"spec": {"type": "object", "required": ["image"], "properties": {
+				"replicas": {"type": "integer", "minimum": 1},
+				"image": {"type": "string", "minLength": 1}
+			}}}}`,
+			args: map[string]interface{}{"spec": map[string]interface{}{"replicas": "0"}},
+			violations: []ValidationError{
+				{Path: "spec.image", Message: "is required"},
+				{Path: "spec.replicas", Message: "must be >= 1"},
+			},
+		},
+		{
+			name: "arrays of objects",
+			schema: `{"properties": {"containers": {"type": "array", "maxItems": 2, "items": {"type": "object", "properties": {
+				"port": {"type": "integer"}
+			}}}}}`,
+			args: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"port": "80"},
+				map[string]interface{}{"port": 443},
+			}},
+			want: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"port": float64(80)},
+				map[string]interface{}{"port": float64(443)},
+			}},
+		},
+		{
+			name:       "array item violations carry their index",
+			schema:     `{"properties": {"tags": {"type": "array", "minItems": 1, "items": {"type": "string", "pattern": "^[a-z]+$"}}}}`,
+			args:       map[string]interface{}{"tags": []interface{}{"ok", "Not-OK"}},
+			violations: []ValidationError{{Path: "tags[1]", Message: "must match pattern ^[a-z]+$"}},
+		},
+		{
+			name:   "anyOf coerces by the first match",
+			schema: `{"properties": {"limit": {"anyOf": [{"type": "integer"}, {"type": "string", "const": "all"}]}}}`,
+			args:   map[string]interface{}{"limit": "10"},
+			want:   map[string]interface{}{"limit": float64(10)},
+		},
+		{
+			name:       "anyOf without a match",
+			schema:     `{"properties": {"limit": {"anyOf": [{"type": "integer"}, {"type": "string", "const": "all"}]}}}`,
+			args:       map[string]interface{}{"limit": "some"},
+			violations: []ValidationError{{Path: "limit", Message: "does not match any allowed schema"}},
+		},
+		{
+			name:   "oneOf with one match",
+			schema: `{"properties": {"target": {"oneOf": [{"type": "string", "pattern": "^pod/"}, {"type": "string", "pattern": "^node/"}]}}}`,
+			args:   map[string]interface{}{"target": "pod/web"},
+			want:   map[string]interface{}{"target": "pod/web"},
+		},
+		{
+			name:       "oneOf with several matches",
+			schema:     `{"properties": {"count": {"oneOf": [{"type": "integer"}, {"type": "number"}]}}}`,
+			args:       map[string]interface{}{"count": 3},
+			violations: []ValidationError{{Path: "count", Message: "matches 2 schemas but must match exactly one"}},
+		},
+		{
+			name:       "additionalProperties false",
+			schema:     `{"additionalProperties": false, "properties": {"name": {"type": "string"}}}`,
+			args:       map[string]interface{}{"name": "web", "namespce": "default"},
+			violations: []ValidationError{{Path: "namespce", Message: "unknown property"}},
+		},
+		{
+			name:   "additionalProperties schema",
+			schema: `{"additionalProperties": {"type": "integer"}}`,
+			args:   map[string]interface{}{"cpu": "2", "memory": "4"},
+			want:   map[string]interface{}{"cpu": float64(2), "memory": float64(4)},
+		},
+		{
+			name:   "additionalProperties allowed by default",
+			schema: `{"properties": {"name": {"type": "string"}}}`,
+			args:   map[string]interface{}{"name": "web", "extra": "kept"},
+			want:   map[string]interface{}{"name": "web", "extra": "kept"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toolWithSchema(t, tt.schema).ValidateArguments(tt.args)
+			if tt.violations == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("arguments = %#v, want %#v", got, tt.want)
+				}
+				return
+			}
+
+			var invalid *ValidationErrors
+			if !errors.As(err, &invalid) {
+				t.Fatalf("error = %v, want ValidationErrors", err)
+			}
+			if !errors.Is(err, ErrInvalidArguments) || errors.Is(err, ErrInvalidOutput) {
+				t.Errorf("error %v does not match ErrInvalidArguments only", err)
+			}
+			if invalid.Tool != "test" || !reflect.DeepEqual(invalid.Violations, tt.violations) {
+				t.Fatalf("violations = %+v, want %+v", invalid.Violations, tt.violations)
+			}
+		})
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	tool := Tool{Name: "test", OutputSchema: &Schema{
+		Required:   []string{"count"},
+		Properties: map[string]*Schema{"count": {Type: SchemaType{"integer"}}},
+	}}
+
+	tests := []struct {
+		name       string
+		structured string
+		valid      bool
+	}{
+		{"matches", `{"count": 3}`, true},
+		{"missing", ``, false},
+		{"not JSON", `{`, false},
+		// Output is never coerced
+		{"string number", `{"count": "3"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tool.ValidateOutput(&CallToolResult{StructuredContent: json.RawMessage(tt.structured)})
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidOutput) || errors.Is(err, ErrInvalidArguments) {
+				t.Fatalf("error = %v, want ErrInvalidOutput", err)
+			}
+		})
+	}
+}
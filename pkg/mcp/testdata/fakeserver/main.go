@@ -0,0 +1,60 @@
+// fakeserver is a minimal stdio MCP server for the bridge tests. It answers
+// initialize, tools/list and ping, echoes the "text" argument of the echo
+// tool and exits when the crash tool is called.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+type request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func main() {
+	out := json.NewEncoder(os.Stdout)
+	reply := func(id json.RawMessage, result interface{}) {
+		out.Encode(map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue // notifications need no answer
+		}
+
+		switch req.Method {
+		case "initialize":
+			reply(req.ID, map[string]interface{}{
+				"protocolVersion": "2024-11-05",
+				"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+				"serverInfo":      map[string]string{"name": "fakeserver", "version": "1.0.0"},
+			})
+		case "tools/list":
+			reply(req.ID, map[string]interface{}{"tools": []map[string]interface{}{
+				{"name": "echo", "description": "Echo text", "inputSchema": map[string]interface{}{"type": "object"}},
+				{"name": "crash", "description": "Exit the server", "inputSchema": map[string]interface{}{"type": "object"}},
+			}})
+		case "tools/call":
+			var params struct {
+				Name      string            `json:"name"`
+				Arguments map[string]string `json:"arguments"`
+			}
+			json.Unmarshal(req.Params, &params)
+			if params.Name == "crash" {
+				os.Exit(1)
+			}
+			reply(req.ID, map[string]interface{}{"content": []map[string]string{{"type": "text", "text": params.Arguments["text"]}}})
+		case "ping":
+			reply(req.ID, map[string]interface{}{})
+		default:
+			out.Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "method not found"}})
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Transport carries JSON-RPC messages between a Client and an MCP server
+type Transport interface {
+	// Start connects to the server and delivers every incoming message to
+	// handle until the transport is closed
+	Start(ctx context.Context, handle func([]byte)) error
+	// Send delivers one JSON-RPC message. Transports that answer on the same
+	// exchange (Streamable HTTP) hand the response to handle before returning.
+	Send(ctx context.Context, data []byte) error
+	// Done is closed when the server exits or the connection is lost for good
+	Done() <-chan struct{}
+	// Err explains why Done was closed, and is nil before that
+	Err() error
+	// Close shuts the transport down
+	Close() error
+}
+
+// ConnectionError reports that the connection to a remote MCP server was
+// lost. Like ExitError it matches ErrServerExited, so the Bridge supervisor
+// reconnects.
+type ConnectionError struct {
+	Server string
+	Err    error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("MCP server %s disconnected: %v", e.Server, e.Err)
+}
+
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// Is makes errors.Is(err, ErrServerExited) match any ConnectionError
+func (e *ConnectionError) Is(target error) bool { return target == ErrServerExited }
+
+// closer records the terminal error of a transport exactly once
+type closer struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func newCloser() closer {
+	return closer{done: make(chan struct{})}
+}
+
+func (c *closer) close(err error) {
+	c.once.Do(func() {
+		c.err = err
+		close(c.done)
+	})
+}
+
+// Done implements Transport
+func (c *closer) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err implements Transport
+func (c *closer) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// stdioTransport runs an MCP server as a child process and exchanges
+// newline-delimited JSON-RPC over its stdin and stdout
+type stdioTransport struct {
+	closer
+	name       string
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	stdout     *bufio.Reader
+	stderr     io.ReadCloser
+	writeMu    sync.Mutex
+	stderrDone chan struct{}
+	lastStderr atomic.Value
+}
+
+// NewStdioTransport creates a transport for the given command. env entries
+// ("KEY=value") are added to the console's environment.
+func NewStdioTransport(name, command string, args []string, env []string) (Transport, error) {
+	cmd := exec.Command(command, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	return &stdioTransport{
+		closer:     newCloser(),
+		name:       name,
+		cmd:        cmd,
+		stdin:      stdin,
+		stdout:     bufio.NewReader(stdout),
+		stderr:     stderr,
+		stderrDone: make(chan struct{}),
+	}, nil
+}
+
+// Start starts the server process
+func (t *stdioTransport) Start(ctx context.Context, handle func([]byte)) error {
+	if err := t.cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", t.name, err)
+	}
+
+	go t.readStderr()
+	go t.readStdout(handle)
+	return nil
+}
+
+// Send writes one message line to the server's stdin
+func (t *stdioTransport) Send(ctx context.Context, data []byte) error {
+	if err := t.Err(); err != nil {
+		return err
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	if _, err := t.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	return nil
+}
+
+// Close kills the server process
+func (t *stdioTransport) Close() error {
+	if t.cmd.Process != nil {
+		if err := t.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *stdioTransport) readStdout(handle func([]byte)) {
+	for {
+		line, err := t.stdout.ReadBytes('\n')
+		if err != nil {
+			if err != io.EOF {
+				fmt.Printf("[%s] read error: %v\n", t.name, err)
+			}
+			t.exited()
+			return
+		}
+		handle(line)
+	}
+}
+
+// exited reaps the server process and closes the transport
+func (t *stdioTransport) exited() {
+	// Wait closes the pipes, so let stderr finish first; a child process
+	// holding stderr open must not block the exit
+	select {
+	case <-t.stderrDone:
+	case <-time.After(time.Second):
+	}
+	waitErr := t.cmd.Wait()
+
+	stderr, _ := t.lastStderr.Load().(string)
+	t.close(&ExitError{Server: t.name, Err: waitErr, Stderr: stderr})
+}
+
+// readStderr drains the server's stderr so it cannot block on a full pipe,
+// keeping the last line for exit errors
+func (t *stdioTransport) readStderr() {
+	defer close(t.stderrDone)
+	scanner := bufio.NewScanner(t.stderr)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			t.lastStderr.Store(line)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/kubestellar/console/pkg/tracing"
+)
+
+const (
+	// sessionHeader carries the Streamable HTTP session ID
+	sessionHeader = "Mcp-Session-Id"
+
+	// maxSSEEventSize caps a single server-sent event
+	maxSSEEventSize = 16 << 20
+
+	streamMinBackoff = 1 * time.Second
+	streamMaxBackoff = 30 * time.Second
+)
+
+// httpTransport implements the MCP Streamable HTTP transport: every message
+// is POSTed to one endpoint and answered with JSON or an SSE stream, and an
+// optional GET stream carries server-initiated messages
+type httpTransport struct {
+	closer
+	name    string
+	url     string
+	headers map[string]string
+	client  *http.Client
+	handle  func([]byte)
+
+	mu        sync.Mutex
+	sessionID string
+
+	listenOnce sync.Once
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// NewHTTPTransport creates a Streamable HTTP transport for endpoint. headers
+// are sent with every request (e.g. Authorization).
+func NewHTTPTransport(name, endpoint string, headers map[string]string) Transport {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &httpTransport{
+		closer:  newCloser(),
+		name:    name,
+		url:     endpoint,
+		headers: headers,
+		client:  &http.Client{},
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start records the message handler; the connection is made by the first Send
+func (t *httpTransport) Start(ctx context.Context, handle func([]byte)) error {
+	t.handle = handle
+	return nil
+}
+
+// Send POSTs a message and delivers the messages in the reply
+func (t *httpTransport) Send(ctx context.Context, data []byte) error {
+	if err := t.Err(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	sessionID := t.session()
+	t.setHeaders(req, sessionID)
+	tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", t.url, err)
+	}
+	defer resp.Body.Close()
+
+	if id := resp.Header.Get(sessionHeader); id != "" && id != sessionID {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && sessionID != "":
+		// The server dropped our session; a new one needs a fresh initialize
+		t.close(&ConnectionError{Server: t.name, Err: errors.New("session expired")})
+		return t.Err()
+	case resp.StatusCode == http.StatusAccepted:
+		return nil
+	case resp.StatusCode >= 300:
+		return httpStatusError(resp)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		return readSSE(resp.Body, func(ev sseEvent) {
+			if ev.data != "" {
+				deliver(t.handle, []byte(ev.data))
+			}
+		})
+	case "application/json":
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxSSEEventSize))
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			deliver(t.handle, body)
+		}
+		return nil
+	}
+	return fmt.Errorf("unexpected content type %q", resp.Header.Get("Content-Type"))
+}
+
+// Listen opens the GET stream for server-initiated messages. It is called
+// once the session is initialized.
+func (t *httpTransport) Listen() {
+	t.listenOnce.Do(func() { go t.listen() })
+}
+
+// listen keeps the GET stream open, resuming with Last-Event-ID after a drop
+func (t *httpTransport) listen() {
+	backoff := streamMinBackoff
+	lastEventID := ""
+	for {
+		req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.url, nil)
+		if err != nil {
+			return
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		sessionID := t.session()
+		t.setHeaders(req, sessionID)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		resp, err := t.client.Do(req)
+		if err == nil {
+			switch {
+			case resp.StatusCode == http.StatusMethodNotAllowed:
+				// The server does not offer a standalone stream
+				resp.Body.Close()
+				return
+			case resp.StatusCode == http.StatusNotFound && sessionID != "":
+				resp.Body.Close()
+				t.close(&ConnectionError{Server: t.name, Err: errors.New("session expired")})
+				return
+			case resp.StatusCode == http.StatusOK:
+				backoff = streamMinBackoff
+				readSSE(resp.Body, func(ev sseEvent) {
+					if ev.id != "" {
+						lastEventID = ev.id
+					}
+					if ev.data != "" {
+						deliver(t.handle, []byte(ev.data))
+					}
+				})
+			}
+			resp.Body.Close()
+		}
+
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-t.done:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > streamMaxBackoff {
+			backoff = streamMaxBackoff
+		}
+	}
+}
+
+// Close ends the session and the GET stream
+func (t *httpTransport) Close() error {
+	t.cancel()
+	if sessionID := t.session(); sessionID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil); err == nil {
+			t.setHeaders(req, sessionID)
+			if resp, err := t.client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+	t.close(&ConnectionError{Server: t.name, Err: errors.New("transport closed")})
+	return nil
+}
+
+func (t *httpTransport) session() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+func (t *httpTransport) setHeaders(req *http.Request, sessionID string) {
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+}
+
+// sseTransport implements the legacy MCP HTTP+SSE transport: the server
+// streams messages over a GET event stream and announces the endpoint that
+// client messages are POSTed to
+type sseTransport struct {
+	closer
+	name     string
+	url      string
+	headers  map[string]string
+	client   *http.Client
+	endpoint string
+	cancel   context.CancelFunc
+}
+
+// NewSSETransport creates a legacy HTTP+SSE transport for the SSE URL
+func NewSSETransport(name, sseURL string, headers map[string]string) Transport {
+	return &sseTransport{
+		closer:  newCloser(),
+		name:    name,
+		url:     sseURL,
+		headers: headers,
+		client:  &http.Client{},
+	}
+}
+
+// Start opens the event stream and waits for the endpoint event
+func (t *sseTransport) Start(ctx context.Context, handle func([]byte)) error {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, t.url, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("GET %s: %w", t.url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		cancel()
+		return httpStatusError(resp)
+	}
+
+	base, _ := url.Parse(t.url)
+	endpointCh := make(chan string, 1)
+	go func() {
+		defer resp.Body.Close()
+		err := readSSE(resp.Body, func(ev sseEvent) {
+			switch ev.event {
+			case "endpoint":
+				ref, err := url.Parse(strings.TrimSpace(ev.data))
+				if err != nil {
+					return
+				}
+				select {
+				case endpointCh <- base.ResolveReference(ref).String():
+				default:
+				}
+			case "", "message":
+				deliver(handle, []byte(ev.data))
+			}
+		})
+		if err == nil {
+			err = errors.New("event stream closed")
+		}
+		// The legacy transport cannot resume a session
+		t.close(&ConnectionError{Server: t.name, Err: err})
+	}()
+
+	select {
+	case endpoint := <-endpointCh:
+		t.endpoint = endpoint
+		return nil
+	case <-t.done:
+		return t.Err()
+	case <-ctx.Done():
+		cancel()
+		return fmt.Errorf("waiting for endpoint event: %w", ctx.Err())
+	}
+}
+
+// Send POSTs a message to the announced endpoint; replies arrive on the stream
+func (t *sseTransport) Send(ctx context.Context, data []byte) error {
+	if err := t.Err(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", t.endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return httpStatusError(resp)
+	}
+	return nil
+}
+
+// Close closes the event stream
+func (t *sseTransport) Close() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.close(&ConnectionError{Server: t.name, Err: errors.New("transport closed")})
+	return nil
+}
+
+// deliver passes a message, or each message of a JSON-RPC batch, to handle
+func deliver(handle func([]byte), data []byte) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err == nil {
+			for _, msg := range batch {
+				handle(msg)
+			}
+			return
+		}
+	}
+	handle(trimmed)
+}
+
+// httpStatusError describes a failed HTTP exchange
+func httpStatusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return fmt.Errorf("HTTP %d: %s", resp.StatusCode, msg)
+}
+
+// sseEvent is one server-sent event
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readSSE parses a text/event-stream, calling fn for each event
+func readSSE(r io.Reader, fn func(sseEvent)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSEEventSize)
+
+	var ev sseEvent
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 || ev.event != "" {
+				ev.data = strings.Join(data, "\n")
+				fn(ev)
+			}
+			ev = sseEvent{}
+			data = nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.id = value
+		case "event":
+			ev.event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,263 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collect returns a message handler and the channel it delivers to
+func collect() (func([]byte), chan string) {
+	ch := make(chan string, 16)
+	return func(msg []byte) { ch <- string(msg) }, ch
+}
+
+func receive(t *testing.T, ch chan string) string {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+		return ""
+	}
+}
+
+func send(t *testing.T, tr Transport, msg string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tr.Send(ctx, []byte(msg)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+}
+
+func TestHTTPTransportSession(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	deleted := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := r.Header.Get(sessionHeader)
+		switch r.Method {
+		case http.MethodDelete:
+			deleted <- session
+			return
+		case http.MethodGet:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		seen = append(seen, session)
+		mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"initialize"`) {
+			w.Header().Set(sessionHeader, "session-1")
+		} else if session != "session-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if strings.Contains(string(body), `"notifications/`) {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+	}))
+	defer srv.Close()
+
+	tr := NewHTTPTransport("test", srv.URL, map[string]string{"Authorization": "Bearer token"})
+	handle, msgs := collect()
+	if err := tr.Start(context.Background(), handle); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	send(t, tr, `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+	if got := receive(t, msgs); got != `{"jsonrpc":"2.0","id":1,"result":{}}` {
+		t.Fatalf("initialize reply = %s", got)
+	}
+	send(t, tr, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(t, tr, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	receive(t, msgs)
+
+	mu.Lock()
+	want := []string{"", "session-1", "session-1"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Fatalf("session headers sent = %q, want %q", seen, want)
+	}
+	mu.Unlock()
+
+	tr.Close()
+	select {
+	case session := <-deleted:
+		if session != "session-1" {
+			t.Fatalf("DELETE carried session %q", session)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not DELETE the session")
+	}
+}
+
+func TestHTTPTransportSessionExpired(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(sessionHeader) == "" {
+			w.Header().Set(sessionHeader, "session-1")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	tr := NewHTTPTransport("test", srv.URL, nil)
+	handle, _ := collect()
+	tr.Start(context.Background(), handle)
+	send(t, tr, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	err := tr.Send(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	if err == nil || !strings.Contains(err.Error(), "session expired") {
+		t.Fatalf("Send after 404 = %v, want session expired", err)
+	}
+	select {
+	case <-tr.Done():
+	default:
+		t.Fatal("transport not closed after the session expired")
+	}
+}
+
+func TestHTTPTransportSSEResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+		fmt.Fprint(w, "data: [{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{}},\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":2,\"result\":{}}]\n\n")
+	}))
+	defer srv.Close()
+
+	tr := NewHTTPTransport("test", srv.URL, nil)
+	handle, msgs := collect()
+	tr.Start(context.Background(), handle)
+	send(t, tr, `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"ping"}]`)
+
+	want := []string{
+		`{"jsonrpc":"2.0","method":"notifications/progress"}`,
+		`{"jsonrpc":"2.0","id":1,"result":{}}`,
+		`{"jsonrpc":"2.0","id":2,"result":{}}`,
+	}
+	for _, w := range want {
+		if got := receive(t, msgs); got != w {
+			t.Fatalf("got %s, want %s", got, w)
+		}
+	}
+}
+
+func TestHTTPTransportListen(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 1\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	tr := NewHTTPTransport("test", srv.URL, nil)
+	defer tr.Close()
+	handle, msgs := collect()
+	tr.Start(context.Background(), handle)
+	tr.(interface{ Listen() }).Listen()
+
+	if got := receive(t, msgs); got != `{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}` {
+		t.Fatalf("stream message = %s", got)
+	}
+}
+
+func TestSSETransport(t *testing.T) {
+	replies := make(chan string, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?session=abc\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case reply := <-replies:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", reply)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("session") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		replies <- strings.Replace(string(body), `"method":"ping"`, `"result":{}`, 1)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tr := NewSSETransport("test", srv.URL+"/sse", map[string]string{"Authorization": "Bearer token"})
+	handle, msgs := collect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tr.Start(ctx, handle); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if endpoint := tr.(*sseTransport).endpoint; endpoint != srv.URL+"/messages?session=abc" {
+		t.Fatalf("endpoint = %q", endpoint)
+	}
+
+	send(t, tr, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	if got := receive(t, msgs); got != `{"jsonrpc":"2.0","id":1,"result":{}}` {
+		t.Fatalf("reply = %s", got)
+	}
+
+	tr.Close()
+	if err := tr.Send(context.Background(), []byte(`{}`)); err == nil {
+		t.Fatal("Send succeeded after Close")
+	}
+}
+
+func TestSSETransportWithoutEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {}\n\n")
+	}))
+	defer srv.Close()
+
+	tr := NewSSETransport("test", srv.URL, nil)
+	handle, _ := collect()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := tr.Start(ctx, handle)
+	if err == nil || !strings.Contains(err.Error(), "event stream closed") {
+		t.Fatalf("Start = %v, want event stream closed", err)
+	}
+}
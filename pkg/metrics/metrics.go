@@ -0,0 +1,183 @@
+// Package metrics defines the Prometheus metrics of the console server.
+// Collectors are registered with the default registry and served by
+// Handler.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "kubestellar_console"
+
+// UnknownTool is the tool label of calls to tools the server did not list
+const UnknownTool = "unknown"
+
+// AgentCluster is the cluster label of requests to clusters registered by
+// an agent, whose names are private to the agent's owner
+const AgentCluster = "agent"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	k8sRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "k8s_requests_total",
+		Help:      "Kubernetes API requests by cluster, verb and status code (\"error\" when no response).",
+	}, []string{"cluster", "verb", "code"})
+
+	k8sDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "k8s_request_duration_seconds",
+		Help:      "Kubernetes API request latency by cluster and verb.",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"cluster", "verb"})
+
+	mcpCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mcp_calls_total",
+		Help:      "MCP requests by server, method, tool and outcome (success, error, timeout, cancelled).",
+	}, []string{"server", "method", "tool", "outcome"})
+
+	mcpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mcp_call_duration_seconds",
+		Help:      "MCP request latency by server, method and tool.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"server", "method", "tool"})
+
+	mcpInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mcp_calls_in_flight",
+		Help:      "MCP requests awaiting a response, by server.",
+	}, []string{"server"})
+
+	mcpToolErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mcp_tool_errors_total",
+		Help:      "Tool calls that completed with isError set, by server and tool.",
+	}, []string{"server", "tool"})
+
+	storeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "store_query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation", "table"})
+
+	storeErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "store_query_errors_total",
+		Help:      "Failed database queries by operation and table.",
+	}, []string{"operation", "table"})
+)
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records a served HTTP request
+func ObserveHTTPRequest(method, route string, status int, d time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+// ObserveMCPCall records a completed MCP request. tool is empty for
+// requests other than tools/call and UnknownTool for tools the server did
+// not list, so callers cannot grow the label set.
+func ObserveMCPCall(server, method, tool, outcome string, d time.Duration) {
+	mcpCalls.WithLabelValues(server, method, tool, outcome).Inc()
+	mcpDuration.WithLabelValues(server, method, tool).Observe(d.Seconds())
+}
+
+// MCPCallStarted and MCPCallFinished track in-flight MCP requests
+func MCPCallStarted(server string)  { mcpInFlight.WithLabelValues(server).Inc() }
+func MCPCallFinished(server string) { mcpInFlight.WithLabelValues(server).Dec() }
+
+// ObserveMCPToolError records a tool call whose result had isError set
+func ObserveMCPToolError(server, tool string) {
+	mcpToolErrors.WithLabelValues(server, tool).Inc()
+}
+
+// ObserveStoreQuery records a database query
+func ObserveStoreQuery(operation, table string, d time.Duration, err error) {
+	storeDuration.WithLabelValues(operation, table).Observe(d.Seconds())
+	if err != nil {
+		storeErrors.WithLabelValues(operation, table).Inc()
+	}
+}
+
+// RegisterWebSocketClients exposes the number of connected WebSocket
+// clients as reported by count
+func RegisterWebSocketClients(count func() int) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_clients",
+		Help:      "Connected WebSocket clients.",
+	}, func() float64 { return float64(count()) }))
+}
+
+// RegisterHealthCacheAge exposes the age of each cluster's cached health
+// result as reported by ages
+func RegisterHealthCacheAge(ages func() map[string]time.Duration) {
+	prometheus.MustRegister(&healthCacheCollector{ages: ages})
+}
+
+var healthCacheAgeDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "cluster_health_cache_age_seconds"),
+	"Age of the cached health result per cluster.",
+	[]string{"cluster"}, nil,
+)
+
+// healthCacheCollector reports cache ages at scrape time, so clusters that
+// leave the kubeconfig disappear from the output
+type healthCacheCollector struct {
+	ages func() map[string]time.Duration
+}
+
+func (c *healthCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- healthCacheAgeDesc
+}
+
+func (c *healthCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for cluster, age := range c.ages() {
+		ch <- prometheus.MustNewConstMetric(healthCacheAgeDesc, prometheus.GaugeValue, age.Seconds(), cluster)
+	}
+}
+
+// InstrumentRoundTripper records Kubernetes API requests made through rt
+// for cluster. It is meant for rest.Config.WrapTransport.
+func InstrumentRoundTripper(cluster string, rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := rt.RoundTrip(req)
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		k8sRequests.WithLabelValues(cluster, req.Method, code).Inc()
+		k8sDuration.WithLabelValues(cluster, req.Method).Observe(time.Since(start).Seconds())
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
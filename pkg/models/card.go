@@ -125,7 +125,7 @@ func GetCardTypes() []CardTypeInfo {
 		{CardTypeGitOpsDrift, "GitOps Drift", "Clusters out of sync with git", "git-branch", "detect_drift"},
 		{CardTypeSecurityIssues, "Security Issues", "Privileged, root, host network", "shield-alert", "check_security_issues"},
 		{CardTypeRBACOverview, "RBAC Overview", "Permission summary", "key", "get_roles"},
-		{CardTypePolicyViolations, "Policy Violations", "OPA Gatekeeper violations", "file-warning", "list_ownership_violations"},
+		{CardTypePolicyViolations, "Policy Violations", "OPA Gatekeeper and Kyverno violations", "file-warning", "list_ownership_violations"},
 		{CardTypeUpgradeStatus, "Upgrade Status", "Cluster upgrade progress", "download", "get_upgrade_status"},
 		{CardTypeNamespaceAnalysis, "Namespace Analysis", "Deep dive into namespace", "folder", "analyze_namespace"},
 	}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PSSSuppression silences a Pod Security Standards rule in a namespace,
+// for example for a logging DaemonSet that needs hostPath volumes
+type PSSSuppression struct {
+	ID uuid.UUID `json:"id"`
+	// Cluster is empty to suppress the rule in the namespace of every cluster
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	RuleID    string    `json:"rule_id"`
+	Reason    string    `json:"reason"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
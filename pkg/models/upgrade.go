@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClusterVersionSnapshot records a cluster's versions when they change, so
+// the progress of an upgrade can be followed
+type ClusterVersionSnapshot struct {
+	ID                  uuid.UUID `json:"id"`
+	Cluster             string    `json:"cluster"`
+	ControlPlaneVersion string    `json:"control_plane_version"`
+	// KubeletVersions counts nodes per kubelet version
+	KubeletVersions map[string]int `json:"kubelet_versions"`
+	Phase           string         `json:"phase"`
+	Nodes           int            `json:"nodes"`
+	NodesUpgraded   int            `json:"nodes_upgraded"`
+	NodesOutOfSkew  int            `json:"nodes_out_of_skew"`
+	CordonedNodes   int            `json:"cordoned_nodes"`
+	CapturedAt      time.Time      `json:"captured_at"`
+}
@@ -1,9 +1,11 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +16,7 @@ import (
 
 // SQLiteStore implements Store using SQLite
 type SQLiteStore struct {
-	db *sql.DB
+	db timedDB
 }
 
 // NewSQLiteStore creates a new SQLite store
@@ -24,7 +26,7 @@ func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	store := &SQLiteStore{db: db}
+	store := &SQLiteStore{db: timedDB{db}}
 	if err := store.migrate(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to migrate: %w", err)
@@ -110,10 +112,35 @@ func (s *SQLiteStore) migrate() error {
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS cluster_version_history (
+		id TEXT PRIMARY KEY,
+		cluster TEXT NOT NULL,
+		control_plane_version TEXT NOT NULL,
+		kubelet_versions TEXT,
+		phase TEXT NOT NULL,
+		nodes INTEGER DEFAULT 0,
+		nodes_upgraded INTEGER DEFAULT 0,
+		nodes_out_of_skew INTEGER DEFAULT 0,
+		cordoned_nodes INTEGER DEFAULT 0,
+		captured_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS pss_suppressions (
+		id TEXT PRIMARY KEY,
+		cluster TEXT NOT NULL DEFAULT '',
+		namespace TEXT NOT NULL,
+		rule_id TEXT NOT NULL,
+		reason TEXT,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(cluster, namespace, rule_id)
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_dashboards_user ON dashboards(user_id);
 	CREATE INDEX IF NOT EXISTS idx_cards_dashboard ON cards(dashboard_id);
 	CREATE INDEX IF NOT EXISTS idx_events_user_time ON user_events(user_id, created_at);
 	CREATE INDEX IF NOT EXISTS idx_pending_swaps_due ON pending_swaps(swap_at, status);
+	CREATE INDEX IF NOT EXISTS idx_version_history_cluster_time ON cluster_version_history(cluster, captured_at);
 	`
 	_, err := s.db.Exec(schema)
 	if err != nil {
@@ -133,6 +160,11 @@ func (s *SQLiteStore) migrate() error {
 	return nil
 }
 
+// Ping checks that the database is reachable
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
 // Close closes the database connection
 func (s *SQLiteStore) Close() error {
 	return s.db.Close()
@@ -140,13 +172,13 @@ func (s *SQLiteStore) Close() error {
 
 // User methods
 
-func (s *SQLiteStore) GetUser(id uuid.UUID) (*models.User, error) {
-	row := s.db.QueryRow(`SELECT id, github_id, github_login, email, slack_id, avatar_url, role, onboarded, created_at, last_login FROM users WHERE id = ?`, id.String())
+func (s *SQLiteStore) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, github_id, github_login, email, slack_id, avatar_url, role, onboarded, created_at, last_login FROM users WHERE id = ?`, id.String())
 	return s.scanUser(row)
 }
 
-func (s *SQLiteStore) GetUserByGitHubID(githubID string) (*models.User, error) {
-	row := s.db.QueryRow(`SELECT id, github_id, github_login, email, slack_id, avatar_url, role, onboarded, created_at, last_login FROM users WHERE github_id = ?`, githubID)
+func (s *SQLiteStore) GetUserByGitHubID(ctx context.Context, githubID string) (*models.User, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, github_id, github_login, email, slack_id, avatar_url, role, onboarded, created_at, last_login FROM users WHERE github_id = ?`, githubID)
 	return s.scanUser(row)
 }
 
@@ -187,7 +219,7 @@ func (s *SQLiteStore) scanUser(row *sql.Row) (*models.User, error) {
 	return &u, nil
 }
 
-func (s *SQLiteStore) CreateUser(user *models.User) error {
+func (s *SQLiteStore) CreateUser(ctx context.Context, user *models.User) error {
 	if user.ID == uuid.Nil {
 		user.ID = uuid.New()
 	}
@@ -196,25 +228,25 @@ func (s *SQLiteStore) CreateUser(user *models.User) error {
 		user.Role = "viewer" // default role
 	}
 
-	_, err := s.db.Exec(`INSERT INTO users (id, github_id, github_login, email, slack_id, avatar_url, role, onboarded, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, `INSERT INTO users (id, github_id, github_login, email, slack_id, avatar_url, role, onboarded, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		user.ID.String(), user.GitHubID, user.GitHubLogin, nullString(user.Email), nullString(user.SlackID), nullString(user.AvatarURL), user.Role, boolToInt(user.Onboarded), user.CreatedAt)
 	return err
 }
 
-func (s *SQLiteStore) UpdateUser(user *models.User) error {
-	_, err := s.db.Exec(`UPDATE users SET github_login = ?, email = ?, slack_id = ?, avatar_url = ?, role = ?, onboarded = ? WHERE id = ?`,
+func (s *SQLiteStore) UpdateUser(ctx context.Context, user *models.User) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE users SET github_login = ?, email = ?, slack_id = ?, avatar_url = ?, role = ?, onboarded = ? WHERE id = ?`,
 		user.GitHubLogin, nullString(user.Email), nullString(user.SlackID), nullString(user.AvatarURL), user.Role, boolToInt(user.Onboarded), user.ID.String())
 	return err
 }
 
-func (s *SQLiteStore) UpdateLastLogin(userID uuid.UUID) error {
-	_, err := s.db.Exec(`UPDATE users SET last_login = ? WHERE id = ?`, time.Now(), userID.String())
+func (s *SQLiteStore) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE users SET last_login = ? WHERE id = ?`, time.Now(), userID.String())
 	return err
 }
 
 // ListUsers returns all users
-func (s *SQLiteStore) ListUsers() ([]models.User, error) {
-	rows, err := s.db.Query(`SELECT id, github_id, github_login, email, slack_id, avatar_url, role, onboarded, created_at, last_login FROM users ORDER BY created_at DESC`)
+func (s *SQLiteStore) ListUsers(ctx context.Context) ([]models.User, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, github_id, github_login, email, slack_id, avatar_url, role, onboarded, created_at, last_login FROM users ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -257,20 +289,20 @@ func (s *SQLiteStore) ListUsers() ([]models.User, error) {
 }
 
 // DeleteUser removes a user by ID
-func (s *SQLiteStore) DeleteUser(id uuid.UUID) error {
-	_, err := s.db.Exec(`DELETE FROM users WHERE id = ?`, id.String())
+func (s *SQLiteStore) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id.String())
 	return err
 }
 
 // UpdateUserRole updates only the user's role
-func (s *SQLiteStore) UpdateUserRole(userID uuid.UUID, role string) error {
-	_, err := s.db.Exec(`UPDATE users SET role = ? WHERE id = ?`, role, userID.String())
+func (s *SQLiteStore) UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE users SET role = ? WHERE id = ?`, role, userID.String())
 	return err
 }
 
 // CountUsersByRole returns the count of users by role
-func (s *SQLiteStore) CountUsersByRole() (admins, editors, viewers int, err error) {
-	rows, err := s.db.Query(`SELECT role, COUNT(*) FROM users GROUP BY role`)
+func (s *SQLiteStore) CountUsersByRole(ctx context.Context) (admins, editors, viewers int, err error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT role, COUNT(*) FROM users GROUP BY role`)
 	if err != nil {
 		return 0, 0, 0, err
 	}
@@ -296,19 +328,19 @@ func (s *SQLiteStore) CountUsersByRole() (admins, editors, viewers int, err erro
 
 // Onboarding methods
 
-func (s *SQLiteStore) SaveOnboardingResponse(response *models.OnboardingResponse) error {
+func (s *SQLiteStore) SaveOnboardingResponse(ctx context.Context, response *models.OnboardingResponse) error {
 	if response.ID == uuid.Nil {
 		response.ID = uuid.New()
 	}
 	response.CreatedAt = time.Now()
 
-	_, err := s.db.Exec(`INSERT OR REPLACE INTO onboarding_responses (id, user_id, question_key, answer, created_at) VALUES (?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO onboarding_responses (id, user_id, question_key, answer, created_at) VALUES (?, ?, ?, ?, ?)`,
 		response.ID.String(), response.UserID.String(), response.QuestionKey, response.Answer, response.CreatedAt)
 	return err
 }
 
-func (s *SQLiteStore) GetOnboardingResponses(userID uuid.UUID) ([]models.OnboardingResponse, error) {
-	rows, err := s.db.Query(`SELECT id, user_id, question_key, answer, created_at FROM onboarding_responses WHERE user_id = ?`, userID.String())
+func (s *SQLiteStore) GetOnboardingResponses(ctx context.Context, userID uuid.UUID) ([]models.OnboardingResponse, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, question_key, answer, created_at FROM onboarding_responses WHERE user_id = ?`, userID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -328,20 +360,20 @@ func (s *SQLiteStore) GetOnboardingResponses(userID uuid.UUID) ([]models.Onboard
 	return responses, rows.Err()
 }
 
-func (s *SQLiteStore) SetUserOnboarded(userID uuid.UUID) error {
-	_, err := s.db.Exec(`UPDATE users SET onboarded = 1 WHERE id = ?`, userID.String())
+func (s *SQLiteStore) SetUserOnboarded(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE users SET onboarded = 1 WHERE id = ?`, userID.String())
 	return err
 }
 
 // Dashboard methods
 
-func (s *SQLiteStore) GetDashboard(id uuid.UUID) (*models.Dashboard, error) {
-	row := s.db.QueryRow(`SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE id = ?`, id.String())
+func (s *SQLiteStore) GetDashboard(ctx context.Context, id uuid.UUID) (*models.Dashboard, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE id = ?`, id.String())
 	return s.scanDashboard(row)
 }
 
-func (s *SQLiteStore) GetUserDashboards(userID uuid.UUID) ([]models.Dashboard, error) {
-	rows, err := s.db.Query(`SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE user_id = ? ORDER BY is_default DESC, created_at`, userID.String())
+func (s *SQLiteStore) GetUserDashboards(ctx context.Context, userID uuid.UUID) ([]models.Dashboard, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE user_id = ? ORDER BY is_default DESC, created_at`, userID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -358,8 +390,8 @@ func (s *SQLiteStore) GetUserDashboards(userID uuid.UUID) ([]models.Dashboard, e
 	return dashboards, rows.Err()
 }
 
-func (s *SQLiteStore) GetDefaultDashboard(userID uuid.UUID) (*models.Dashboard, error) {
-	row := s.db.QueryRow(`SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE user_id = ? AND is_default = 1`, userID.String())
+func (s *SQLiteStore) GetDefaultDashboard(ctx context.Context, userID uuid.UUID) (*models.Dashboard, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE user_id = ? AND is_default = 1`, userID.String())
 	return s.scanDashboard(row)
 }
 
@@ -414,7 +446,7 @@ func (s *SQLiteStore) scanDashboardRow(rows *sql.Rows) (*models.Dashboard, error
 	return &d, nil
 }
 
-func (s *SQLiteStore) CreateDashboard(dashboard *models.Dashboard) error {
+func (s *SQLiteStore) CreateDashboard(ctx context.Context, dashboard *models.Dashboard) error {
 	if dashboard.ID == uuid.Nil {
 		dashboard.ID = uuid.New()
 	}
@@ -426,12 +458,12 @@ func (s *SQLiteStore) CreateDashboard(dashboard *models.Dashboard) error {
 		layoutStr = &str
 	}
 
-	_, err := s.db.Exec(`INSERT INTO dashboards (id, user_id, name, layout, is_default, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, `INSERT INTO dashboards (id, user_id, name, layout, is_default, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
 		dashboard.ID.String(), dashboard.UserID.String(), dashboard.Name, layoutStr, boolToInt(dashboard.IsDefault), dashboard.CreatedAt)
 	return err
 }
 
-func (s *SQLiteStore) UpdateDashboard(dashboard *models.Dashboard) error {
+func (s *SQLiteStore) UpdateDashboard(ctx context.Context, dashboard *models.Dashboard) error {
 	now := time.Now()
 	dashboard.UpdatedAt = &now
 
@@ -441,25 +473,25 @@ func (s *SQLiteStore) UpdateDashboard(dashboard *models.Dashboard) error {
 		layoutStr = &str
 	}
 
-	_, err := s.db.Exec(`UPDATE dashboards SET name = ?, layout = ?, is_default = ?, updated_at = ? WHERE id = ?`,
+	_, err := s.db.ExecContext(ctx, `UPDATE dashboards SET name = ?, layout = ?, is_default = ?, updated_at = ? WHERE id = ?`,
 		dashboard.Name, layoutStr, boolToInt(dashboard.IsDefault), dashboard.UpdatedAt, dashboard.ID.String())
 	return err
 }
 
-func (s *SQLiteStore) DeleteDashboard(id uuid.UUID) error {
-	_, err := s.db.Exec(`DELETE FROM dashboards WHERE id = ?`, id.String())
+func (s *SQLiteStore) DeleteDashboard(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM dashboards WHERE id = ?`, id.String())
 	return err
 }
 
 // Card methods
 
-func (s *SQLiteStore) GetCard(id uuid.UUID) (*models.Card, error) {
-	row := s.db.QueryRow(`SELECT id, dashboard_id, card_type, config, position, last_summary, last_focus, created_at FROM cards WHERE id = ?`, id.String())
+func (s *SQLiteStore) GetCard(ctx context.Context, id uuid.UUID) (*models.Card, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, dashboard_id, card_type, config, position, last_summary, last_focus, created_at FROM cards WHERE id = ?`, id.String())
 	return s.scanCard(row)
 }
 
-func (s *SQLiteStore) GetDashboardCards(dashboardID uuid.UUID) ([]models.Card, error) {
-	rows, err := s.db.Query(`SELECT id, dashboard_id, card_type, config, position, last_summary, last_focus, created_at FROM cards WHERE dashboard_id = ? ORDER BY created_at`, dashboardID.String())
+func (s *SQLiteStore) GetDashboardCards(ctx context.Context, dashboardID uuid.UUID) ([]models.Card, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, dashboard_id, card_type, config, position, last_summary, last_focus, created_at FROM cards WHERE dashboard_id = ? ORDER BY created_at`, dashboardID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -535,7 +567,7 @@ func (s *SQLiteStore) scanCardRow(rows *sql.Rows) (*models.Card, error) {
 	return &c, nil
 }
 
-func (s *SQLiteStore) CreateCard(card *models.Card) error {
+func (s *SQLiteStore) CreateCard(ctx context.Context, card *models.Card) error {
 	if card.ID == uuid.Nil {
 		card.ID = uuid.New()
 	}
@@ -548,12 +580,12 @@ func (s *SQLiteStore) CreateCard(card *models.Card) error {
 		configStr = &str
 	}
 
-	_, err := s.db.Exec(`INSERT INTO cards (id, dashboard_id, card_type, config, position, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, `INSERT INTO cards (id, dashboard_id, card_type, config, position, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
 		card.ID.String(), card.DashboardID.String(), string(card.CardType), configStr, string(positionJSON), card.CreatedAt)
 	return err
 }
 
-func (s *SQLiteStore) UpdateCard(card *models.Card) error {
+func (s *SQLiteStore) UpdateCard(ctx context.Context, card *models.Card) error {
 	positionJSON, _ := json.Marshal(card.Position)
 	var configStr *string
 	if card.Config != nil {
@@ -561,24 +593,24 @@ func (s *SQLiteStore) UpdateCard(card *models.Card) error {
 		configStr = &str
 	}
 
-	_, err := s.db.Exec(`UPDATE cards SET card_type = ?, config = ?, position = ? WHERE id = ?`,
+	_, err := s.db.ExecContext(ctx, `UPDATE cards SET card_type = ?, config = ?, position = ? WHERE id = ?`,
 		string(card.CardType), configStr, string(positionJSON), card.ID.String())
 	return err
 }
 
-func (s *SQLiteStore) DeleteCard(id uuid.UUID) error {
-	_, err := s.db.Exec(`DELETE FROM cards WHERE id = ?`, id.String())
+func (s *SQLiteStore) DeleteCard(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM cards WHERE id = ?`, id.String())
 	return err
 }
 
-func (s *SQLiteStore) UpdateCardFocus(cardID uuid.UUID, summary string) error {
-	_, err := s.db.Exec(`UPDATE cards SET last_focus = ?, last_summary = ? WHERE id = ?`, time.Now(), summary, cardID.String())
+func (s *SQLiteStore) UpdateCardFocus(ctx context.Context, cardID uuid.UUID, summary string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE cards SET last_focus = ?, last_summary = ? WHERE id = ?`, time.Now(), summary, cardID.String())
 	return err
 }
 
 // Card History methods
 
-func (s *SQLiteStore) AddCardHistory(history *models.CardHistory) error {
+func (s *SQLiteStore) AddCardHistory(ctx context.Context, history *models.CardHistory) error {
 	if history.ID == uuid.Nil {
 		history.ID = uuid.New()
 	}
@@ -595,13 +627,13 @@ func (s *SQLiteStore) AddCardHistory(history *models.CardHistory) error {
 		configStr = &str
 	}
 
-	_, err := s.db.Exec(`INSERT INTO card_history (id, user_id, original_card_id, card_type, config, swapped_out_at, reason) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, `INSERT INTO card_history (id, user_id, original_card_id, card_type, config, swapped_out_at, reason) VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		history.ID.String(), history.UserID.String(), origCardID, string(history.CardType), configStr, history.SwappedOutAt, history.Reason)
 	return err
 }
 
-func (s *SQLiteStore) GetUserCardHistory(userID uuid.UUID, limit int) ([]models.CardHistory, error) {
-	rows, err := s.db.Query(`SELECT id, user_id, original_card_id, card_type, config, swapped_out_at, reason FROM card_history WHERE user_id = ? ORDER BY swapped_out_at DESC LIMIT ?`, userID.String(), limit)
+func (s *SQLiteStore) GetUserCardHistory(ctx context.Context, userID uuid.UUID, limit int) ([]models.CardHistory, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, original_card_id, card_type, config, swapped_out_at, reason FROM card_history WHERE user_id = ? ORDER BY swapped_out_at DESC LIMIT ?`, userID.String(), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -638,13 +670,13 @@ func (s *SQLiteStore) GetUserCardHistory(userID uuid.UUID, limit int) ([]models.
 
 // Pending Swap methods
 
-func (s *SQLiteStore) GetPendingSwap(id uuid.UUID) (*models.PendingSwap, error) {
-	row := s.db.QueryRow(`SELECT id, user_id, card_id, new_card_type, new_card_config, reason, swap_at, status, created_at FROM pending_swaps WHERE id = ?`, id.String())
+func (s *SQLiteStore) GetPendingSwap(ctx context.Context, id uuid.UUID) (*models.PendingSwap, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, user_id, card_id, new_card_type, new_card_config, reason, swap_at, status, created_at FROM pending_swaps WHERE id = ?`, id.String())
 	return s.scanPendingSwap(row)
 }
 
-func (s *SQLiteStore) GetUserPendingSwaps(userID uuid.UUID) ([]models.PendingSwap, error) {
-	rows, err := s.db.Query(`SELECT id, user_id, card_id, new_card_type, new_card_config, reason, swap_at, status, created_at FROM pending_swaps WHERE user_id = ? AND status = 'pending' ORDER BY swap_at`, userID.String())
+func (s *SQLiteStore) GetUserPendingSwaps(ctx context.Context, userID uuid.UUID) ([]models.PendingSwap, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, card_id, new_card_type, new_card_config, reason, swap_at, status, created_at FROM pending_swaps WHERE user_id = ? AND status = 'pending' ORDER BY swap_at`, userID.String())
 	if err != nil {
 		return nil, err
 	}
@@ -661,8 +693,8 @@ func (s *SQLiteStore) GetUserPendingSwaps(userID uuid.UUID) ([]models.PendingSwa
 	return swaps, rows.Err()
 }
 
-func (s *SQLiteStore) GetDueSwaps() ([]models.PendingSwap, error) {
-	rows, err := s.db.Query(`SELECT id, user_id, card_id, new_card_type, new_card_config, reason, swap_at, status, created_at FROM pending_swaps WHERE status = 'pending' AND swap_at <= ?`, time.Now())
+func (s *SQLiteStore) GetDueSwaps(ctx context.Context) ([]models.PendingSwap, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, card_id, new_card_type, new_card_config, reason, swap_at, status, created_at FROM pending_swaps WHERE status = 'pending' AND swap_at <= ?`, time.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -730,7 +762,7 @@ func (s *SQLiteStore) scanPendingSwapRow(rows *sql.Rows) (*models.PendingSwap, e
 	return &swap, nil
 }
 
-func (s *SQLiteStore) CreatePendingSwap(swap *models.PendingSwap) error {
+func (s *SQLiteStore) CreatePendingSwap(ctx context.Context, swap *models.PendingSwap) error {
 	if swap.ID == uuid.Nil {
 		swap.ID = uuid.New()
 	}
@@ -745,24 +777,24 @@ func (s *SQLiteStore) CreatePendingSwap(swap *models.PendingSwap) error {
 		configStr = &str
 	}
 
-	_, err := s.db.Exec(`INSERT INTO pending_swaps (id, user_id, card_id, new_card_type, new_card_config, reason, swap_at, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, `INSERT INTO pending_swaps (id, user_id, card_id, new_card_type, new_card_config, reason, swap_at, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		swap.ID.String(), swap.UserID.String(), swap.CardID.String(), string(swap.NewCardType), configStr, swap.Reason, swap.SwapAt, string(swap.Status), swap.CreatedAt)
 	return err
 }
 
-func (s *SQLiteStore) UpdateSwapStatus(id uuid.UUID, status models.SwapStatus) error {
-	_, err := s.db.Exec(`UPDATE pending_swaps SET status = ? WHERE id = ?`, string(status), id.String())
+func (s *SQLiteStore) UpdateSwapStatus(ctx context.Context, id uuid.UUID, status models.SwapStatus) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE pending_swaps SET status = ? WHERE id = ?`, string(status), id.String())
 	return err
 }
 
-func (s *SQLiteStore) SnoozeSwap(id uuid.UUID, newSwapAt time.Time) error {
-	_, err := s.db.Exec(`UPDATE pending_swaps SET swap_at = ?, status = 'snoozed' WHERE id = ?`, newSwapAt, id.String())
+func (s *SQLiteStore) SnoozeSwap(ctx context.Context, id uuid.UUID, newSwapAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE pending_swaps SET swap_at = ?, status = 'snoozed' WHERE id = ?`, newSwapAt, id.String())
 	return err
 }
 
 // User Event methods
 
-func (s *SQLiteStore) RecordEvent(event *models.UserEvent) error {
+func (s *SQLiteStore) RecordEvent(ctx context.Context, event *models.UserEvent) error {
 	if event.ID == uuid.Nil {
 		event.ID = uuid.New()
 	}
@@ -779,14 +811,14 @@ func (s *SQLiteStore) RecordEvent(event *models.UserEvent) error {
 		metadataStr = &str
 	}
 
-	_, err := s.db.Exec(`INSERT INTO user_events (id, user_id, event_type, card_id, metadata, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, `INSERT INTO user_events (id, user_id, event_type, card_id, metadata, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
 		event.ID.String(), event.UserID.String(), string(event.EventType), cardID, metadataStr, event.CreatedAt)
 	return err
 }
 
-func (s *SQLiteStore) GetRecentEvents(userID uuid.UUID, since time.Duration) ([]models.UserEvent, error) {
+func (s *SQLiteStore) GetRecentEvents(ctx context.Context, userID uuid.UUID, since time.Duration) ([]models.UserEvent, error) {
 	cutoff := time.Now().Add(-since)
-	rows, err := s.db.Query(`SELECT id, user_id, event_type, card_id, metadata, created_at FROM user_events WHERE user_id = ? AND created_at >= ? ORDER BY created_at DESC`, userID.String(), cutoff)
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, event_type, card_id, metadata, created_at FROM user_events WHERE user_id = ? AND created_at >= ? ORDER BY created_at DESC`, userID.String(), cutoff)
 	if err != nil {
 		return nil, err
 	}
@@ -818,6 +850,137 @@ func (s *SQLiteStore) GetRecentEvents(userID uuid.UUID, since time.Duration) ([]
 	return events, rows.Err()
 }
 
+// Cluster Version History methods
+
+// AddVersionSnapshot stores a snapshot unless the cluster's latest one has
+// the same versions and upgrade state. The check and the insert are one
+// statement, so concurrent writers cannot both add the same change.
+func (s *SQLiteStore) AddVersionSnapshot(ctx context.Context, snapshot *models.ClusterVersionSnapshot) (bool, error) {
+	if snapshot.ID == uuid.Nil {
+		snapshot.ID = uuid.New()
+	}
+	if snapshot.CapturedAt.IsZero() {
+		snapshot.CapturedAt = time.Now()
+	}
+
+	// Maps marshal with sorted keys, so equal counts compare equal as text
+	kubeletVersions := snapshot.KubeletVersions
+	if kubeletVersions == nil {
+		kubeletVersions = map[string]int{}
+	}
+	versions, err := json.Marshal(kubeletVersions)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.db.ExecContext(ctx, `INSERT INTO cluster_version_history (id, cluster, control_plane_version, kubelet_versions, phase, nodes, nodes_upgraded, nodes_out_of_skew, cordoned_nodes, captured_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM (
+				SELECT control_plane_version, kubelet_versions, phase, nodes, nodes_upgraded, nodes_out_of_skew, cordoned_nodes
+				FROM cluster_version_history WHERE cluster = ? ORDER BY captured_at DESC LIMIT 1
+			) latest
+			WHERE latest.control_plane_version = ? AND latest.kubelet_versions = ? AND latest.phase = ?
+				AND latest.nodes = ? AND latest.nodes_upgraded = ? AND latest.nodes_out_of_skew = ? AND latest.cordoned_nodes = ?
+		)`,
+		snapshot.ID.String(), snapshot.Cluster, snapshot.ControlPlaneVersion, string(versions), snapshot.Phase,
+		snapshot.Nodes, snapshot.NodesUpgraded, snapshot.NodesOutOfSkew, snapshot.CordonedNodes, snapshot.CapturedAt,
+		snapshot.Cluster,
+		snapshot.ControlPlaneVersion, string(versions), snapshot.Phase,
+		snapshot.Nodes, snapshot.NodesUpgraded, snapshot.NodesOutOfSkew, snapshot.CordonedNodes)
+	if err != nil {
+		return false, err
+	}
+	added, err := result.RowsAffected()
+	return added > 0, err
+}
+
+// GetVersionHistory returns the newest snapshots of the given clusters
+// first; no clusters returns none
+func (s *SQLiteStore) GetVersionHistory(ctx context.Context, clusters []string, limit int) ([]models.ClusterVersionSnapshot, error) {
+	if len(clusters) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, 0, len(clusters)+1)
+	for _, cluster := range clusters {
+		args = append(args, cluster)
+	}
+	args = append(args, limit)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(clusters)), ", ")
+	rows, err := s.db.QueryContext(ctx, `SELECT id, cluster, control_plane_version, kubelet_versions, phase, nodes, nodes_upgraded, nodes_out_of_skew, cordoned_nodes, captured_at FROM cluster_version_history WHERE cluster IN (`+placeholders+`) ORDER BY captured_at DESC LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.ClusterVersionSnapshot
+	for rows.Next() {
+		var h models.ClusterVersionSnapshot
+		var idStr string
+		var versions sql.NullString
+
+		if err := rows.Scan(&idStr, &h.Cluster, &h.ControlPlaneVersion, &versions, &h.Phase, &h.Nodes, &h.NodesUpgraded, &h.NodesOutOfSkew, &h.CordonedNodes, &h.CapturedAt); err != nil {
+			return nil, err
+		}
+
+		h.ID, _ = uuid.Parse(idStr)
+		h.KubeletVersions = map[string]int{}
+		if versions.Valid {
+			json.Unmarshal([]byte(versions.String), &h.KubeletVersions)
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// Pod Security Standards suppression methods
+
+func (s *SQLiteStore) ListPSSSuppressions(ctx context.Context) ([]models.PSSSuppression, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, cluster, namespace, rule_id, reason, created_by, created_at FROM pss_suppressions ORDER BY cluster, namespace, rule_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suppressions []models.PSSSuppression
+	for rows.Next() {
+		var sup models.PSSSuppression
+		var idStr string
+		var reason, createdBy sql.NullString
+
+		if err := rows.Scan(&idStr, &sup.Cluster, &sup.Namespace, &sup.RuleID, &reason, &createdBy, &sup.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		sup.ID, _ = uuid.Parse(idStr)
+		sup.Reason = reason.String
+		if createdBy.Valid {
+			sup.CreatedBy, _ = uuid.Parse(createdBy.String)
+		}
+		suppressions = append(suppressions, sup)
+	}
+	return suppressions, rows.Err()
+}
+
+func (s *SQLiteStore) CreatePSSSuppression(ctx context.Context, suppression *models.PSSSuppression) error {
+	if suppression.ID == uuid.Nil {
+		suppression.ID = uuid.New()
+	}
+	if suppression.CreatedAt.IsZero() {
+		suppression.CreatedAt = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO pss_suppressions (id, cluster, namespace, rule_id, reason, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		suppression.ID.String(), suppression.Cluster, suppression.Namespace, suppression.RuleID,
+		nullString(suppression.Reason), suppression.CreatedBy.String(), suppression.CreatedAt)
+	return err
+}
+
+func (s *SQLiteStore) DeletePSSSuppression(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM pss_suppressions WHERE id = ?`, id.String())
+	return err
+}
+
 // Helper functions
 
 func nullString(s string) sql.NullString {
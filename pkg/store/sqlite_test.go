@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+func newTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "console.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func versionSnapshot(cluster, version string, upgraded int) *models.ClusterVersionSnapshot {
+	return &models.ClusterVersionSnapshot{
+		Cluster:             cluster,
+		ControlPlaneVersion: version,
+		KubeletVersions:     map[string]int{"v1.30.2": 3 - upgraded, "v1.31.0": upgraded},
+		Phase:               "in-progress",
+		Nodes:               3,
+		NodesUpgraded:       upgraded,
+	}
+}
+
+func TestAddVersionSnapshotSkipsUnchanged(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	steps := []struct {
+		snapshot *models.ClusterVersionSnapshot
+		added    bool
+	}{
+		{versionSnapshot("a", "v1.31.0", 1), true},
+		{versionSnapshot("a", "v1.31.0", 1), false},
+		// Another cluster has its own latest snapshot
+		{versionSnapshot("b", "v1.31.0", 1), true},
+		{versionSnapshot("a", "v1.31.0", 2), true},
+		// Returning to an earlier state is a change from the latest
+		{versionSnapshot("a", "v1.31.0", 1), true},
+	}
+	for i, step := range steps {
+		step.snapshot.CapturedAt = time.Now().Add(time.Duration(i) * time.Second)
+		added, err := s.AddVersionSnapshot(ctx, step.snapshot)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if added != step.added {
+			t.Fatalf("step %d: added = %v, want %v", i, added, step.added)
+		}
+	}
+
+	history, err := s.GetVersionHistory(ctx, []string{"a"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[0].NodesUpgraded != 1 || history[1].NodesUpgraded != 2 {
+		t.Fatalf("history = %+v", history)
+	}
+}
+
+func TestGetVersionHistoryClusters(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	for i, cluster := range []string{"a", "b", "c"} {
+		snapshot := versionSnapshot(cluster, "v1.31.0", 1)
+		snapshot.CapturedAt = time.Now().Add(time.Duration(i) * time.Second)
+		if _, err := s.AddVersionSnapshot(ctx, snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		clusters []string
+		limit    int
+		want     []string
+	}{
+		{[]string{"a", "c"}, 10, []string{"c", "a"}},
+		{[]string{"a", "b", "c"}, 2, []string{"c", "b"}},
+		{[]string{"unknown"}, 10, nil},
+		// No clusters is no history, not every cluster's
+		{nil, 10, nil},
+	}
+	for _, tt := range tests {
+		history, err := s.GetVersionHistory(ctx, tt.clusters, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, h := range history {
+			got = append(got, h.Cluster)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetVersionHistory(%v, %d) = %v, want %v", tt.clusters, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestAddVersionSnapshotConcurrent(t *testing.T) {
+	s := newTestStore(t)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.AddVersionSnapshot(context.Background(), versionSnapshot("a", "v1.31.0", 1)); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	history, err := s.GetVersionHistory(context.Background(), []string{"a"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("concurrent writers recorded %d snapshots, want 1", len(history))
+	}
+}
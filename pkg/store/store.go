@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,53 +11,63 @@ import (
 // Store defines the interface for data persistence
 type Store interface {
 	// Users
-	GetUser(id uuid.UUID) (*models.User, error)
-	GetUserByGitHubID(githubID string) (*models.User, error)
-	CreateUser(user *models.User) error
-	UpdateUser(user *models.User) error
-	UpdateLastLogin(userID uuid.UUID) error
-	ListUsers() ([]models.User, error)
-	DeleteUser(id uuid.UUID) error
-	UpdateUserRole(userID uuid.UUID, role string) error
-	CountUsersByRole() (admins, editors, viewers int, err error)
+	GetUser(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetUserByGitHubID(ctx context.Context, githubID string) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	UpdateUser(ctx context.Context, user *models.User) error
+	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
+	ListUsers(ctx context.Context) ([]models.User, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) error
+	CountUsersByRole(ctx context.Context) (admins, editors, viewers int, err error)
 
 	// Onboarding
-	SaveOnboardingResponse(response *models.OnboardingResponse) error
-	GetOnboardingResponses(userID uuid.UUID) ([]models.OnboardingResponse, error)
-	SetUserOnboarded(userID uuid.UUID) error
+	SaveOnboardingResponse(ctx context.Context, response *models.OnboardingResponse) error
+	GetOnboardingResponses(ctx context.Context, userID uuid.UUID) ([]models.OnboardingResponse, error)
+	SetUserOnboarded(ctx context.Context, userID uuid.UUID) error
 
 	// Dashboards
-	GetDashboard(id uuid.UUID) (*models.Dashboard, error)
-	GetUserDashboards(userID uuid.UUID) ([]models.Dashboard, error)
-	GetDefaultDashboard(userID uuid.UUID) (*models.Dashboard, error)
-	CreateDashboard(dashboard *models.Dashboard) error
-	UpdateDashboard(dashboard *models.Dashboard) error
-	DeleteDashboard(id uuid.UUID) error
+	GetDashboard(ctx context.Context, id uuid.UUID) (*models.Dashboard, error)
+	GetUserDashboards(ctx context.Context, userID uuid.UUID) ([]models.Dashboard, error)
+	GetDefaultDashboard(ctx context.Context, userID uuid.UUID) (*models.Dashboard, error)
+	CreateDashboard(ctx context.Context, dashboard *models.Dashboard) error
+	UpdateDashboard(ctx context.Context, dashboard *models.Dashboard) error
+	DeleteDashboard(ctx context.Context, id uuid.UUID) error
 
 	// Cards
-	GetCard(id uuid.UUID) (*models.Card, error)
-	GetDashboardCards(dashboardID uuid.UUID) ([]models.Card, error)
-	CreateCard(card *models.Card) error
-	UpdateCard(card *models.Card) error
-	DeleteCard(id uuid.UUID) error
-	UpdateCardFocus(cardID uuid.UUID, summary string) error
+	GetCard(ctx context.Context, id uuid.UUID) (*models.Card, error)
+	GetDashboardCards(ctx context.Context, dashboardID uuid.UUID) ([]models.Card, error)
+	CreateCard(ctx context.Context, card *models.Card) error
+	UpdateCard(ctx context.Context, card *models.Card) error
+	DeleteCard(ctx context.Context, id uuid.UUID) error
+	UpdateCardFocus(ctx context.Context, cardID uuid.UUID, summary string) error
 
 	// Card History
-	AddCardHistory(history *models.CardHistory) error
-	GetUserCardHistory(userID uuid.UUID, limit int) ([]models.CardHistory, error)
+	AddCardHistory(ctx context.Context, history *models.CardHistory) error
+	GetUserCardHistory(ctx context.Context, userID uuid.UUID, limit int) ([]models.CardHistory, error)
 
 	// Pending Swaps
-	GetPendingSwap(id uuid.UUID) (*models.PendingSwap, error)
-	GetUserPendingSwaps(userID uuid.UUID) ([]models.PendingSwap, error)
-	GetDueSwaps() ([]models.PendingSwap, error)
-	CreatePendingSwap(swap *models.PendingSwap) error
-	UpdateSwapStatus(id uuid.UUID, status models.SwapStatus) error
-	SnoozeSwap(id uuid.UUID, newSwapAt time.Time) error
+	GetPendingSwap(ctx context.Context, id uuid.UUID) (*models.PendingSwap, error)
+	GetUserPendingSwaps(ctx context.Context, userID uuid.UUID) ([]models.PendingSwap, error)
+	GetDueSwaps(ctx context.Context) ([]models.PendingSwap, error)
+	CreatePendingSwap(ctx context.Context, swap *models.PendingSwap) error
+	UpdateSwapStatus(ctx context.Context, id uuid.UUID, status models.SwapStatus) error
+	SnoozeSwap(ctx context.Context, id uuid.UUID, newSwapAt time.Time) error
 
 	// User Events
-	RecordEvent(event *models.UserEvent) error
-	GetRecentEvents(userID uuid.UUID, since time.Duration) ([]models.UserEvent, error)
+	RecordEvent(ctx context.Context, event *models.UserEvent) error
+	GetRecentEvents(ctx context.Context, userID uuid.UUID, since time.Duration) ([]models.UserEvent, error)
+
+	// Cluster version history
+	AddVersionSnapshot(ctx context.Context, snapshot *models.ClusterVersionSnapshot) (bool, error)
+	GetVersionHistory(ctx context.Context, clusters []string, limit int) ([]models.ClusterVersionSnapshot, error)
+
+	// Pod Security Standards suppressions
+	ListPSSSuppressions(ctx context.Context) ([]models.PSSSuppression, error)
+	CreatePSSSuppression(ctx context.Context, suppression *models.PSSSuppression) error
+	DeletePSSSuppression(ctx context.Context, id uuid.UUID) error
 
 	// Lifecycle
+	Ping(ctx context.Context) error
 	Close() error
 }
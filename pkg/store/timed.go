@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kubestellar/console/pkg/metrics"
+	"github.com/kubestellar/console/pkg/tracing"
+)
+
+// timedDB records the latency of every query in the store metrics and
+// traces it as a child span of ctx
+type timedDB struct {
+	*sql.DB
+}
+
+func (db timedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, done := startQuery(ctx, query)
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
+}
+
+func (db timedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, done := startQuery(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+// QueryRowContext's error surfaces at Scan, so only latency is recorded
+func (db timedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, done := startQuery(ctx, query)
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	done(nil)
+	return row
+}
+
+// startQuery starts a span for query; the returned function ends it and
+// records the query metrics
+func startQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	start := time.Now()
+	operation, table := queryLabels(query)
+	ctx, span := tracing.StartKind(ctx, "store "+operation+" "+table, trace.SpanKindClient,
+		attribute.String("db.system", "sqlite"),
+		attribute.String("db.operation.name", operation),
+		attribute.String("db.collection.name", table),
+	)
+	return ctx, func(err error) {
+		metrics.ObserveStoreQuery(operation, table, time.Since(start), err)
+		tracing.End(span, err)
+	}
+}
+
+// queryLabels returns the statement kind and main table of a query, e.g.
+// ("select", "users")
+func queryLabels(query string) (operation, table string) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "unknown", "unknown"
+	}
+	operation = strings.ToLower(fields[0])
+
+	// The table follows FROM, INTO or UPDATE; CREATE statements run only
+	// in migrations
+	for i, f := range fields[:len(fields)-1] {
+		switch strings.ToUpper(f) {
+		case "FROM", "INTO", "UPDATE":
+			return operation, strings.Trim(strings.ToLower(fields[i+1]), "`\"(")
+		}
+	}
+	return operation, "unknown"
+}
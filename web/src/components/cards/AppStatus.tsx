@@ -1,6 +1,8 @@
 import { useState, useMemo } from 'react'
-import { Box, CheckCircle, AlertTriangle, Clock, ChevronRight } from 'lucide-react'
+import { Box, CheckCircle, AlertTriangle, Clock, ChevronRight, GitCompare } from 'lucide-react'
 import { ClusterBadge } from '../ui/ClusterBadge'
+import { useApps, Application } from '../../hooks/useMCP'
+import { useGlobalFilters } from '../../hooks/useGlobalFilters'
 import { useDrillDownActions } from '../../hooks/useDrillDown'
 import { CardControls, SortDirection } from '../ui/CardControls'
 
@@ -16,38 +18,29 @@ interface AppStatusProps {
   config?: any
 }
 
-// Demo data
-const rawApps = [
-  {
-    name: 'api-gateway',
-    clusters: ['vllm-d', 'prod-east', 'prod-west'],
-    status: { healthy: 3, warning: 0, pending: 0 },
-  },
-  {
-    name: 'frontend',
-    clusters: ['vllm-d', 'prod-east'],
-    status: { healthy: 1, warning: 1, pending: 0 },
-  },
-  {
-    name: 'worker-service',
-    clusters: ['prod-east', 'prod-west'],
-    status: { healthy: 1, warning: 0, pending: 1 },
-  },
-]
-
 export function AppStatus(_props: AppStatusProps) {
+  const { apps: allApps, isLoading, error } = useApps()
   const { drillToDeployment } = useDrillDownActions()
+  const { selectedClusters, isAllClustersSelected, customFilter } = useGlobalFilters()
   const [sortBy, setSortBy] = useState<SortByOption>('status')
   const [sortDirection, setSortDirection] = useState<SortDirection>('desc')
   const [limit, setLimit] = useState<number | 'unlimited'>(5)
 
   const apps = useMemo(() => {
-    const sorted = [...rawApps].sort((a, b) => {
+    let filtered = allApps
+    if (!isAllClustersSelected) {
+      filtered = filtered.filter(a => a.clusters.some(c => selectedClusters.includes(c)))
+    }
+    if (customFilter.trim()) {
+      const query = customFilter.toLowerCase()
+      filtered = filtered.filter(a => a.name.toLowerCase().includes(query))
+    }
+    const sorted = [...filtered].sort((a, b) => {
       let result = 0
       if (sortBy === 'status') {
-        // Sort by warning count (most warnings first)
-        const aScore = a.status.warning * 10 + a.status.pending
-        const bScore = b.status.warning * 10 + b.status.pending
+        // Sort by degraded clusters (most first), then progressing ones
+        const aScore = a.status.degraded * 10 + a.status.progressing
+        const bScore = b.status.degraded * 10 + b.status.progressing
         result = bScore - aScore
       } else if (sortBy === 'name') result = a.name.localeCompare(b.name)
       else if (sortBy === 'clusters') result = b.clusters.length - a.clusters.length
@@ -55,11 +48,23 @@ export function AppStatus(_props: AppStatusProps) {
     })
     if (limit === 'unlimited') return sorted
     return sorted.slice(0, limit)
-  }, [sortBy, sortDirection, limit])
+  }, [allApps, isAllClustersSelected, selectedClusters, customFilter, sortBy, sortDirection, limit])
 
-  const handleAppClick = (appName: string, cluster: string) => {
-    // Drill down to the deployment in the first cluster
-    drillToDeployment(cluster, 'default', appName)
+  const handleAppClick = (app: Application) => {
+    // Drill down to the app's first workload in its least healthy cluster
+    const target = app.clusterDetails.find(c => c.health === app.health) ?? app.clusterDetails[0]
+    const workload = target?.workloads[0]
+    if (workload) {
+      drillToDeployment(workload.cluster, workload.namespace, workload.name)
+    }
+  }
+
+  if (isLoading && allApps.length === 0) {
+    return (
+      <div className="h-full flex items-center justify-center">
+        <div className="spinner w-8 h-8" />
+      </div>
+    )
   }
 
   return (
@@ -79,19 +84,35 @@ export function AppStatus(_props: AppStatusProps) {
       </div>
 
       <div className="flex-1 space-y-3 overflow-y-auto">
+      {error && <div className="text-xs text-red-400">{error}</div>}
+      {!error && apps.length === 0 && (
+        <div className="flex items-center justify-center h-full text-sm text-muted-foreground">
+          No applications found
+        </div>
+      )}
       {apps.map((app) => {
-        const total = app.status.healthy + app.status.warning + app.status.pending
+        const total = app.clusters.length
 
         return (
           <div
-            key={app.name}
-            onClick={() => handleAppClick(app.name, app.clusters[0])}
+            key={`${app.source}/${app.namespace ?? ''}/${app.name}`}
+            onClick={() => handleAppClick(app)}
             className="p-3 rounded-lg bg-secondary/30 hover:bg-secondary/50 transition-colors cursor-pointer group"
           >
             <div className="flex items-center justify-between mb-2">
               <div className="flex items-center gap-2">
                 <Box className="w-4 h-4 text-purple-400" />
                 <span className="text-sm font-medium text-foreground">{app.name}</span>
+                {app.namespace && <span className="text-xs text-muted-foreground">{app.namespace}</span>}
+                {app.drifted && (
+                  <span
+                    className="flex items-center gap-1 text-xs px-1.5 py-0.5 rounded bg-orange-500/20 text-orange-400"
+                    title={app.imageDrift.map(d => `${d.image}: ${d.driftedClusters.join(', ')}`).join('\n')}
+                  >
+                    <GitCompare className="w-3 h-3" />
+                    version drift
+                  </span>
+                )}
               </div>
               <div className="flex items-center gap-2">
                 <span className="text-xs text-muted-foreground">
@@ -109,16 +130,16 @@ export function AppStatus(_props: AppStatusProps) {
                   <span className="text-xs text-green-400">{app.status.healthy}</span>
                 </div>
               )}
-              {app.status.warning > 0 && (
+              {app.status.degraded > 0 && (
                 <div className="flex items-center gap-1">
                   <AlertTriangle className="w-3.5 h-3.5 text-yellow-400" />
-                  <span className="text-xs text-yellow-400">{app.status.warning}</span>
+                  <span className="text-xs text-yellow-400">{app.status.degraded}</span>
                 </div>
               )}
-              {app.status.pending > 0 && (
+              {app.status.progressing > 0 && (
                 <div className="flex items-center gap-1">
                   <Clock className="w-3.5 h-3.5 text-blue-400" />
-                  <span className="text-xs text-blue-400">{app.status.pending}</span>
+                  <span className="text-xs text-blue-400">{app.status.progressing}</span>
                 </div>
               )}
             </div>
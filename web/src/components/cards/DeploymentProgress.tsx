@@ -1,22 +1,37 @@
-import { CheckCircle, Clock, XCircle, Loader2 } from 'lucide-react'
-import { useDeployments } from '../../hooks/useMCP'
+import { useState } from 'react'
+import { CheckCircle, Clock, AlertTriangle, PauseCircle, Loader2, History, RotateCcw, Pause, Play, RefreshCw } from 'lucide-react'
+import {
+  useRollouts,
+  getRolloutHistory,
+  runRolloutAction,
+  type RolloutStatus,
+  type RolloutPhase,
+  type RolloutAction,
+  type WorkloadRevision,
+} from '../../hooks/useMCP'
 import { ClusterBadge } from '../ui/ClusterBadge'
 
-const statusConfig = {
-  running: {
+const statusConfig: Record<RolloutPhase, { icon: typeof CheckCircle; color: string; bg: string; barColor: string }> = {
+  complete: {
     icon: CheckCircle,
     color: 'text-green-400',
     bg: 'bg-green-500/20',
     barColor: 'bg-green-500',
   },
-  deploying: {
+  progressing: {
     icon: Clock,
     color: 'text-yellow-400',
     bg: 'bg-yellow-500/20',
     barColor: 'bg-yellow-500',
   },
-  failed: {
-    icon: XCircle,
+  paused: {
+    icon: PauseCircle,
+    color: 'text-blue-400',
+    bg: 'bg-blue-500/20',
+    barColor: 'bg-blue-500',
+  },
+  stalled: {
+    icon: AlertTriangle,
     color: 'text-red-400',
     bg: 'bg-red-500/20',
     barColor: 'bg-red-500',
@@ -30,13 +45,60 @@ interface DeploymentProgressProps {
   }
 }
 
+const rolloutKey = (r: RolloutStatus) => `${r.cluster}/${r.kind}/${r.namespace}/${r.name}`
+
+// Extract version from image tag (e.g., "api-gateway:v2.4.1" -> "v2.4.1")
+const getVersion = (image?: string) => {
+  if (!image) return ''
+  const parts = image.split(':')
+  return parts.length > 1 ? parts[parts.length - 1] : 'latest'
+}
+
 export function DeploymentProgress({ config }: DeploymentProgressProps) {
   const cluster = config?.cluster
   const namespace = config?.namespace
-  const { deployments, isLoading, error } = useDeployments(cluster, namespace)
+  const { rollouts, isLoading, error, refetch } = useRollouts(cluster, namespace)
+  const [expanded, setExpanded] = useState<string | null>(null)
+  const [revisions, setRevisions] = useState<WorkloadRevision[]>([])
+  const [busy, setBusy] = useState<string | null>(null)
+  const [actionError, setActionError] = useState<string | null>(null)
 
-  const activeDeployments = deployments.filter((d) => d.status === 'deploying').length
-  const failedDeployments = deployments.filter((d) => d.status === 'failed').length
+  const activeRollouts = rollouts.filter((r) => r.phase === 'progressing').length
+  const stalledRollouts = rollouts.filter((r) => r.phase === 'stalled').length
+
+  const toggleHistory = async (rollout: RolloutStatus) => {
+    const key = rolloutKey(rollout)
+    if (expanded === key) {
+      setExpanded(null)
+      return
+    }
+    setExpanded(key)
+    setRevisions([])
+    try {
+      const data = await getRolloutHistory(rollout)
+      setRevisions(data.revisions || [])
+    } catch {
+      setRevisions([])
+    }
+  }
+
+  const runAction = async (rollout: RolloutStatus, action: RolloutAction, revision?: number) => {
+    const key = rolloutKey(rollout)
+    setBusy(key)
+    setActionError(null)
+    try {
+      await runRolloutAction(rollout, action, revision)
+      await refetch()
+      if (expanded === key) {
+        const data = await getRolloutHistory(rollout)
+        setRevisions(data.revisions || [])
+      }
+    } catch (err) {
+      setActionError(err instanceof Error ? err.message : `Failed to ${action} ${rollout.name}`)
+    } finally {
+      setBusy(null)
+    }
+  }
 
   if (isLoading) {
     return (
@@ -46,7 +108,7 @@ export function DeploymentProgress({ config }: DeploymentProgressProps) {
     )
   }
 
-  if (error && deployments.length === 0) {
+  if (error && rollouts.length === 0) {
     return (
       <div className="h-full flex items-center justify-center text-muted-foreground text-sm">
         {error}
@@ -54,7 +116,7 @@ export function DeploymentProgress({ config }: DeploymentProgressProps) {
     )
   }
 
-  if (deployments.length === 0) {
+  if (rollouts.length === 0) {
     return (
       <div className="h-full flex items-center justify-center text-muted-foreground text-sm">
         No deployments found
@@ -62,13 +124,6 @@ export function DeploymentProgress({ config }: DeploymentProgressProps) {
     )
   }
 
-  // Extract version from image tag (e.g., "api-gateway:v2.4.1" -> "v2.4.1")
-  const getVersion = (image?: string) => {
-    if (!image) return ''
-    const parts = image.split(':')
-    return parts.length > 1 ? parts[parts.length - 1] : 'latest'
-  }
-
   return (
     <div className="h-full flex flex-col">
       {/* Header */}
@@ -77,48 +132,63 @@ export function DeploymentProgress({ config }: DeploymentProgressProps) {
           Deployment Progress
         </span>
         <div className="flex gap-2">
-          {activeDeployments > 0 && (
+          {activeRollouts > 0 && (
             <span className="text-xs px-2 py-0.5 rounded bg-yellow-500/20 text-yellow-400">
-              {activeDeployments} deploying
+              {activeRollouts} rolling out
             </span>
           )}
-          {failedDeployments > 0 && (
+          {stalledRollouts > 0 && (
             <span className="text-xs px-2 py-0.5 rounded bg-red-500/20 text-red-400">
-              {failedDeployments} failed
+              {stalledRollouts} stalled
             </span>
           )}
         </div>
       </div>
 
-      {/* Deployments list */}
+      {actionError && (
+        <p className="text-xs text-red-400 mb-2">{actionError}</p>
+      )}
+
+      {/* Rollouts list */}
       <div className="flex-1 space-y-3 overflow-y-auto">
-        {deployments.map((deployment) => {
-          const config = statusConfig[deployment.status]
+        {rollouts.map((rollout) => {
+          const key = rolloutKey(rollout)
+          const config = statusConfig[rollout.phase] || statusConfig.progressing
           const StatusIcon = config.icon
-          const version = getVersion(deployment.image)
+          const version = getVersion(rollout.images[0])
+          const isBusy = busy === key
 
           return (
             <div
-              key={`${deployment.cluster}-${deployment.namespace}-${deployment.name}`}
+              key={key}
               className="p-3 rounded-lg bg-secondary/30 border border-border/50"
             >
               <div className="flex items-start justify-between mb-2">
                 <div>
                   <div className="flex items-center gap-2 mb-1">
-                    <ClusterBadge cluster={deployment.cluster || 'default'} />
-                    <span className="text-xs text-muted-foreground">{deployment.namespace}</span>
+                    <ClusterBadge cluster={rollout.cluster || 'default'} />
+                    <span className="text-xs text-muted-foreground">{rollout.namespace}</span>
                     <StatusIcon className={`w-4 h-4 ${config.color}`} />
+                    <span className={`text-xs px-1.5 py-0.5 rounded ${config.bg} ${config.color}`}>
+                      {rollout.phase}
+                    </span>
                   </div>
                   <span className="text-sm font-medium text-foreground">
-                    {deployment.name}
+                    {rollout.name}
                   </span>
+                  {rollout.kind !== 'Deployment' && (
+                    <span className="text-xs text-muted-foreground ml-2">{rollout.kind}</span>
+                  )}
                 </div>
                 <div className="text-right">
-                  <div className="flex items-center gap-1 text-xs">
+                  <div className="flex items-center justify-end gap-1 text-xs">
                     <span className="text-foreground">{version}</span>
+                    {rollout.revision > 0 && (
+                      <span className="text-muted-foreground">rev {rollout.revision}</span>
+                    )}
                   </div>
                   <span className="text-xs text-muted-foreground">
-                    {deployment.readyReplicas}/{deployment.replicas} ready
+                    {rollout.ready}/{rollout.desired} ready
                   </span>
                 </div>
               </div>
@@ -127,12 +197,84 @@ export function DeploymentProgress({ config }: DeploymentProgressProps) {
               <div className="h-1.5 bg-secondary rounded-full overflow-hidden">
                 <div
                   className={`h-full ${config.barColor} transition-all duration-500`}
-                  style={{ width: `${deployment.progress}%` }}
+                  style={{ width: `${rollout.progress}%` }}
                 />
               </div>
 
-              {deployment.age && (
-                <p className="text-xs text-muted-foreground mt-1">Age: {deployment.age}</p>
+              {rollout.message && (
+                <p className={`text-xs mt-1 ${rollout.phase === 'stalled' ? 'text-red-400' : 'text-muted-foreground'}`}>
+                  {rollout.message}
+                </p>
+              )}
+
+              {/* Actions */}
+              <div className="flex items-center gap-1 mt-2">
+                <button
+                  onClick={() => toggleHistory(rollout)}
+                  className="p-1 rounded hover:bg-secondary text-muted-foreground hover:text-foreground"
+                  title="Revision history"
+                >
+                  <History className="w-3.5 h-3.5" />
+                </button>
+                <button
+                  onClick={() => runAction(rollout, 'rollback')}
+                  disabled={isBusy || rollout.paused}
+                  className="p-1 rounded hover:bg-secondary text-muted-foreground hover:text-foreground disabled:opacity-50"
+                  title="Roll back to previous revision"
+                >
+                  <RotateCcw className="w-3.5 h-3.5" />
+                </button>
+                {rollout.kind === 'Deployment' && (
+                  <button
+                    onClick={() => runAction(rollout, rollout.paused ? 'resume' : 'pause')}
+                    disabled={isBusy}
+                    className="p-1 rounded hover:bg-secondary text-muted-foreground hover:text-foreground disabled:opacity-50"
+                    title={rollout.paused ? 'Resume rollout' : 'Pause rollout'}
+                  >
+                    {rollout.paused ? <Play className="w-3.5 h-3.5" /> : <Pause className="w-3.5 h-3.5" />}
+                  </button>
+                )}
+                <button
+                  onClick={() => runAction(rollout, 'restart')}
+                  disabled={isBusy}
+                  className="p-1 rounded hover:bg-secondary text-muted-foreground hover:text-foreground disabled:opacity-50"
+                  title="Restart"
+                >
+                  <RefreshCw className={`w-3.5 h-3.5 ${isBusy ? 'animate-spin' : ''}`} />
+                </button>
+              </div>
+
+              {/* Revision history */}
+              {expanded === key && (
+                <div className="mt-2 space-y-1 border-t border-border/50 pt-2">
+                  {revisions.length === 0 && (
+                    <p className="text-xs text-muted-foreground">No revision history</p>
+                  )}
+                  {revisions.map((rev) => (
+                    <div key={rev.name} className="flex items-center justify-between text-xs">
+                      <div className="min-w-0">
+                        <span className={rev.current ? 'text-green-400' : 'text-foreground'}>
+                          #{rev.revision}
+                        </span>
+                        <span className="text-muted-foreground ml-2">
+                          {rev.images.map(getVersion).join(', ')}
+                        </span>
+                        {rev.changeCause && (
+                          <p className="text-muted-foreground truncate">{rev.changeCause}</p>
+                        )}
+                      </div>
+                      {!rev.current && (
+                        <button
+                          onClick={() => runAction(rollout, 'rollback', rev.revision)}
+                          disabled={isBusy || rollout.paused}
+                          className="px-1.5 py-0.5 rounded bg-secondary hover:bg-secondary/80 text-muted-foreground disabled:opacity-50"
+                        >
+                          Roll back
+                        </button>
+                      )}
+                    </div>
+                  ))}
+                </div>
               )}
             </div>
           )
@@ -0,0 +1,254 @@
+import { useState, useMemo } from 'react'
+import { Layers, Box, Shield, Network, HardDrive, Users, AlertTriangle, RefreshCw, Gauge } from 'lucide-react'
+import { useClusters, useNamespaces, useNamespaceAnalysis } from '../../hooks/useMCP'
+import { useGlobalFilters } from '../../hooks/useGlobalFilters'
+import { Skeleton } from '../ui/Skeleton'
+import { ClusterBadge } from '../ui/ClusterBadge'
+
+interface NamespaceAnalysisProps {
+  config?: {
+    cluster?: string
+    namespace?: string
+  }
+}
+
+export function NamespaceAnalysis({ config }: NamespaceAnalysisProps) {
+  const { clusters: allClusters, isLoading: clustersLoading } = useClusters()
+  const [selectedCluster, setSelectedCluster] = useState<string>(config?.cluster || '')
+  const [selectedNamespace, setSelectedNamespace] = useState<string>(config?.namespace || '')
+  const {
+    selectedClusters: globalSelectedClusters,
+    isAllClustersSelected,
+  } = useGlobalFilters()
+
+  const clusters = useMemo(() => {
+    if (isAllClustersSelected) return allClusters
+    return allClusters.filter(c => globalSelectedClusters.includes(c.name))
+  }, [allClusters, globalSelectedClusters, isAllClustersSelected])
+
+  const { namespaces } = useNamespaces(selectedCluster || undefined)
+  const { analysis, isLoading, error, refetch } = useNamespaceAnalysis(
+    selectedCluster || undefined,
+    selectedNamespace || undefined
+  )
+
+  if (clustersLoading) {
+    return (
+      <div className="h-full flex flex-col min-h-card">
+        <div className="flex items-center justify-between mb-4">
+          <Skeleton variant="text" width={150} height={20} />
+          <Skeleton variant="rounded" width={200} height={32} />
+        </div>
+        <div className="grid grid-cols-2 gap-3">
+          <Skeleton variant="rounded" height={80} />
+          <Skeleton variant="rounded" height={80} />
+        </div>
+      </div>
+    )
+  }
+
+  const needsSelection = !selectedCluster || !selectedNamespace
+  const unhealthyWorkloads = analysis?.workloads.reduce((sum, w) => sum + (w.total - w.healthy), 0) ?? 0
+  const totalWorkloads = analysis?.workloads.reduce((sum, w) => sum + w.total, 0) ?? 0
+  const boundPVCs = analysis?.pvcs.filter(p => p.phase === 'Bound').length ?? 0
+  const highIssues = analysis?.securityIssues.filter(i => i.severity === 'high').length ?? 0
+  const netpol = analysis?.networkPolicies
+  const coverage = netpol && netpol.podsTotal > 0
+    ? Math.round((netpol.podsCovered / netpol.podsTotal) * 100)
+    : 100
+
+  return (
+    <div className="h-full flex flex-col min-h-card content-loaded">
+      {/* Header */}
+      <div className="flex items-center justify-between mb-4">
+        <div className="flex items-center gap-2">
+          <Layers className="w-4 h-4 text-purple-400" />
+          <span className="text-sm font-medium text-muted-foreground">Namespace Analysis</span>
+        </div>
+        <button
+          onClick={() => refetch()}
+          disabled={needsSelection}
+          className="p-1 hover:bg-secondary rounded transition-colors disabled:opacity-50"
+        >
+          <RefreshCw className={`w-4 h-4 text-muted-foreground ${isLoading ? 'animate-spin' : ''}`} />
+        </button>
+      </div>
+
+      {/* Selectors */}
+      <div className="flex gap-2 mb-4">
+        <select
+          value={selectedCluster}
+          onChange={(e) => {
+            setSelectedCluster(e.target.value)
+            setSelectedNamespace('')
+          }}
+          className="flex-1 px-3 py-1.5 rounded-lg bg-secondary border border-border text-sm text-foreground"
+        >
+          <option value="">Select cluster...</option>
+          {clusters.map(c => (
+            <option key={c.name} value={c.name}>{c.name}</option>
+          ))}
+        </select>
+        <select
+          value={selectedNamespace}
+          onChange={(e) => setSelectedNamespace(e.target.value)}
+          disabled={!selectedCluster}
+          className="flex-1 px-3 py-1.5 rounded-lg bg-secondary border border-border text-sm text-foreground disabled:opacity-50"
+        >
+          <option value="">Select namespace...</option>
+          {namespaces.map(ns => (
+            <option key={ns} value={ns}>{ns}</option>
+          ))}
+        </select>
+      </div>
+
+      {needsSelection ? (
+        <div className="flex-1 flex items-center justify-center text-muted-foreground text-sm">
+          Select a cluster and namespace to analyze
+        </div>
+      ) : error ? (
+        <div className="flex-1 flex items-center justify-center text-red-400 text-sm">{error}</div>
+      ) : !analysis ? (
+        <div className="grid grid-cols-2 gap-3">
+          <Skeleton variant="rounded" height={80} />
+          <Skeleton variant="rounded" height={80} />
+        </div>
+      ) : (
+        <div className="flex-1 overflow-y-auto space-y-3">
+          {/* Scope badge */}
+          <div className="flex items-center gap-2 p-2 rounded-lg bg-purple-500/10 border border-purple-500/20">
+            <ClusterBadge cluster={selectedCluster} />
+            <span className="text-purple-400">/</span>
+            <span className="text-sm font-medium text-purple-300">{analysis.namespace}</span>
+            <span className="text-xs text-muted-foreground ml-auto">{analysis.status}</span>
+          </div>
+
+          {/* Stats */}
+          <div className="grid grid-cols-2 gap-3">
+            <Stat
+              icon={<Box className="w-4 h-4 text-green-400" />}
+              label="Workloads healthy"
+              value={`${totalWorkloads - unhealthyWorkloads}/${totalWorkloads}`}
+              warn={unhealthyWorkloads > 0}
+            />
+            <Stat
+              icon={<Box className="w-4 h-4 text-blue-400" />}
+              label="Pods not ready"
+              value={`${analysis.pods.notReady}/${analysis.pods.total}`}
+              warn={analysis.pods.notReady > 0}
+            />
+            <Stat
+              icon={<Shield className="w-4 h-4 text-red-400" />}
+              label="Security issues"
+              value={`${analysis.securityIssues.length}${highIssues > 0 ? ` (${highIssues} high)` : ''}`}
+              warn={highIssues > 0}
+            />
+            <Stat
+              icon={<Network className="w-4 h-4 text-cyan-400" />}
+              label="Network policy coverage"
+              value={`${coverage}%`}
+              warn={coverage < 100 && !netpol?.defaultDenyIngress}
+            />
+          </div>
+
+          {/* Unhealthy workloads */}
+          {analysis.workloads.filter(w => w.unhealthy?.length).map(w => (
+            <div key={w.kind} className="p-2 rounded-lg bg-orange-500/10 border border-orange-500/20">
+              <div className="flex items-center gap-2 text-xs">
+                <AlertTriangle className="w-3 h-3 text-orange-400" />
+                <span className="text-foreground">{w.kind}</span>
+                <span className="text-muted-foreground truncate">{w.unhealthy!.join(', ')}</span>
+              </div>
+            </div>
+          ))}
+
+          {/* Quotas */}
+          {analysis.quotas.length > 0 && (
+            <Section icon={<Gauge className="w-3 h-3" />} title="Resource quotas">
+              {analysis.quotas.flatMap(q => q.resources.map(r => (
+                <div key={`${q.name}-${r.resource}`} className="text-xs">
+                  <div className="flex justify-between text-muted-foreground">
+                    <span>{r.resource}</span>
+                    <span>{r.used} / {r.hard}</span>
+                  </div>
+                  <div className="h-1 bg-secondary rounded-full overflow-hidden">
+                    <div
+                      className={`h-full ${r.ratio >= 0.9 ? 'bg-red-500' : r.ratio >= 0.7 ? 'bg-yellow-500' : 'bg-green-500'}`}
+                      style={{ width: `${Math.min(r.ratio * 100, 100)}%` }}
+                    />
+                  </div>
+                </div>
+              )))}
+            </Section>
+          )}
+
+          {/* PVCs */}
+          {analysis.pvcs.length > 0 && (
+            <Section icon={<HardDrive className="w-3 h-3" />} title={`Volumes (${boundPVCs}/${analysis.pvcs.length} bound)`}>
+              {analysis.pvcs.filter(p => p.phase !== 'Bound').map(p => (
+                <div key={p.name} className="flex justify-between text-xs">
+                  <span className="text-foreground truncate">{p.name}</span>
+                  <span className="text-yellow-400">{p.phase}</span>
+                </div>
+              ))}
+            </Section>
+          )}
+
+          {/* Access */}
+          <Section icon={<Users className="w-3 h-3" />} title={`Access (${analysis.access.length} subjects)`}>
+            {analysis.access.slice(0, 5).map(s => (
+              <div key={`${s.binding}-${s.kind}-${s.name}`} className="flex justify-between text-xs">
+                <span className="text-foreground truncate">{s.kind}/{s.name}</span>
+                <span className={s.clusterWide ? 'text-orange-400' : 'text-muted-foreground'}>
+                  {s.role}{s.clusterWide ? ' (cluster-wide)' : ''}
+                </span>
+              </div>
+            ))}
+          </Section>
+
+          {/* Warning events */}
+          {analysis.warningEvents.length > 0 && (
+            <Section icon={<AlertTriangle className="w-3 h-3" />} title="Recent warnings">
+              {analysis.warningEvents.slice(0, 3).map((e, idx) => (
+                <div key={idx} className="text-xs">
+                  <span className="text-yellow-400">{e.reason}</span>{' '}
+                  <span className="text-muted-foreground">{e.object}: {e.message}</span>
+                </div>
+              ))}
+            </Section>
+          )}
+
+          {analysis.errors && (
+            <div className="text-xs text-muted-foreground">
+              Not readable: {Object.keys(analysis.errors).join(', ')}
+            </div>
+          )}
+        </div>
+      )}
+    </div>
+  )
+}
+
+function Stat({ icon, label, value, warn }: { icon: React.ReactNode; label: string; value: string; warn?: boolean }) {
+  return (
+    <div className="p-3 rounded-lg bg-secondary/30">
+      <div className="flex items-center gap-2 mb-1">
+        {icon}
+        <span className="text-xs text-muted-foreground">{label}</span>
+      </div>
+      <span className={`text-lg font-bold ${warn ? 'text-orange-400' : 'text-foreground'}`}>{value}</span>
+    </div>
+  )
+}
+
+function Section({ icon, title, children }: { icon: React.ReactNode; title: string; children: React.ReactNode }) {
+  return (
+    <div className="space-y-1">
+      <div className="flex items-center gap-1 text-xs font-medium text-muted-foreground">
+        {icon}
+        <span>{title}</span>
+      </div>
+      {children}
+    </div>
+  )
+}
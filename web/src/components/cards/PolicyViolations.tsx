@@ -0,0 +1,185 @@
+import { useState, useMemo } from 'react'
+import { FileWarning, RefreshCw, ShieldCheck, AlertTriangle } from 'lucide-react'
+import { usePolicyViolations, PolicyViolation } from '../../hooks/useMCP'
+import { useGlobalFilters } from '../../hooks/useGlobalFilters'
+import { PaginatedList } from '../ui/PaginatedList'
+import { ClusterBadge } from '../ui/ClusterBadge'
+import { CardControls } from '../ui/CardControls'
+
+type SortByOption = 'action' | 'policy' | 'cluster'
+
+const SORT_OPTIONS = [
+  { value: 'action' as const, label: 'Action' },
+  { value: 'policy' as const, label: 'Policy' },
+  { value: 'cluster' as const, label: 'Cluster' },
+]
+
+interface PolicyViolationsProps {
+  config?: Record<string, unknown>
+}
+
+// Blocking actions first, then warnings, then audit-only results
+const actionOrder: Record<string, number> = { deny: 0, enforce: 0, warn: 1, dryrun: 2, audit: 2 }
+
+const getActionColor = (action: string) => {
+  switch (actionOrder[action]) {
+    case 0:
+      return { bg: 'bg-red-500/10', border: 'border-red-500/20', text: 'text-red-400', badge: 'bg-red-500/20' }
+    case 1:
+      return { bg: 'bg-orange-500/10', border: 'border-orange-500/20', text: 'text-orange-400', badge: 'bg-orange-500/20' }
+    default:
+      return { bg: 'bg-yellow-500/10', border: 'border-yellow-500/20', text: 'text-yellow-400', badge: 'bg-yellow-500/20' }
+  }
+}
+
+export function PolicyViolations({ config }: PolicyViolationsProps) {
+  const cluster = config?.cluster as string | undefined
+  const namespace = config?.namespace as string | undefined
+  const { violations: rawViolations, policies, engines, isLoading, error, refetch } = usePolicyViolations(cluster, namespace)
+  const { filterItems } = useGlobalFilters()
+  const [sortBy, setSortBy] = useState<SortByOption>('action')
+  const [limit, setLimit] = useState<number | 'unlimited'>(5)
+
+  const violations = useMemo(() => {
+    const filtered = filterItems(rawViolations)
+    const sorted = [...filtered].sort((a, b) => {
+      if (sortBy === 'action') return (actionOrder[a.enforcementAction] ?? 3) - (actionOrder[b.enforcementAction] ?? 3)
+      if (sortBy === 'policy') return a.policy.localeCompare(b.policy)
+      if (sortBy === 'cluster') return a.cluster.localeCompare(b.cluster)
+      return 0
+    })
+    if (limit === 'unlimited') return sorted
+    return sorted.slice(0, limit)
+  }, [rawViolations, sortBy, limit, filterItems])
+
+  const hasEngine = Object.values(engines).some(e => e.length > 0)
+  const totalViolations = policies.reduce((sum, p) => sum + p.violations, 0)
+
+  if (isLoading) {
+    return (
+      <div className="h-full flex items-center justify-center">
+        <div className="spinner w-8 h-8" />
+      </div>
+    )
+  }
+
+  if (violations.length === 0) {
+    return (
+      <div className="h-full flex flex-col">
+        <div className="flex items-center justify-between mb-3">
+          <span className="text-sm font-medium text-muted-foreground">Policy Violations</span>
+          <button
+            onClick={() => refetch()}
+            className="p-1 hover:bg-secondary rounded transition-colors"
+            title="Refresh policy violations"
+          >
+            <RefreshCw className="w-4 h-4 text-muted-foreground" />
+          </button>
+        </div>
+        <div className="flex-1 flex flex-col items-center justify-center text-center">
+          <div className="w-12 h-12 rounded-full bg-green-500/10 flex items-center justify-center mb-3">
+            <ShieldCheck className="w-6 h-6 text-green-400" />
+          </div>
+          <p className="text-foreground font-medium">No policy violations</p>
+          <p className="text-sm text-muted-foreground">
+            {hasEngine ? 'All resources pass Gatekeeper and Kyverno policies' : 'No Gatekeeper or Kyverno found'}
+          </p>
+        </div>
+      </div>
+    )
+  }
+
+  return (
+    <div className="h-full flex flex-col">
+      {/* Header */}
+      <div className="flex items-center justify-between mb-3">
+        <div className="flex items-center gap-2">
+          <span className="text-sm font-medium text-muted-foreground">Policy Violations</span>
+          <span className="text-xs px-1.5 py-0.5 rounded bg-red-500/20 text-red-400" title={`${totalViolations} violations of ${policies.length} policies`}>
+            {totalViolations}
+          </span>
+        </div>
+        <div className="flex items-center gap-2">
+          <CardControls
+            limit={limit}
+            onLimitChange={setLimit}
+            sortBy={sortBy}
+            sortOptions={SORT_OPTIONS}
+            onSortChange={setSortBy}
+          />
+          <button
+            onClick={() => refetch()}
+            className="p-1 hover:bg-secondary rounded transition-colors"
+            title="Refresh policy violations"
+          >
+            <RefreshCw className="w-4 h-4 text-muted-foreground" />
+          </button>
+        </div>
+      </div>
+
+      {/* Most violated policies */}
+      <div className="flex flex-wrap gap-1 mb-2">
+        {policies.slice(0, 3).map(p => (
+          <span
+            key={`${p.engine}-${p.policy}-${p.enforcementAction}`}
+            className="text-xs px-2 py-0.5 rounded bg-secondary text-muted-foreground truncate max-w-full"
+            title={`${p.policy} (${p.engine}, ${p.enforcementAction}) in ${p.clusters.join(', ')}`}
+          >
+            {p.policy}: {p.violations}
+          </span>
+        ))}
+      </div>
+
+      {/* Violations list with pagination */}
+      <div className="flex-1 overflow-y-auto">
+        <PaginatedList
+          items={violations}
+          pageSize={limit === 'unlimited' ? 1000 : limit}
+          pageSizeOptions={[]}
+          emptyMessage="No policy violations"
+          renderItem={(v: PolicyViolation, idx: number) => {
+            const colors = getActionColor(v.enforcementAction)
+            return (
+              <div
+                key={`${v.cluster}-${v.policy}-${v.resource}-${idx}`}
+                className={`p-3 rounded-lg ${colors.bg} border ${colors.border}`}
+                title={v.message}
+              >
+                <div className="flex items-start gap-3">
+                  <div className={`p-2 rounded-lg ${colors.badge} flex-shrink-0`}>
+                    <FileWarning className={`w-4 h-4 ${colors.text}`} />
+                  </div>
+                  <div className="flex-1 min-w-0">
+                    <div className="flex items-center gap-2 mb-1">
+                      <ClusterBadge cluster={v.cluster} />
+                      {v.namespace && <span className="text-xs text-muted-foreground">{v.namespace}</span>}
+                    </div>
+                    <p className="text-sm font-medium text-foreground truncate" title={v.resource}>{v.resource}</p>
+                    <div className="flex items-center gap-2 mt-2 flex-wrap">
+                      <span className={`text-xs px-2 py-0.5 rounded ${colors.badge} ${colors.text}`} title={`${v.engine} policy`}>
+                        {v.rule ? `${v.policy}/${v.rule}` : v.policy}
+                      </span>
+                      <span className={`text-xs px-2 py-0.5 rounded ${colors.badge} ${colors.text}`} title="Enforcement action">
+                        {v.enforcementAction}
+                      </span>
+                    </div>
+                    <p className="text-xs text-muted-foreground mt-1 truncate" title={v.message}>
+                      {v.message}
+                    </p>
+                  </div>
+                </div>
+              </div>
+            )
+          }}
+        />
+      </div>
+
+      {error && (
+        <div className="mt-2 text-xs text-yellow-400 flex items-center gap-1">
+          <AlertTriangle className="w-3 h-3" />
+          {error}
+        </div>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,183 @@
+import { useState, useMemo } from 'react'
+import { Key, RefreshCw, AlertTriangle, Unlink, ChevronDown, ChevronRight } from 'lucide-react'
+import { useClusters, useRBACAnalysis, type RBACRiskSeverity, type SubjectPermissions } from '../../hooks/useMCP'
+import { useGlobalFilters } from '../../hooks/useGlobalFilters'
+import { Skeleton } from '../ui/Skeleton'
+
+interface RBACOverviewProps {
+  config?: {
+    cluster?: string
+  }
+}
+
+const severityColors: Record<RBACRiskSeverity, string> = {
+  critical: 'text-red-400 bg-red-500/20',
+  high: 'text-orange-400 bg-orange-500/20',
+  medium: 'text-yellow-400 bg-yellow-500/20',
+  low: 'text-green-400 bg-green-500/20',
+}
+
+const subjectKey = (s: SubjectPermissions) => `${s.kind}/${s.namespace || ''}/${s.name}`
+
+export function RBACOverview({ config }: RBACOverviewProps) {
+  const { clusters: allClusters, isLoading: clustersLoading } = useClusters()
+  const [selectedCluster, setSelectedCluster] = useState<string>(config?.cluster || '')
+  const [includeSystem, setIncludeSystem] = useState(false)
+  const [expanded, setExpanded] = useState<string | null>(null)
+  const {
+    selectedClusters: globalSelectedClusters,
+    isAllClustersSelected,
+  } = useGlobalFilters()
+
+  const clusters = useMemo(() => {
+    if (isAllClustersSelected) return allClusters
+    return allClusters.filter(c => globalSelectedClusters.includes(c.name))
+  }, [allClusters, globalSelectedClusters, isAllClustersSelected])
+
+  const { analysis, isLoading, error, refetch } = useRBACAnalysis(selectedCluster || undefined, includeSystem)
+
+  if (clustersLoading) {
+    return (
+      <div className="h-full flex flex-col min-h-card">
+        <div className="flex items-center justify-between mb-4">
+          <Skeleton variant="text" width={150} height={20} />
+          <Skeleton variant="rounded" width={200} height={32} />
+        </div>
+        <Skeleton variant="rounded" height={80} />
+      </div>
+    )
+  }
+
+  return (
+    <div className="h-full flex flex-col min-h-card content-loaded">
+      {/* Header */}
+      <div className="flex items-center justify-between mb-4">
+        <div className="flex items-center gap-2">
+          <Key className="w-4 h-4 text-amber-400" />
+          <span className="text-sm font-medium text-muted-foreground">RBAC Overview</span>
+        </div>
+        <button
+          onClick={() => refetch()}
+          disabled={!selectedCluster}
+          className="p-1 hover:bg-secondary rounded transition-colors disabled:opacity-50"
+        >
+          <RefreshCw className={`w-4 h-4 text-muted-foreground ${isLoading ? 'animate-spin' : ''}`} />
+        </button>
+      </div>
+
+      {/* Selectors */}
+      <div className="flex items-center gap-2 mb-4">
+        <select
+          value={selectedCluster}
+          onChange={(e) => setSelectedCluster(e.target.value)}
+          className="flex-1 px-3 py-1.5 rounded-lg bg-secondary border border-border text-sm text-foreground"
+        >
+          <option value="">Select cluster...</option>
+          {clusters.map(c => (
+            <option key={c.name} value={c.name}>{c.name}</option>
+          ))}
+        </select>
+        <label className="flex items-center gap-1 text-xs text-muted-foreground">
+          <input
+            type="checkbox"
+            checked={includeSystem}
+            onChange={(e) => setIncludeSystem(e.target.checked)}
+          />
+          System
+        </label>
+      </div>
+
+      {!selectedCluster ? (
+        <div className="flex-1 flex items-center justify-center text-muted-foreground text-sm">
+          Select a cluster to analyze
+        </div>
+      ) : error ? (
+        <div className="flex-1 flex items-center justify-center text-red-400 text-sm">{error}</div>
+      ) : !analysis ? (
+        <div className="grid grid-cols-2 gap-3">
+          <Skeleton variant="rounded" height={80} />
+          <Skeleton variant="rounded" height={80} />
+        </div>
+      ) : (
+        <div className="flex-1 overflow-y-auto space-y-3">
+          {/* Stats */}
+          <div className="grid grid-cols-4 gap-2">
+            <Stat label="Subjects" value={analysis.summary.subjects} />
+            <Stat label="Critical" value={analysis.summary.critical} warn="text-red-400" />
+            <Stat label="High" value={analysis.summary.high} warn="text-orange-400" />
+            <Stat label="Orphaned" value={analysis.summary.orphaned} warn="text-yellow-400" />
+          </div>
+
+          {/* Subjects, riskiest first */}
+          <div className="space-y-1">
+            {analysis.subjects.map(s => {
+              const key = subjectKey(s)
+              const isExpanded = expanded === key
+              const reasons = Array.from(new Set(s.risks.map(r => r.reason)))
+              return (
+                <div key={key} className="p-2 rounded-lg bg-secondary/30">
+                  <button
+                    onClick={() => setExpanded(isExpanded ? null : key)}
+                    className="w-full flex items-center gap-2 text-xs text-left"
+                  >
+                    {isExpanded ? <ChevronDown className="w-3 h-3" /> : <ChevronRight className="w-3 h-3" />}
+                    <span className="text-muted-foreground">{s.kind}</span>
+                    <span className="text-foreground truncate">
+                      {s.namespace ? `${s.namespace}/` : ''}{s.name}
+                    </span>
+                    <span className={`ml-auto px-1.5 py-0.5 rounded ${severityColors[s.riskLevel]}`}>
+                      {s.riskScore}
+                    </span>
+                  </button>
+                  {isExpanded && (
+                    <div className="mt-2 ml-5 space-y-1 text-xs">
+                      {reasons.map(reason => (
+                        <div key={reason} className="flex items-center gap-1 text-orange-400">
+                          <AlertTriangle className="w-3 h-3 shrink-0" />
+                          <span>{reason}</span>
+                        </div>
+                      ))}
+                      {s.grants.map(g => (
+                        <div key={`${g.bindingKind}/${g.namespace || ''}/${g.binding}`} className="text-muted-foreground">
+                          {g.roleKind} <span className="text-foreground">{g.roleName}</span> via {g.bindingKind} {g.binding}
+                          {g.namespace ? ` in ${g.namespace}` : ' (cluster-wide)'}
+                        </div>
+                      ))}
+                    </div>
+                  )}
+                </div>
+              )
+            })}
+          </div>
+
+          {/* Orphaned bindings */}
+          {analysis.orphaned.length > 0 && (
+            <div className="space-y-1">
+              <div className="flex items-center gap-1 text-xs font-medium text-muted-foreground">
+                <Unlink className="w-3 h-3" />
+                <span>Orphaned bindings</span>
+              </div>
+              {analysis.orphaned.map(o => (
+                <div key={`${o.bindingKind}/${o.namespace || ''}/${o.binding}/${o.missing}`} className="flex justify-between text-xs">
+                  <span className="text-foreground truncate">
+                    {o.namespace ? `${o.namespace}/` : ''}{o.binding}
+                  </span>
+                  <span className="text-yellow-400">missing {o.missing}</span>
+                </div>
+              ))}
+            </div>
+          )}
+        </div>
+      )}
+    </div>
+  )
+}
+
+function Stat({ label, value, warn }: { label: string; value: number; warn?: string }) {
+  return (
+    <div className="p-2 rounded-lg bg-secondary/30 text-center">
+      <div className={`text-lg font-bold ${warn && value > 0 ? warn : 'text-foreground'}`}>{value}</div>
+      <div className="text-xs text-muted-foreground">{label}</div>
+    </div>
+  )
+}
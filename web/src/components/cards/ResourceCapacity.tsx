@@ -1,6 +1,6 @@
 import { useMemo } from 'react'
-import { RefreshCw, Cpu, HardDrive, Zap } from 'lucide-react'
-import { useClusters } from '../../hooks/useMCP'
+import { RefreshCw, Cpu, HardDrive, Zap, Box } from 'lucide-react'
+import { useClusters, useClusterCapacity, type ResourceAmounts } from '../../hooks/useMCP'
 import { useGlobalFilters } from '../../hooks/useGlobalFilters'
 import { useDrillDownActions } from '../../hooks/useDrillDown'
 
@@ -10,6 +10,7 @@ interface ResourceCapacityProps {
 
 export function ResourceCapacity({ config: _config }: ResourceCapacityProps) {
   const { clusters: allClusters, isLoading, refetch } = useClusters()
+  const { clusters: capacities, refetch: refetchCapacity } = useClusterCapacity()
   const { drillToResources } = useDrillDownActions()
   const {
     selectedClusters: globalSelectedClusters,
@@ -36,6 +37,23 @@ export function ResourceCapacity({ config: _config }: ResourceCapacityProps) {
     return result
   }, [allClusters, globalSelectedClusters, isAllClustersSelected, customFilter])
 
+  // Requested vs allocatable across the filtered clusters
+  const capacityTotals = useMemo(() => {
+    const names = new Set(clusters.map(c => c.name))
+    const sums: Record<string, ResourceAmounts> = {}
+    for (const capacity of capacities) {
+      if (!names.has(capacity.cluster)) continue
+      for (const [name, amounts] of Object.entries(capacity.totals)) {
+        const sum = sums[name] || { allocatable: 0, requested: 0, limits: 0 }
+        sum.allocatable = (sum.allocatable || 0) + (amounts.allocatable || 0)
+        sum.requested += amounts.requested
+        sum.limits += amounts.limits
+        sums[name] = sum
+      }
+    }
+    return sums
+  }, [capacities, clusters])
+
   if (isLoading) {
     return (
       <div className="h-full flex items-center justify-center">
@@ -53,8 +71,8 @@ export function ResourceCapacity({ config: _config }: ResourceCapacityProps) {
     { nodes: 0, pods: 0 }
   )
 
-  // Mock resource data - in real implementation this would come from metrics
-  const resourceData = {
+  // Estimate from node counts until capacity has been reported
+  const estimate = {
     cpu: {
       used: Math.round(totals.nodes * 2.4),
       total: totals.nodes * 4,
@@ -72,13 +90,35 @@ export function ResourceCapacity({ config: _config }: ResourceCapacityProps) {
     },
   }
 
+  const GiB = 1024 ** 3
+  const hasCapacity = Object.keys(capacityTotals).length > 0
+  const amount = (name: string, scale: number) => ({
+    used: Math.round(((capacityTotals[name]?.requested || 0) / scale) * 10) / 10,
+    total: Math.round(((capacityTotals[name]?.allocatable || 0) / scale) * 10) / 10,
+  })
+  const resourceData = hasCapacity
+    ? {
+        cpu: { ...amount('cpu', 1000), unit: 'cores' },
+        memory: { ...amount('memory', GiB), unit: 'GB' },
+        pods: { ...amount('pods', 1), unit: 'pods' },
+      }
+    : estimate
+
+  // GPUs of any vendor (nvidia.com/gpu, amd.com/gpu, gpu.intel.com/*)
+  const gpus = Object.entries(capacityTotals)
+    .filter(([name]) => name.startsWith('nvidia.com/gpu') || name.startsWith('amd.com/gpu') || name.startsWith('gpu.intel.com/'))
+    .reduce((acc, [, a]) => ({ used: acc.used + a.requested, total: acc.total + (a.allocatable || 0) }), { used: 0, total: 0 })
+
   return (
     <div className="h-full flex flex-col">
       {/* Header */}
       <div className="flex items-center justify-between mb-4">
         <span className="text-sm font-medium text-muted-foreground">Resource Capacity</span>
         <button
-          onClick={() => refetch()}
+          onClick={() => {
+            refetch()
+            refetchCapacity()
+          }}
           className="p-1 hover:bg-secondary rounded transition-colors"
         >
           <RefreshCw className="w-4 h-4 text-muted-foreground" />
@@ -111,6 +151,16 @@ export function ResourceCapacity({ config: _config }: ResourceCapacityProps) {
           unit={resourceData.pods.unit}
           color="green"
         />
+        {gpus.total > 0 && (
+          <ResourceBar
+            icon={<Box className="w-4 h-4" />}
+            label="GPU"
+            used={gpus.used}
+            total={gpus.total}
+            unit="GPUs"
+            color="yellow"
+          />
+        )}
       </div>
 
       {/* Summary */}
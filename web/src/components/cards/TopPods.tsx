@@ -1,10 +1,10 @@
 import { useState } from 'react'
 import { RefreshCw, Loader2, AlertTriangle } from 'lucide-react'
-import { usePods } from '../../hooks/useMCP'
+import { useTopPods, type PodUsage, type TopPodsBy } from '../../hooks/useMCP'
 import { ClusterBadge } from '../ui/ClusterBadge'
 import { CardControls } from '../ui/CardControls'
 
-type SortByOption = 'restarts' | 'name'
+type SortByOption = TopPodsBy
 
 interface TopPodsProps {
   config?: {
@@ -16,18 +16,45 @@ interface TopPodsProps {
 }
 
 const SORT_OPTIONS = [
+  { value: 'cpu' as const, label: 'CPU' },
+  { value: 'memory' as const, label: 'Memory' },
   { value: 'restarts' as const, label: 'Restarts' },
-  { value: 'name' as const, label: 'Name' },
 ]
 
+// Saved configs may still sort by name, which is no longer offered
+function initialSort(sortBy?: string): SortByOption {
+  return SORT_OPTIONS.some(o => o.value === sortBy) ? (sortBy as SortByOption) : 'cpu'
+}
+
+function formatCPU(millicores: number) {
+  return millicores >= 1000 ? `${(millicores / 1000).toFixed(2)} cores` : `${millicores}m`
+}
+
+function formatMemory(bytes: number) {
+  const MiB = 1024 * 1024
+  return bytes >= 1024 * MiB ? `${(bytes / (1024 * MiB)).toFixed(1)}Gi` : `${Math.round(bytes / MiB)}Mi`
+}
+
+// The value pods are ranked by
+function rankValue(pod: PodUsage, sortBy: SortByOption) {
+  switch (sortBy) {
+    case 'cpu':
+      return pod.cpu.usage
+    case 'memory':
+      return pod.memory.usage
+    default:
+      return pod.restarts
+  }
+}
+
 export function TopPods({ config }: TopPodsProps) {
   const cluster = config?.cluster
   const namespace = config?.namespace
-  const [sortBy, setSortBy] = useState<SortByOption>(config?.sortBy || 'restarts')
+  const [sortBy, setSortBy] = useState<SortByOption>(initialSort(config?.sortBy))
   const [limit, setLimit] = useState<number | 'unlimited'>(config?.limit || 5)
 
   const effectiveLimit = limit === 'unlimited' ? 1000 : limit
-  const { pods, isLoading, error, refetch } = usePods(cluster, namespace, sortBy, effectiveLimit)
+  const { pods, metricsUnavailable, isLoading, error, refetch } = useTopPods(cluster, namespace, sortBy, effectiveLimit)
 
   if (isLoading && pods.length === 0) {
     return (
@@ -45,8 +72,8 @@ export function TopPods({ config }: TopPodsProps) {
     )
   }
 
-  // Find the max restarts for visual scaling
-  const maxRestarts = Math.max(...pods.map(p => p.restarts), 1)
+  // Find the max of the ranked value for visual scaling
+  const maxValue = Math.max(...pods.map(p => rankValue(p, sortBy)), 1)
 
   return (
     <div className="h-full flex flex-col">
@@ -71,6 +98,12 @@ export function TopPods({ config }: TopPodsProps) {
         </div>
       </div>
 
+      {metricsUnavailable.length > 0 && sortBy !== 'restarts' && (
+        <div className="mb-2 text-xs text-yellow-400" title={metricsUnavailable.join(', ')}>
+          No usage metrics from {metricsUnavailable.length} cluster(s); is metrics-server installed?
+        </div>
+      )}
+
       {/* Pods list */}
       {pods.length === 0 ? (
         <div className="flex-1 flex items-center justify-center text-muted-foreground text-sm">
@@ -111,7 +144,15 @@ export function TopPods({ config }: TopPodsProps) {
                 )}
               </div>
 
-              {/* Progress bar for restarts visualization */}
+              {/* Progress bar for the ranked value */}
+              {sortBy !== 'restarts' && rankValue(pod, sortBy) > 0 && (
+                <div className="h-1 bg-secondary rounded-full overflow-hidden mt-1">
+                  <div
+                    className={`h-full transition-all duration-300 ${sortBy === 'cpu' ? 'bg-blue-500' : 'bg-purple-500'}`}
+                    style={{ width: `${(rankValue(pod, sortBy) / maxValue) * 100}%` }}
+                  />
+                </div>
+              )}
               {sortBy === 'restarts' && pod.restarts > 0 && (
                 <div className="h-1 bg-secondary rounded-full overflow-hidden mt-1">
                   <div
@@ -120,7 +161,7 @@ export function TopPods({ config }: TopPodsProps) {
                       pod.restarts >= 5 ? 'bg-orange-500' :
                       'bg-yellow-500'
                     }`}
-                    style={{ width: `${(pod.restarts / maxRestarts) * 100}%` }}
+                    style={{ width: `${(pod.restarts / maxValue) * 100}%` }}
                   />
                 </div>
               )}
@@ -134,8 +175,12 @@ export function TopPods({ config }: TopPodsProps) {
               {/* Details row */}
               <div className="flex items-center gap-3 text-xs text-muted-foreground">
                 <span className="flex-shrink-0">{pod.status}</span>
-                <span className="flex-shrink-0">{pod.ready}</span>
-                <span className="flex-shrink-0">{pod.age}</span>
+                <span className="flex-shrink-0" title={usageTitle(pod.cpu.requestRatio, pod.cpu.limitRatio)}>
+                  CPU {formatCPU(pod.cpu.usage)}
+                </span>
+                <span className="flex-shrink-0" title={usageTitle(pod.memory.requestRatio, pod.memory.limitRatio)}>
+                  Mem {formatMemory(pod.memory.usage)}
+                </span>
               </div>
             </div>
           ))}
@@ -144,3 +189,10 @@ export function TopPods({ config }: TopPodsProps) {
     </div>
   )
 }
+
+function usageTitle(requestRatio?: number, limitRatio?: number) {
+  const parts = []
+  if (requestRatio !== undefined) parts.push(`${Math.round(requestRatio * 100)}% of request`)
+  if (limitRatio !== undefined) parts.push(`${Math.round(limitRatio * 100)}% of limit`)
+  return parts.join(', ') || 'No request or limit set'
+}
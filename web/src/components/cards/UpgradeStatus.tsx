@@ -1,6 +1,6 @@
 import { useMemo } from 'react'
 import { RefreshCw, ArrowUp, CheckCircle, AlertTriangle, Clock } from 'lucide-react'
-import { useClusters } from '../../hooks/useMCP'
+import { useUpgradeStatus, UpgradePhase } from '../../hooks/useMCP'
 import { useGlobalFilters } from '../../hooks/useGlobalFilters'
 import { useDrillDownActions } from '../../hooks/useDrillDown'
 
@@ -9,7 +9,7 @@ interface UpgradeStatusProps {
 }
 
 export function UpgradeStatus({ config: _config }: UpgradeStatusProps) {
-  const { clusters: allClusters, isLoading, refetch } = useClusters()
+  const { clusters: allClusters, isLoading, error, refetch } = useUpgradeStatus()
   const { drillToCluster } = useDrillDownActions()
   const {
     selectedClusters: globalSelectedClusters,
@@ -22,21 +22,21 @@ export function UpgradeStatus({ config: _config }: UpgradeStatusProps) {
     let result = allClusters
 
     if (!isAllClustersSelected) {
-      result = result.filter(c => globalSelectedClusters.includes(c.name))
+      result = result.filter(c => globalSelectedClusters.includes(c.cluster))
     }
 
     if (customFilter.trim()) {
       const query = customFilter.toLowerCase()
       result = result.filter(c =>
-        c.name.toLowerCase().includes(query) ||
-        c.context?.toLowerCase().includes(query)
+        c.cluster.toLowerCase().includes(query) ||
+        c.controlPlaneVersion.toLowerCase().includes(query)
       )
     }
 
     return result
   }, [allClusters, globalSelectedClusters, isAllClustersSelected, customFilter])
 
-  if (isLoading) {
+  if (isLoading && allClusters.length === 0) {
     return (
       <div className="h-full flex items-center justify-center">
         <div className="spinner w-8 h-8" />
@@ -44,17 +44,9 @@ export function UpgradeStatus({ config: _config }: UpgradeStatusProps) {
     )
   }
 
-  // Mock version data for clusters (in real implementation, this would come from cluster API)
-  const clusterVersions = clusters.map((c) => ({
-    name: c.name,
-    currentVersion: getClusterVersion(c.name),
-    targetVersion: getTargetVersion(c.name),
-    status: getUpgradeStatus(c.name),
-    progress: getUpgradeProgress(c.name),
-  }))
-
-  const upgradesInProgress = clusterVersions.filter((c) => c.status === 'upgrading').length
-  const pendingUpgrades = clusterVersions.filter((c) => c.status === 'available').length
+  const upgradesInProgress = clusters.filter((c) => c.phase === 'in-progress').length
+  const pendingUpgrades = clusters.filter((c) => c.phase === 'pending').length
+  const outOfSkew = clusters.reduce((sum, c) => sum + c.nodesOutOfSkew, 0)
 
   return (
     <div className="h-full flex flex-col">
@@ -69,7 +61,15 @@ export function UpgradeStatus({ config: _config }: UpgradeStatusProps) {
           )}
           {pendingUpgrades > 0 && (
             <span className="text-xs px-1.5 py-0.5 rounded bg-yellow-500/20 text-yellow-400">
-              {pendingUpgrades} available
+              {pendingUpgrades} pending
+            </span>
+          )}
+          {outOfSkew > 0 && (
+            <span
+              className="text-xs px-1.5 py-0.5 rounded bg-red-500/20 text-red-400"
+              title="Nodes outside the supported kubelet version skew"
+            >
+              {outOfSkew} out of skew
             </span>
           )}
         </div>
@@ -77,89 +77,88 @@ export function UpgradeStatus({ config: _config }: UpgradeStatusProps) {
           onClick={() => refetch()}
           className="p-1 hover:bg-secondary rounded transition-colors"
         >
-          <RefreshCw className="w-4 h-4 text-muted-foreground" />
+          <RefreshCw className={`w-4 h-4 text-muted-foreground ${isLoading ? 'animate-spin' : ''}`} />
         </button>
       </div>
 
+      {error && (
+        <div className="mb-2 text-xs text-red-400">{error}</div>
+      )}
+
       {/* Clusters list */}
       <div className="flex-1 space-y-2 overflow-y-auto">
-        {clusterVersions.map((cluster) => (
-          <div
-            key={cluster.name}
-            className="p-3 rounded-lg bg-secondary/30 cursor-pointer hover:bg-secondary/50 transition-colors"
-            onClick={() => drillToCluster(cluster.name, { tab: 'upgrade', version: cluster.currentVersion, targetVersion: cluster.targetVersion })}
-          >
-            <div className="flex items-center justify-between mb-2">
-              <span className="text-sm font-medium text-foreground truncate">{cluster.name}</span>
-              {getStatusIcon(cluster.status)}
-            </div>
-            <div className="flex items-center gap-2 text-xs text-muted-foreground">
-              <span className="font-mono">{cluster.currentVersion}</span>
-              {cluster.targetVersion && cluster.targetVersion !== cluster.currentVersion && (
-                <>
-                  <ArrowUp className="w-3 h-3" />
-                  <span className="font-mono text-green-400">{cluster.targetVersion}</span>
-                </>
+        {clusters.map((cluster) => {
+          const nodes = cluster.nodes.length
+          const progress = nodes > 0 ? Math.round((cluster.nodesUpgraded / nodes) * 100) : 100
+          const kubeletVersions = Object.keys(cluster.kubeletVersions).filter(
+            (v) => v !== cluster.controlPlaneVersion
+          )
+          return (
+            <div
+              key={cluster.cluster}
+              className="p-3 rounded-lg bg-secondary/30 cursor-pointer hover:bg-secondary/50 transition-colors"
+              onClick={() => drillToCluster(cluster.cluster, {
+                tab: 'upgrade',
+                version: cluster.controlPlaneVersion,
+                phase: cluster.phase,
+              })}
+            >
+              <div className="flex items-center justify-between mb-2">
+                <span className="text-sm font-medium text-foreground truncate">{cluster.cluster}</span>
+                {getStatusIcon(cluster.phase, cluster.nodesOutOfSkew > 0)}
+              </div>
+              <div className="flex items-center gap-2 text-xs text-muted-foreground">
+                {kubeletVersions.length > 0 && (
+                  <>
+                    <span className="font-mono">{kubeletVersions.join(', ')}</span>
+                    <ArrowUp className="w-3 h-3" />
+                  </>
+                )}
+                <span className={`font-mono ${kubeletVersions.length > 0 ? 'text-green-400' : ''}`}>
+                  {cluster.controlPlaneVersion}
+                </span>
+                {cluster.cordonedNodes > 0 && (
+                  <span className="ml-auto">{cluster.cordonedNodes} cordoned</span>
+                )}
+              </div>
+              {cluster.nodesOutOfSkew > 0 && (
+                <div className="mt-1 text-xs text-red-400">
+                  {cluster.nodesOutOfSkew} of {nodes} nodes outside the supported version skew
+                </div>
               )}
-            </div>
-            {cluster.status === 'upgrading' && (
-              <div className="mt-2">
-                <div className="h-1.5 bg-secondary rounded-full overflow-hidden">
-                  <div
-                    className="h-full bg-blue-500 transition-all duration-300"
-                    style={{ width: `${cluster.progress}%` }}
-                  />
+              {cluster.phase !== 'current' && (
+                <div className="mt-2">
+                  <div className="h-1.5 bg-secondary rounded-full overflow-hidden">
+                    <div
+                      className="h-full bg-blue-500 transition-all duration-300"
+                      style={{ width: `${progress}%` }}
+                    />
+                  </div>
+                  <span className="text-xs text-muted-foreground">
+                    {cluster.nodesUpgraded}/{nodes} nodes upgraded
+                  </span>
                 </div>
-                <span className="text-xs text-muted-foreground">{cluster.progress}% complete</span>
-              </div>
-            )}
-          </div>
-        ))}
+              )}
+            </div>
+          )
+        })}
       </div>
     </div>
   )
 }
 
-function getStatusIcon(status: string) {
-  switch (status) {
+function getStatusIcon(phase: UpgradePhase, outOfSkew: boolean) {
+  if (outOfSkew) {
+    return <AlertTriangle className="w-4 h-4 text-red-400" />
+  }
+  switch (phase) {
     case 'current':
       return <CheckCircle className="w-4 h-4 text-green-400" />
-    case 'upgrading':
+    case 'in-progress':
       return <Clock className="w-4 h-4 text-blue-400 animate-pulse" />
-    case 'available':
+    case 'pending':
       return <ArrowUp className="w-4 h-4 text-yellow-400" />
-    case 'failed':
-      return <AlertTriangle className="w-4 h-4 text-red-400" />
     default:
       return null
   }
 }
-
-// Mock functions - would be replaced with real API calls
-function getClusterVersion(name: string): string {
-  const versions: Record<string, string> = {
-    'ops': 'v1.29.2',
-    'prow': 'v1.28.5',
-    'kubestellar-docs': 'v1.29.1',
-    'oci': 'v1.28.3',
-  }
-  return versions[name] || 'v1.28.0'
-}
-
-function getTargetVersion(name: string): string {
-  const targets: Record<string, string> = {
-    'prow': 'v1.29.2',
-    'oci': 'v1.29.2',
-  }
-  return targets[name] || getClusterVersion(name)
-}
-
-function getUpgradeStatus(name: string): 'current' | 'upgrading' | 'available' | 'failed' {
-  if (name === 'prow') return 'available'
-  if (name === 'oci') return 'available'
-  return 'current'
-}
-
-function getUpgradeProgress(_name: string): number {
-  return 0
-}
@@ -44,6 +44,7 @@ import { GPUInventory } from '../cards/GPUInventory'
 import { GPUStatus } from '../cards/GPUStatus'
 import { GPUOverview } from '../cards/GPUOverview'
 import { SecurityIssues } from '../cards/SecurityIssues'
+import { PolicyViolations } from '../cards/PolicyViolations'
 // Cluster-scoped cards
 import { ClusterFocus } from '../cards/ClusterFocus'
 import { ClusterComparison } from '../cards/ClusterComparison'
@@ -51,6 +52,8 @@ import { ClusterCosts } from '../cards/ClusterCosts'
 import { ClusterNetwork } from '../cards/ClusterNetwork'
 // Namespace-scoped cards
 import { NamespaceOverview } from '../cards/NamespaceOverview'
+import { NamespaceAnalysis } from '../cards/NamespaceAnalysis'
+import { RBACOverview } from '../cards/RBACOverview'
 import { NamespaceQuotas } from '../cards/NamespaceQuotas'
 import { NamespaceRBAC } from '../cards/NamespaceRBAC'
 import { NamespaceEvents } from '../cards/NamespaceEvents'
@@ -114,6 +117,8 @@ const CARD_COMPONENTS: Record<string, React.ComponentType<{ config?: Record<stri
   gpu_status: GPUStatus,
   gpu_overview: GPUOverview,
   security_issues: SecurityIssues,
+  policy_violations: PolicyViolations,
+  rbac_overview: RBACOverview,
   // Cluster-scoped cards
   cluster_focus: ClusterFocus,
   cluster_comparison: ClusterComparison,
@@ -121,6 +126,7 @@ const CARD_COMPONENTS: Record<string, React.ComponentType<{ config?: Record<stri
   cluster_network: ClusterNetwork,
   // Namespace-scoped cards
   namespace_overview: NamespaceOverview,
+  namespace_analysis: NamespaceAnalysis,
   namespace_quotas: NamespaceQuotas,
   namespace_rbac: NamespaceRBAC,
   namespace_events: NamespaceEvents,
@@ -1,5 +1,5 @@
 import { useState, useEffect, useCallback } from 'react'
-import { api } from '../lib/api'
+import { api, type ListResponse } from '../lib/api'
 
 // Types matching the backend MCP bridge
 export interface ClusterInfo {
@@ -45,6 +45,7 @@ export interface PodInfo {
   restarts: number
   age: string
   node?: string
+  createdAt?: string
 }
 
 export interface PodIssue {
@@ -381,18 +382,11 @@ export function usePods(cluster?: string, namespace?: string, sortBy: 'restarts'
       const params = new URLSearchParams()
       if (cluster) params.append('cluster', cluster)
       if (namespace) params.append('namespace', namespace)
-      const { data } = await api.get<{ pods: PodInfo[] }>(`/api/mcp/pods?${params}`)
-      let sortedPods = data.pods || []
-
-      // Sort by restarts (descending) or name
-      if (sortBy === 'restarts') {
-        sortedPods = sortedPods.sort((a, b) => b.restarts - a.restarts)
-      } else {
-        sortedPods = sortedPods.sort((a, b) => a.name.localeCompare(b.name))
-      }
-
-      // Limit results
-      setPods(sortedPods.slice(0, limit))
+      // Sort by restarts (descending) or name, server side
+      params.append('sort', sortBy === 'restarts' ? '-restarts' : 'name')
+      params.append('limit', limit.toString())
+      const { data } = await api.get<ListResponse<PodInfo>>(`/api/mcp/pods?${params}`)
+      setPods(data.items || [])
       setError(null)
       setLastUpdated(new Date())
     } catch (err) {
@@ -466,8 +460,8 @@ export function useEvents(cluster?: string, namespace?: string, limit = 20) {
       if (cluster) params.append('cluster', cluster)
       if (namespace) params.append('namespace', namespace)
       params.append('limit', limit.toString())
-      const { data } = await api.get<{ events: ClusterEvent[] }>(`/api/mcp/events?${params}`)
-      setEvents(data.events || [])
+      const { data } = await api.get<ListResponse<ClusterEvent>>(`/api/mcp/events?${params}`)
+      setEvents(data.items || [])
       setError(null)
       setLastUpdated(new Date())
     } catch (err) {
@@ -583,8 +577,8 @@ export function useWarningEvents(cluster?: string, namespace?: string, limit = 2
       if (cluster) params.append('cluster', cluster)
       if (namespace) params.append('namespace', namespace)
       params.append('limit', limit.toString())
-      const { data } = await api.get<{ events: ClusterEvent[] }>(`/api/mcp/events/warnings?${params}`)
-      setEvents(data.events || [])
+      const { data } = await api.get<ListResponse<ClusterEvent>>(`/api/mcp/events/warnings?${params}`)
+      setEvents(data.items || [])
       setError(null)
       setLastUpdated(new Date())
     } catch (err) {
@@ -637,6 +631,622 @@ export function useGPUNodes(cluster?: string) {
   return { nodes, isLoading, error, refetch }
 }
 
+// Resource amounts in base units (bytes, counts); CPU in millicores
+export interface ResourceAmounts {
+  capacity?: number
+  allocatable?: number
+  requested: number
+  limits: number
+}
+
+export interface NodeCapacity {
+  name: string
+  cluster: string
+  ready: boolean
+  unschedulable?: boolean
+  roles?: string[]
+  instanceType?: string
+  pods: number
+  resources: Record<string, ResourceAmounts>
+}
+
+export interface NamespaceCapacity {
+  namespace: string
+  cluster: string
+  pods: number
+  resources: Record<string, ResourceAmounts>
+}
+
+export interface ClusterCapacity {
+  cluster: string
+  nodes: NodeCapacity[]
+  namespaces: NamespaceCapacity[]
+  pendingPods: number
+  totals: Record<string, ResourceAmounts>
+}
+
+// Hook to get node capacity against pod requests for all clusters
+export function useClusterCapacity() {
+  const [clusters, setClusters] = useState<ClusterCapacity[]>([])
+  const [isLoading, setIsLoading] = useState(true)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    setIsLoading(true)
+    try {
+      const { data } = await api.get<{ clusters: ClusterCapacity[] }>('/api/clusters/capacity')
+      setClusters(data.clusters || [])
+      setError(null)
+    } catch (err) {
+      setError('Failed to fetch cluster capacity')
+      setClusters([])
+    } finally {
+      setIsLoading(false)
+    }
+  }, [])
+
+  useEffect(() => {
+    refetch()
+  }, [refetch])
+
+  return { clusters, isLoading, error, refetch }
+}
+
+// Usage from metrics.k8s.io; CPU in millicores, memory in bytes
+export interface ResourceUsage {
+  usage: number
+  request: number
+  limit: number
+  requestRatio?: number
+  limitRatio?: number
+}
+
+export interface PodUsage {
+  name: string
+  namespace: string
+  cluster: string
+  node?: string
+  status: string
+  restarts: number
+  cpu: ResourceUsage
+  memory: ResourceUsage
+}
+
+export type TopPodsBy = 'cpu' | 'memory' | 'restarts'
+
+// Hook to rank pods by CPU or memory usage or by restarts
+export function useTopPods(cluster?: string, namespace?: string, by: TopPodsBy = 'cpu', limit = 10) {
+  const [pods, setPods] = useState<PodUsage[]>([])
+  const [metricsUnavailable, setMetricsUnavailable] = useState<string[]>([])
+  const [isLoading, setIsLoading] = useState(true)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    setIsLoading(true)
+    try {
+      const params = new URLSearchParams()
+      if (cluster) params.append('cluster', cluster)
+      if (namespace) params.append('namespace', namespace)
+      params.append('by', by)
+      params.append('limit', limit.toString())
+      const { data } = await api.get<{ pods: PodUsage[]; metricsUnavailable: string[] }>(`/api/mcp/top-pods?${params}`)
+      setPods(data.pods || [])
+      setMetricsUnavailable(data.metricsUnavailable || [])
+      setError(null)
+    } catch (err) {
+      setError('Failed to fetch top pods')
+    } finally {
+      setIsLoading(false)
+    }
+  }, [cluster, namespace, by, limit])
+
+  useEffect(() => {
+    refetch()
+  }, [refetch])
+
+  return { pods, metricsUnavailable, isLoading, error, refetch }
+}
+
+// Policy engine violations (OPA Gatekeeper and Kyverno)
+export interface PolicyViolation {
+  engine: 'gatekeeper' | 'kyverno'
+  policy: string
+  rule?: string
+  cluster: string
+  namespace?: string
+  resource: string
+  message: string
+  enforcementAction: string
+  severity?: string
+}
+
+export interface PolicySummary {
+  engine: 'gatekeeper' | 'kyverno'
+  policy: string
+  enforcementAction: string
+  violations: number
+  clusters: string[]
+}
+
+// Hook to get policy violations with per-policy aggregates
+export function usePolicyViolations(cluster?: string, namespace?: string) {
+  const [violations, setViolations] = useState<PolicyViolation[]>([])
+  const [policies, setPolicies] = useState<PolicySummary[]>([])
+  const [engines, setEngines] = useState<Record<string, string[]>>({})
+  // Per cluster, per engine: what could not be read
+  const [engineErrors, setEngineErrors] = useState<Record<string, Record<string, string>>>({})
+  const [isLoading, setIsLoading] = useState(true)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    setIsLoading(true)
+    try {
+      const params = new URLSearchParams()
+      if (cluster) params.append('cluster', cluster)
+      if (namespace) params.append('namespace', namespace)
+      const { data } = await api.get<ListResponse<PolicyViolation> & {
+        policies: PolicySummary[]
+        engines: Record<string, string[]>
+        engineErrors?: Record<string, Record<string, string>>
+      }>(`/api/policy/violations?${params}`)
+      setViolations(data.items || [])
+      setPolicies(data.policies || [])
+      setEngines(data.engines || {})
+      setEngineErrors(data.engineErrors || {})
+      setError(null)
+    } catch (err) {
+      setError('Failed to fetch policy violations')
+    } finally {
+      setIsLoading(false)
+    }
+  }, [cluster, namespace])
+
+  useEffect(() => {
+    refetch()
+  }, [refetch])
+
+  return { violations, policies, engines, engineErrors, isLoading, error, refetch }
+}
+
+// Cluster upgrade status and version skew
+export type UpgradePhase = 'current' | 'pending' | 'in-progress'
+
+export interface NodeVersion {
+  name: string
+  cluster: string
+  kubeletVersion: string
+  containerRuntime: string
+  osImage?: string
+  kernelVersion?: string
+  ready: boolean
+  cordoned: boolean
+  minorSkew: number
+  skewSupported: boolean
+  skewReason?: string
+}
+
+export interface ClusterUpgradeStatus {
+  cluster: string
+  controlPlaneVersion: string
+  platform?: string
+  phase: UpgradePhase
+  nodes: NodeVersion[]
+  kubeletVersions: Record<string, number>
+  nodesUpgraded: number
+  nodesOutOfSkew: number
+  cordonedNodes: number
+}
+
+export function useUpgradeStatus() {
+  const [clusters, setClusters] = useState<ClusterUpgradeStatus[]>([])
+  const [phases, setPhases] = useState<Record<UpgradePhase, number>>({ current: 0, pending: 0, 'in-progress': 0 })
+  const [isLoading, setIsLoading] = useState(true)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    setIsLoading(true)
+    try {
+      const { data } = await api.get<{
+        clusters: ClusterUpgradeStatus[]
+        phases: Record<UpgradePhase, number>
+      }>('/api/clusters/upgrade')
+      setClusters(data.clusters || [])
+      setPhases(data.phases || { current: 0, pending: 0, 'in-progress': 0 })
+      setError(null)
+    } catch (err) {
+      setError('Failed to fetch upgrade status')
+      setClusters([])
+    } finally {
+      setIsLoading(false)
+    }
+  }, [])
+
+  useEffect(() => {
+    refetch()
+  }, [refetch])
+
+  return { clusters, phases, isLoading, error, refetch }
+}
+
+// Namespace deep analysis
+export interface WorkloadHealth {
+  kind: string
+  total: number
+  healthy: number
+  unhealthy?: string[]
+}
+
+export interface QuotaResource {
+  resource: string
+  hard: string
+  used: string
+  ratio: number
+}
+
+export interface NamespaceSubject {
+  kind: string
+  name: string
+  namespace?: string
+  role: string
+  roleKind: string
+  binding: string
+  clusterWide: boolean
+}
+
+export interface NamespaceAnalysis {
+  namespace: string
+  cluster: string
+  status: string
+  labels?: Record<string, string>
+  createdAt: string
+  workloads: WorkloadHealth[]
+  pods: { total: number; phases: Record<string, number>; notReady: number; restarts: number }
+  quotas: { name: string; resources: QuotaResource[] }[]
+  limitRanges: {
+    name: string
+    limits: { type: string; resource: string; min?: string; max?: string; default?: string; defaultRequest?: string }[]
+  }[]
+  pvcs: {
+    name: string
+    phase: string
+    storageClass?: string
+    volumeName?: string
+    accessModes?: string[]
+    requested?: string
+    capacity?: string
+  }[]
+  warningEvents: ClusterEvent[]
+  securityIssues: SecurityIssue[]
+  access: NamespaceSubject[]
+  networkPolicies: {
+    policies: string[]
+    defaultDenyIngress: boolean
+    defaultDenyEgress: boolean
+    podsCovered: number
+    podsTotal: number
+    uncovered?: string[]
+  }
+  errors?: Record<string, string>
+  analyzedAt: string
+}
+
+export function useNamespaceAnalysis(cluster?: string, namespace?: string) {
+  const [analysis, setAnalysis] = useState<NamespaceAnalysis | null>(null)
+  const [isLoading, setIsLoading] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const fetchAnalysis = useCallback(async (refresh: boolean) => {
+    if (!cluster || !namespace) {
+      setAnalysis(null)
+      return
+    }
+    setIsLoading(true)
+    try {
+      const params = new URLSearchParams({ cluster })
+      if (refresh) params.append('refresh', 'true')
+      const { data } = await api.get<NamespaceAnalysis>(
+        `/api/namespaces/${encodeURIComponent(namespace)}/analysis?${params}`
+      )
+      setAnalysis(data)
+      setError(null)
+    } catch (err) {
+      setError('Failed to analyze namespace')
+      setAnalysis(null)
+    } finally {
+      setIsLoading(false)
+    }
+  }, [cluster, namespace])
+
+  useEffect(() => {
+    fetchAnalysis(false)
+  }, [fetchAnalysis])
+
+  const refetch = useCallback(() => fetchAnalysis(true), [fetchAnalysis])
+
+  return { analysis, isLoading, error, refetch }
+}
+
+// Applications grouped from workloads across clusters
+export type AppHealth = 'healthy' | 'progressing' | 'degraded'
+
+export interface AppWorkload {
+  kind: string
+  name: string
+  namespace: string
+  cluster: string
+  desired: number
+  ready: number
+  updated: number
+  health: AppHealth
+  images: string[]
+}
+
+export interface Application {
+  name: string
+  source: 'kubestellar' | 'part-of' | 'instance' | 'name' | 'helm'
+  // Empty for KubeStellar apps, which may span namespaces
+  namespace?: string
+  health: AppHealth
+  status: Record<AppHealth, number>
+  clusters: string[]
+  clusterDetails: {
+    cluster: string
+    health: AppHealth
+    namespaces: string[]
+    workloads: AppWorkload[]
+    images: Record<string, string[]>
+  }[]
+  workloads: number
+  imageDrift: { image: string; versions: Record<string, string>; driftedClusters: string[] }[]
+  drifted: boolean
+}
+
+export function useApps() {
+  const [apps, setApps] = useState<Application[]>([])
+  const [isLoading, setIsLoading] = useState(true)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    setIsLoading(true)
+    try {
+      const { data } = await api.get<ListResponse<Application>>('/api/apps')
+      setApps(data.items || [])
+      setError(null)
+    } catch (err) {
+      setError('Failed to fetch applications')
+      setApps([])
+    } finally {
+      setIsLoading(false)
+    }
+  }, [])
+
+  useEffect(() => {
+    refetch()
+  }, [refetch])
+
+  return { apps, isLoading, error, refetch }
+}
+
+// Workload rollouts (Deployments, StatefulSets and DaemonSets)
+export type RolloutPhase = 'complete' | 'progressing' | 'paused' | 'stalled'
+
+export interface RolloutStatus {
+  kind: 'Deployment' | 'StatefulSet' | 'DaemonSet'
+  name: string
+  namespace: string
+  cluster: string
+  revision: number
+  desired: number
+  updated: number
+  ready: number
+  available: number
+  progress: number
+  paused: boolean
+  phase: RolloutPhase
+  message?: string
+  images: string[]
+}
+
+export interface WorkloadRevision {
+  revision: number
+  name: string
+  images: string[]
+  changeCause?: string
+  createdAt: string
+  replicas: number
+  current: boolean
+}
+
+export type RolloutAction = 'rollback' | 'pause' | 'resume' | 'restart'
+
+const rolloutResources: Record<RolloutStatus['kind'], string> = {
+  Deployment: 'deployments',
+  StatefulSet: 'statefulsets',
+  DaemonSet: 'daemonsets',
+}
+
+const rolloutKey = (r: Pick<RolloutStatus, 'cluster' | 'kind' | 'namespace' | 'name'>) =>
+  `${r.cluster}/${r.kind}/${r.namespace}/${r.name}`
+
+// Hook to track rollouts, with live progress from rollout_progress messages
+export function useRollouts(cluster?: string, namespace?: string) {
+  const [rollouts, setRollouts] = useState<RolloutStatus[]>([])
+  const [isLoading, setIsLoading] = useState(true)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    try {
+      const params = new URLSearchParams()
+      if (cluster) params.append('cluster', cluster)
+      if (namespace) params.append('namespace', namespace)
+      const { data } = await api.get<ListResponse<RolloutStatus>>(`/api/rollouts?${params}`)
+      setRollouts(data.items || [])
+      setError(null)
+    } catch (err) {
+      setError('Failed to fetch rollouts')
+      setRollouts([])
+    } finally {
+      setIsLoading(false)
+    }
+  }, [cluster, namespace])
+
+  useEffect(() => {
+    refetch()
+
+    const isLocalhost = window.location.hostname === 'localhost' || window.location.hostname === '127.0.0.1'
+    if (!isLocalhost) {
+      return
+    }
+
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
+    let ws: WebSocket | null = null
+    let reconnectTimeout: ReturnType<typeof setTimeout>
+    let closed = false
+
+    const connect = () => {
+      ws = new WebSocket(`${protocol}//localhost:8080/ws`)
+
+      ws.onmessage = (event) => {
+        try {
+          const message = JSON.parse(event.data)
+          if (message.type !== 'rollout_progress') return
+          const update = message.data as RolloutStatus
+          if (cluster && update.cluster !== cluster) return
+          if (namespace && update.namespace !== namespace) return
+          setRollouts((prev) => {
+            const key = rolloutKey(update)
+            const i = prev.findIndex((r) => rolloutKey(r) === key)
+            if (i < 0) return [...prev, update]
+            const next = [...prev]
+            next[i] = update
+            return next
+          })
+        } catch (e) {
+          // Ignore non-JSON messages
+        }
+      }
+
+      ws.onclose = () => {
+        if (!closed) reconnectTimeout = setTimeout(connect, 5000)
+      }
+
+      ws.onerror = () => {
+        ws?.close()
+      }
+    }
+
+    connect()
+
+    return () => {
+      closed = true
+      clearTimeout(reconnectTimeout)
+      ws?.close()
+    }
+  }, [refetch, cluster, namespace])
+
+  return { rollouts, isLoading, error, refetch }
+}
+
+// Get a workload's rollout status and revision history
+export async function getRolloutHistory(rollout: RolloutStatus) {
+  const resource = rolloutResources[rollout.kind]
+  const { data } = await api.get<{ rollout: RolloutStatus; revisions: WorkloadRevision[] }>(
+    `/api/${resource}/${rollout.namespace}/${rollout.name}/rollout?cluster=${encodeURIComponent(rollout.cluster)}`
+  )
+  return data
+}
+
+// Roll back, pause, resume or restart a workload's rollout. Rollback goes
+// to the previous revision unless one is given.
+export async function runRolloutAction(rollout: RolloutStatus, action: RolloutAction, revision?: number) {
+  const resource = rolloutResources[rollout.kind]
+  const params = new URLSearchParams({ cluster: rollout.cluster })
+  if (revision !== undefined) params.append('revision', String(revision))
+  const { data } = await api.post<{ success: boolean; revision?: number; rollout?: RolloutStatus }>(
+    `/api/${resource}/${rollout.namespace}/${rollout.name}/${action}?${params}`
+  )
+  return data
+}
+
+// RBAC analysis: effective permissions and risky grants per subject
+export type RBACRiskSeverity = 'critical' | 'high' | 'medium' | 'low'
+
+export interface PolicyRule {
+  verbs: string[]
+  apiGroups?: string[]
+  resources?: string[]
+  resourceNames?: string[]
+  nonResourceURLs?: string[]
+}
+
+export interface RBACGrant {
+  binding: string
+  bindingKind: 'RoleBinding' | 'ClusterRoleBinding'
+  namespace?: string
+  roleKind: 'Role' | 'ClusterRole'
+  roleName: string
+  rules: PolicyRule[]
+}
+
+export interface SubjectPermissions {
+  kind: 'User' | 'Group' | 'ServiceAccount'
+  name: string
+  namespace?: string
+  grants: RBACGrant[]
+  risks: { severity: RBACRiskSeverity; reason: string; binding: string; namespace?: string; rule: PolicyRule }[]
+  riskScore: number
+  riskLevel: RBACRiskSeverity
+}
+
+export interface RBACAnalysis {
+  cluster: string
+  subjects: SubjectPermissions[]
+  orphaned: { binding: string; bindingKind: string; namespace?: string; missing: string }[]
+  summary: {
+    subjects: number
+    roles: number
+    clusterRoles: number
+    bindings: number
+    critical: number
+    high: number
+    medium: number
+    orphaned: number
+  }
+}
+
+// Hook to get the RBAC analysis of one cluster
+export function useRBACAnalysis(cluster?: string, includeSystem = false) {
+  const [analysis, setAnalysis] = useState<RBACAnalysis | null>(null)
+  const [isLoading, setIsLoading] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    if (!cluster) {
+      setAnalysis(null)
+      return
+    }
+    setIsLoading(true)
+    try {
+      const params = new URLSearchParams({ cluster })
+      if (includeSystem) params.append('includeSystem', 'true')
+      const { data } = await api.get<RBACAnalysis>(`/api/rbac/analysis?${params}`)
+      setAnalysis(data)
+      setError(null)
+    } catch (err) {
+      setError('Failed to analyze RBAC')
+      setAnalysis(null)
+    } finally {
+      setIsLoading(false)
+    }
+  }, [cluster, includeSystem])
+
+  useEffect(() => {
+    refetch()
+  }, [refetch])
+
+  return { analysis, isLoading, error, refetch }
+}
+
 // Security issue types
 export interface SecurityIssue {
   name: string
@@ -689,6 +1299,150 @@ export function useSecurityIssues(cluster?: string, namespace?: string) {
   return { issues, isLoading, isRefreshing, lastUpdated, error, refetch }
 }
 
+// Pod Security Standards types
+export type PSSProfile = 'baseline' | 'restricted'
+
+export interface PSSViolation {
+  ruleId: string
+  profile: PSSProfile
+  severity: 'critical' | 'high' | 'medium'
+  title: string
+  remediation: string
+  cluster: string
+  namespace: string
+  kind: string
+  name: string
+  pods: number
+  details: string[]
+  suppressed: boolean
+}
+
+export interface PSSProfileScore {
+  compliant: number
+  score: number
+}
+
+export interface PSSNamespacePosture {
+  namespace: string
+  enforced?: PSSProfile | 'privileged'
+  workloads: number
+  baseline: PSSProfileScore
+  restricted: PSSProfileScore
+  violations: number
+  suppressed: number
+}
+
+export interface PSSPosture {
+  cluster: string
+  namespaces: PSSNamespacePosture[]
+  violations: PSSViolation[]
+  baseline: PSSProfileScore
+  restricted: PSSProfileScore
+}
+
+// Hook to evaluate pods of a cluster against Pod Security Standards
+export function usePodSecurityPosture(cluster?: string, namespace?: string) {
+  const [posture, setPosture] = useState<PSSPosture | null>(null)
+  const [isLoading, setIsLoading] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    if (!cluster) {
+      setPosture(null)
+      return
+    }
+    setIsLoading(true)
+    try {
+      const params = new URLSearchParams({ cluster })
+      if (namespace) params.append('namespace', namespace)
+      const { data } = await api.get<PSSPosture>(`/api/security/posture?${params}`)
+      setPosture(data)
+      setError(null)
+    } catch (err) {
+      setError('Failed to evaluate pod security')
+      setPosture(null)
+    } finally {
+      setIsLoading(false)
+    }
+  }, [cluster, namespace])
+
+  useEffect(() => {
+    refetch()
+  }, [refetch])
+
+  return { posture, isLoading, error, refetch }
+}
+
+// Secret hygiene types
+export type SecretFindingType =
+  | 'legacy-sa-token'
+  | 'automount-powerful-sa'
+  | 'unused-service-account'
+  | 'missing-pull-secret'
+  | 'unused-pull-secret'
+  | 'tls-expiring'
+  | 'tls-expired'
+  | 'tls-invalid'
+
+export interface SecretFinding {
+  type: SecretFindingType
+  severity: RBACRiskSeverity
+  kind: 'Secret' | 'ServiceAccount'
+  namespace: string
+  name: string
+  message: string
+  remediation: string
+  expiresAt?: string
+  referencedBy?: string[]
+}
+
+export interface SecretHygieneReport {
+  cluster: string
+  expiryDays: number
+  findings: SecretFinding[]
+  summary: {
+    secrets: number
+    serviceAccounts: number
+    critical: number
+    high: number
+    medium: number
+    low: number
+  }
+}
+
+// Hook to scan secrets and service accounts of a cluster for hygiene problems
+export function useSecretHygiene(cluster?: string, expiryDays = 30, includeSystem = false) {
+  const [report, setReport] = useState<SecretHygieneReport | null>(null)
+  const [isLoading, setIsLoading] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const refetch = useCallback(async () => {
+    if (!cluster) {
+      setReport(null)
+      return
+    }
+    setIsLoading(true)
+    try {
+      const params = new URLSearchParams({ cluster, days: String(expiryDays) })
+      if (includeSystem) params.append('includeSystem', 'true')
+      const { data } = await api.get<SecretHygieneReport>(`/api/security/secrets?${params}`)
+      setReport(data)
+      setError(null)
+    } catch (err) {
+      setError('Failed to scan secrets')
+      setReport(null)
+    } finally {
+      setIsLoading(false)
+    }
+  }, [cluster, expiryDays, includeSystem])
+
+  useEffect(() => {
+    refetch()
+  }, [refetch])
+
+  return { report, isLoading, error, refetch }
+}
+
 // GitOps drift types
 export interface GitOpsDrift {
   resource: string
@@ -989,9 +1743,9 @@ export function useNamespaces(cluster?: string) {
     setIsLoading(true)
     try {
       // Fetch pods for the cluster to get namespaces
-      const { data } = await api.get<{ pods: PodInfo[] }>(`/api/mcp/pods?cluster=${encodeURIComponent(cluster)}`)
+      const { data } = await api.get<ListResponse<PodInfo>>(`/api/mcp/pods?cluster=${encodeURIComponent(cluster)}`)
       const nsSet = new Set<string>()
-      data.pods?.forEach(pod => {
+      data.items?.forEach(pod => {
         if (pod.namespace) nsSet.add(pod.namespace)
       })
       // Sort and set namespaces
@@ -1,5 +1,5 @@
 import { useState, useEffect, useCallback } from 'react'
-import { api } from '../lib/api'
+import { api, type ListResponse } from '../lib/api'
 import type {
   ConsoleUser,
   K8sServiceAccount,
@@ -25,8 +25,8 @@ export function useConsoleUsers() {
     setIsLoading(true)
     setError(null)
     try {
-      const { data } = await api.get<ConsoleUser[]>('/api/users')
-      setUsers(data || [])
+      const { data } = await api.get<ListResponse<ConsoleUser>>('/api/users')
+      setUsers(data.items || [])
     } catch (err) {
       setError('Failed to load users')
       console.error('Failed to load users:', err)
@@ -116,8 +116,8 @@ export function useK8sUsers(cluster?: string) {
     setIsLoading(true)
     setError(null)
     try {
-      const { data } = await api.get<K8sUser[]>(`/api/rbac/users?cluster=${cluster}`)
-      setUsers(data || [])
+      const { data } = await api.get<ListResponse<K8sUser>>(`/api/rbac/users?cluster=${cluster}`)
+      setUsers(data.items || [])
     } catch (err) {
       setError('Failed to load K8s users')
       console.error('Failed to load K8s users:', err)
@@ -148,8 +148,8 @@ export function useK8sServiceAccounts(cluster?: string, namespace?: string) {
       const params = new URLSearchParams()
       if (cluster) params.set('cluster', cluster)
       if (namespace) params.set('namespace', namespace)
-      const { data } = await api.get<K8sServiceAccount[]>(`/api/rbac/service-accounts?${params}`)
-      setServiceAccounts(data || [])
+      const { data } = await api.get<ListResponse<K8sServiceAccount>>(`/api/rbac/service-accounts?${params}`)
+      setServiceAccounts(data.items || [])
     } catch (err) {
       setError('Failed to load service accounts')
       console.error('Failed to load service accounts:', err)
@@ -199,8 +199,8 @@ export function useK8sRoles(cluster: string, namespace?: string, includeSystem?:
       const params = new URLSearchParams({ cluster })
       if (namespace) params.set('namespace', namespace)
       if (includeSystem) params.set('includeSystem', 'true')
-      const { data } = await api.get<K8sRole[]>(`/api/rbac/roles?${params}`)
-      setRoles(data || [])
+      const { data } = await api.get<ListResponse<K8sRole>>(`/api/rbac/roles?${params}`)
+      setRoles(data.items || [])
     } catch (err) {
       setError('Failed to load roles')
       console.error('Failed to load roles:', err)
@@ -233,8 +233,8 @@ export function useK8sRoleBindings(cluster: string, namespace?: string, includeS
       const params = new URLSearchParams({ cluster })
       if (namespace) params.set('namespace', namespace)
       if (includeSystem) params.set('includeSystem', 'true')
-      const { data } = await api.get<K8sRoleBinding[]>(`/api/rbac/bindings?${params}`)
-      setBindings(data || [])
+      const { data } = await api.get<ListResponse<K8sRoleBinding>>(`/api/rbac/bindings?${params}`)
+      setBindings(data.items || [])
     } catch (err) {
       setError('Failed to load role bindings')
       console.error('Failed to load role bindings:', err)
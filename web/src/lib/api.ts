@@ -69,4 +69,12 @@ class ApiClient {
   }
 }
 
+// Envelope of paginated list endpoints (limit, continue, sort,
+// labelSelector, fieldSelector and q query parameters)
+export interface ListResponse<T> {
+  items: T[]
+  continue?: string
+  total?: number
+}
+
 export const api = new ApiClient()
@@ -241,14 +241,13 @@ export const handlers = [
   // Events
   http.get('/api/mcp/events', async () => {
     await delay(100)
-    return HttpResponse.json({ events: demoEvents })
+    return HttpResponse.json({ items: demoEvents, total: demoEvents.length })
   }),
 
   http.get('/api/mcp/events/warnings', async () => {
     await delay(100)
-    return HttpResponse.json({
-      events: demoEvents.filter((e) => e.type === 'Warning'),
-    })
+    const warnings = demoEvents.filter((e) => e.type === 'Warning')
+    return HttpResponse.json({ items: warnings, total: warnings.length })
   }),
 
   // GPU nodes
@@ -457,7 +456,7 @@ export const scenarios = {
     }),
     http.get('/api/mcp/events/warnings', async () => {
       await delay(100)
-      return HttpResponse.json({ events: [] })
+      return HttpResponse.json({ items: [], total: 0 })
     }),
   ],
 